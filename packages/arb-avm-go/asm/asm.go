@@ -0,0 +1,419 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+type nodeKind int
+
+const (
+	nodeInt nodeKind = iota
+	nodeTuple
+	// nodeCodeRef is a code point abbreviated as @pc
+	nodeCodeRef
+	nodeCodePoint
+)
+
+// node is a value whose code points haven't been resolved yet
+type node struct {
+	kind     nodeKind
+	num      *big.Int
+	children []*node
+	pc       int64
+	op       *opNode
+	nextHash common.Hash
+}
+
+type opNode struct {
+	op        value.Opcode
+	immediate *node
+}
+
+var maxInt = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+func tokenize(line string) []string {
+	var tokens []string
+	start := -1
+	for i, c := range line {
+		if strings.ContainsRune("(),:@", c) || c == ' ' || c == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, line[start:i])
+				start = -1
+			}
+			if c != ' ' && c != '\t' {
+				tokens = append(tokens, string(c))
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, line[start:])
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("unexpected end of line")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *parser) expect(tok string) error {
+	next, err := p.next()
+	if err != nil {
+		return err
+	}
+	if next != tok {
+		return fmt.Errorf("expected %v but found %v", tok, next)
+	}
+	return nil
+}
+
+func (p *parser) done() error {
+	if p.pos < len(p.tokens) {
+		return fmt.Errorf("unexpected %v", p.tokens[p.pos])
+	}
+	return nil
+}
+
+func parseNumber(tok string) (*big.Int, error) {
+	num, ok := new(big.Int).SetString(tok, 0)
+	if !ok {
+		return nil, fmt.Errorf("expected a number but found %v", tok)
+	}
+	return num, nil
+}
+
+func (p *parser) int64() (int64, error) {
+	tok, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	num, err := strconv.ParseInt(tok, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number but found %v", tok)
+	}
+	return num, nil
+}
+
+func (p *parser) value() (*node, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case "(":
+		n := &node{kind: nodeTuple}
+		if p.peek() == ")" {
+			p.pos++
+			return n, nil
+		}
+		for {
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+			sep, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if sep == ")" {
+				return n, nil
+			}
+			if sep != "," {
+				return nil, fmt.Errorf("expected , or ) but found %v", sep)
+			}
+		}
+	case "@":
+		pc, err := p.int64()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nodeCodeRef, pc: pc}, nil
+	case "CodePoint":
+		return p.codePoint()
+	default:
+		num, err := parseNumber(tok)
+		if err != nil {
+			return nil, err
+		}
+		if num.Sign() < 0 || num.Cmp(maxInt) > 0 {
+			return nil, fmt.Errorf("integer %v doesn't fit in 256 bits", tok)
+		}
+		return &node{kind: nodeInt, num: num}, nil
+	}
+}
+
+func (p *parser) codePoint() (*node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	pc, err := p.int64()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	op, err := p.operation(",")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	hashTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	hashBytes, err := hexutil.Decode(hashTok)
+	if err != nil || len(hashBytes) != 32 {
+		return nil, fmt.Errorf("expected a 32 byte hash but found %v", hashTok)
+	}
+	n := &node{kind: nodeCodePoint, pc: pc, op: op}
+	copy(n.nextHash[:], hashBytes)
+	return n, p.expect(")")
+}
+
+// operation parses a mnemonic followed by an optional immediate value which
+// continues until the end of the line or the terminator token
+func (p *parser) operation(terminator string) (*opNode, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var op value.Opcode
+	if strings.HasPrefix(name, "0x") {
+		num, err := strconv.ParseUint(name[2:], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opcode %v", name)
+		}
+		op = value.Opcode(num)
+	} else {
+		var ok bool
+		op, ok = code.OpcodeFromName(strings.ToLower(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown instruction %v", name)
+		}
+	}
+	ret := &opNode{op: op}
+	if next := p.peek(); next != "" && next != terminator {
+		ret.immediate, err = p.value()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// resolver builds values from nodes once the code points they refer to are
+// known
+type resolver struct {
+	codePoints []value.CodePointValue
+}
+
+// value builds the value of n. Abbreviated code points must refer to an
+// instruction after minPC, all of which must already be resolved
+func (r *resolver) value(n *node, minPC int64) (value.Value, error) {
+	switch n.kind {
+	case nodeInt:
+		return value.NewIntValue(n.num), nil
+	case nodeTuple:
+		vals := make([]value.Value, 0, len(n.children))
+		for _, child := range n.children {
+			val, err := r.value(child, minPC)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return value.NewTupleFromSlice(vals)
+	case nodeCodeRef:
+		if n.pc < 0 || n.pc >= int64(len(r.codePoints)) {
+			return nil, fmt.Errorf("@%v is outside of the program", n.pc)
+		}
+		if n.pc <= minPC {
+			return nil, fmt.Errorf("@%v refers to an earlier instruction and must be written as CodePoint(pc, op, nextHash)", n.pc)
+		}
+		return r.codePoints[n.pc], nil
+	default:
+		op, err := r.operation(n.op, minPC)
+		if err != nil {
+			return nil, err
+		}
+		return value.CodePointValue{InsnNum: n.pc, Op: op, NextHash: n.nextHash}, nil
+	}
+}
+
+func (r *resolver) operation(n *opNode, minPC int64) (value.Operation, error) {
+	if n.immediate == nil {
+		return value.BasicOperation{Op: n.op}, nil
+	}
+	val, err := r.value(n.immediate, minPC)
+	if err != nil {
+		return nil, err
+	}
+	return value.ImmediateOperation{Op: n.op, Val: val}, nil
+}
+
+// Assemble reads a program in the format written by Disassemble
+func Assemble(rd io.Reader) (*goloader.Program, error) {
+	program := &goloader.Program{
+		Version:    goloader.CurrentAOVersion,
+		Extensions: make([]goloader.RawExtension, 0),
+	}
+	var static *node
+	var ops []*opNode
+	var opLines []int
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		p := &parser{tokens: tokenize(line)}
+		if len(p.tokens) == 0 {
+			continue
+		}
+		err := func() error {
+			switch p.peek() {
+			case ".version":
+				p.pos++
+				version, err := p.int64()
+				if err != nil {
+					return err
+				}
+				program.Version = uint32(version)
+			case ".extension":
+				p.pos++
+				id, err := p.int64()
+				if err != nil {
+					return err
+				}
+				dataTok, err := p.next()
+				if err != nil {
+					return err
+				}
+				data, err := hexutil.Decode(dataTok)
+				if err != nil {
+					return fmt.Errorf("invalid extension data %v: %v", dataTok, err)
+				}
+				program.Extensions = append(program.Extensions, goloader.RawExtension{
+					ID:   uint32(id),
+					Data: data,
+				})
+			case ".static":
+				p.pos++
+				if static != nil {
+					return errors.New("static value is set more than once")
+				}
+				var err error
+				static, err = p.value()
+				if err != nil {
+					return err
+				}
+			default:
+				if len(p.tokens) > 1 && p.tokens[1] == ":" {
+					pc, err := p.int64()
+					if err != nil {
+						return err
+					}
+					p.pos++
+					if pc != int64(len(ops)) {
+						return fmt.Errorf("instruction is labelled pc %v but is at pc %v", pc, len(ops))
+					}
+				}
+				op, err := p.operation("")
+				if err != nil {
+					return err
+				}
+				ops = append(ops, op)
+				opLines = append(opLines, lineNum)
+			}
+			return p.done()
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Each code point's hash depends on the code after it so instructions
+	// are built from last to first
+	r := &resolver{codePoints: make([]value.CodePointValue, len(ops))}
+	program.Code = make([]value.Operation, len(ops))
+	nextHash := vm.HashOfLastInstruction
+	for pc := len(ops) - 1; pc >= 0; pc-- {
+		op, err := r.operation(ops[pc], int64(pc))
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", opLines[pc], err)
+		}
+		program.Code[pc] = op
+		r.codePoints[pc] = value.CodePointValue{InsnNum: int64(pc), Op: op, NextHash: nextHash}
+		nextHash = r.codePoints[pc].Hash()
+	}
+
+	if static == nil {
+		program.Static = value.NewEmptyTuple()
+	} else {
+		var err error
+		program.Static, err = r.value(static, -1)
+		if err != nil {
+			return nil, fmt.Errorf("static value: %v", err)
+		}
+	}
+	return program, nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+func TestRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../../arb-validator/proofmachine/opcodetest*.ao")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "../../arb-validator/contract.ao")
+	for _, fileName := range files {
+		t.Run(filepath.Base(fileName), func(t *testing.T) {
+			data, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			program, err := goloader.ReadProgram(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var text bytes.Buffer
+			if err := Disassemble(&text, program); err != nil {
+				t.Fatal(err)
+			}
+			assembled, err := Assemble(&text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := assembled.Marshal(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Error("assembled program differs from the original file")
+			}
+		})
+	}
+}
+
+const testProgram = `
+; Pushes a value, calls a function which adds 2 to it and logs the result
+.version 1
+.extension 7 0xabcd
+.static (1, 0x10000000000000000, @4)
+
+nop 3
+0x3b @3       ; push the return address
+jump @5
+log
+halt
+; the function
+1: nop        ; labelled wrong on purpose
+`
+
+func TestAssemble(t *testing.T) {
+	if _, err := Assemble(strings.NewReader(testProgram)); err == nil || !strings.Contains(err.Error(), "line 13") {
+		t.Fatal("expected wrong pc label to fail on line 13, got", err)
+	}
+
+	source := strings.Replace(testProgram, "1: nop        ; labelled wrong on purpose", "5: swap1\nadd 2\nswap1\njump", 1)
+	program, err := Assemble(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(program.Code) != 9 || len(program.Extensions) != 1 || program.Extensions[0].ID != 7 {
+		t.Fatal("unexpected program", program)
+	}
+
+	mach := vm.NewMachine(program.Code, program.Static, false, 100)
+	ctx := &logContext{}
+	mach.SetContext(ctx)
+	for i := 0; i < 10 && !mach.IsHalted(); i++ {
+		if _, blocked := vm.RunInstruction(mach, mach.GetOperation()); blocked != nil {
+			t.Fatal("machine blocked", blocked)
+		}
+	}
+	if !mach.IsHalted() || len(ctx.logs) != 1 || !value.Eq(ctx.logs[0], value.NewInt64Value(5)) {
+		t.Fatal("program didn't log 5", ctx.logs)
+	}
+
+	// The code points in the program should match the ones the machine uses
+	static := program.Static.(value.TupleValue)
+	staticCodePoint, _ := static.GetByInt64(2)
+	mach.SetPC(staticCodePoint)
+	if mach.GetPC().Hash() != staticCodePoint.Hash() {
+		t.Error("static code point doesn't match the machine")
+	}
+
+	var text bytes.Buffer
+	if err := Disassemble(&text, program); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{".static (1, 0x10000000000000000, @4)", "1: nop @3", "2: jump @5", "8: jump"} {
+		if !strings.Contains(text.String(), line+"\n") {
+			t.Errorf("disassembly is missing %v:\n%v", line, text.String())
+		}
+	}
+}
+
+func TestAssembleBackwardReference(t *testing.T) {
+	_, err := Assemble(strings.NewReader("nop\njump @0\n"))
+	if err == nil || !strings.Contains(err.Error(), "must be written as CodePoint") {
+		t.Fatal("expected backward reference to be rejected, got", err)
+	}
+
+	program, err := Assemble(strings.NewReader("halt\njump CodePoint(0, halt, 0x0000000000000000000000000000000000000000000000000000000000000000)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text bytes.Buffer
+	if err := Disassemble(&text, program); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "1: jump CodePoint(0, halt, 0x0000") {
+		t.Error("backward reference should be written in full", text.String())
+	}
+}
+
+type logContext struct {
+	vm.NoContext
+	logs []value.Value
+}
+
+func (c *logContext) LoggedValue(val value.Value) {
+	c.logs = append(c.logs, val)
+}
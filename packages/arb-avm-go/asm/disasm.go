@@ -0,0 +1,204 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package asm converts AO programs to and from a textual assembly format.
+//
+// A program is written as one directive or instruction per line, with
+// comments starting with a semicolon:
+//
+//	.version 1
+//	.extension 2 0x0102
+//	.static (1, @3)
+//	0: nop 5
+//	1: jump @3
+//	2: halt
+//	3: jump
+//
+// The PC before each instruction is optional but must be correct when
+// present. Integers are decimal or 0x prefixed hex and tuples are written as
+// parenthesized lists. A code point is usually written as @pc, which stands
+// for the code point the machine would push for that instruction. Since the
+// hash of a code point depends on the code after it, @pc can only refer
+// forward from an instruction. Other code points are written out in full as
+// CodePoint(pc, op, nextHash)
+package asm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// codePoints returns the code point of every instruction in insns as
+// computed by the machine
+func codePoints(insns []value.Operation) []value.CodePointValue {
+	ret := make([]value.CodePointValue, len(insns))
+	nextHash := vm.HashOfLastInstruction
+	for i := len(insns) - 1; i >= 0; i-- {
+		ret[i] = value.CodePointValue{InsnNum: int64(i), Op: insns[i], NextHash: nextHash}
+		nextHash = ret[i].Hash()
+	}
+	return ret
+}
+
+func sameOperation(a, b value.Operation) bool {
+	var bufA, bufB bytes.Buffer
+	if err := value.MarshalOperation(a, &bufA); err != nil {
+		return false
+	}
+	if err := value.MarshalOperation(b, &bufB); err != nil {
+		return false
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
+
+type formatter struct {
+	codePoints []value.CodePointValue
+}
+
+// formatValue writes val in assembly syntax. Code points referring to
+// instructions after minPC are abbreviated if they match the code
+func (f *formatter) formatValue(sb *strings.Builder, val value.Value, minPC int64) error {
+	switch val := val.(type) {
+	case value.IntValue:
+		sb.WriteString(formatInt(val.BigInt()))
+	case value.TupleValue:
+		sb.WriteString("(")
+		for i, child := range val.Contents() {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if err := f.formatValue(sb, child, minPC); err != nil {
+				return err
+			}
+		}
+		sb.WriteString(")")
+	case value.CodePointValue:
+		pc := val.InsnNum
+		if pc > minPC && pc < int64(len(f.codePoints)) {
+			expected := f.codePoints[pc]
+			if expected.NextHash == val.NextHash && sameOperation(expected.Op, val.Op) {
+				fmt.Fprintf(sb, "@%v", pc)
+				return nil
+			}
+		}
+		fmt.Fprintf(sb, "CodePoint(%v, ", pc)
+		if err := f.formatOperation(sb, val.Op, minPC); err != nil {
+			return err
+		}
+		fmt.Fprintf(sb, ", %v)", val.NextHash)
+	default:
+		return fmt.Errorf("can't disassemble value %v of type %v", val, value.TypeCodeName(val.TypeCode()))
+	}
+	return nil
+}
+
+func (f *formatter) formatOperation(sb *strings.Builder, op value.Operation, minPC int64) error {
+	name, ok := code.InstructionNames[op.GetOp()]
+	if !ok {
+		name = fmt.Sprintf("0x%02x", byte(op.GetOp()))
+	}
+	sb.WriteString(name)
+	if immediate, ok := op.(value.ImmediateOperation); ok {
+		sb.WriteString(" ")
+		return f.formatValue(sb, immediate.Val, minPC)
+	}
+	return nil
+}
+
+func formatInt(val *big.Int) string {
+	if val.BitLen() <= 64 {
+		return val.String()
+	}
+	return "0x" + val.Text(16)
+}
+
+func describeExtension(ext goloader.Extension) string {
+	switch ext := ext.(type) {
+	case *goloader.CompilerInfo:
+		return fmt.Sprintf("compiled by %v", ext)
+	case *goloader.SourceMap:
+		return fmt.Sprintf("source map of %v files with %v entries", len(ext.Files), len(ext.Entries))
+	case *goloader.ContractABI:
+		return fmt.Sprintf("contract ABI with %v methods and %v events", len(ext.Methods), len(ext.Events))
+	default:
+		return fmt.Sprintf("%T", ext)
+	}
+}
+
+// Disassemble writes program in the format read by Assemble
+func Disassemble(w io.Writer, program *goloader.Program) error {
+	f := &formatter{codePoints: codePoints(program.Code)}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ".version %v\n", program.Version)
+	var sourceMap *goloader.SourceMap
+	for _, ext := range program.Extensions {
+		fmt.Fprintf(&sb, ".extension %v %v", ext.ID, hexutil.Encode(ext.Data))
+		decoded, known, err := ext.Decode()
+		switch {
+		case err != nil:
+			fmt.Fprintf(&sb, " ; %v", err)
+		case !known:
+			sb.WriteString(" ; unknown extension")
+		default:
+			fmt.Fprintf(&sb, " ; %v", describeExtension(decoded))
+			if decodedMap, ok := decoded.(*goloader.SourceMap); ok {
+				sourceMap = decodedMap
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(".static ")
+	// The static value isn't part of the code so it may refer to any
+	// instruction
+	if err := f.formatValue(&sb, program.Static, -1); err != nil {
+		return err
+	}
+	sb.WriteString("\n\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	lastLocation := ""
+	for pc, op := range program.Code {
+		sb.Reset()
+		fmt.Fprintf(&sb, "%v: ", pc)
+		if err := f.formatOperation(&sb, op, int64(pc)); err != nil {
+			return fmt.Errorf("pc %v: %v", pc, err)
+		}
+		// Only show source locations where they change to keep the listing
+		// readable
+		if sourceMap != nil {
+			if loc, ok := sourceMap.Locate(int64(pc)); ok && loc != lastLocation {
+				fmt.Fprintf(&sb, " ; %v", loc)
+				lastLocation = loc
+			}
+		}
+		sb.WriteString("\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
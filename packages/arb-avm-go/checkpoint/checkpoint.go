@@ -69,13 +69,10 @@ func NewCheckpointer(machine *vm.Machine, destroyOldCheckpoints bool) (*Checkpoi
 		}
 	}
 
-	opts := badger.DefaultOptions(defaultCheckpointPath)
-	opts.ValueDir = defaultCheckpointPath
-	db, err := badger.Open(opts)
+	ret, err := openCheckpointer(defaultCheckpointPath)
 	if err != nil {
 		return nil, err
 	}
-	ret := &Checkpointer{db, make(chan struct{})}
 	if machine != nil {
 		// TODO: save the code asynchronously; have machine checkpoints wait for completion
 		//  open question: how to handle errors in saving the code; probably best to just retry
@@ -83,6 +80,17 @@ func NewCheckpointer(machine *vm.Machine, destroyOldCheckpoints bool) (*Checkpoi
 			return nil, err
 		}
 	}
+	return ret, nil
+}
+
+func openCheckpointer(path string) (*Checkpointer, error) {
+	opts := badger.DefaultOptions(path)
+	opts.ValueDir = path
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Checkpointer{db, make(chan struct{})}
 
 	// start Badger garbage collector
 	go func() {
@@ -181,11 +189,11 @@ func (vcp *VersionedCheckpointer) saveState() error {
 }
 
 func vcpStateDataKey(versionNum int64) []byte {
-	return []byte("VersionedCheckpointer:stateData:" + string(versionNum))
+	return []byte("VersionedCheckpointer:stateData:" + string(rune(versionNum)))
 }
 
 func vcpMachineVersionKey(versionNum int64) string {
-	return "versioned:" + string(versionNum)
+	return "versioned:" + string(rune(versionNum))
 }
 
 func (vcp *VersionedCheckpointer) SaveVersion(machine *vm.Machine, stateData []byte) (versionNum int64, returnErr error) {
@@ -428,7 +436,7 @@ func (ecc *EventChainCheckpointer) Discard() error {
 }
 
 func (ecc *EventChainCheckpointer) eccKeyForSeqNum(seqNum uint64, kind string) []byte {
-	return append(ecc.fullKey, []byte(string(seqNum)+kind)...)
+	return append(ecc.fullKey, []byte(string(rune(seqNum))+kind)...)
 }
 
 func (ecc *EventChainCheckpointer) RecordIntentToSign(seqNum uint64, machine *vm.Machine, inbox value.Value) error {
@@ -147,46 +147,9 @@ func (cp *Checkpointer) RemoveRefToValue(hash common.Hash) {
 func (cp *Checkpointer) synchronousRemoveRefToValue(hash common.Hash) error {
 	var more []common.Hash
 	err := cp.db.Update(func(txn *badger.Txn) error {
-		key := append([]byte{PrefixValue}, hash[:]...)
-		item, err := txn.Get(key)
-		if err != nil {
-			return err
-		}
-
-		var refCount uint64
-		var valCopy []byte
-		if err := item.Value(func(val []byte) error {
-			valCopy = append([]byte{}, val...)
-			rd := bytes.NewReader(val[:8])
-			return binary.Read(rd, binary.LittleEndian, &refCount)
-		}); err != nil {
-			return err
-		}
-
-		refCount--
-		if refCount == 0 {
-			if err := txn.Delete(key); err != nil {
-				return err
-			}
-			if valCopy[8] == value.TypeCodeTuple {
-				size := int(valCopy[9])
-				rd := bytes.NewReader(valCopy[10:])
-				more = make([]common.Hash, size)
-				for i := 0; i < size; i++ {
-					h := common.Hash{}
-					if _, err := io.ReadFull(rd, h[:]); err != nil {
-						return err
-					}
-					more[i] = h
-				}
-			}
-			return nil
-		}
-		var buf bytes.Buffer
-		if err := binary.Write(&buf, binary.LittleEndian, &refCount); err != nil {
-			return err
-		}
-		return txn.Set(key, append(buf.Bytes(), valCopy[8:]...))
+		var err error
+		more, err = cp.removeRefToValueInTxn(txn, hash)
+		return err
 	})
 	if err != nil {
 		return err
@@ -197,6 +160,61 @@ func (cp *Checkpointer) synchronousRemoveRefToValue(hash common.Hash) error {
 	return nil
 }
 
+// removeRefToValueInTxn decrements the reference count of the value with the
+// given hash. If the value is deleted, it returns the hashes of the values it
+// referenced which must then have their own reference removed
+func (cp *Checkpointer) removeRefToValueInTxn(txn *badger.Txn, hash common.Hash) ([]common.Hash, error) {
+	key := append([]byte{PrefixValue}, hash[:]...)
+	item, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var refCount uint64
+	var valCopy []byte
+	if err := item.Value(func(val []byte) error {
+		valCopy = append([]byte{}, val...)
+		rd := bytes.NewReader(val[:8])
+		return binary.Read(rd, binary.LittleEndian, &refCount)
+	}); err != nil {
+		return nil, err
+	}
+
+	refCount--
+	if refCount == 0 {
+		if err := txn.Delete(key); err != nil {
+			return nil, err
+		}
+		switch valCopy[8] {
+		case value.TypeCodeTuple:
+			size := int(valCopy[9])
+			rd := bytes.NewReader(valCopy[10:])
+			more := make([]common.Hash, size)
+			for i := 0; i < size; i++ {
+				if _, err := io.ReadFull(rd, more[i][:]); err != nil {
+					return nil, err
+				}
+			}
+			return more, nil
+		case value.TypeCodeCodePoint:
+			// The insnNum is followed by the operation which references its
+			// immediate value if it has one
+			op := valCopy[17:]
+			if op[0] == 1 {
+				var h common.Hash
+				copy(h[:], op[2:])
+				return []common.Hash{h}, nil
+			}
+		}
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, &refCount); err != nil {
+		return nil, err
+	}
+	return nil, txn.Set(key, append(buf.Bytes(), valCopy[8:]...))
+}
+
 func (cp *Checkpointer) RestoreValueFromHash(hash common.Hash) (value.Value, error) {
 	txn := cp.db.NewTransaction(false) // open a read-only transaction
 	defer txn.Discard()
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+const (
+	storageDataPrefix  = "data:"
+	storageBlockPrefix = "block:"
+	blockHeightLength  = 32
+)
+
+// CheckpointStorage implements machine.CheckpointStorage using badger so that
+// it can be used without cgo. Machines are saved and restored as Go machines
+// running the code in the contract the storage was created with
+type CheckpointStorage struct {
+	cp      *Checkpointer
+	initial *vm.Machine
+}
+
+func NewCheckpointStorage(dbPath string, contractPath string) (*CheckpointStorage, error) {
+	initial, err := goloader.LoadMachineFromFile(contractPath, false)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := openCheckpointer(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointStorage{cp: cp, initial: initial}, nil
+}
+
+func (s *CheckpointStorage) CloseCheckpointStorage() bool {
+	return s.cp.Close() == nil
+}
+
+func (s *CheckpointStorage) GetInitialMachine() (machine.Machine, error) {
+	return s.initial.Clone(), nil
+}
+
+func (s *CheckpointStorage) GetMachine(machineHash common.Hash) (machine.Machine, error) {
+	return s.initial.RestoreCheckpoint(s, machineHash)
+}
+
+func (s *CheckpointStorage) DeleteCheckpoint(machineHash common.Hash) bool {
+	return vm.DeleteCheckpoint(s, machineHash)
+}
+
+func (s *CheckpointStorage) SaveValue(val value.Value) bool {
+	return s.cp.AddRefToValue(val) == nil
+}
+
+func (s *CheckpointStorage) GetValue(hashValue common.Hash) value.Value {
+	val, err := s.cp.RestoreValueFromHash(hashValue)
+	if err != nil {
+		return nil
+	}
+	return val
+}
+
+// DeleteValue removes a reference to the value with the given hash and
+// deletes it, along with any values only it referenced, once no references
+// remain
+func (s *CheckpointStorage) DeleteValue(hashValue common.Hash) bool {
+	err := s.cp.db.Update(func(txn *badger.Txn) error {
+		hashes := []common.Hash{hashValue}
+		for len(hashes) > 0 {
+			more, err := s.cp.removeRefToValueInTxn(txn, hashes[0])
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes[1:], more...)
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func dataKey(key []byte) []byte {
+	return append([]byte(storageDataPrefix), key...)
+}
+
+func (s *CheckpointStorage) SaveData(key []byte, serializedValue []byte) bool {
+	err := s.cp.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(dataKey(key), serializedValue)
+	})
+	return err == nil
+}
+
+func (s *CheckpointStorage) GetData(key []byte) []byte {
+	var data []byte
+	err := s.cp.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(dataKey(key))
+		if err != nil {
+			return err
+		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+func (s *CheckpointStorage) DeleteData(key []byte) bool {
+	err := s.cp.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(dataKey(key))
+	})
+	return err == nil
+}
+
+// Blocks are keyed by their big endian height followed by their hash so that
+// iterating over the keys visits them in order of height
+func blockHeightPrefix(height *common.TimeBlocks) []byte {
+	var heightBytes [blockHeightLength]byte
+	heightInt := height.AsInt().Bytes()
+	copy(heightBytes[blockHeightLength-len(heightInt):], heightInt)
+	return append([]byte(storageBlockPrefix), heightBytes[:]...)
+}
+
+func blockKey(id *common.BlockId) []byte {
+	return append(blockHeightPrefix(id.Height), id.HeaderHash[:]...)
+}
+
+func blockIdFromKey(key []byte) *common.BlockId {
+	key = key[len(storageBlockPrefix):]
+	var hash common.Hash
+	copy(hash[:], key[blockHeightLength:])
+	return &common.BlockId{
+		Height:     common.NewTimeBlocks(new(big.Int).SetBytes(key[:blockHeightLength])),
+		HeaderHash: hash,
+	}
+}
+
+func (s *CheckpointStorage) PutBlock(id *common.BlockId, data []byte) error {
+	return s.cp.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(blockKey(id), data)
+	})
+}
+
+func (s *CheckpointStorage) DeleteBlock(id *common.BlockId) error {
+	return s.cp.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(blockKey(id))
+	})
+}
+
+func (s *CheckpointStorage) GetBlock(id *common.BlockId) ([]byte, error) {
+	var data []byte
+	err := s.cp.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(blockKey(id))
+		if err == badger.ErrKeyNotFound {
+			return errors.New("not found")
+		}
+		if err != nil {
+			return err
+		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	return data, err
+}
+
+func (s *CheckpointStorage) BlocksAtHeight(height *common.TimeBlocks) []*common.BlockId {
+	var ids []*common.BlockId
+	_ = s.cp.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := blockHeightPrefix(height)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			ids = append(ids, blockIdFromKey(it.Item().KeyCopy(nil)))
+		}
+		return nil
+	})
+	return ids
+}
+
+// boundaryBlock returns the block with the lowest height, or the highest
+// height if reverse is set, or nil if the block store is empty
+func (s *CheckpointStorage) boundaryBlock(reverse bool) *common.BlockId {
+	var id *common.BlockId
+	_ = s.cp.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = reverse
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := []byte(storageBlockPrefix)
+		seekKey := prefix
+		if reverse {
+			// Seek to the end of the prefix since a reverse iterator starts at
+			// the largest key less than or equal to the one sought
+			seekKey = append(append([]byte{}, prefix...), 0xff)
+		}
+		it.Seek(seekKey)
+		if it.ValidForPrefix(prefix) {
+			id = blockIdFromKey(it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+	return id
+}
+
+func (s *CheckpointStorage) IsBlockStoreEmpty() bool {
+	return s.boundaryBlock(false) == nil
+}
+
+func (s *CheckpointStorage) MaxBlockStoreHeight() *common.TimeBlocks {
+	id := s.boundaryBlock(true)
+	if id == nil {
+		return common.NewTimeBlocksInt(0)
+	}
+	return id.Height
+}
+
+func (s *CheckpointStorage) MinBlockStoreHeight() *common.TimeBlocks {
+	id := s.boundaryBlock(false)
+	if id == nil {
+		return common.NewTimeBlocksInt(0)
+	}
+	return id.Height
+}
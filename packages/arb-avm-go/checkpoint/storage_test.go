@@ -0,0 +1,192 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+var _ machine.CheckpointStorage = &CheckpointStorage{}
+
+const codeFile = "../../arb-validator/contract.ao"
+
+func openTestStorage(t *testing.T) (*CheckpointStorage, func()) {
+	dbPath, err := ioutil.TempDir("", "checkpointstorage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := NewCheckpointStorage(dbPath, codeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage, func() {
+		storage.CloseCheckpointStorage()
+		if err := os.RemoveAll(dbPath); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStorageValues(t *testing.T) {
+	storage, cleanup := openTestStorage(t)
+	defer cleanup()
+
+	inner := value.NewTuple2(value.NewInt64Value(1), value.NewInt64Value(2))
+	outer := value.NewTuple2(inner, value.NewInt64Value(3))
+	if !storage.SaveValue(outer) || !storage.SaveValue(inner) {
+		t.Fatal("failed to save values")
+	}
+	if val := storage.GetValue(outer.Hash()); val == nil || !value.Eq(val, outer) {
+		t.Error("restored wrong value", val)
+	}
+
+	if !storage.DeleteValue(outer.Hash()) {
+		t.Fatal("failed to delete value")
+	}
+	if storage.GetValue(outer.Hash()) != nil {
+		t.Error("value should have been deleted")
+	}
+	if storage.GetValue(inner.Hash()) == nil {
+		t.Error("value saved separately should still exist")
+	}
+	if !storage.DeleteValue(inner.Hash()) {
+		t.Fatal("failed to delete value")
+	}
+	if storage.GetValue(value.NewInt64Value(1).Hash()) != nil {
+		t.Error("value without references should have been deleted")
+	}
+}
+
+func TestStorageData(t *testing.T) {
+	storage, cleanup := openTestStorage(t)
+	defer cleanup()
+
+	if storage.GetData([]byte("key")) != nil {
+		t.Error("should have empty value")
+	}
+	if !storage.SaveData([]byte("key"), []byte("data")) {
+		t.Fatal("failed to save data")
+	}
+	if !bytes.Equal(storage.GetData([]byte("key")), []byte("data")) {
+		t.Error("restored wrong data")
+	}
+	if !storage.DeleteData([]byte("key")) {
+		t.Fatal("failed to delete data")
+	}
+	if storage.GetData([]byte("key")) != nil {
+		t.Error("data should have been deleted")
+	}
+}
+
+func TestStorageBlocks(t *testing.T) {
+	storage, cleanup := openTestStorage(t)
+	defer cleanup()
+
+	if !storage.IsBlockStoreEmpty() {
+		t.Error("block store should start empty")
+	}
+	ids := []*common.BlockId{
+		{Height: common.NewTimeBlocksInt(3), HeaderHash: common.Hash{1}},
+		{Height: common.NewTimeBlocksInt(300), HeaderHash: common.Hash{2}},
+		{Height: common.NewTimeBlocksInt(300), HeaderHash: common.Hash{3}},
+		{Height: common.NewTimeBlocksInt(5), HeaderHash: common.Hash{4}},
+	}
+	for i, id := range ids {
+		if err := storage.PutBlock(id, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if storage.IsBlockStoreEmpty() {
+		t.Error("block store shouldn't be empty")
+	}
+	if storage.MinBlockStoreHeight().AsInt().Cmp(big.NewInt(3)) != 0 {
+		t.Error("wrong min height", storage.MinBlockStoreHeight())
+	}
+	if storage.MaxBlockStoreHeight().AsInt().Cmp(big.NewInt(300)) != 0 {
+		t.Error("wrong max height", storage.MaxBlockStoreHeight())
+	}
+	if len(storage.BlocksAtHeight(common.NewTimeBlocksInt(300))) != 2 {
+		t.Error("wrong number of blocks at height")
+	}
+	data, err := storage.GetBlock(ids[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{3}) {
+		t.Error("restored wrong block")
+	}
+
+	if err := storage.DeleteBlock(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.GetBlock(ids[0]); err == nil {
+		t.Error("block should have been deleted")
+	}
+	if storage.MinBlockStoreHeight().AsInt().Cmp(big.NewInt(5)) != 0 {
+		t.Error("wrong min height after delete", storage.MinBlockStoreHeight())
+	}
+}
+
+func TestStorageMachine(t *testing.T) {
+	storage, cleanup := openTestStorage(t)
+	defer cleanup()
+
+	mach, err := storage.GetInitialMachine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mach.ExecuteAssertion(
+		1000,
+		&protocol.TimeBounds{
+			LowerBoundBlock:     common.NewTimeBlocks(big.NewInt(100)),
+			UpperBoundBlock:     common.NewTimeBlocks(big.NewInt(120)),
+			LowerBoundTimestamp: big.NewInt(100),
+			UpperBoundTimestamp: big.NewInt(120),
+		},
+		value.NewEmptyTuple(),
+		time.Hour,
+	)
+
+	if !mach.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+	loadedMach, err := storage.GetMachine(mach.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mach.Hash() != loadedMach.Hash() {
+		t.Error("restored machine with wrong hash", mach.Hash(), loadedMach.Hash())
+	}
+
+	if !storage.DeleteCheckpoint(mach.Hash()) {
+		t.Fatal("failed to delete checkpoint")
+	}
+	if _, err := storage.GetMachine(mach.Hash()); err == nil {
+		t.Error("checkpoint should have been deleted")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/asm"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+)
+
+const usage = `usage:
+  aotool disasm <program.ao> [program.s]   disassemble an AO file, writing to stdout by default
+  aotool asm <program.s> <program.ao>      assemble a program into an AO file
+`
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	var err error
+	switch {
+	case os.Args[1] == "disasm" && len(os.Args) <= 4:
+		err = disassemble(os.Args[2], os.Args[3:])
+	case os.Args[1] == "asm" && len(os.Args) == 4:
+		err = assemble(os.Args[2], os.Args[3])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func disassemble(inFile string, outFile []string) error {
+	program, err := goloader.ReadProgramFromFile(inFile)
+	if err != nil {
+		return err
+	}
+	if len(outFile) == 0 {
+		wr := bufio.NewWriter(os.Stdout)
+		if err := asm.Disassemble(wr, program); err != nil {
+			return err
+		}
+		return wr.Flush()
+	}
+	return writeFile(outFile[0], func(wr io.Writer) error {
+		return asm.Disassemble(wr, program)
+	})
+}
+
+func assemble(inFile string, outFile string) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	program, err := asm.Assemble(f)
+	if err != nil {
+		return fmt.Errorf("%v: %v", inFile, err)
+	}
+	return writeFile(outFile, program.Marshal)
+}
+
+func writeFile(fileName string, write func(io.Writer) error) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	wr := bufio.NewWriter(f)
+	if err := write(wr); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := wr.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/debugger"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+const debugHelp = `Commands:
+  s, step [n]          execute n instructions (default 1)
+  n, next              step, running over any call made by a jump
+  c, continue          run until a breakpoint or the machine stops
+  b, break <pc|op>     stop before the instruction at pc or any op instruction
+  d, delete <pc|op>    remove a breakpoint
+  breakpoints          list breakpoints
+  catch                toggle stopping on instructions which raise errors
+  l, list [pc]         show the code around pc (default the current pc)
+  stack [n] [depth]    show the top n values of the data stack
+  aux [n] [depth]      show the top n values of the aux stack
+  register [depth]     show the register
+  static [depth]       show the static value
+  errhandler           show the error handler
+  inbox [depth]        show messages delivered but not yet read
+  deliver <script>     deliver the messages in an inbox script
+  logs                 show decoded logs emitted so far
+  status               show the machine status, steps and gas
+  q, quit              exit
+`
+
+const defaultDepth = 3
+
+type debugSession struct {
+	dbg        *debugger.Debugger
+	insns      []value.Operation
+	chain      common.Address
+	maxSteps   uint64
+	nextMsgNum int64
+	out        io.Writer
+}
+
+func runDebugger(dbg *debugger.Debugger, chain common.Address, maxSteps uint64, firstMsgNum int64, in io.Reader, out io.Writer) {
+	s := &debugSession{
+		dbg:        dbg,
+		insns:      dbg.Machine().GetAllOperations(),
+		chain:      chain,
+		maxSteps:   maxSteps,
+		nextMsgNum: firstMsgNum,
+		out:        out,
+	}
+	fmt.Fprintln(out, "Type help for a list of commands")
+	s.printCurrent()
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "(avm) ")
+		if !scanner.Scan() {
+			return
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "q" || fields[0] == "quit" {
+			return
+		}
+		if err := s.handle(fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(out, "Error:", err)
+		}
+	}
+}
+
+func intArg(args []int, i int, def int) int {
+	if i < len(args) {
+		return args[i]
+	}
+	return def
+}
+
+func parseInts(args []string) ([]int, error) {
+	ret := make([]int, 0, len(args))
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number but got %v", arg)
+		}
+		ret = append(ret, n)
+	}
+	return ret, nil
+}
+
+func (s *debugSession) handle(cmd string, args []string) error {
+	m := s.dbg.Machine()
+	switch cmd {
+	case "help", "h":
+		fmt.Fprint(s.out, debugHelp)
+	case "s", "step":
+		nums, err := parseInts(args)
+		if err != nil {
+			return err
+		}
+		count := intArg(nums, 0, 1)
+		if count < 0 {
+			return fmt.Errorf("can't step %v instructions", count)
+		}
+		s.printStop(s.dbg.Step(uint64(count)))
+	case "n", "next":
+		s.printStop(s.dbg.StepOver(s.maxSteps))
+	case "c", "continue":
+		s.printStop(s.dbg.Continue(s.maxSteps))
+	case "b", "break", "d", "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %v <pc|op>", cmd)
+		}
+		return s.setBreakpoint(args[0], cmd == "b" || cmd == "break")
+	case "breakpoints":
+		fmt.Fprintln(s.out, "PCs:", s.dbg.PCBreakpoints())
+		fmt.Fprintln(s.out, "Opcodes:", s.dbg.OpBreakpoints())
+		fmt.Fprintln(s.out, "Stop on errors:", s.dbg.BreakOnError)
+	case "catch":
+		s.dbg.BreakOnError = !s.dbg.BreakOnError
+		fmt.Fprintln(s.out, "Stop on errors:", s.dbg.BreakOnError)
+	case "l", "list":
+		nums, err := parseInts(args)
+		if err != nil {
+			return err
+		}
+		s.listCode(int64(intArg(nums, 0, int(m.PCIndex()))))
+	case "stack", "aux":
+		nums, err := parseInts(args)
+		if err != nil {
+			return err
+		}
+		st := m.Stack()
+		if cmd == "aux" {
+			st = m.AuxStack()
+		}
+		count := intArg(nums, 0, 5)
+		if count < 0 {
+			return fmt.Errorf("can't show %v values", count)
+		}
+		if int64(count) > st.Count() {
+			count = int(st.Count())
+		}
+		vals := st.Top(count)
+		fmt.Fprintf(s.out, "%v of %v values\n", len(vals), st.Count())
+		for i, val := range vals {
+			fmt.Fprintf(s.out, "[%v] %v\n", i, debugger.FormatValue(val, intArg(nums, 1, defaultDepth)))
+		}
+	case "register", "static", "inbox":
+		nums, err := parseInts(args)
+		if err != nil {
+			return err
+		}
+		var val value.Value
+		switch cmd {
+		case "register":
+			val = m.Register().Get()
+		case "static":
+			val = m.Static().Get()
+		default:
+			val = s.dbg.PendingInbox()
+		}
+		fmt.Fprintln(s.out, debugger.FormatValue(val, intArg(nums, 0, defaultDepth)))
+	case "errhandler":
+		handler := m.GetErrHandler()
+		if handler.Equal(value.ErrorCodePoint) {
+			fmt.Fprintln(s.out, "none")
+		} else {
+			fmt.Fprintf(s.out, "pc %v: %v%v\n", handler.InsnNum, debugger.FormatOperation(handler.Op), s.location(handler.InsnNum))
+		}
+	case "deliver":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: deliver <script>")
+		}
+		script, err := loadInboxScript(args[0])
+		if err != nil {
+			return err
+		}
+		msgs, err := buildMessages(script, s.chain, s.dbg.TimeBounds(), s.nextMsgNum)
+		if err != nil {
+			return err
+		}
+		s.nextMsgNum += int64(len(msgs))
+		s.dbg.Deliver(msgs...)
+		for _, msg := range msgs {
+			fmt.Fprintln(s.out, "Delivered", msg)
+		}
+	case "logs":
+		printLogs(s.out, s.dbg.Logs(), s.chain)
+		printOutMessages(s.out, s.dbg.OutMessages(), s.chain)
+	case "status":
+		fmt.Fprintln(s.out, "Status:", m.CurrentStatus())
+		fmt.Fprintln(s.out, "Steps:", s.dbg.TotalSteps())
+		fmt.Fprintln(s.out, "Gas:", s.dbg.TotalGas())
+		fmt.Fprintln(s.out, "Hash:", m.Hash())
+	default:
+		return fmt.Errorf("unknown command %v, type help for a list of commands", cmd)
+	}
+	return nil
+}
+
+func (s *debugSession) setBreakpoint(arg string, add bool) error {
+	if pc, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		if pc < 0 || pc >= int64(len(s.insns)) {
+			return fmt.Errorf("pc %v is outside of the program", pc)
+		}
+		if add {
+			s.dbg.AddPCBreakpoint(pc)
+		} else if !s.dbg.RemovePCBreakpoint(pc) {
+			return fmt.Errorf("no breakpoint at pc %v", pc)
+		}
+		return nil
+	}
+	op, ok := code.OpcodeFromName(strings.ToLower(arg))
+	if !ok {
+		return fmt.Errorf("%v is neither a pc nor an opcode", arg)
+	}
+	if add {
+		s.dbg.AddOpBreakpoint(op)
+	} else if !s.dbg.RemoveOpBreakpoint(op) {
+		return fmt.Errorf("no breakpoint on %v", arg)
+	}
+	return nil
+}
+
+func (s *debugSession) listCode(center int64) {
+	current := s.dbg.Machine().PCIndex()
+	for pc := center - 5; pc <= center+5; pc++ {
+		if pc < 0 || pc >= int64(len(s.insns)) {
+			continue
+		}
+		marker := "  "
+		if pc == current {
+			marker = "=>"
+		}
+		fmt.Fprintf(s.out, "%v %6d  %v%v\n", marker, pc, debugger.FormatOperation(s.insns[pc]), s.location(pc))
+	}
+}
+
+func (s *debugSession) printCurrent() {
+	m := s.dbg.Machine()
+	pc := m.PCIndex()
+	if pc < 0 || pc >= int64(len(s.insns)) || m.CurrentStatus() != machine.Extensive {
+		fmt.Fprintln(s.out, "Machine status:", m.CurrentStatus())
+		return
+	}
+	fmt.Fprintf(s.out, "=> %6d  %v%v\n", pc, debugger.FormatOperation(s.insns[pc]), s.location(pc))
+}
+
+// location describes the source location of pc if it's known
+func (s *debugSession) location(pc int64) string {
+	if loc, ok := s.dbg.Machine().SourceLocation(pc); ok {
+		return "  (" + loc + ")"
+	}
+	return ""
+}
+
+func (s *debugSession) printStop(stop debugger.Stop) {
+	fmt.Fprintln(s.out, "Stopped:", stop)
+	s.printCurrent()
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/yaml.v2"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+)
+
+// scriptMessage is a single entry of an inbox script. Fields which don't
+// apply to the given message type are ignored and the id of an ERC721 token
+// is given as its value. Numbers may be given in decimal or as 0x prefixed hex
+type scriptMessage struct {
+	Type      string `yaml:"type"`
+	From      string `yaml:"from"`
+	To        string `yaml:"to"`
+	Token     string `yaml:"token"`
+	Value     string `yaml:"value"`
+	Seq       string `yaml:"seq"`
+	Data      string `yaml:"data"`
+	Block     string `yaml:"block"`
+	Timestamp string `yaml:"timestamp"`
+}
+
+type inboxScript struct {
+	Messages []scriptMessage `yaml:"messages"`
+}
+
+// loadInboxScript reads an inbox script in either YAML or JSON since any JSON
+// document is also valid YAML
+func loadInboxScript(fileName string) (*inboxScript, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	script := &inboxScript{}
+	if err := yaml.Unmarshal(data, script); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+func parseBigInt(str string, defaultVal *big.Int) (*big.Int, error) {
+	if str == "" {
+		return new(big.Int).Set(defaultVal), nil
+	}
+	val, ok := new(big.Int).SetString(str, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %v", str)
+	}
+	return val, nil
+}
+
+func parseData(str string) ([]byte, error) {
+	if str == "" {
+		return nil, nil
+	}
+	return hexutil.Decode(str)
+}
+
+// buildMessages converts the messages in the script to the messages
+// delivered to the VM, numbering them from firstMessageNum. Messages without
+// an explicit block or timestamp are delivered at the lower bounds of
+// timeBounds
+func buildMessages(script *inboxScript, chain common.Address, timeBounds *protocol.TimeBounds, firstMessageNum int64) ([]message.Message, error) {
+	msgs := make([]message.Message, 0, len(script.Messages))
+	for i, scriptMsg := range script.Messages {
+		msg, err := scriptMsg.toMessage(chain, big.NewInt(firstMessageNum+int64(i)), timeBounds)
+		if err != nil {
+			return nil, fmt.Errorf("message %v: %v", i, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (m scriptMessage) toMessage(chain common.Address, messageNum *big.Int, timeBounds *protocol.TimeBounds) (message.Message, error) {
+	blockNum, err := parseBigInt(m.Block, timeBounds.LowerBoundBlock.AsInt())
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := parseBigInt(m.Timestamp, timeBounds.LowerBoundTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseBigInt(m.Value, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parseData(m.Data)
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress(m.To)
+	from := common.HexToAddress(m.From)
+	token := common.HexToAddress(m.Token)
+	block := common.NewTimeBlocks(blockNum)
+
+	switch strings.ToLower(m.Type) {
+	case "transaction":
+		seq, err := parseBigInt(m.Seq, big.NewInt(0))
+		if err != nil {
+			return nil, err
+		}
+		return message.DeliveredTransaction{
+			Transaction: message.Transaction{
+				Chain:       chain,
+				To:          to,
+				From:        from,
+				SequenceNum: seq,
+				Value:       amount,
+				Data:        data,
+			},
+			BlockNum:  block,
+			Timestamp: timestamp,
+		}, nil
+	case "eth":
+		return message.DeliveredEth{
+			Eth: message.Eth{
+				To:    to,
+				From:  from,
+				Value: amount,
+			},
+			BlockNum:   block,
+			Timestamp:  timestamp,
+			MessageNum: messageNum,
+		}, nil
+	case "erc20":
+		return message.DeliveredERC20{
+			ERC20: message.ERC20{
+				To:           to,
+				From:         from,
+				TokenAddress: token,
+				Value:        amount,
+			},
+			BlockNum:   block,
+			Timestamp:  timestamp,
+			MessageNum: messageNum,
+		}, nil
+	case "erc721":
+		return message.DeliveredERC721{
+			ERC721: message.ERC721{
+				To:           to,
+				From:         from,
+				TokenAddress: token,
+				Id:           amount,
+			},
+			BlockNum:   block,
+			Timestamp:  timestamp,
+			MessageNum: messageNum,
+		}, nil
+	case "contract":
+		return message.DeliveredContractTransaction{
+			ContractTransaction: message.ContractTransaction{
+				To:    to,
+				From:  from,
+				Value: amount,
+				Data:  data,
+			},
+			BlockNum:   block,
+			Timestamp:  timestamp,
+			MessageNum: messageNum,
+		}, nil
+	case "call":
+		return message.Call{
+			To:        to,
+			From:      from,
+			Data:      data,
+			BlockNum:  block,
+			Timestamp: timestamp,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown message type %v", m.Type)
+	}
+}
@@ -16,63 +16,192 @@
 
 package main
 
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/debugger"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/profiler"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+)
+
 func main() {
-	//
-	// cMac := vm.CreateVM(os.Args[1], os.Args[2])
-	// cSteps := vm.RunVM(cMac, 1000000)
-	// fmt.Println("cMachine ended ", cSteps, " steps run.")
-	//
-	// candidatesCountBytes, _ := hexutil.Decode("0x2d35a8a2")
-	// candidatesCount, _ := evm.BytesToSizedByteArray(candidatesCountBytes)
-	//
-	// candidates1Bytes, _ := hexutil.Decode("0x3477ee2e0000000000000000000000000000000000000000000000000000000000000001")
-	// candidates1, _ := evm.BytesToSizedByteArray(candidates1Bytes)
-	//
-	// candidates2Bytes, _ := hexutil.Decode("0x3477ee2e0000000000000000000000000000000000000000000000000000000000000002")
-	// candidates2, _ := evm.BytesToSizedByteArray(candidates2Bytes)
-	//
-	////var machine *vm.Machine
-	// machine, err := loader.LoadMachineFromFile(os.Args[1], true)
-	// if err != nil {
-	//	log.Fatal("Loader Error: ", err)
-	//}
-	//
-	// balanceTracker := protocol.NewBalanceTracker()
-	// inbox := protocol.NewEmptyInbox()
-	// rawAddress, _ := new(big.Int).SetString("784030224795475933405737832577560929931042096197", 10)
-	// addressVal := value.NewIntValue(rawAddress)
-	// inbox.SendMessage(protocol.NewMessage(value.NewTuple2(addressVal, candidatesCount), [21]byte{}, big.NewInt(0), protocol.Identity{}))
-	// inbox.SendMessage(protocol.NewMessage(value.NewTuple2(addressVal, candidates1), [21]byte{}, big.NewInt(0), protocol.Identity{}))
-	// inbox.SendMessage(protocol.NewMessage(value.NewTuple2(addressVal, candidates2), [21]byte{}, big.NewInt(0), protocol.Identity{}))
-	// inbox.DeliverMessages()
-	//
-	// ctx := protocol.NewMachineAssertionContext(machine, balanceTracker, [2]uint64{0, 10000}, inbox.Receive())
-	// steps := machine.Run(800000)
-	// fmt.Println(steps)
-	// ad := ctx.Finalize(machine)
-	// assertion := ad.GetAssertion()
-	// for _, val := range assertion.Logs {
-	//	log, err := evm.ProcessLog(val)
-	//	if err != nil {
-	//		fmt.Printf("Logged1 invalid %v: %v\n", err, val)
-	//	} else {
-	//		fmt.Printf("Logged1 %v\n", log)
-	//	}
-	//
-	//}
-	// precondition := ad.GetAssertion().Stub().GeneratePostcondition(ad.GetPrecondition())
-	//
-	// ctx = protocol.NewMachineAssertionContext(machine, precondition.BeforeBalance, precondition.TimeBounds, inbox.Receive())
-	// steps = machine.Run(110000)
-	// fmt.Println(steps)
-	// ad = ctx.Finalize(machine)
-	// assertion = ad.GetAssertion()
-	// for _, val := range assertion.Logs {
-	//	log, err := evm.ProcessLog(val)
-	//	if err != nil {
-	//		fmt.Printf("Logged1 invalid %v: %v\n", err, val)
-	//	} else {
-	//		fmt.Printf("Logged1 %v\n", log)
-	//	}
-	//}
+	maxSteps := flag.Uint64("steps", 10000000, "maximum number of steps to run")
+	maxWallTime := flag.Duration("walltime", 0, "maximum time to run for, or 0 for no limit")
+	startBlock := flag.Int64("start-block", 0, "lower bound block of the assertion")
+	endBlock := flag.Int64("end-block", 1000, "upper bound block of the assertion")
+	startTime := flag.Int64("start-time", 0, "lower bound timestamp of the assertion")
+	endTime := flag.Int64("end-time", 1000000, "upper bound timestamp of the assertion")
+	chainAddress := flag.String("chain", "", "address of the chain that transactions are sent to")
+	warn := flag.Bool("warn", false, "print warnings from the VM")
+	trace := flag.String("trace", "", "write a trace of every step to stderr as \"text\" or \"json\"")
+	traceDepth := flag.Int("trace-depth", 4, "number of stack values to include in each traced step")
+	debug := flag.Bool("debug", false, "run the contract in an interactive debugger")
+	profile := flag.String("profile", "", "write a profile of the run to this file as folded stacks for flamegraph tools")
+	profileWeight := flag.String("profile-weight", "gas", "cost recorded in the profile, \"gas\" or \"steps\"")
+	profileTop := flag.Int("profile-top", 20, "number of entries in each table of the profile summary")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: run-vm [flags] <contract.ao> [inbox.yaml]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	chain := common.HexToAddress(*chainAddress)
+	timeBounds := &protocol.TimeBounds{
+		LowerBoundBlock:     common.NewTimeBlocks(big.NewInt(*startBlock)),
+		UpperBoundBlock:     common.NewTimeBlocks(big.NewInt(*endBlock)),
+		LowerBoundTimestamp: big.NewInt(*startTime),
+		UpperBoundTimestamp: big.NewInt(*endTime),
+	}
+
+	mach, err := goloader.LoadMachineFromFile(flag.Arg(0), *warn)
+	if err != nil {
+		log.Fatal("Loader Error: ", err)
+	}
+
+	if *debug && *trace != "" {
+		log.Fatal("tracing isn't supported in the debugger")
+	}
+	if *profile != "" && (*debug || *trace != "") {
+		log.Fatal("profiling can't be combined with tracing or the debugger")
+	}
+	weight, err := profiler.ParseWeight(*profileWeight)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var prof *profiler.Profiler
+	if *profile != "" {
+		prof = profiler.New()
+		mach.SetTracer(prof)
+	}
+	switch *trace {
+	case "":
+	case "text":
+		mach.SetTracer(vm.NewTextTracer(os.Stderr, *traceDepth))
+	case "json":
+		mach.SetTracer(vm.NewJSONTracer(os.Stderr, *traceDepth))
+	default:
+		log.Fatal("invalid trace format ", *trace)
+	}
+
+	inbox := value.NewEmptyTuple()
+	var msgCount int64
+	if flag.NArg() == 2 {
+		script, err := loadInboxScript(flag.Arg(1))
+		if err != nil {
+			log.Fatal("Inbox Error: ", err)
+		}
+		msgs, err := buildMessages(script, chain, timeBounds, 0)
+		if err != nil {
+			log.Fatal("Inbox Error: ", err)
+		}
+		for _, msg := range msgs {
+			fmt.Println("Delivering", msg)
+			inbox = message.AddToPrev(inbox, msg)
+		}
+		msgCount = int64(len(msgs))
+	}
+
+	if *debug {
+		dbg := debugger.New(mach, timeBounds, inbox)
+		runDebugger(dbg, chain, *maxSteps, msgCount, os.Stdin, os.Stdout)
+		return
+	}
+
+	start := time.Now()
+	assertion, steps := mach.ExecuteAssertion(*maxSteps, timeBounds, inbox, *maxWallTime)
+	elapsed := time.Since(start)
+
+	printLogs(os.Stdout, assertion.Logs, chain)
+	printOutMessages(os.Stdout, assertion.OutMsgs, chain)
+
+	fmt.Println("Steps:", steps)
+	fmt.Println("Gas:", assertion.NumGas)
+	fmt.Println("Ran for:", elapsed)
+	fmt.Println("Consumed inbox:", assertion.DidInboxInsn)
+	fmt.Println("Machine status:", mach.CurrentStatus())
+	fmt.Println("Machine hash:", assertion.AfterHash)
+	if reason := mach.IsBlocked(timeBounds.UpperBoundBlock, false); reason != nil {
+		fmt.Println("Blocked:", reason)
+	}
+
+	if prof != nil {
+		if err := writeProfile(prof, *profile, weight); err != nil {
+			log.Fatal("Profile Error: ", err)
+		}
+		fmt.Println()
+		if err := prof.WriteReport(os.Stdout, mach.GetAllOperations(), weight, *profileTop); err != nil {
+			log.Fatal("Profile Error: ", err)
+		}
+	}
+}
+
+func writeProfile(prof *profiler.Profiler, fileName string, weight profiler.Weight) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := prof.WriteFolded(f, weight); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printLogs(w io.Writer, logs []value.Value, chain common.Address) {
+	fmt.Fprintln(w, "Logs:", len(logs))
+	for i, logVal := range logs {
+		res, err := evm.ProcessLog(logVal, chain)
+		if err != nil {
+			fmt.Fprintf(w, "  %v: undecodable log (%v): %v\n", i, err, logVal)
+			continue
+		}
+		fmt.Fprintf(w, "  %v: %v\n", i, res)
+	}
+}
+
+// printOutMessages prints the messages sent by the VM, decoding them as
+// Arbitrum messages when they're in the same format as delivered messages
+func printOutMessages(w io.Writer, outMsgs []value.Value, chain common.Address) {
+	fmt.Fprintln(w, "Out messages:", len(outMsgs))
+	for i, msgVal := range outMsgs {
+		msg, err := decodeOutMessage(msgVal, chain)
+		if err != nil {
+			fmt.Fprintf(w, "  %v: %v\n", i, msgVal)
+			continue
+		}
+		fmt.Fprintf(w, "  %v: %v\n", i, msg)
+	}
+}
+
+func decodeOutMessage(val value.Value, chain common.Address) (message.ExecutionMessage, error) {
+	tup, ok := val.(value.TupleValue)
+	if !ok {
+		return nil, fmt.Errorf("expected tuple but received %v", val)
+	}
+	typeVal, err := tup.GetByInt64(0)
+	if err != nil {
+		return nil, err
+	}
+	typeInt, ok := typeVal.(value.IntValue)
+	if !ok {
+		return nil, fmt.Errorf("expected int type code but received %v", typeVal)
+	}
+	return message.UnmarshalExecuted(message.MessageType(typeInt.BigInt().Uint64()), val, chain)
 }
@@ -172,6 +172,21 @@ var InstructionNames = map[value.Opcode]string{
 	DEBUG:   "debug",
 }
 
+var instructionCodes = func() map[string]value.Opcode {
+	codes := make(map[string]value.Opcode, len(InstructionNames))
+	for op, name := range InstructionNames {
+		codes[name] = op
+	}
+	return codes
+}()
+
+// OpcodeFromName returns the opcode with the given mnemonic as used in
+// InstructionNames
+func OpcodeFromName(name string) (value.Opcode, bool) {
+	op, ok := instructionCodes[name]
+	return op, ok
+}
+
 var InstructionStackPops = map[value.Opcode][]byte{
 	ADD:    {1, 1},
 	MUL:    {1, 1},
@@ -0,0 +1,339 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debugger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+)
+
+type StopReason int
+
+const (
+	// StopStep means the requested number of steps was run
+	StopStep StopReason = iota
+	// StopStepLimit means the step limit was reached before anything else
+	// caused the machine to stop
+	StopStepLimit
+	StopPCBreakpoint
+	StopOpBreakpoint
+	// StopBreakpointInsn means the machine executed a BREAKPOINT instruction
+	StopBreakpointInsn
+	// StopError means an instruction raised an error and BreakOnError is set
+	StopError
+	// StopBlocked means the machine is waiting for an inbox message
+	StopBlocked
+	StopHalted
+	StopErrored
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopStep:
+		return "step"
+	case StopStepLimit:
+		return "step limit reached"
+	case StopPCBreakpoint:
+		return "breakpoint"
+	case StopOpBreakpoint:
+		return "opcode breakpoint"
+	case StopBreakpointInsn:
+		return "breakpoint instruction"
+	case StopError:
+		return "instruction error"
+	case StopBlocked:
+		return "blocked"
+	case StopHalted:
+		return "halted"
+	case StopErrored:
+		return "error stop"
+	default:
+		return fmt.Sprintf("StopReason(%d)", int(r))
+	}
+}
+
+// Stop describes why a run of the machine ended
+type Stop struct {
+	Reason StopReason
+	Steps  uint64
+	// Blocked is the reason the machine couldn't run when Reason is
+	// StopBlocked
+	Blocked machine.BlockReason
+	// Err is the error raised when Reason is StopError
+	Err error
+}
+
+func (s Stop) String() string {
+	switch s.Reason {
+	case StopBlocked:
+		return fmt.Sprintf("%v (%v) after %v steps", s.Reason, s.Blocked, s.Steps)
+	case StopError:
+		return fmt.Sprintf("%v (%v) after %v steps", s.Reason, s.Err, s.Steps)
+	default:
+		return fmt.Sprintf("%v after %v steps", s.Reason, s.Steps)
+	}
+}
+
+// Debugger runs a machine under the control of breakpoints. The machine
+// runs inside an assertion context so that inbox, log and send instructions
+// behave as they do in a validator
+type Debugger struct {
+	mach       *vm.Machine
+	timeBounds *protocol.TimeBounds
+	context    *vm.MachineAssertionContext
+
+	pcBreakpoints map[int64]bool
+	opBreakpoints map[value.Opcode]bool
+
+	// BreakOnError stops the machine after any instruction which raises an
+	// error, before the error handler runs
+	BreakOnError bool
+
+	lastResult vm.StepResult
+	totalSteps uint64
+	totalGas   uint64
+	logs       []value.Value
+	outMsgs    []value.Value
+}
+
+func New(mach *vm.Machine, timeBounds *protocol.TimeBounds, inbox value.TupleValue) *Debugger {
+	d := &Debugger{
+		mach:          mach,
+		timeBounds:    timeBounds,
+		pcBreakpoints: make(map[int64]bool),
+		opBreakpoints: make(map[value.Opcode]bool),
+	}
+	d.context = vm.NewMachineAssertionContext(mach, timeBounds, inbox)
+	mach.SetTracer(d)
+	return d
+}
+
+func (d *Debugger) Machine() *vm.Machine {
+	return d.mach
+}
+
+func (d *Debugger) TimeBounds() *protocol.TimeBounds {
+	return d.timeBounds
+}
+
+func (d *Debugger) TotalSteps() uint64 {
+	return d.totalSteps
+}
+
+func (d *Debugger) TotalGas() uint64 {
+	return d.totalGas
+}
+
+// Logs returns every value logged by the machine while debugging
+func (d *Debugger) Logs() []value.Value {
+	return d.logs
+}
+
+// OutMessages returns every message sent by the machine while debugging
+func (d *Debugger) OutMessages() []value.Value {
+	return d.outMsgs
+}
+
+// PendingInbox returns the messages that have been delivered but not yet
+// read by the machine
+func (d *Debugger) PendingInbox() value.TupleValue {
+	return d.context.GetInbox()
+}
+
+// collect gathers the output of the current assertion context and replaces
+// it with a fresh one which has the given inbox
+func (d *Debugger) collect(inbox value.TupleValue) {
+	assertion, _ := d.context.Finalize(d.mach)
+	d.logs = append(d.logs, assertion.Logs...)
+	d.outMsgs = append(d.outMsgs, assertion.OutMsgs...)
+	d.context = vm.NewMachineAssertionContext(d.mach, d.timeBounds, inbox)
+}
+
+// flush gathers the output of the current assertion context, keeping any
+// messages the machine hasn't read yet
+func (d *Debugger) flush() {
+	d.collect(d.context.GetInbox())
+}
+
+// Deliver adds messages to the machine's inbox which it will receive the
+// next time it executes an inbox instruction
+func (d *Debugger) Deliver(msgs ...message.Message) {
+	inbox := d.context.GetInbox()
+	for _, msg := range msgs {
+		inbox = message.AddToPrev(inbox, msg)
+	}
+	d.collect(inbox)
+}
+
+func (d *Debugger) SetTimeBounds(timeBounds *protocol.TimeBounds) {
+	d.timeBounds = timeBounds
+	d.flush()
+}
+
+func (d *Debugger) AddPCBreakpoint(pc int64) {
+	d.pcBreakpoints[pc] = true
+}
+
+func (d *Debugger) RemovePCBreakpoint(pc int64) bool {
+	if !d.pcBreakpoints[pc] {
+		return false
+	}
+	delete(d.pcBreakpoints, pc)
+	return true
+}
+
+func (d *Debugger) AddOpBreakpoint(op value.Opcode) {
+	d.opBreakpoints[op] = true
+}
+
+func (d *Debugger) RemoveOpBreakpoint(op value.Opcode) bool {
+	if !d.opBreakpoints[op] {
+		return false
+	}
+	delete(d.opBreakpoints, op)
+	return true
+}
+
+// PCBreakpoints returns the PCs of all breakpoints in ascending order
+func (d *Debugger) PCBreakpoints() []int64 {
+	pcs := make([]int64, 0, len(d.pcBreakpoints))
+	for pc := range d.pcBreakpoints {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	return pcs
+}
+
+// OpBreakpoints returns the names of all opcodes with a breakpoint in
+// alphabetical order
+func (d *Debugger) OpBreakpoints() []string {
+	names := make([]string, 0, len(d.opBreakpoints))
+	for op := range d.opBreakpoints {
+		names = append(names, code.InstructionNames[op])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeforeStep implements vm.Tracer
+func (d *Debugger) BeforeStep(*vm.Machine, value.Operation) {}
+
+// AfterStep implements vm.Tracer
+func (d *Debugger) AfterStep(_ *vm.Machine, _ value.Operation, result vm.StepResult) {
+	d.lastResult = result
+	d.totalGas += result.Gas
+}
+
+// stopped returns the reason the machine can't run any further, if any
+func (d *Debugger) stopped() (StopReason, bool) {
+	switch {
+	case d.mach.IsHalted():
+		return StopHalted, true
+	case d.mach.IsErrored() || d.mach.HaveSizeException():
+		return StopErrored, true
+	default:
+		return 0, false
+	}
+}
+
+// run executes instructions until done returns true after a step, a
+// breakpoint is reached, the machine can't continue or maxSteps steps have
+// been run
+func (d *Debugger) run(maxSteps uint64, done func() bool) Stop {
+	var steps uint64
+	for steps < maxSteps {
+		if reason, ok := d.stopped(); ok {
+			return Stop{Reason: reason, Steps: steps}
+		}
+		d.lastResult = vm.StepResult{}
+		_, blocked := vm.RunInstruction(d.mach, d.mach.GetOperation())
+		if _, ok := blocked.(machine.BreakpointBlocked); ok {
+			// The breakpoint instruction advances the PC before blocking
+			// so continuing resumes after it
+			steps++
+			d.totalSteps++
+			return Stop{Reason: StopBreakpointInsn, Steps: steps}
+		}
+		if blocked != nil {
+			return Stop{Reason: StopBlocked, Steps: steps, Blocked: blocked}
+		}
+		steps++
+		d.totalSteps++
+
+		if d.lastResult.Err != nil && d.BreakOnError {
+			return Stop{Reason: StopError, Steps: steps, Err: d.lastResult.Err}
+		}
+		if reason, ok := d.stopped(); ok {
+			return Stop{Reason: reason, Steps: steps}
+		}
+		if done() {
+			return Stop{Reason: StopStep, Steps: steps}
+		}
+		if d.pcBreakpoints[d.mach.PCIndex()] {
+			return Stop{Reason: StopPCBreakpoint, Steps: steps}
+		}
+		if d.opBreakpoints[d.mach.GetOperation().GetOp()] {
+			return Stop{Reason: StopOpBreakpoint, Steps: steps}
+		}
+	}
+	return Stop{Reason: StopStepLimit, Steps: steps}
+}
+
+// Step executes up to count instructions
+func (d *Debugger) Step(count uint64) Stop {
+	defer d.flush()
+	var steps uint64
+	return d.run(count, func() bool {
+		steps++
+		return steps >= count
+	})
+}
+
+// StepOver executes a single instruction unless the machine is at a jump,
+// in which case it runs until execution returns to the instruction after
+// the jump. This steps over calls to functions which return to their call
+// site
+func (d *Debugger) StepOver(maxSteps uint64) Stop {
+	if _, ok := d.stopped(); ok {
+		return d.Step(1)
+	}
+	op := d.mach.GetOperation().GetOp()
+	if op != code.JUMP && op != code.CJUMP {
+		return d.Step(1)
+	}
+	defer d.flush()
+	returnPC := d.mach.PCIndex() + 1
+	return d.run(maxSteps, func() bool {
+		return d.mach.PCIndex() == returnPC
+	})
+}
+
+// Continue runs the machine until it stops for any reason other than
+// completing a step
+func (d *Debugger) Continue(maxSteps uint64) Stop {
+	defer d.flush()
+	return d.run(maxSteps, func() bool {
+		return false
+	})
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debugger
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+)
+
+func codePoint(pc int64) value.CodePointValue {
+	return value.CodePointValue{InsnNum: pc, Op: value.BasicOperation{Op: code.NOP}}
+}
+
+func newTestDebugger() *Debugger {
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(6)},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(1)},
+		value.BasicOperation{Op: code.BREAKPOINT},
+		value.ImmediateOperation{Op: code.INBOX, Val: value.NewInt64Value(1000)},
+		value.BasicOperation{Op: code.LOG},
+		value.BasicOperation{Op: code.HALT},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(2)},
+		value.BasicOperation{Op: code.POP},
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(1)},
+	}
+	mach := vm.NewMachine(insns, value.NewEmptyTuple(), false, 1000)
+	timeBounds := &protocol.TimeBounds{
+		LowerBoundBlock:     common.NewTimeBlocksInt(0),
+		UpperBoundBlock:     common.NewTimeBlocksInt(100),
+		LowerBoundTimestamp: big.NewInt(0),
+		UpperBoundTimestamp: big.NewInt(100),
+	}
+	return New(mach, timeBounds, value.NewEmptyTuple())
+}
+
+func checkStop(t *testing.T, d *Debugger, stop Stop, reason StopReason, pc int64) {
+	t.Helper()
+	if stop.Reason != reason {
+		t.Fatalf("expected to stop for %v but stopped for %v", reason, stop)
+	}
+	if d.Machine().PCIndex() != pc {
+		t.Fatalf("expected to stop at pc %v but stopped at %v", pc, d.Machine().PCIndex())
+	}
+}
+
+func TestStepOverAndInbox(t *testing.T) {
+	d := newTestDebugger()
+	stop := d.StepOver(100)
+	checkStop(t, d, stop, StopStep, 1)
+	if stop.Steps != 4 {
+		t.Error("expected step over to run 4 steps, ran", stop.Steps)
+	}
+
+	checkStop(t, d, d.Continue(100), StopBreakpointInsn, 3)
+	checkStop(t, d, d.Continue(100), StopBlocked, 3)
+
+	d.Deliver(message.DeliveredEth{
+		Eth: message.Eth{
+			To:    common.Address{1},
+			From:  common.Address{2},
+			Value: big.NewInt(5),
+		},
+		BlockNum:   common.NewTimeBlocksInt(0),
+		Timestamp:  big.NewInt(0),
+		MessageNum: big.NewInt(0),
+	})
+	checkStop(t, d, d.Continue(100), StopHalted, 5)
+	if len(d.Logs()) != 1 {
+		t.Fatal("expected machine to log its inbox, got", len(d.Logs()), "logs")
+	}
+	if d.TotalSteps() != 9 {
+		t.Error("expected 9 total steps, got", d.TotalSteps())
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	d := newTestDebugger()
+	d.AddPCBreakpoint(7)
+	d.AddOpBreakpoint(code.LOG)
+	checkStop(t, d, d.Continue(100), StopPCBreakpoint, 7)
+	checkStop(t, d, d.Step(1), StopStep, 8)
+	if !d.RemovePCBreakpoint(7) || d.RemovePCBreakpoint(7) {
+		t.Error("breakpoint should be removed exactly once")
+	}
+	checkStop(t, d, d.Continue(100), StopBreakpointInsn, 3)
+	d.Deliver(message.Call{
+		To:        common.Address{1},
+		From:      common.Address{2},
+		Data:      []byte{1, 2, 3, 4},
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	})
+	checkStop(t, d, d.Continue(100), StopOpBreakpoint, 4)
+	checkStop(t, d, d.Continue(1), StopStepLimit, 5)
+}
+
+func TestBreakOnError(t *testing.T) {
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.ERRSET, Val: codePoint(3)},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewEmptyTuple()},
+		value.ImmediateOperation{Op: code.ADD, Val: value.NewInt64Value(1)},
+		value.BasicOperation{Op: code.HALT},
+	}
+	mach := vm.NewMachine(insns, value.NewEmptyTuple(), false, 1000)
+	d := New(mach, &protocol.TimeBounds{
+		LowerBoundBlock:     common.NewTimeBlocksInt(0),
+		UpperBoundBlock:     common.NewTimeBlocksInt(100),
+		LowerBoundTimestamp: big.NewInt(0),
+		UpperBoundTimestamp: big.NewInt(100),
+	}, value.NewEmptyTuple())
+	d.BreakOnError = true
+	stop := d.Continue(100)
+	checkStop(t, d, stop, StopError, 3)
+	if stop.Err == nil {
+		t.Error("stop should include the error")
+	}
+	checkStop(t, d, d.Continue(100), StopHalted, 3)
+}
+
+func TestFormatValue(t *testing.T) {
+	val := value.NewTuple2(value.NewInt64Value(1), value.NewTuple2(value.NewInt64Value(2), value.NewEmptyTuple()))
+	formatted := FormatValue(val, 1)
+	lines := strings.Split(formatted, "\n")
+	if len(lines) != 3 || lines[1] != "  [0] 1" || !strings.HasPrefix(lines[2], "  [1] Tuple(len: 2, hash:") {
+		t.Error("unexpected formatting", formatted)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// FormatValue renders val as an indented tree with one line per value.
+// Tuples nested deeper than maxDepth are shown by their hash
+func FormatValue(val value.Value, maxDepth int) string {
+	var sb strings.Builder
+	writeValue(&sb, val, "", maxDepth)
+	return sb.String()
+}
+
+// FormatOperation renders an instruction as its mnemonic followed by its
+// immediate value if it has one
+func FormatOperation(op value.Operation) string {
+	name, ok := code.InstructionNames[op.GetOp()]
+	if !ok {
+		name = fmt.Sprintf("0x%02x", byte(op.GetOp()))
+	}
+	if immediate, ok := op.(value.ImmediateOperation); ok {
+		return fmt.Sprintf("%v %v", name, formatScalar(immediate.Val))
+	}
+	return name
+}
+
+func formatScalar(val value.Value) string {
+	switch val := val.(type) {
+	case value.IntValue:
+		return val.BigInt().String()
+	case value.CodePointValue:
+		return fmt.Sprintf("CodePoint(%v)", val.InsnNum)
+	case value.TupleValue:
+		if val.Len() == 0 {
+			return "Tuple()"
+		}
+		return fmt.Sprintf("Tuple(len: %v, hash: %v)", val.Len(), val.Hash())
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func writeValue(sb *strings.Builder, val value.Value, indent string, depth int) {
+	tup, ok := val.(value.TupleValue)
+	if !ok || tup.Len() == 0 || depth <= 0 {
+		sb.WriteString(formatScalar(val))
+		return
+	}
+	fmt.Fprintf(sb, "Tuple(len: %v)", tup.Len())
+	for i, child := range tup.Contents() {
+		fmt.Fprintf(sb, "\n%v  [%v] ", indent, i)
+		writeValue(sb, child, indent+"  ", depth-1)
+	}
+}
@@ -6,8 +6,12 @@ require (
 	github.com/dgraph-io/badger v1.6.1
 	github.com/ethereum/go-ethereum v1.9.13
 	github.com/offchainlabs/arbitrum/packages/arb-util v0.5.0
+	github.com/offchainlabs/arbitrum/packages/arb-validator-core v0.5.0
 	github.com/robertkrimen/otto v0.0.0-20170205013659-6a77b7cbc37d // indirect
 	gopkg.in/sourcemap.v1 v1.0.5 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 replace github.com/offchainlabs/arbitrum/packages/arb-util => ../arb-util
+
+replace github.com/offchainlabs/arbitrum/packages/arb-validator-core => ../arb-validator-core
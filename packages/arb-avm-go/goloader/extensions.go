@@ -0,0 +1,275 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package goloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const (
+	CompilerInfoExtensionID uint32 = 1
+	SourceMapExtensionID    uint32 = 2
+	ABIExtensionID          uint32 = 3
+)
+
+// Extension is the decoded form of an AO file extension
+type Extension interface {
+	ExtensionID() uint32
+	MarshalExtension() ([]byte, error)
+}
+
+// ExtensionDecoder parses the data of an extension
+type ExtensionDecoder func(data []byte) (Extension, error)
+
+var extensionsMut sync.RWMutex
+var extensionDecoders = make(map[uint32]ExtensionDecoder)
+
+func init() {
+	RegisterExtension(CompilerInfoExtensionID, decodeCompilerInfo)
+	RegisterExtension(SourceMapExtensionID, decodeSourceMap)
+	RegisterExtension(ABIExtensionID, decodeContractABI)
+}
+
+// RegisterExtension adds a decoder for extensions with the given id. It
+// panics if the id is 0 or already has a decoder
+func RegisterExtension(id uint32, decoder ExtensionDecoder) {
+	extensionsMut.Lock()
+	defer extensionsMut.Unlock()
+	if id == 0 {
+		panic("extension id 0 is reserved")
+	}
+	if _, ok := extensionDecoders[id]; ok {
+		panic(fmt.Sprintf("extension %v registered twice", id))
+	}
+	extensionDecoders[id] = decoder
+}
+
+// NewRawExtension encodes ext for inclusion in a Program
+func NewRawExtension(ext Extension) (RawExtension, error) {
+	data, err := ext.MarshalExtension()
+	if err != nil {
+		return RawExtension{}, err
+	}
+	return RawExtension{ID: ext.ExtensionID(), Data: data}, nil
+}
+
+// Decode parses the extension with the decoder registered for its id. The
+// second return value is false if no decoder is registered
+func (ext RawExtension) Decode() (Extension, bool, error) {
+	extensionsMut.RLock()
+	decoder, ok := extensionDecoders[ext.ID]
+	extensionsMut.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	decoded, err := decoder(ext.Data)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid extension %v: %v", ext.ID, err)
+	}
+	return decoded, true, nil
+}
+
+// Extensions holds the decoded extensions of a program. Extensions without
+// a registered decoder are kept in Unknown. The program itself always keeps
+// its raw extensions so that writing it out reproduces them unchanged
+type Extensions struct {
+	Known   map[uint32]Extension
+	Unknown []RawExtension
+}
+
+// DecodeExtensions parses every extension of the program which has a
+// registered decoder
+func (p *Program) DecodeExtensions() (*Extensions, error) {
+	ret := &Extensions{Known: make(map[uint32]Extension)}
+	for _, raw := range p.Extensions {
+		decoded, ok, err := raw.Decode()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			ret.Unknown = append(ret.Unknown, raw)
+			continue
+		}
+		if _, ok := ret.Known[raw.ID]; ok {
+			return nil, fmt.Errorf("program has more than one extension %v", raw.ID)
+		}
+		ret.Known[raw.ID] = decoded
+	}
+	return ret, nil
+}
+
+// CompilerInfo returns the compiler info extension or nil if there isn't one
+func (e *Extensions) CompilerInfo() *CompilerInfo {
+	info, _ := e.Known[CompilerInfoExtensionID].(*CompilerInfo)
+	return info
+}
+
+// SourceMap returns the source map extension or nil if there isn't one
+func (e *Extensions) SourceMap() *SourceMap {
+	sourceMap, _ := e.Known[SourceMapExtensionID].(*SourceMap)
+	return sourceMap
+}
+
+// ABI returns the contract ABI extension or nil if there isn't one
+func (e *Extensions) ABI() *ContractABI {
+	contractABI, _ := e.Known[ABIExtensionID].(*ContractABI)
+	return contractABI
+}
+
+// CompilerInfo records the compiler which produced a program
+type CompilerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func decodeCompilerInfo(data []byte) (Extension, error) {
+	info := &CompilerInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *CompilerInfo) ExtensionID() uint32 {
+	return CompilerInfoExtensionID
+}
+
+func (c *CompilerInfo) MarshalExtension() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *CompilerInfo) String() string {
+	return fmt.Sprintf("%v %v", c.Name, c.Version)
+}
+
+// SourceLocation is a position in a source file. Lines and columns start
+// at 1
+type SourceLocation struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (l SourceLocation) String() string {
+	if l.Column == 0 {
+		return fmt.Sprintf("%v:%v", l.File, l.Line)
+	}
+	return fmt.Sprintf("%v:%v:%v", l.File, l.Line, l.Column)
+}
+
+// SourceMapEntry marks the instructions from PC up to the PC of the next
+// entry as compiled from a location in Files[File]
+type SourceMapEntry struct {
+	PC     int64 `json:"pc"`
+	File   int   `json:"file"`
+	Line   int   `json:"line"`
+	Column int   `json:"column,omitempty"`
+}
+
+// SourceMap maps instructions to the source code they were compiled from.
+// It implements vm.Locator
+type SourceMap struct {
+	Files   []string         `json:"files"`
+	Entries []SourceMapEntry `json:"entries"`
+}
+
+func decodeSourceMap(data []byte) (Extension, error) {
+	sourceMap := &SourceMap{}
+	if err := json.Unmarshal(data, sourceMap); err != nil {
+		return nil, err
+	}
+	for _, entry := range sourceMap.Entries {
+		if entry.File < 0 || entry.File >= len(sourceMap.Files) {
+			return nil, fmt.Errorf("source map entry for pc %v refers to unknown file %v", entry.PC, entry.File)
+		}
+	}
+	sort.SliceStable(sourceMap.Entries, func(i, j int) bool {
+		return sourceMap.Entries[i].PC < sourceMap.Entries[j].PC
+	})
+	return sourceMap, nil
+}
+
+func (s *SourceMap) ExtensionID() uint32 {
+	return SourceMapExtensionID
+}
+
+func (s *SourceMap) MarshalExtension() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// Lookup returns the source location of the instruction at pc
+func (s *SourceMap) Lookup(pc int64) (SourceLocation, bool) {
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].PC > pc
+	})
+	if i == 0 {
+		return SourceLocation{}, false
+	}
+	entry := s.Entries[i-1]
+	return SourceLocation{
+		File:   s.Files[entry.File],
+		Line:   entry.Line,
+		Column: entry.Column,
+	}, true
+}
+
+// Locate implements vm.Locator
+func (s *SourceMap) Locate(pc int64) (string, bool) {
+	loc, ok := s.Lookup(pc)
+	if !ok {
+		return "", false
+	}
+	return loc.String(), true
+}
+
+// ContractABI is the Ethereum ABI of the contract a program implements
+type ContractABI struct {
+	abi.ABI
+	definition []byte
+}
+
+func decodeContractABI(data []byte) (Extension, error) {
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &ContractABI{ABI: parsed, definition: data}, nil
+}
+
+// NewContractABI creates an ABI extension from its JSON definition
+func NewContractABI(definition []byte) (*ContractABI, error) {
+	ext, err := decodeContractABI(definition)
+	if err != nil {
+		return nil, err
+	}
+	return ext.(*ContractABI), nil
+}
+
+func (c *ContractABI) ExtensionID() uint32 {
+	return ABIExtensionID
+}
+
+// MarshalExtension returns the JSON definition the ABI was created from
+func (c *ContractABI) MarshalExtension() ([]byte, error) {
+	return c.definition, nil
+}
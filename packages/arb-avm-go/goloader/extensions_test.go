@@ -0,0 +1,117 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package goloader
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+const testABI = `[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[]}]`
+
+func TestExtensions(t *testing.T) {
+	contractABI, err := NewContractABI([]byte(testABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceMap := &SourceMap{
+		Files: []string{"a.sol", "b.sol"},
+		Entries: []SourceMapEntry{
+			{PC: 0, File: 0, Line: 3, Column: 5},
+			{PC: 2, File: 1, Line: 10},
+		},
+	}
+	program := &Program{
+		Version: CurrentAOVersion,
+		Code: []value.Operation{
+			value.BasicOperation{Op: code.NOP},
+			value.BasicOperation{Op: code.NOP},
+			value.BasicOperation{Op: code.HALT},
+		},
+		Static: value.NewEmptyTuple(),
+	}
+	for _, ext := range []Extension{&CompilerInfo{Name: "arbc", Version: "0.5.0"}, sourceMap, contractABI} {
+		raw, err := NewRawExtension(ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program.Extensions = append(program.Extensions, raw)
+	}
+	unknown := RawExtension{ID: 1000, Data: []byte{1, 2, 3}}
+	program.Extensions = append(program.Extensions, unknown)
+
+	var buf bytes.Buffer
+	if err := program.Marshal(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	loaded, err := ReadProgram(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf = bytes.Buffer{}
+	if err := loaded.Marshal(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("extensions changed after reloading the program")
+	}
+
+	exts, err := loaded.DecodeExtensions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exts.Known) != 3 || len(exts.Unknown) != 1 || exts.Unknown[0].ID != unknown.ID {
+		t.Fatal("unexpected extensions", exts)
+	}
+	if info := exts.CompilerInfo(); info == nil || info.String() != "arbc 0.5.0" {
+		t.Error("wrong compiler info", info)
+	}
+	if abi := exts.ABI(); abi == nil || len(abi.Methods["transfer"].Inputs) != 2 {
+		t.Error("wrong ABI", abi)
+	}
+	if loc, ok := exts.SourceMap().Lookup(1); !ok || loc.String() != "a.sol:3:5" {
+		t.Error("wrong location for pc 1", loc)
+	}
+
+	mach := loaded.NewMachine(false)
+	if loc, ok := mach.SourceLocation(2); !ok || loc != "b.sol:10" {
+		t.Error("machine has wrong location for pc 2", loc)
+	}
+}
+
+func TestInvalidSourceMap(t *testing.T) {
+	raw := RawExtension{ID: SourceMapExtensionID, Data: []byte(`{"files":[],"entries":[{"pc":0,"file":0,"line":1}]}`)}
+	if _, known, err := raw.Decode(); !known || err == nil {
+		t.Error("expected source map with a missing file to be invalid")
+	}
+	program := &Program{
+		Version:    CurrentAOVersion,
+		Extensions: []RawExtension{raw},
+		Code:       []value.Operation{value.BasicOperation{Op: code.HALT}},
+		Static:     value.NewEmptyTuple(),
+	}
+	if _, err := program.DecodeExtensions(); err == nil {
+		t.Error("expected decoding extensions to fail")
+	}
+	if _, ok := program.NewMachine(false).SourceLocation(0); ok {
+		t.Error("machine shouldn't use an invalid source map")
+	}
+}
@@ -18,6 +18,7 @@ package goloader
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -26,9 +27,11 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 )
 
+// RawExtension is an extension section of an AO file. Extensions carry
+// information for tools such as debuggers and don't affect execution
 type RawExtension struct {
-	id   uint32
-	data []byte
+	ID   uint32
+	Data []byte
 }
 
 type Error struct {
@@ -51,7 +54,52 @@ func LoadMachineFromFile(fileName string, warnMode bool) (*vm.Machine, error) {
 
 const CurrentAOVersion uint32 = 1
 
+// Program is the contents of an AO file
+type Program struct {
+	Version    uint32
+	Extensions []RawExtension
+	Code       []value.Operation
+	Static     value.Value
+}
+
 func LoadMachine(rd io.Reader, warnMode bool) (*vm.Machine, error) {
+	program, err := ReadProgram(rd)
+	if err != nil {
+		return nil, err
+	}
+	return program.NewMachine(warnMode), nil
+}
+
+// NewMachine creates a machine running the program. If the program has a
+// valid source map, the machine uses it to report source locations
+func (p *Program) NewMachine(warnMode bool) *vm.Machine {
+	maxSize := int64(1) << 62
+	mach := vm.NewMachine(p.Code, p.Static, warnMode, maxSize)
+	for _, raw := range p.Extensions {
+		if raw.ID != SourceMapExtensionID {
+			continue
+		}
+		// Debug information is optional so a broken source map doesn't
+		// prevent the program from running
+		if sourceMap, ok, err := raw.Decode(); ok && err == nil {
+			mach.SetLocator(sourceMap.(*SourceMap))
+		}
+		break
+	}
+	return mach
+}
+
+func ReadProgramFromFile(fileName string) (*Program, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadProgram(f)
+}
+
+func ReadProgram(rd io.Reader) (*Program, error) {
 	var aoVersion uint32
 	err := binary.Read(rd, binary.BigEndian, &aoVersion)
 	if err != nil {
@@ -76,13 +124,13 @@ func LoadMachine(rd io.Reader, warnMode bool) (*vm.Machine, error) {
 				return nil, err
 			}
 			extensionData := make([]byte, extensionLength)
-			_, err = rd.Read(extensionData)
+			_, err = io.ReadFull(rd, extensionData)
 			if err != nil {
 				return nil, err
 			}
 			extensions = append(extensions, RawExtension{
-				id:   extensionID,
-				data: extensionData,
+				ID:   extensionID,
+				Data: extensionData,
 			})
 		}
 	}
@@ -105,6 +153,43 @@ func LoadMachine(rd io.Reader, warnMode bool) (*vm.Machine, error) {
 		return nil, err2
 	}
 
-	maxSize := int64(1) << 62
-	return vm.NewMachine(insns, static, warnMode, maxSize), nil
+	return &Program{
+		Version:    aoVersion,
+		Extensions: extensions,
+		Code:       insns,
+		Static:     static,
+	}, nil
+}
+
+// Marshal writes the program in the format read by ReadProgram
+func (p *Program) Marshal(wr io.Writer) error {
+	if err := binary.Write(wr, binary.BigEndian, p.Version); err != nil {
+		return err
+	}
+	for _, ext := range p.Extensions {
+		if ext.ID == 0 {
+			return errors.New("extension id 0 is reserved to end the extension list")
+		}
+		if err := binary.Write(wr, binary.BigEndian, ext.ID); err != nil {
+			return err
+		}
+		if err := binary.Write(wr, binary.BigEndian, uint32(len(ext.Data))); err != nil {
+			return err
+		}
+		if _, err := wr.Write(ext.Data); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(wr, binary.BigEndian, uint32(0)); err != nil {
+		return err
+	}
+	if err := binary.Write(wr, binary.BigEndian, uint64(len(p.Code))); err != nil {
+		return err
+	}
+	for _, op := range p.Code {
+		if err := value.MarshalOperation(op, wr); err != nil {
+			return err
+		}
+	}
+	return value.MarshalValue(p.Static, wr)
 }
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package profiler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// Weight selects which cost of a Sample is reported
+type Weight int
+
+const (
+	WeightGas Weight = iota
+	WeightSteps
+)
+
+func ParseWeight(name string) (Weight, error) {
+	switch name {
+	case "gas":
+		return WeightGas, nil
+	case "steps":
+		return WeightSteps, nil
+	default:
+		return 0, errors.New("profile weight must be gas or steps")
+	}
+}
+
+func (w Weight) String() string {
+	if w == WeightSteps {
+		return "steps"
+	}
+	return "gas"
+}
+
+func (w Weight) of(s Sample) uint64 {
+	if w == WeightSteps {
+		return s.Steps
+	}
+	return s.Gas
+}
+
+// WriteFolded writes the profile in the folded stack format read by
+// flamegraph.pl, speedscope and similar tools. Each line is a call stack of
+// functions separated by semicolons followed by the cost of instructions
+// executed with exactly that stack
+func (p *Profiler) WriteFolded(w io.Writer, weight Weight) error {
+	stacks := make([]string, 0, len(p.byStack))
+	for stack := range p.byStack {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	for _, stack := range stacks {
+		cost := weight.of(*p.byStack[stack])
+		if cost == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%v %v\n", stack, cost); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type entry struct {
+	name   string
+	sample Sample
+}
+
+func topEntries(entries []entry, weight Weight, count int) []entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return weight.of(entries[i].sample) > weight.of(entries[j].sample)
+	})
+	if count > 0 && len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries
+}
+
+func writeTable(w io.Writer, title string, entries []entry, total Sample) error {
+	if _, err := fmt.Fprintf(w, "%v\n%12v %20v\n", title, "steps", "gas"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_, err := fmt.Fprintf(
+			w,
+			"%12d %6.2f%% %12d %6.2f%%  %v\n",
+			e.sample.Steps,
+			percent(e.sample.Steps, total.Steps),
+			e.sample.Gas,
+			percent(e.sample.Gas, total.Gas),
+			e.name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func percent(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+// WriteReport writes tables of the count most expensive functions, opcodes
+// and instructions sorted by weight. Function costs exclude the functions
+// they call. insns is used to show the instruction at each PC and may be nil
+func (p *Profiler) WriteReport(w io.Writer, insns []value.Operation, weight Weight, count int) error {
+	if _, err := fmt.Fprintf(w, "Total: %v steps, %v gas\n\n", p.total.Steps, p.total.Gas); err != nil {
+		return err
+	}
+
+	functions := make([]entry, 0, len(p.byFunction))
+	for pc, s := range p.byFunction {
+		functions = append(functions, entry{functionName(pc), *s})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].name < functions[j].name })
+	if err := writeTable(w, "Functions:", topEntries(functions, weight, count), p.total); err != nil {
+		return err
+	}
+
+	ops := make([]entry, 0, len(p.byOp))
+	for op, s := range p.byOp {
+		name, ok := code.InstructionNames[op]
+		if !ok {
+			name = fmt.Sprintf("0x%02x", byte(op))
+		}
+		ops = append(ops, entry{name, *s})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].name < ops[j].name })
+	if err := writeTable(w, "Opcodes:", topEntries(ops, weight, count), p.total); err != nil {
+		return err
+	}
+
+	pcs := make([]int64, 0, len(p.byPC))
+	for pc := range p.byPC {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	insnEntries := make([]entry, 0, len(pcs))
+	for _, pc := range pcs {
+		name := fmt.Sprintf("pc %v", pc)
+		if pc >= 0 && pc < int64(len(insns)) {
+			name = fmt.Sprintf("pc %v: %v", pc, code.InstructionNames[insns[pc].GetOp()])
+		}
+		insnEntries = append(insnEntries, entry{name, *p.byPC[pc]})
+	}
+	return writeTable(w, "Instructions:", topEntries(insnEntries, weight, count), p.total)
+}
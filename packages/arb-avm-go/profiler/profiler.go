@@ -0,0 +1,206 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package profiler
+
+import (
+	"fmt"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// Calls are recognized by looking for the return address among the values
+// this close to the top of the stack or aux stack after a jump
+const returnAddressSearchDepth = 3
+
+// Unreturned calls past this depth are treated as jumps so that code which
+// never returns to its caller can't grow the call stack without bound
+const maxCallDepth = 256
+
+// Sample is the cost of a set of executed instructions
+type Sample struct {
+	Steps uint64
+	Gas   uint64
+}
+
+func (s *Sample) add(gas uint64) {
+	s.Steps++
+	s.Gas += gas
+}
+
+type frame struct {
+	// entry is the PC the function was called at
+	entry int64
+	// ret is the PC execution continues at when the function returns
+	ret int64
+	// stack is the folded call stack ending with this frame
+	stack string
+}
+
+// Profiler records the steps and gas used by every instruction a machine
+// executes, aggregated by PC, by opcode and by function.
+//
+// AVM code has no call instruction so functions are reconstructed from
+// jumps. A jump from pc which leaves the code point pc+1 near the top of the
+// stack or aux stack is treated as a call to the jump target, and a jump back
+// to the return address of a call on the call stack is treated as a return
+// from it. Every other jump stays within the current function
+type Profiler struct {
+	byPC       map[int64]*Sample
+	byOp       map[value.Opcode]*Sample
+	byFunction map[int64]*Sample
+	byStack    map[string]*Sample
+
+	frames []frame
+	pc     int64
+	total  Sample
+}
+
+func New() *Profiler {
+	return &Profiler{
+		byPC:       make(map[int64]*Sample),
+		byOp:       make(map[value.Opcode]*Sample),
+		byFunction: make(map[int64]*Sample),
+		byStack:    make(map[string]*Sample),
+	}
+}
+
+func functionName(entry int64) string {
+	return fmt.Sprintf("func@%d", entry)
+}
+
+// BeforeStep implements vm.Tracer
+func (p *Profiler) BeforeStep(m *vm.Machine, op value.Operation) {
+	p.pc = m.PCIndex()
+	if len(p.frames) == 0 {
+		// Execution is attributed to the function containing the first
+		// instruction profiled until the profiler sees it return
+		p.frames = append(p.frames, frame{
+			entry: p.pc,
+			ret:   -1,
+			stack: functionName(p.pc),
+		})
+	}
+}
+
+// AfterStep implements vm.Tracer
+func (p *Profiler) AfterStep(m *vm.Machine, op value.Operation, result vm.StepResult) {
+	if result.Blocked != nil {
+		return
+	}
+	current := p.frames[len(p.frames)-1]
+	p.total.add(result.Gas)
+	sampleFor(p.byPC, p.pc).add(result.Gas)
+	sampleForOp(p.byOp, op.GetOp()).add(result.Gas)
+	sampleFor(p.byFunction, current.entry).add(result.Gas)
+	sampleForStack(p.byStack, current.stack).add(result.Gas)
+
+	if result.Err != nil || (op.GetOp() != code.JUMP && op.GetOp() != code.CJUMP) {
+		return
+	}
+	target := m.PCIndex()
+	if target == p.pc+1 {
+		// Untaken conditional jump
+		return
+	}
+	for i := len(p.frames) - 1; i > 0; i-- {
+		if p.frames[i].ret == target {
+			p.frames = p.frames[:i]
+			return
+		}
+	}
+	if len(p.frames) < maxCallDepth && hasReturnAddress(m, p.pc+1) {
+		p.frames = append(p.frames, frame{
+			entry: target,
+			ret:   p.pc + 1,
+			stack: current.stack + ";" + functionName(target),
+		})
+	}
+}
+
+func hasReturnAddress(m *vm.Machine, ret int64) bool {
+	vals := append(m.Stack().Top(returnAddressSearchDepth), m.AuxStack().Top(returnAddressSearchDepth)...)
+	for _, val := range vals {
+		if cp, ok := val.(value.CodePointValue); ok && cp.InsnNum == ret {
+			return true
+		}
+	}
+	return false
+}
+
+func sampleFor(samples map[int64]*Sample, key int64) *Sample {
+	s, ok := samples[key]
+	if !ok {
+		s = &Sample{}
+		samples[key] = s
+	}
+	return s
+}
+
+func sampleForOp(samples map[value.Opcode]*Sample, key value.Opcode) *Sample {
+	s, ok := samples[key]
+	if !ok {
+		s = &Sample{}
+		samples[key] = s
+	}
+	return s
+}
+
+func sampleForStack(samples map[string]*Sample, key string) *Sample {
+	s, ok := samples[key]
+	if !ok {
+		s = &Sample{}
+		samples[key] = s
+	}
+	return s
+}
+
+// Total returns the cost of every instruction profiled
+func (p *Profiler) Total() Sample {
+	return p.total
+}
+
+// PC returns the cost of the instruction at pc
+func (p *Profiler) PC(pc int64) Sample {
+	if s, ok := p.byPC[pc]; ok {
+		return *s
+	}
+	return Sample{}
+}
+
+// Opcode returns the cost of every instruction with the given opcode
+func (p *Profiler) Opcode(op value.Opcode) Sample {
+	if s, ok := p.byOp[op]; ok {
+		return *s
+	}
+	return Sample{}
+}
+
+// Function returns the cost of the instructions executed directly by the
+// function starting at entry, excluding the functions it calls
+func (p *Profiler) Function(entry int64) Sample {
+	if s, ok := p.byFunction[entry]; ok {
+		return *s
+	}
+	return Sample{}
+}
+
+// Depth returns the current depth of the reconstructed call stack
+func (p *Profiler) Depth() int {
+	return len(p.frames)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package profiler
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+func codePoint(pc int64) value.CodePointValue {
+	return value.CodePointValue{InsnNum: pc, Op: value.BasicOperation{Op: code.NOP}}
+}
+
+func TestProfileCall(t *testing.T) {
+	insns := []value.Operation{
+		// Call the function at 4, returning to 2
+		value.ImmediateOperation{Op: code.NOP, Val: codePoint(2)},
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(4)},
+		value.BasicOperation{Op: code.HALT},
+		value.BasicOperation{Op: code.HALT},
+		// A jump within the function followed by the return
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(5)},
+		value.BasicOperation{Op: code.JUMP},
+	}
+	mach := vm.NewMachine(insns, value.NewEmptyTuple(), false, 100)
+	prof := New()
+	mach.SetTracer(prof)
+	timeBounds := &protocol.TimeBounds{
+		LowerBoundBlock:     common.NewTimeBlocksInt(0),
+		UpperBoundBlock:     common.NewTimeBlocksInt(100),
+		LowerBoundTimestamp: big.NewInt(0),
+		UpperBoundTimestamp: big.NewInt(100),
+	}
+	assertion, steps := mach.ExecuteAssertion(100, timeBounds, value.NewEmptyTuple(), 0)
+	if !mach.IsHalted() {
+		t.Fatal("machine should have halted")
+	}
+
+	total := prof.Total()
+	if total.Steps != steps || total.Gas != assertion.NumGas {
+		t.Errorf("profile total %v doesn't match assertion with %v steps and %v gas", total, steps, assertion.NumGas)
+	}
+	if prof.Function(0).Steps != 3 || prof.Function(4).Steps != 2 {
+		t.Error("steps attributed to the wrong functions")
+	}
+	if prof.Opcode(code.JUMP).Steps != 3 || prof.PC(5).Steps != 1 {
+		t.Error("wrong instruction counts")
+	}
+	if prof.Depth() != 1 {
+		t.Error("function should have returned, depth is", prof.Depth())
+	}
+
+	var buf bytes.Buffer
+	if err := prof.WriteFolded(&buf, WeightSteps); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "func@0 3\nfunc@0;func@4 2\n" {
+		t.Error("unexpected folded stacks", buf.String())
+	}
+
+	buf.Reset()
+	if err := prof.WriteReport(&buf, insns, WeightGas, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "pc 2: halt") || strings.Contains(buf.String(), "pc 1: jump") {
+		t.Error("report should only include the most expensive instruction", buf.String())
+	}
+}
+
+func TestProfileRecursion(t *testing.T) {
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.NOP, Val: codePoint(2)},
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(3)},
+		value.BasicOperation{Op: code.HALT},
+		// A function which calls itself forever
+		value.ImmediateOperation{Op: code.NOP, Val: codePoint(5)},
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(3)},
+	}
+	mach := vm.NewMachine(insns, value.NewEmptyTuple(), false, 10000)
+	prof := New()
+	mach.SetTracer(prof)
+	for i := 0; i < 2*maxCallDepth+2; i++ {
+		vm.RunInstruction(mach, mach.GetOperation())
+	}
+	if prof.Depth() != maxCallDepth {
+		t.Error("call stack should stop growing at", maxCallDepth, "but has depth", prof.Depth())
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm/stack"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// A checkpointed machine is stored under its hash using the record layout of
+// the C++ machine storage: a reference count followed by the status and the
+// hash keys of the register, data stack, aux stack, pc and error handler.
+// Those values are saved with SaveValue so they share the hash addressed,
+// reference counted value store with every other machine and value saved in
+// the same storage. As in the C++ storage, the static value and the code
+// aren't saved and are taken from the initial machine on restore.
+//
+// CheckpointStorage only exposes the data column of the C++ storage rather
+// than the column C++ machines are saved in, so the reference count is kept
+// in the record by this package instead of by the storage
+const checkpointValueCount = 5
+
+const (
+	refCountLength = 4
+	hashKeyLength  = 33
+)
+
+const (
+	registerValueIndex = iota
+	dataStackValueIndex
+	auxStackValueIndex
+	pcValueIndex
+	errHandlerValueIndex
+)
+
+func MachineCheckpointKey(machineHash common.Hash) []byte {
+	return append([]byte{}, machineHash[:]...)
+}
+
+type checkpointRecord struct {
+	refCount uint32
+	status   machine.Status
+	values   [checkpointValueCount]common.Hash
+}
+
+func (r *checkpointRecord) marshal(wr io.Writer) error {
+	// The C++ storage writes the reference count in native byte order
+	if err := binary.Write(wr, binary.LittleEndian, r.refCount); err != nil {
+		return err
+	}
+	if _, err := wr.Write([]byte{byte(r.status)}); err != nil {
+		return err
+	}
+	for _, h := range r.values {
+		// Hash keys are marshalled as int values
+		if _, err := wr.Write([]byte{value.TypeCodeInt}); err != nil {
+			return err
+		}
+		if _, err := wr.Write(h[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalCheckpointRecord(rd io.Reader) (*checkpointRecord, error) {
+	r := &checkpointRecord{}
+	if err := binary.Read(rd, binary.LittleEndian, &r.refCount); err != nil {
+		return nil, err
+	}
+	var status [1]byte
+	if _, err := io.ReadFull(rd, status[:]); err != nil {
+		return nil, err
+	}
+	r.status = machine.Status(status[0])
+	for i := range r.values {
+		var hashKey [hashKeyLength]byte
+		if _, err := io.ReadFull(rd, hashKey[:]); err != nil {
+			return nil, err
+		}
+		copy(r.values[i][:], hashKey[1:])
+	}
+	return r, nil
+}
+
+func getCheckpointRecord(storage machine.CheckpointStorage, machineHash common.Hash) (*checkpointRecord, error) {
+	data := storage.GetData(MachineCheckpointKey(machineHash))
+	if data == nil {
+		return nil, fmt.Errorf("no checkpoint for machine %v", machineHash)
+	}
+	return unmarshalCheckpointRecord(bytes.NewReader(data))
+}
+
+func saveCheckpointRecord(storage machine.CheckpointStorage, machineHash common.Hash, record *checkpointRecord) bool {
+	var buf bytes.Buffer
+	if err := record.marshal(&buf); err != nil {
+		return false
+	}
+	return storage.SaveData(MachineCheckpointKey(machineHash), buf.Bytes())
+}
+
+// pcValue returns the code point at the machine's pc, or the pc itself as an
+// unsigned int if it's outside of the code after the machine halted or
+// errored. Code point hashes don't include the instruction number so it
+// couldn't be recovered from a placeholder code point
+func (m *Machine) pcValue() value.Value {
+	if m.pc.pc < 0 || m.pc.pc >= int64(len(m.pc.flat)) {
+		return value.NewIntValue(new(big.Int).SetUint64(uint64(m.pc.pc)))
+	}
+	return m.pc.GetPC()
+}
+
+func (m *Machine) checkpointValues() [checkpointValueCount]value.Value {
+	var vals [checkpointValueCount]value.Value
+	vals[registerValueIndex] = m.register.Get()
+	vals[dataStackValueIndex] = m.stack.FullyExpandedValue()
+	vals[auxStackValueIndex] = m.auxstack.FullyExpandedValue()
+	vals[pcValueIndex] = m.pcValue()
+	vals[errHandlerValueIndex] = m.errHandler
+	return vals
+}
+
+// Checkpoint saves the machine to storage. Saving a machine which is already
+// saved adds a reference to its checkpoint which must be released with a
+// separate call to DeleteCheckpoint
+func (m *Machine) Checkpoint(storage machine.CheckpointStorage) bool {
+	machineHash := m.Hash()
+	if record, err := getCheckpointRecord(storage, machineHash); err == nil {
+		record.refCount++
+		return saveCheckpointRecord(storage, machineHash, record)
+	}
+	record := &checkpointRecord{
+		refCount: 1,
+		status:   m.status,
+	}
+	for i, val := range m.checkpointValues() {
+		if !storage.SaveValue(val) {
+			return false
+		}
+		record.values[i] = val.Hash()
+	}
+	return saveCheckpointRecord(storage, machineHash, record)
+}
+
+// RestoreCheckpoint loads the machine with the given hash which was
+// previously saved with Checkpoint. The checkpoint doesn't include the code
+// of the machine so it is taken from m which must be running the same program
+func (m *Machine) RestoreCheckpoint(storage machine.CheckpointStorage, machineHash common.Hash) (*Machine, error) {
+	record, err := getCheckpointRecord(storage, machineHash)
+	if err != nil {
+		return nil, err
+	}
+	var vals [checkpointValueCount]value.Value
+	for i, h := range record.values {
+		vals[i] = storage.GetValue(h)
+		if vals[i] == nil {
+			return nil, fmt.Errorf("checkpoint of machine %v is missing value %v", machineHash, h)
+		}
+	}
+	errHandler, ok := vals[errHandlerValueIndex].(value.CodePointValue)
+	if !ok {
+		return nil, errors.New("checkpointed error handler must be a codepoint")
+	}
+
+	wh := NewSilentWarningHandler()
+	pc := NewMachinePC(m.pc.flat, wh)
+	wh.SwitchMachinePC(pc)
+	switch pcVal := vals[pcValueIndex].(type) {
+	case value.CodePointValue:
+		pc.pc = pcVal.InsnNum
+	case value.IntValue:
+		pc.pc = int64(pcVal.BigInt().Uint64())
+	default:
+		return nil, errors.New("checkpointed pc must be a codepoint or int")
+	}
+	ret := &Machine{
+		stack.FlatFromTupleChain(vals[dataStackValueIndex]),
+		stack.FlatFromTupleChain(vals[auxStackValueIndex]),
+		NewMachineValue(vals[registerValueIndex]),
+		NewMachineValue(m.static.Get()),
+		pc,
+		errHandler,
+		&NoContext{},
+		record.status,
+		m.sizeLimit,
+		false,
+		wh,
+		nil,
+		nil,
+	}
+	ret.checkSize()
+	if ret.Hash() != machineHash {
+		return nil, fmt.Errorf("restored machine has hash %v instead of %v", ret.Hash(), machineHash)
+	}
+	return ret, nil
+}
+
+// DeleteCheckpoint removes a reference to the checkpoint of the machine with
+// the given hash. Once no references remain the checkpoint is removed along
+// with its references to the values it was saved with
+func DeleteCheckpoint(storage machine.CheckpointStorage, machineHash common.Hash) bool {
+	record, err := getCheckpointRecord(storage, machineHash)
+	if err != nil {
+		return false
+	}
+	if record.refCount > 1 {
+		record.refCount--
+		return saveCheckpointRecord(storage, machineHash, record)
+	}
+	if !storage.DeleteData(MachineCheckpointKey(machineHash)) {
+		return false
+	}
+	success := true
+	for _, h := range record.values {
+		success = storage.DeleteValue(h) && success
+	}
+	return success
+}
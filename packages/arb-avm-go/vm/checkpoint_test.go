@@ -0,0 +1,194 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+type memoryValue struct {
+	val      value.Value
+	refCount int
+}
+
+// memoryStorage implements the value and data parts of CheckpointStorage
+type memoryStorage struct {
+	machine.CheckpointStorage
+	values map[common.Hash]*memoryValue
+	data   map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		values: make(map[common.Hash]*memoryValue),
+		data:   make(map[string][]byte),
+	}
+}
+
+func (s *memoryStorage) GetInitialMachine() (machine.Machine, error) {
+	return nil, errors.New("no initial machine")
+}
+
+func (s *memoryStorage) SaveValue(val value.Value) bool {
+	entry, ok := s.values[val.Hash()]
+	if !ok {
+		entry = &memoryValue{val: val}
+		s.values[val.Hash()] = entry
+	}
+	entry.refCount++
+	return true
+}
+
+func (s *memoryStorage) GetValue(hashValue common.Hash) value.Value {
+	entry, ok := s.values[hashValue]
+	if !ok {
+		return nil
+	}
+	return entry.val
+}
+
+func (s *memoryStorage) DeleteValue(hashValue common.Hash) bool {
+	entry, ok := s.values[hashValue]
+	if !ok {
+		return false
+	}
+	entry.refCount--
+	if entry.refCount == 0 {
+		delete(s.values, hashValue)
+	}
+	return true
+}
+
+func (s *memoryStorage) SaveData(key []byte, serializedValue []byte) bool {
+	s.data[string(key)] = serializedValue
+	return true
+}
+
+func (s *memoryStorage) GetData(key []byte) []byte {
+	return s.data[string(key)]
+}
+
+func (s *memoryStorage) DeleteData(key []byte) bool {
+	delete(s.data, string(key))
+	return true
+}
+
+func checkpointTestMachine() *Machine {
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(2)},
+		value.ImmediateOperation{Op: code.ADD, Val: value.NewInt64Value(4)},
+		value.BasicOperation{Op: code.LOG},
+		value.BasicOperation{Op: code.HALT},
+	}
+	m := NewMachine(insns, value.NewInt64Value(1), false, 100)
+	m.stack.Push(value.NewInt64Value(5))
+	m.stack.Push(value.NewTuple2(value.NewInt64Value(6), m.GetPC()))
+	m.auxstack.Push(value.NewInt64Value(7))
+	m.register.Set(value.NewTuple2(value.NewInt64Value(8), value.NewEmptyTuple()))
+	m.IncrPC()
+	return m
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	storage := newMemoryStorage()
+	m := checkpointTestMachine()
+	if !m.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+
+	initial := NewMachine(m.GetAllOperations(), value.NewInt64Value(1), false, 100)
+	restored, err := initial.RestoreCheckpoint(storage, m.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Hash() != m.Hash() {
+		t.Error("restored machine has wrong hash")
+	}
+	if ok, msg := Equal(m, restored); !ok {
+		t.Error("restored machine doesn't match:", msg)
+	}
+}
+
+func TestCheckpointHalted(t *testing.T) {
+	storage := newMemoryStorage()
+	m := checkpointTestMachine()
+	m.Halt()
+	if !m.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+	restored, err := m.RestoreCheckpoint(storage, m.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.IsHalted() {
+		t.Error("restored machine should be halted")
+	}
+}
+
+func TestCheckpointPCOutsideCode(t *testing.T) {
+	storage := newMemoryStorage()
+	m := checkpointTestMachine()
+	if err := m.pc.SetPCForced(value.CodePointValue{InsnNum: -1}); err != nil {
+		t.Fatal(err)
+	}
+	m.Halt()
+	if !m.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+	restored, err := m.RestoreCheckpoint(storage, m.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.PCIndex() != -1 {
+		t.Error("restored machine has pc", restored.PCIndex())
+	}
+}
+
+func TestDeleteCheckpoint(t *testing.T) {
+	storage := newMemoryStorage()
+	m := checkpointTestMachine()
+	if !m.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+	if !m.Checkpoint(storage) {
+		t.Fatal("failed to checkpoint machine")
+	}
+	if !DeleteCheckpoint(storage, m.Hash()) {
+		t.Fatal("failed to delete checkpoint")
+	}
+	if _, err := m.RestoreCheckpoint(storage, m.Hash()); err != nil {
+		t.Error("checkpoint saved twice was removed after one delete:", err)
+	}
+	if !DeleteCheckpoint(storage, m.Hash()) {
+		t.Fatal("failed to delete checkpoint")
+	}
+	if _, err := m.RestoreCheckpoint(storage, m.Hash()); err == nil {
+		t.Error("restored deleted checkpoint")
+	}
+	if DeleteCheckpoint(storage, m.Hash()) {
+		t.Error("deleted checkpoint more times than it was saved")
+	}
+	if len(storage.values) != 0 {
+		t.Error("deleting checkpoint left", len(storage.values), "values saved")
+	}
+}
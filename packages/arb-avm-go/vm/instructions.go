@@ -136,6 +136,17 @@ func RunInstruction(m *Machine, op value.Operation) (StackMods, machine.BlockRea
 	if m.HaveSizeException() {
 		return NewStackMods(0, 0), machine.ErrorBlocked{}
 	}
+	if m.tracer != nil {
+		m.tracer.BeforeStep(m, op)
+	}
+	mods, result := runOperation(m, op)
+	if m.tracer != nil {
+		m.tracer.AfterStep(m, op, result)
+	}
+	return mods, result.Blocked
+}
+
+func runOperation(m *Machine, op value.Operation) (StackMods, StepResult) {
 	mods, gas, err := func() (StackMods, uint64, error) {
 		if _, ok := code.InstructionNames[op.GetOp()]; !ok {
 			return StackMods{}, 0, errors.New("invalid opcode")
@@ -151,16 +162,17 @@ func RunInstruction(m *Machine, op value.Operation) (StackMods, machine.BlockRea
 
 	if err == nil {
 		m.context.NotifyStep(gas)
-		return mods, nil
+		return mods, StepResult{Gas: gas}
 	}
 
 	if blocked, isBlocked := err.(BlockedError); isBlocked {
 		if _, ok := op.(value.ImmediateOperation); ok {
 			PopStackBox(m, mods)
 		}
-		return mods, blocked.reason
+		return mods, StepResult{Blocked: blocked.reason}
 	}
 	m.context.NotifyStep(gas)
+	result := StepResult{Gas: gas, Err: err}
 
 	//fmt.Printf("error running instruction %v: %v\n", code.InstructionNames[op.GetOp()], err)
 
@@ -183,7 +195,7 @@ func RunInstruction(m *Machine, op value.Operation) (StackMods, machine.BlockRea
 	if err != nil {
 		m.ErrorStop()
 	}
-	return mods, nil
+	return mods, result
 }
 
 func (insn Instruction) GetName() string {
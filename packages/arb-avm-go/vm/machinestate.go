@@ -46,10 +46,8 @@ type Machine struct {
 	sizeException bool
 
 	warnHandler WarningHandler
-}
-
-func (m *Machine) Checkpoint(storage machine.CheckpointStorage) bool {
-	panic("implement me")
+	tracer      Tracer
+	locator     Locator
 }
 
 func Equal(x, y *Machine) (bool, string) {
@@ -107,23 +105,13 @@ func NewMachine(opCodes []value.Operation, staticVal value.Value, warn bool, siz
 		sizeLimit,
 		false,
 		wh,
+		nil,
+		nil,
 	}
 	ret.checkSize()
 	return ret
 }
 
-// func RestoreMachine(opCodes []value.Operation, stackVal, auxStackVal, registerVal, staticVal, pcVal value.Value, errHandlerVal value.CodePointValue, sizeLimit int64) *Machine {
-//	datastack := stack.FlatFromTupleChain(stackVal)
-//	auxStack := stack.FlatFromTupleChain(auxStackVal)
-//	register := NewMachineValue(registerVal)
-//	static := NewMachineValue(staticVal)
-//	wh := NewSilentWarningHandler()
-//	pc := NewMachinePC(opCodes, wh)
-//	wh.SwitchMachinePC(pc)
-//	pc.SetPCForced(pcVal)
-//	return &Machine{datastack, auxStack, register, static, pc, errHandlerVal, &machine.NoContext{}, Extensive, sizeLimit, false, wh}
-//}
-
 func (m *Machine) Stack() stack.Stack {
 	return m.stack
 }
@@ -140,6 +128,32 @@ func (m *Machine) Static() *MachineValue {
 	return m.static
 }
 
+// SetTracer installs a tracer which is notified around every instruction the
+// machine executes. Passing nil removes the current tracer
+func (m *Machine) SetTracer(tracer Tracer) {
+	m.tracer = tracer
+}
+
+// Locator maps instructions to the source code they were compiled from
+type Locator interface {
+	Locate(pc int64) (string, bool)
+}
+
+// SetLocator makes the machine report source locations alongside PCs in
+// warnings, traces and the debugger. Passing nil removes the current locator
+func (m *Machine) SetLocator(locator Locator) {
+	m.locator = locator
+}
+
+// SourceLocation returns the source location of the instruction at pc if the
+// machine has a locator which knows it
+func (m *Machine) SourceLocation(pc int64) (string, bool) {
+	if m.locator == nil {
+		return "", false
+	}
+	return m.locator.Locate(pc)
+}
+
 func (m *Machine) SetContext(mc Context) {
 	m.context = mc
 }
@@ -173,6 +187,12 @@ func (m *Machine) IncrPC() {
 	}
 }
 
+// PCIndex returns the index of the current instruction, which unlike GetPC is
+// safe to call after the machine has run off the end of its code
+func (m *Machine) PCIndex() int64 {
+	return m.pc.pc
+}
+
 func (m *Machine) GetPC() value.CodePointValue {
 	return m.pc.GetPC()
 }
@@ -300,6 +320,9 @@ func (m *Machine) Send(message value.Value) {
 }
 
 func (m *Machine) Warn(str string) {
+	if loc, ok := m.SourceLocation(m.PCIndex()); ok {
+		str = fmt.Sprintf("%v at %v", str, loc)
+	}
 	m.warnHandler.Warn(str)
 }
 
@@ -371,8 +394,6 @@ func (m *Machine) marshalForProof(wr io.Writer) error {
 	staticHash := m.static.ProofValue().Hash()
 	errHandlerHash := m.errHandler.Hash()
 
-	fmt.Printf("Proof of %v has %d stack vals and %d aux stack vals s\n", codePoint, len(stackVals), len(auxStackVals))
-
 	if _, err := wr.Write(codePoint.NextHash[:]); err != nil {
 		return err
 	}
@@ -425,6 +446,8 @@ func (m *Machine) Clone() machine.Machine { // clone machine state--new machine
 		m.sizeLimit,
 		m.sizeException,
 		newWarnHandler,
+		nil,
+		m.locator,
 	}
 	// WARNING: risk of bug here, because of shallow copy of stack, callstack
 	return ret
@@ -250,6 +250,19 @@ func (f *Flat) Count() int64 {
 	return int64(len(f.itemTypes))
 }
 
+func (f *Flat) Top(n int) []value.Value {
+	f.verifyHeight()
+	// counts tracks how many items of each type are above the current one
+	counts := make(map[byte]int)
+	ret := make([]value.Value, 0, n)
+	for i := len(f.itemTypes) - 1; i >= 0 && len(ret) < n; i-- {
+		tipe := f.itemTypes[i]
+		counts[tipe]++
+		ret = append(ret, f.itemOfType(tipe, f.countOfType(tipe)-counts[tipe]))
+	}
+	return ret
+}
+
 func (f *Flat) StateValue() value.Value {
 	f.updateHashes()
 	if len(f.itemTypes) == 0 {
@@ -349,6 +362,21 @@ func (f *Flat) hashOfItem(tipe byte, offset int) common.Hash {
 	}
 }
 
+func (f *Flat) itemOfType(tipe byte, offset int) value.Value {
+	switch tipe {
+	case value.TypeCodeInt:
+		return f.ints[offset]
+	case value.TypeCodeTuple:
+		return f.tuples[offset]
+	case value.TypeCodeCodePoint:
+		return f.codePoints[offset]
+	case value.TypeCodeHashOnly:
+		return f.hashOnly[offset]
+	default:
+		panic("itemOfType: Unhandled type")
+	}
+}
+
 func (f *Flat) addedValue(tipe byte, size int64) {
 	f.itemTypes = append(f.itemTypes, tipe)
 	f.size += size + 1
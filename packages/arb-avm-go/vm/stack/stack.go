@@ -54,6 +54,7 @@ type Stack interface {
 	IsEmpty() bool
 	Size() int64
 	Count() int64
+	Top(n int) []value.Value // Up to n values from the top of the stack, topmost first
 
 	StateValue() value.Value
 	ProofValue([]byte) value.Value
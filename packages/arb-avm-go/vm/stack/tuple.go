@@ -141,6 +141,18 @@ func (m *Tuple) Count() int64 {
 	return m.count
 }
 
+func (m *Tuple) Top(n int) []value.Value {
+	ret := make([]value.Value, 0, n)
+	current, ok := m.stack.(value.TupleValue)
+	for ok && len(ret) < n && current.Len() == 2 {
+		top, _ := current.GetByInt64(0)
+		rest, _ := current.GetByInt64(1)
+		ret = append(ret, top)
+		current, ok = rest.(value.TupleValue)
+	}
+	return ret
+}
+
 func (m *Tuple) StateValue() value.Value {
 	return value.NewHashOnlyValueFromValue(m.stack)
 }
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// StepResult describes the outcome of executing a single instruction
+type StepResult struct {
+	// Gas charged for the instruction
+	Gas uint64
+	// Err is the error raised by the instruction, if any. When set the
+	// machine has either jumped to its error handler or stopped with an error
+	Err error
+	// Blocked is set if the instruction couldn't run, in which case the
+	// machine is left as it was before the step
+	Blocked machine.BlockReason
+}
+
+// Tracer is notified before and after every instruction executed by a
+// machine it is installed on with SetTracer. Tracers must not modify the
+// machine
+type Tracer interface {
+	BeforeStep(m *Machine, op value.Operation)
+	AfterStep(m *Machine, op value.Operation, result StepResult)
+}
+
+func opName(op value.Operation) string {
+	name, ok := code.InstructionNames[op.GetOp()]
+	if !ok {
+		return fmt.Sprintf("0x%02x", byte(op.GetOp()))
+	}
+	return name
+}
+
+// StepTrace is a snapshot of a machine around the execution of one
+// instruction. The PC and the machine state are taken before the
+// instruction ran
+type StepTrace struct {
+	Step      uint64   `json:"step"`
+	PC        int64    `json:"pc"`
+	Op        string   `json:"op"`
+	Source    string   `json:"source,omitempty"`
+	Immediate string   `json:"immediate,omitempty"`
+	Stack     []string `json:"stack"`
+	AuxStack  []string `json:"auxstack"`
+	Register  string   `json:"register"`
+	Gas       uint64   `json:"gas"`
+	TotalGas  uint64   `json:"totalGas"`
+	Error     string   `json:"error,omitempty"`
+	Blocked   string   `json:"blocked,omitempty"`
+	Status    string   `json:"status"`
+}
+
+// Values larger than this are summarized by their hash in traces so that a
+// large register or static value doesn't swamp every step
+const maxTracedValueSize = 32
+
+func traceValueString(val value.Value) string {
+	if val.Size() > maxTracedValueSize {
+		return fmt.Sprintf("%v(size: %v, hash: %v)", value.TypeCodeName(val.TypeCode()), val.Size(), val.Hash())
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+func valueStrings(vals []value.Value) []string {
+	ret := make([]string, 0, len(vals))
+	for _, val := range vals {
+		ret = append(ret, traceValueString(val))
+	}
+	return ret
+}
+
+// stepRecorder builds a StepTrace for each instruction, keeping track of the
+// step count and total gas across steps
+type stepRecorder struct {
+	depth    int
+	step     uint64
+	totalGas uint64
+	current  StepTrace
+}
+
+func (r *stepRecorder) before(m *Machine, op value.Operation) {
+	r.current = StepTrace{
+		Step:     r.step,
+		PC:       m.PCIndex(),
+		Op:       opName(op),
+		Stack:    valueStrings(m.stack.Top(r.depth)),
+		AuxStack: valueStrings(m.auxstack.Top(r.depth)),
+		Register: traceValueString(m.register.Get()),
+	}
+	if immediate, ok := op.(value.ImmediateOperation); ok {
+		r.current.Immediate = traceValueString(immediate.Val)
+	}
+	if loc, ok := m.SourceLocation(r.current.PC); ok {
+		r.current.Source = loc
+	}
+}
+
+func (r *stepRecorder) after(m *Machine, result StepResult) *StepTrace {
+	r.step++
+	r.totalGas += result.Gas
+	r.current.Gas = result.Gas
+	r.current.TotalGas = r.totalGas
+	if result.Err != nil {
+		r.current.Error = result.Err.Error()
+	}
+	if result.Blocked != nil {
+		r.current.Blocked = fmt.Sprintf("%v", result.Blocked)
+	}
+	r.current.Status = m.status.String()
+	return &r.current
+}
+
+// JSONTracer writes a JSON object describing every step on its own line
+type JSONTracer struct {
+	rec stepRecorder
+	enc *json.Encoder
+}
+
+// NewJSONTracer creates a tracer writing to w which includes up to depth
+// values from the top of the stack and aux stack in each step
+func NewJSONTracer(w io.Writer, depth int) *JSONTracer {
+	return &JSONTracer{
+		rec: stepRecorder{depth: depth},
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (t *JSONTracer) BeforeStep(m *Machine, op value.Operation) {
+	t.rec.before(m, op)
+}
+
+func (t *JSONTracer) AfterStep(m *Machine, op value.Operation, result StepResult) {
+	// Write errors are ignored so tracing can't affect execution
+	_ = t.enc.Encode(t.rec.after(m, result))
+}
+
+// TextTracer writes a human readable line for every step
+type TextTracer struct {
+	rec stepRecorder
+	w   io.Writer
+}
+
+// NewTextTracer creates a tracer writing to w which includes up to depth
+// values from the top of the stack and aux stack in each step
+func NewTextTracer(w io.Writer, depth int) *TextTracer {
+	return &TextTracer{
+		rec: stepRecorder{depth: depth},
+		w:   w,
+	}
+}
+
+func (t *TextTracer) BeforeStep(m *Machine, op value.Operation) {
+	t.rec.before(m, op)
+}
+
+func (t *TextTracer) AfterStep(m *Machine, op value.Operation, result StepResult) {
+	trace := t.rec.after(m, result)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%6d pc %-6d %-13s", trace.Step, trace.PC, trace.Op)
+	if trace.Immediate != "" {
+		fmt.Fprintf(&sb, " imm=%v", trace.Immediate)
+	}
+	fmt.Fprintf(&sb, " gas=%v/%v", trace.Gas, trace.TotalGas)
+	if trace.Source != "" {
+		fmt.Fprintf(&sb, " at %v", trace.Source)
+	}
+	fmt.Fprintf(&sb, "\n       stack=[%v]", strings.Join(trace.Stack, ", "))
+	if len(trace.AuxStack) > 0 {
+		fmt.Fprintf(&sb, "\n       aux=[%v]", strings.Join(trace.AuxStack, ", "))
+	}
+	fmt.Fprintf(&sb, "\n       register=%v", trace.Register)
+	if trace.Error != "" {
+		fmt.Fprintf(&sb, "\n       error: %v (status %v)", trace.Error, trace.Status)
+	}
+	if trace.Blocked != "" {
+		fmt.Fprintf(&sb, "\n       blocked: %v", trace.Blocked)
+	}
+	sb.WriteString("\n")
+	_, _ = io.WriteString(t.w, sb.String())
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+func tracerTestMachine() *Machine {
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(1)},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(2)},
+		value.BasicOperation{Op: code.ADD},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewEmptyTuple()},
+		value.BasicOperation{Op: code.ADD},
+		value.BasicOperation{Op: code.BREAKPOINT},
+	}
+	return NewMachine(insns, value.NewEmptyTuple(), false, 100)
+}
+
+func TestJSONTracer(t *testing.T) {
+	m := tracerTestMachine()
+	var buf bytes.Buffer
+	m.SetTracer(NewJSONTracer(&buf, 2))
+	for i := 0; i < 5; i++ {
+		if _, blocked := RunInstruction(m, m.GetOperation()); blocked != nil {
+			t.Fatal("machine blocked unexpectedly", blocked)
+		}
+	}
+	m.SetTracer(nil)
+	RunInstruction(m, m.GetOperation())
+
+	var traces []StepTrace
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var trace StepTrace
+		if err := json.Unmarshal(scanner.Bytes(), &trace); err != nil {
+			t.Fatal(err)
+		}
+		traces = append(traces, trace)
+	}
+	if len(traces) != 5 {
+		t.Fatal("expected 5 traced steps but got", len(traces))
+	}
+
+	add := traces[2]
+	if add.PC != 2 || add.Op != "add" || len(add.Stack) != 2 {
+		t.Error("unexpected trace of add", add)
+	}
+	if add.Gas != 3 || add.TotalGas != 5 || add.Error != "" {
+		t.Error("unexpected gas or error in trace of add", add)
+	}
+
+	failed := traces[4]
+	if failed.Error == "" {
+		t.Error("expected trace of failed add to have an error")
+	}
+	if failed.Status != "errorStop" {
+		t.Error("expected machine to stop after failed add, got status", failed.Status)
+	}
+	if m.CurrentStatus() != machine.ErrorStop {
+		t.Error("expected machine to be stopped")
+	}
+}
+
+func TestTextTracerBreakpoint(t *testing.T) {
+	insns := []value.Operation{
+		value.BasicOperation{Op: code.BREAKPOINT},
+		value.BasicOperation{Op: code.HALT},
+	}
+	m := NewMachine(insns, value.NewEmptyTuple(), false, 100)
+	var buf bytes.Buffer
+	m.SetTracer(NewTextTracer(&buf, 2))
+	if _, blocked := RunInstruction(m, m.GetOperation()); blocked == nil {
+		t.Fatal("expected breakpoint to block")
+	}
+	if !strings.Contains(buf.String(), "blocked: BreakpointBlocked") {
+		t.Error("trace didn't include breakpoint:", buf.String())
+	}
+}
+
+func TestStackTop(t *testing.T) {
+	m := NewMachine(nil, value.NewEmptyTuple(), false, 100)
+	m.stack.Push(value.NewInt64Value(1))
+	m.stack.Push(value.NewEmptyTuple())
+	m.stack.Push(value.NewInt64Value(3))
+	top := m.stack.Top(2)
+	if len(top) != 2 || !value.Eq(top[0], value.NewInt64Value(3)) || !value.Eq(top[1], value.NewEmptyTuple()) {
+		t.Error("wrong top of stack", top)
+	}
+	if len(m.stack.Top(5)) != 3 {
+		t.Error("top of stack should include every item")
+	}
+	if m.stack.Count() != 3 {
+		t.Error("top of stack modified stack")
+	}
+}
+
+type testLocator map[int64]string
+
+func (l testLocator) Locate(pc int64) (string, bool) {
+	loc, ok := l[pc]
+	return loc, ok
+}
+
+func TestTracerSourceLocation(t *testing.T) {
+	m := tracerTestMachine()
+	m.SetLocator(testLocator{1: "a.sol:7"})
+	var buf bytes.Buffer
+	m.SetTracer(NewJSONTracer(&buf, 1))
+	RunInstruction(m, m.GetOperation())
+	RunInstruction(m, m.GetOperation())
+
+	dec := json.NewDecoder(&buf)
+	var first, second StepTrace
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Source != "" || second.Source != "a.sol:7" {
+		t.Error("wrong source locations in trace", first.Source, second.Source)
+	}
+}
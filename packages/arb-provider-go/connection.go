@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"sync"
 	"time"
@@ -16,6 +15,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
@@ -28,11 +29,52 @@ import (
 var ARB_SYS_ADDRESS = ethcommon.HexToAddress("0x0000000000000000000000000000000000000064")
 var ARB_INFO_ADDRESS = ethcommon.HexToAddress("0x0000000000000000000000000000000000000065")
 
+// GasConfig controls the gas limits and prices ArbConnection reports to
+// transactors
+type GasConfig struct {
+	// ArbGasPerGas is the amount of ArbGas counted as one unit of EVM gas
+	ArbGasPerGas uint64
+	// MarginPercent is added to the measured gas in case the state changes
+	// before the transaction executes
+	MarginPercent uint64
+	// GasPrice is the suggested gas price. If it's nil, the current L1 gas
+	// price is suggested since transactions are paid for when they're sent
+	// to the GlobalInbox
+	GasPrice *big.Int
+}
+
+// DefaultGasConfig is the gas configuration used by connections unless
+// SetGasConfig is called
+var DefaultGasConfig = GasConfig{
+	ArbGasPerGas:  1,
+	MarginPercent: 20,
+}
+
+// gasLimit converts the ArbGas used by a call to the EVM gas limit of a
+// transaction making it
+func (c GasConfig) gasLimit(arbGas uint64) uint64 {
+	gas := arbGas
+	if c.ArbGasPerGas > 1 {
+		gas /= c.ArbGasPerGas
+	}
+	gas += gas * c.MarginPercent / 100
+	if gas < params.TxGas {
+		gas = params.TxGas
+	}
+	return gas
+}
+
 type ArbConnection struct {
 	proxy       ValidatorProxy
 	vmId        common.Address
 	globalInbox arbbridge.GlobalInbox
 	sequenceNum *big.Int
+	l1          *ethclient.Client
+	gasConfig   GasConfig
+
+	// stream is the validator's Ethereum JSON-RPC websocket, if connected,
+	// which pushes logs as they're finalized
+	stream *rpc.Client
 }
 
 func Dial(url string, auth *bind.TransactOpts, ethclint *ethclient.Client) (*ArbConnection, error) {
@@ -55,7 +97,18 @@ func Dial(url string, auth *bind.TransactOpts, ethclint *ethclient.Client) (*Arb
 	if err != nil {
 		return nil, err
 	}
-	return &ArbConnection{proxy: proxy, vmId: vmId, globalInbox: globalInbox}, nil
+	return &ArbConnection{
+		proxy:       proxy,
+		vmId:        vmId,
+		globalInbox: globalInbox,
+		l1:          ethclint,
+		gasConfig:   DefaultGasConfig,
+	}, nil
+}
+
+// SetGasConfig changes how gas limits are estimated and gas prices suggested
+func (conn *ArbConnection) SetGasConfig(config GasConfig) {
+	conn.gasConfig = config
 }
 
 func (conn *ArbConnection) getInfoCon() (*ArbInfo, error) {
@@ -109,29 +162,34 @@ func (conn *ArbConnection) CodeAt(
 	}, contract)
 }
 
+// call executes call on the validator, returning its result and the ArbGas
+// it used
 func (conn *ArbConnection) call(
 	ctx context.Context,
 	call ethereum.CallMsg,
 	blockNumber *big.Int,
-) ([]byte, error) {
-	retValue, err := conn.proxy.CallMessage(*call.To, call.From, call.Data)
+) ([]byte, uint64, error) {
+	if call.To == nil {
+		return nil, 0, errors.New("call must have a destination")
+	}
+	retValue, numGas, err := conn.proxy.CallMessage(*call.To, call.From, call.Data)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	logVal, err := evm.ProcessLog(retValue, conn.vmId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	switch logVal := logVal.(type) {
 	case evm.Return:
-		return logVal.ReturnVal, nil
+		return logVal.ReturnVal, numGas, nil
 	case evm.Stop:
-		return []byte{}, nil
+		return []byte{}, numGas, nil
 	case evm.Revert:
-		return nil, fmt.Errorf("call reverted with result %v", string(logVal.ReturnVal))
+		return nil, 0, fmt.Errorf("call reverted with result %v", string(logVal.ReturnVal))
 	default:
-		return nil, fmt.Errorf("call reverted")
+		return nil, 0, fmt.Errorf("call reverted")
 	}
 }
 
@@ -142,7 +200,8 @@ func (conn *ArbConnection) CallContract(
 	call ethereum.CallMsg,
 	blockNumber *big.Int,
 ) ([]byte, error) {
-	return conn.call(ctx, call, blockNumber)
+	ret, _, err := conn.call(ctx, call, blockNumber)
+	return ret, err
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -164,7 +223,8 @@ func (conn *ArbConnection) PendingCodeAt(
 
 // PendingCallContract executes an Ethereum contract call against the pending state.
 func (conn *ArbConnection) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
-	return conn.call(ctx, call, nil)
+	ret, _, err := conn.call(ctx, call, nil)
+	return ret, err
 }
 
 // PendingNonceAt retrieves the current pending nonce associated with an account.
@@ -184,9 +244,13 @@ func (conn *ArbConnection) PendingNonceAt(
 }
 
 // SuggestGasPrice retrieves the currently suggested gas price to allow a timely
-// execution of a transaction.
+// execution of a transaction. This is the configured gas price if there is
+// one, and the L1 gas price otherwise.
 func (conn *ArbConnection) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
-	return big.NewInt(0), nil
+	if conn.gasConfig.GasPrice != nil {
+		return new(big.Int).Set(conn.gasConfig.GasPrice), nil
+	}
+	return conn.l1.SuggestGasPrice(ctx)
 }
 
 // EstimateGas tries to estimate the gas needed to execute a specific
@@ -194,17 +258,18 @@ func (conn *ArbConnection) SuggestGasPrice(ctx context.Context) (*big.Int, error
 // There is no guarantee that this is the true gas limit requirement as other
 // transactions may be added or removed by miners, but it should provide a basis
 // for setting a reasonable default.
-
-// EstimateGas tries to estimate the gas needed to execute a specific
-// transaction based on the current pending state of the backend blockchain.
-// There is no guarantee that this is the true gas limit requirement as other
-// transactions may be added or removed by miners, but it should provide a basis
-// for setting a reasonable default.
+//
+// The call is executed by the validator and the ArbGas it used is converted
+// to EVM gas according to the connection's GasConfig.
 func (conn *ArbConnection) EstimateGas(
 	ctx context.Context,
 	call ethereum.CallMsg,
 ) (gas uint64, err error) {
-	return 100000, nil
+	_, numGas, err := conn.call(ctx, call, nil)
+	if err != nil {
+		return 0, err
+	}
+	return conn.gasConfig.gasLimit(numGas), nil
 }
 
 // SendTransaction injects the transaction into the pending pool for execution.
@@ -216,44 +281,80 @@ func (conn *ArbConnection) SendTransaction(ctx context.Context, tx *types.Transa
 // Methods of ContractFilterer
 
 // FilterLogs executes a log filter operation, blocking during execution and
-// returning all the results in one batch.
+// returning all the results in one batch. Block numbers and hashes in the
+// query refer to L1 blocks.
 //
 // TODO(karalabe): Deprecate when the subscription one can return past data too.
 func (conn *ArbConnection) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
-	var ret []types.Log
-	address, topics := _extractAddrTopics(query)
-	logInfos, err := conn.proxy.FindLogs(0, math.MaxInt32, address[:], topics)
+	logInfos, err := conn.proxy.FindLogs(query)
 	if err != nil {
 		return nil, err
 	}
+	ret := make([]types.Log, 0, len(logInfos))
 	for _, logInfo := range logInfos {
 		outs, err := _decodeLogInfo(logInfo)
 		if err != nil {
 			return nil, err
 		}
-		ok := true
-		for i, targetTopic := range topics {
-			if targetTopic != outs.Topics[i] {
-				ok = false
-			}
-		}
-		if ok {
-			ret = append(ret, *outs)
-		}
+		ret = append(ret, *outs)
 	}
 	return ret, nil
 }
 
+// ConnectStream dials the websocket Ethereum JSON-RPC interface of the
+// validator at url, after which SubscribeFilterLogs receives logs as soon as
+// they're finalized instead of polling for them
+func (conn *ArbConnection) ConnectStream(ctx context.Context, url string) error {
+	stream, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	if conn.stream != nil {
+		conn.stream.Close()
+	}
+	conn.stream = stream
+	return nil
+}
+
+// Close closes the validator stream, if connected
+func (conn *ArbConnection) Close() {
+	if conn.stream != nil {
+		conn.stream.Close()
+		conn.stream = nil
+	}
+}
+
 // SubscribeFilterLogs creates a background log filtering operation, returning
 // a subscription immediately, which can be used to stream the found events.
+// Logs are pushed by the validator if ConnectStream has been called, and
+// polled for otherwise
 func (conn *ArbConnection) SubscribeFilterLogs(
 	ctx context.Context,
 	query ethereum.FilterQuery,
 	ch chan<- types.Log,
 ) (ethereum.Subscription, error) {
+	if conn.stream != nil {
+		return conn.stream.EthSubscribe(ctx, ch, "logs", toFilterArg(query))
+	}
 	return newSubscription(conn, query, ch), nil
 }
 
+// toFilterArg converts query to the filter object of the logs subscription.
+// The block range is omitted since the subscription only sends new logs
+func toFilterArg(query ethereum.FilterQuery) interface{} {
+	arg := map[string]interface{}{
+		"address": query.Addresses,
+		"topics":  query.Topics,
+	}
+	if query.Addresses == nil {
+		arg["address"] = []ethcommon.Address{}
+	}
+	if query.Topics == nil {
+		arg["topics"] = [][]ethcommon.Hash{}
+	}
+	return arg
+}
+
 const subscriptionPollingInterval = 5 * time.Second
 
 type subscription struct {
@@ -261,29 +362,12 @@ type subscription struct {
 	firstBlockUnseen uint64
 	logChan          chan<- types.Log
 	errChan          chan error
-	address          common.Address
-	topics           [][32]byte
+	query            ethereum.FilterQuery
 	unsubOnce        *sync.Once
 	closeChan        chan interface{}
 	wg               sync.WaitGroup
 }
 
-func _extractAddrTopics(query ethereum.FilterQuery) (addr common.Address, topics [][32]byte) {
-	if len(query.Addresses) > 1 {
-		panic("GoArbitrum: subscription can't handle more than one contract address")
-	}
-	addr = common.NewAddressFromEth(query.Addresses[0])
-
-	topics = make([][32]byte, len(query.Topics))
-	for i, sl := range query.Topics {
-		if len(sl) > 1 {
-			panic("GoArbitrum: subscription can't handle ORs of topics")
-		}
-		copy(topics[i][:], sl[0][:])
-	}
-	return
-}
-
 func _decodeLogInfo(ins *validatorserver.LogInfo) (*types.Log, error) {
 	outs := &types.Log{}
 	addr, err := hexutil.Decode(ins.Address)
@@ -311,7 +395,7 @@ func _decodeLogInfo(ins *validatorserver.LogInfo) (*types.Log, error) {
 		log.Println("_decodeLogInfo error 4:", err)
 		return nil, err
 	}
-	hh, err := hexutil.Decode(ins.Address)
+	hh, err := hexutil.Decode(ins.TransactionHash)
 	if err != nil {
 		log.Println("_decodeLogInfo error 5:", err)
 		return nil, err
@@ -341,18 +425,21 @@ func _decodeLogInfo(ins *validatorserver.LogInfo) (*types.Log, error) {
 }
 
 func newSubscription(conn *ArbConnection, query ethereum.FilterQuery, ch chan<- types.Log) *subscription {
-	address, topics := _extractAddrTopics(query)
+	query.BlockHash = nil
+	query.ToBlock = nil
 	sub := &subscription{
 		conn.proxy,
 		0,
 		ch,
 		make(chan error, 1),
-		address,
-		topics,
+		query,
 		&sync.Once{},
 		make(chan interface{}),
 		sync.WaitGroup{},
 	}
+	if query.FromBlock != nil {
+		sub.firstBlockUnseen = query.FromBlock.Uint64()
+	}
 	sub.wg.Add(1)
 	go func() {
 		defer sub.wg.Done()
@@ -361,10 +448,11 @@ func newSubscription(conn *ArbConnection, query ethereum.FilterQuery, ch chan<-
 		defer ticker.Stop()
 		for {
 			select {
-			case <- sub.closeChan:
+			case <-sub.closeChan:
 				return
-			case <- ticker.C:
-				logInfos, err := sub.proxy.FindLogs(int64(sub.firstBlockUnseen), math.MaxInt32, sub.address[:], sub.topics)
+			case <-ticker.C:
+				sub.query.FromBlock = new(big.Int).SetUint64(sub.firstBlockUnseen)
+				logInfos, err := sub.proxy.FindLogs(sub.query)
 				if err != nil {
 					sub.errChan <- err
 					return
@@ -375,20 +463,12 @@ func newSubscription(conn *ArbConnection, query ethereum.FilterQuery, ch chan<-
 						sub.errChan <- err
 						return
 					}
-					ok := true
-					for i, targetTopic := range topics {
-						if targetTopic != outs.Topics[i] {
-							ok = false
-						}
-					}
 					if outs.BlockNumber < sub.firstBlockUnseen {
-						ok = false
+						continue
 					}
-					if ok {
-						sub.logChan <- *outs
-						if sub.firstBlockUnseen <= outs.BlockNumber {
-							sub.firstBlockUnseen = outs.BlockNumber + 1
-						}
+					sub.logChan <- *outs
+					if sub.firstBlockUnseen <= outs.BlockNumber {
+						sub.firstBlockUnseen = outs.BlockNumber + 1
 					}
 				}
 			}
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/rpc/json"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
@@ -20,8 +20,8 @@ type ValidatorProxy interface {
 	GetMessageResult(txHash []byte) (value.Value, bool, error)
 	GetAssertionCount() (int, error)
 	GetVMInfo() (string, error)
-	FindLogs(fromHeight, toHeight int64, address []byte, topics [][32]byte) ([]*validatorserver.LogInfo, error)
-	CallMessage(contract common.Address, sender common.Address, data []byte) (value.Value, error)
+	FindLogs(query ethereum.FilterQuery) ([]*validatorserver.LogInfo, error)
+	CallMessage(contract common.Address, sender common.Address, data []byte) (value.Value, uint64, error)
 }
 
 type ValidatorProxyImpl struct {
@@ -35,18 +35,6 @@ func NewValidatorProxyImpl(url string) ValidatorProxy {
 	return &ValidatorProxyImpl{url}
 }
 
-func _encodeInt(i int64) string {
-	return "0x" + strconv.FormatInt(i, 16)
-}
-
-func _encodeByteArraySlice(slice [][32]byte) []string {
-	ret := make([]string, len(slice))
-	for i, arr := range slice {
-		ret[i] = hexutil.Encode(arr[:])
-	}
-	return ret
-}
-
 func (vp *ValidatorProxyImpl) doCall(methodName string, request interface{}, response interface{}) error {
 	message, err := json.EncodeClientRequest("Validator."+methodName, request)
 	if err != nil {
@@ -139,12 +127,31 @@ func (vp *ValidatorProxyImpl) GetVMInfo() (string, error) {
 	return response.VmID, nil
 }
 
-func (vp *ValidatorProxyImpl) FindLogs(fromHeight, toHeight int64, address []byte, topics [][32]byte) ([]*validatorserver.LogInfo, error) {
+// FindLogs returns the logs matching query. Block numbers and hashes in the
+// query refer to L1 blocks
+func (vp *ValidatorProxyImpl) FindLogs(query ethereum.FilterQuery) ([]*validatorserver.LogInfo, error) {
 	request := &validatorserver.FindLogsArgs{
-		FromHeight: _encodeInt(fromHeight),
-		ToHeight:   _encodeInt(toHeight),
-		Address:    hexutil.Encode(address),
-		Topics:     _encodeByteArraySlice(topics),
+		ToHeight: "latest",
+	}
+	if query.BlockHash != nil {
+		request.BlockHash = query.BlockHash.Hex()
+	} else {
+		if query.FromBlock != nil {
+			request.FromHeight = hexutil.EncodeBig(query.FromBlock)
+		}
+		if query.ToBlock != nil {
+			request.ToHeight = hexutil.EncodeBig(query.ToBlock)
+		}
+	}
+	for _, address := range query.Addresses {
+		request.Addresses = append(request.Addresses, address.Hex())
+	}
+	for _, alternatives := range query.Topics {
+		group := &validatorserver.TopicGroup{}
+		for _, topic := range alternatives {
+			group.Topics = append(group.Topics, topic.Hex())
+		}
+		request.TopicGroups = append(request.TopicGroups, group)
 	}
 	var response validatorserver.FindLogsReply
 	if err := vp.doCall("FindLogs", request, &response); err != nil {
@@ -153,7 +160,7 @@ func (vp *ValidatorProxyImpl) FindLogs(fromHeight, toHeight int64, address []byt
 	return response.Logs, nil
 }
 
-func (vp *ValidatorProxyImpl) CallMessage(contract common.Address, sender common.Address, data []byte) (value.Value, error) {
+func (vp *ValidatorProxyImpl) CallMessage(contract common.Address, sender common.Address, data []byte) (value.Value, uint64, error) {
 	request := &validatorserver.CallMessageArgs{
 		ContractAddress: hexutil.Encode(contract[:]),
 		Sender:          hexutil.Encode(sender[:]),
@@ -161,16 +168,16 @@ func (vp *ValidatorProxyImpl) CallMessage(contract common.Address, sender common
 	}
 	var response validatorserver.CallMessageReply
 	if err := vp.doCall("CallMessage", request, &response); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	retBuf, err := hexutil.Decode(response.RawVal)
 	if err != nil {
 		log.Println("CallMessage error:", err)
-		return nil, err
+		return nil, 0, err
 	}
 	retVal, err := value.UnmarshalValue(bytes.NewReader(retBuf))
 	if err != nil {
 		log.Println("ValProxy.CallMessage: UnmarshalValue returned error:", err)
 	}
-	return retVal, err
+	return retVal, response.NumGas, err
 }
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/gorilla/rpc"
 	"github.com/gorilla/rpc/json"
@@ -37,6 +38,7 @@ import (
 func main() {
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
 	walletArgs := utils.AddFlags(fs)
+	metricsArgs := utils.AddMetricsFlags(fs)
 
 	err := fs.Parse(os.Args[1:])
 	if err != nil {
@@ -45,8 +47,9 @@ func main() {
 
 	if fs.NArg() != 3 {
 		log.Fatalf(
-			"usage: arb-tx-aggregator %v %v",
+			"usage: arb-tx-aggregator %v %v %v",
 			utils.WalletArgsString,
+			utils.MetricsArgsString,
 			utils.RollupArgsString,
 		)
 	}
@@ -63,6 +66,12 @@ func main() {
 		log.Fatal(err)
 	}
 	client := ethbridge.NewEthAuthClient(ethclint, auth)
+	client.SetMaxGasPrice(walletArgs.MaxGasPrice())
+	if err := client.PersistTransactions(
+		filepath.Join(rollupArgs.ValidatorFolder, "pending_transactions.json"),
+	); err != nil {
+		log.Fatal(err)
+	}
 
 	if err := arbbridge.WaitForNonZeroBalance(
 		context.Background(),
@@ -104,5 +113,8 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if err := utils.LaunchMetrics(metricsArgs); err != nil {
+		log.Println("Not serving metrics:", err)
+	}
 	log.Fatal(utils.LaunchRPC(s, "1237"))
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
@@ -40,6 +41,17 @@ const maxTransactions = 200
 const signatureLength = 65
 const recoverBitPos = signatureLength - 1
 
+var (
+	batchSizeHistogram = metrics.NewRegisteredHistogram(
+		"arb/aggregator/batch/size",
+		nil,
+		metrics.NewExpDecaySample(1028, 0.015),
+	)
+	batchCounter        = metrics.NewRegisteredCounter("arb/aggregator/batch/sent", nil)
+	batchFailureCounter = metrics.NewRegisteredCounter("arb/aggregator/batch/failures", nil)
+	pendingTxGauge      = metrics.NewRegisteredGauge("arb/aggregator/pending", nil)
+)
+
 type Server struct {
 	rollupAddress common.Address
 	globalInbox   arbbridge.GlobalInbox
@@ -98,8 +110,11 @@ func (m *Server) sendBatch(ctx context.Context) {
 		txes = m.transactions
 		m.transactions = nil
 	}
+	pendingTxGauge.Update(int64(len(m.transactions)))
 	m.Unlock()
 
+	batchSizeHistogram.Update(int64(len(txes)))
+
 	log.Println("Submitting batch with", len(txes), "transactions")
 
 	for _, tx := range txes {
@@ -114,9 +129,12 @@ func (m *Server) sendBatch(ctx context.Context) {
 
 	m.Lock()
 	if err != nil {
+		batchFailureCounter.Inc(1)
 		log.Println("Transaction aggregator failed: ", err)
 		m.valid = false
+		return
 	}
+	batchCounter.Inc(1)
 }
 
 // SendTransaction takes a request signed transaction message from a client
@@ -203,6 +221,7 @@ func (m *Server) SendTransaction(
 		Data:   data,
 		Sig:    sigData,
 	})
+	pendingTxGauge.Update(int64(len(m.transactions)))
 
 	return &SendTransactionReply{}, nil
 }
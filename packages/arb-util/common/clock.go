@@ -0,0 +1,184 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for anything which waits on the passage of
+// time, so that tests can substitute a clock they control
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+type Ticker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+// NewRealClock returns a Clock backed by the system time
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+// VirtualClock is a Clock whose time only moves when it is advanced. Timers
+// and tickers fire synchronously during the call to Advance which reaches
+// their deadline, and like their real counterparts drop ticks which aren't
+// received in time
+type VirtualClock struct {
+	sync.Mutex
+	now     time.Time
+	waiters []*virtualWaiter
+}
+
+type virtualWaiter struct {
+	deadline time.Time
+	// period is zero for a timer which fires once
+	period time.Duration
+	c      chan time.Time
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	return c.addWaiter(d, 0).c
+}
+
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &virtualTicker{clock: c, waiter: c.addWaiter(d, d)}
+}
+
+func (c *VirtualClock) addWaiter(d time.Duration, period time.Duration) *virtualWaiter {
+	c.Lock()
+	defer c.Unlock()
+	w := &virtualWaiter{
+		deadline: c.now.Add(d),
+		period:   period,
+		c:        make(chan time.Time, 1),
+	}
+	if d <= 0 && period == 0 {
+		w.c <- c.now
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+// Waiters returns the number of timers and tickers which are waiting for
+// the clock to advance. Tests can use it to make sure that the code under
+// test has started waiting before advancing the clock
+func (c *VirtualClock) Waiters() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.waiters)
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker whose
+// deadline is reached along the way in order
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	end := c.now.Add(d)
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(end) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.deadline
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		if w.period == 0 {
+			c.waiters = c.waiters[1:]
+		} else {
+			w.deadline = w.deadline.Add(w.period)
+		}
+	}
+	c.now = end
+}
+
+// AdvanceBlocks moves the clock forward by the time it takes to mine the
+// given number of blocks
+func (c *VirtualClock) AdvanceBlocks(blocks int64) {
+	c.Advance(NewTimeBlocksInt(blocks).Duration())
+}
+
+func (c *VirtualClock) stop(w *virtualWaiter) {
+	c.Lock()
+	defer c.Unlock()
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type virtualTicker struct {
+	clock  *VirtualClock
+	waiter *virtualWaiter
+}
+
+func (t *virtualTicker) Chan() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *virtualTicker) Stop() {
+	t.clock.stop(t.waiter)
+}
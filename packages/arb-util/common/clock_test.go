@@ -0,0 +1,96 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func expectTick(t *testing.T, c <-chan time.Time, expected time.Time) {
+	t.Helper()
+	select {
+	case tick := <-c:
+		if !tick.Equal(expected) {
+			t.Errorf("expected tick at %v but got %v", expected, tick)
+		}
+	default:
+		t.Error("expected tick")
+	}
+}
+
+func expectNoTick(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case tick := <-c:
+		t.Errorf("unexpected tick at %v", tick)
+	default:
+	}
+}
+
+func TestVirtualClockAfter(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewVirtualClock(start)
+	timer := clock.After(time.Second)
+	if clock.Waiters() != 1 {
+		t.Fatal("timer isn't waiting")
+	}
+
+	clock.Advance(time.Millisecond * 999)
+	expectNoTick(t, timer)
+	clock.Advance(time.Millisecond)
+	expectTick(t, timer, start.Add(time.Second))
+
+	if clock.Waiters() != 0 {
+		t.Error("timer should only fire once")
+	}
+	if !clock.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("clock at wrong time %v", clock.Now())
+	}
+}
+
+func TestVirtualClockTicker(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewVirtualClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(time.Second)
+	expectTick(t, ticker.Chan(), start.Add(time.Second))
+
+	// Ticks that aren't received in time are dropped
+	clock.Advance(time.Second * 3)
+	expectTick(t, ticker.Chan(), start.Add(time.Second*2))
+	expectNoTick(t, ticker.Chan())
+
+	ticker.Stop()
+	clock.Advance(time.Second)
+	expectNoTick(t, ticker.Chan())
+	if clock.Waiters() != 0 {
+		t.Error("stopped ticker is still waiting")
+	}
+}
+
+func TestVirtualClockAdvanceBlocks(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	ticker := clock.NewTicker(NewTimeBlocksInt(2).Duration())
+	defer ticker.Stop()
+
+	clock.AdvanceBlocks(1)
+	expectNoTick(t, ticker.Chan())
+	clock.AdvanceBlocks(1)
+	expectTick(t, ticker.Chan(), time.Unix(0, 0).Add(NewTimeBlocksInt(2).Duration()))
+}
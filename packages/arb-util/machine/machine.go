@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -16,6 +17,20 @@ const (
 	Halt
 )
 
+// String returns the name of the status as shown in traces and by run-vm
+func (s Status) String() string {
+	switch s {
+	case Extensive:
+		return "extensive"
+	case ErrorStop:
+		return "errorStop"
+	case Halt:
+		return "halt"
+	default:
+		return fmt.Sprintf("unknown(%v)", int(s))
+	}
+}
+
 type Machine interface {
 	Hash() common.Hash
 	Clone() Machine
@@ -260,7 +260,7 @@ func (cv CodePointValue) Marshal(w io.Writer) error {
 	if err := binary.Write(w, binary.BigEndian, &cv.InsnNum); err != nil {
 		return err
 	}
-	if err := cv.Op.Marshal(w); err != nil {
+	if err := MarshalOperation(cv.Op, w); err != nil {
 		return err
 	}
 	_, err := w.Write(cv.NextHash[:])
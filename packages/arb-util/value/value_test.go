@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 )
 
 type TestCase struct {
@@ -50,3 +52,26 @@ func TestTupleHash(t *testing.T) {
 		})
 	}
 }
+
+func TestCodePointMarshal(t *testing.T) {
+	values := []Value{
+		CodePointValue{InsnNum: 5, Op: BasicOperation{Op: 0x30}, NextHash: common.Hash{1, 2}},
+		NewTuple2(
+			NewInt64Value(7),
+			CodePointValue{InsnNum: 9, Op: ImmediateOperation{Op: 0x34, Val: NewInt64Value(3)}, NextHash: common.Hash{3}},
+		),
+	}
+	for _, val := range values {
+		data := MarshalValueToBytes(val)
+		decoded, err := UnmarshalValueFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Hash() != val.Hash() {
+			t.Error("value changed after marshalling", val, decoded)
+		}
+		if !bytes.Equal(MarshalValueToBytes(decoded), data) {
+			t.Error("remarshalled value differs", val)
+		}
+	}
+}
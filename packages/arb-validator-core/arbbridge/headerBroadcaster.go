@@ -0,0 +1,266 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbbridge
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+var errSharedHeadersReset = errors.New("shared header subscription was reset")
+var errSubscriberTooSlow = errors.New("subscriber fell behind shared header subscription")
+
+const defaultHeaderCacheSize = 256
+
+var headerResubscribeDelay = time.Second * 2
+
+// HeaderBroadcaster shares a single subscription to the head of the L1 chain
+// between any number of subscribers. A subscriber which starts behind the
+// head catches up using its own subscription and then switches over to the
+// shared one once it reaches a header the broadcaster has seen
+type HeaderBroadcaster struct {
+	sync.Mutex
+	client    ArbClient
+	cacheSize int
+
+	// headers holds the most recent headers of the shared subscription in
+	// order with no gaps
+	headers []MaybeBlockId
+	// generation is incremented whenever the shared subscription restarts,
+	// which invalidates every subscriber following it
+	generation uint64
+	// updated is closed and replaced whenever headers changes
+	updated chan struct{}
+}
+
+func NewHeaderBroadcaster(ctx context.Context, client ArbClient) *HeaderBroadcaster {
+	hb := &HeaderBroadcaster{
+		client:    client,
+		cacheSize: defaultHeaderCacheSize,
+		updated:   make(chan struct{}),
+	}
+	go hb.run(ctx)
+	return hb
+}
+
+func (hb *HeaderBroadcaster) run(ctx context.Context) {
+	for {
+		hb.follow(ctx)
+		hb.reset()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(headerResubscribeDelay):
+		}
+	}
+}
+
+// follow adds headers from the head of the chain to the cache until the
+// underlying subscription fails
+func (hb *HeaderBroadcaster) follow(ctx context.Context) {
+	start, err := hb.client.CurrentBlockId(ctx)
+	if err != nil {
+		log.Println("Shared header subscription failed to get current block", err)
+		return
+	}
+	headersChan, err := hb.client.SubscribeBlockHeaders(ctx, start)
+	if err != nil {
+		log.Println("Shared header subscription failed to subscribe", err)
+		return
+	}
+	for maybeBlockId := range headersChan {
+		if maybeBlockId.Err != nil {
+			log.Println("Shared header subscription hit error", maybeBlockId.Err)
+			break
+		}
+		hb.add(maybeBlockId)
+	}
+	// Let the underlying subscription shut down if it hasn't already
+	go func() {
+		for range headersChan {
+		}
+	}()
+}
+
+func (hb *HeaderBroadcaster) add(maybeBlockId MaybeBlockId) {
+	hb.Lock()
+	defer hb.Unlock()
+	hb.headers = append(hb.headers, maybeBlockId)
+	if len(hb.headers) > hb.cacheSize {
+		hb.headers = hb.headers[len(hb.headers)-hb.cacheSize:]
+	}
+	close(hb.updated)
+	hb.updated = make(chan struct{})
+}
+
+func (hb *HeaderBroadcaster) reset() {
+	hb.Lock()
+	defer hb.Unlock()
+	hb.headers = nil
+	hb.generation++
+	close(hb.updated)
+	hb.updated = make(chan struct{})
+}
+
+// index returns the position of the given block in the cache or -1 if it
+// isn't there. It must be called with the broadcaster locked
+func (hb *HeaderBroadcaster) index(blockId *common.BlockId) int {
+	if len(hb.headers) == 0 {
+		return -1
+	}
+	first := hb.headers[0].BlockId.Height.AsInt().Int64()
+	i := blockId.Height.AsInt().Int64() - first
+	if i < 0 || i >= int64(len(hb.headers)) {
+		return -1
+	}
+	if hb.headers[i].BlockId.HeaderHash != blockId.HeaderHash {
+		return -1
+	}
+	return int(i)
+}
+
+// join returns the current generation and true if the shared subscription
+// has seen the given block, along with a channel which is closed when the
+// broadcaster next changes
+func (hb *HeaderBroadcaster) join(blockId *common.BlockId) (uint64, bool, <-chan struct{}) {
+	hb.Lock()
+	defer hb.Unlock()
+	return hb.generation, hb.index(blockId) >= 0, hb.updated
+}
+
+// next returns the header following the given block if the shared
+// subscription has it yet
+func (hb *HeaderBroadcaster) next(generation uint64, blockId *common.BlockId) (*MaybeBlockId, <-chan struct{}, error) {
+	hb.Lock()
+	defer hb.Unlock()
+	if generation != hb.generation {
+		return nil, nil, errSharedHeadersReset
+	}
+	i := hb.index(blockId)
+	if i < 0 {
+		return nil, nil, errSubscriberTooSlow
+	}
+	if i+1 == len(hb.headers) {
+		return nil, hb.updated, nil
+	}
+	next := hb.headers[i+1]
+	return &next, nil, nil
+}
+
+// SubscribeBlockHeaders behaves like ArbClient.SubscribeBlockHeaders, but
+// shares the work of following the head of the chain with every other
+// subscriber
+func (hb *HeaderBroadcaster) SubscribeBlockHeaders(ctx context.Context, startBlockId *common.BlockId) (<-chan MaybeBlockId, error) {
+	catchUpCtx, cancelCatchUp := context.WithCancel(ctx)
+	catchUpChan, err := hb.client.SubscribeBlockHeaders(catchUpCtx, startBlockId)
+	if err != nil {
+		cancelCatchUp()
+		return nil, err
+	}
+
+	blockIdChan := make(chan MaybeBlockId, 100)
+	send := func(maybeBlockId MaybeBlockId) bool {
+		select {
+		case blockIdChan <- maybeBlockId:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(blockIdChan)
+
+		// Follow our own subscription until reaching a header which the
+		// shared subscription has seen
+		var last *common.BlockId
+		var generation uint64
+		var updated <-chan struct{}
+	catchUp:
+		for {
+			select {
+			case maybeBlockId, ok := <-catchUpChan:
+				if !ok {
+					cancelCatchUp()
+					return
+				}
+				if !send(maybeBlockId) || maybeBlockId.Err != nil {
+					cancelCatchUp()
+					return
+				}
+				last = maybeBlockId.BlockId
+			case <-updated:
+			case <-ctx.Done():
+				cancelCatchUp()
+				return
+			}
+			var joined bool
+			generation, joined, updated = hb.join(last)
+			if joined {
+				break catchUp
+			}
+		}
+		cancelCatchUp()
+		go func() {
+			for range catchUpChan {
+			}
+		}()
+
+		for {
+			next, updated, err := hb.next(generation, last)
+			if err != nil {
+				send(MaybeBlockId{Err: err})
+				return
+			}
+			if next == nil {
+				select {
+				case <-updated:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			if !send(*next) {
+				return
+			}
+			last = next.BlockId
+		}
+	}()
+	return blockIdChan, nil
+}
+
+// AuthClient returns a client which behaves like the given one, except that
+// it subscribes to headers through the broadcaster
+func (hb *HeaderBroadcaster) AuthClient(client ArbAuthClient) ArbAuthClient {
+	return &sharedHeadersAuthClient{
+		ArbAuthClient: client,
+		headers:       hb,
+	}
+}
+
+type sharedHeadersAuthClient struct {
+	ArbAuthClient
+	headers *HeaderBroadcaster
+}
+
+func (c *sharedHeadersAuthClient) SubscribeBlockHeaders(ctx context.Context, startBlockId *common.BlockId) (<-chan MaybeBlockId, error) {
+	return c.headers.SubscribeBlockHeaders(ctx, startBlockId)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbbridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// testChainClient is a chain which grows when blocks are mined, and whose
+// subscriptions poll for new blocks like the real client does
+type testChainClient struct {
+	ArbClient
+	sync.Mutex
+	blocks        []*common.BlockId
+	subscriptions int
+}
+
+func newTestChainClient(height int) *testChainClient {
+	c := &testChainClient{}
+	c.mine(height)
+	return c
+}
+
+func (c *testChainClient) mine(count int) {
+	c.Lock()
+	defer c.Unlock()
+	for i := 0; i < count; i++ {
+		height := len(c.blocks)
+		c.blocks = append(c.blocks, &common.BlockId{
+			Height:     common.NewTimeBlocks(big.NewInt(int64(height))),
+			HeaderHash: common.Hash{byte(height), 1},
+		})
+	}
+}
+
+func (c *testChainClient) block(height int64) *common.BlockId {
+	c.Lock()
+	defer c.Unlock()
+	if height >= int64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[height]
+}
+
+func (c *testChainClient) activeSubscriptions() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.subscriptions
+}
+
+func (c *testChainClient) CurrentBlockId(ctx context.Context) (*common.BlockId, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *testChainClient) SubscribeBlockHeaders(ctx context.Context, startBlockId *common.BlockId) (<-chan MaybeBlockId, error) {
+	if c.block(startBlockId.Height.AsInt().Int64()) == nil {
+		return nil, errors.New("unknown block")
+	}
+	c.Lock()
+	c.subscriptions++
+	c.Unlock()
+	blockIdChan := make(chan MaybeBlockId, 100)
+	blockIdChan <- MaybeBlockId{BlockId: startBlockId, Timestamp: big.NewInt(0)}
+	go func() {
+		defer func() {
+			c.Lock()
+			c.subscriptions--
+			c.Unlock()
+			close(blockIdChan)
+		}()
+		height := startBlockId.Height.AsInt().Int64()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Millisecond):
+			}
+			next := c.block(height + 1)
+			if next == nil {
+				continue
+			}
+			blockIdChan <- MaybeBlockId{BlockId: next, Timestamp: big.NewInt(0)}
+			height++
+		}
+	}()
+	return blockIdChan, nil
+}
+
+func expectHeaders(t *testing.T, headers <-chan MaybeBlockId, from int64, to int64) {
+	t.Helper()
+	for height := from; height <= to; height++ {
+		select {
+		case maybeBlockId, ok := <-headers:
+			if !ok {
+				t.Fatal("subscription closed early")
+			}
+			if maybeBlockId.Err != nil {
+				t.Fatal(maybeBlockId.Err)
+			}
+			if maybeBlockId.BlockId.Height.AsInt().Int64() != height {
+				t.Fatalf("expected header %v but got %v", height, maybeBlockId.BlockId.Height.AsInt())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for header %v", height)
+		}
+	}
+}
+
+func waitForSharedSubscription(t *testing.T, client *testChainClient) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for client.activeSubscriptions() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 1 active subscription but there are %v", client.activeSubscriptions())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHeaderBroadcaster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestChainClient(10)
+	hb := NewHeaderBroadcaster(ctx, client)
+
+	sub1, err := hb.SubscribeBlockHeaders(ctx, client.block(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub2, err := hb.SubscribeBlockHeaders(ctx, client.block(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.mine(10)
+	expectHeaders(t, sub1, 2, 19)
+	expectHeaders(t, sub2, 5, 19)
+
+	// Once both subscribers have caught up they only use the shared
+	// subscription
+	waitForSharedSubscription(t, client)
+
+	client.mine(5)
+	expectHeaders(t, sub1, 20, 24)
+	expectHeaders(t, sub2, 20, 24)
+}
+
+func TestHeaderBroadcasterReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestChainClient(5)
+	hb := NewHeaderBroadcaster(ctx, client)
+
+	sub, err := hb.SubscribeBlockHeaders(ctx, client.block(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.mine(1)
+	expectHeaders(t, sub, 4, 5)
+	waitForSharedSubscription(t, client)
+
+	hb.reset()
+	select {
+	case maybeBlockId := <-sub:
+		if maybeBlockId.Err != errSharedHeadersReset {
+			t.Fatalf("expected reset error but got %v", maybeBlockId)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber wasn't told about reset")
+	}
+}
@@ -151,12 +151,24 @@ func (c *EthArbClient) BlockIdForHeight(ctx context.Context, height *common.Time
 type TransactAuth struct {
 	sync.Mutex
 	auth *bind.TransactOpts
+	txs  *txManager
+}
+
+func newTransactAuth(client *ethclient.Client, auth *bind.TransactOpts) *TransactAuth {
+	return &TransactAuth{
+		auth: auth,
+		txs:  newTxManager(client, auth),
+	}
 }
 
 func (t *TransactAuth) getAuth(ctx context.Context) *bind.TransactOpts {
+	nonce := t.auth.Nonce
+	if nonce == nil {
+		nonce = t.txs.nextNonce(ctx)
+	}
 	return &bind.TransactOpts{
 		From:     t.auth.From,
-		Nonce:    t.auth.Nonce,
+		Nonce:    nonce,
 		Signer:   t.auth.Signer,
 		Value:    t.auth.Value,
 		GasPrice: t.auth.GasPrice,
@@ -165,6 +177,18 @@ func (t *TransactAuth) getAuth(ctx context.Context) *bind.TransactOpts {
 	}
 }
 
+// track hands a transaction which has been sent over to the transaction
+// manager, which makes sure that it gets mined
+func (t *TransactAuth) track(tx *types.Transaction) {
+	t.txs.track(tx)
+}
+
+// waitForReceipt tracks the given transaction and waits for it, or a
+// replacement for it, to be mined
+func (t *TransactAuth) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) (*types.Receipt, error) {
+	return t.txs.wait(ctx, t.txs.track(tx), methodName)
+}
+
 type EthArbAuthClient struct {
 	*EthArbClient
 	auth *TransactAuth
@@ -173,10 +197,32 @@ type EthArbAuthClient struct {
 func NewEthAuthClient(client *ethclient.Client, auth *bind.TransactOpts) *EthArbAuthClient {
 	return &EthArbAuthClient{
 		EthArbClient: NewEthClient(client),
-		auth:         &TransactAuth{auth: auth},
+		auth:         newTransactAuth(client, auth),
 	}
 }
 
+// PersistTransactions saves transactions which haven't been mined yet to the
+// given file, and resumes watching any transactions which were saved there
+// by a previous run
+func (c *EthArbAuthClient) PersistTransactions(path string) error {
+	return c.auth.txs.load(path)
+}
+
+// Close stops watching the transactions sent by the client. Any which
+// haven't been mined yet stay saved if PersistTransactions was called, so
+// that they're resumed by the next run
+func (c *EthArbAuthClient) Close() {
+	c.auth.txs.stop()
+}
+
+// SetMaxGasPrice limits how high the gas price of a stuck transaction will
+// be raised
+func (c *EthArbAuthClient) SetMaxGasPrice(gasPrice *big.Int) {
+	c.auth.txs.Lock()
+	defer c.auth.txs.Unlock()
+	c.auth.txs.maxGasPrice = gasPrice
+}
+
 func (c *EthArbAuthClient) Address() common.Address {
 	return common.NewAddressFromEth(c.auth.auth.From)
 }
@@ -212,17 +258,11 @@ func (c *EthArbAuthClient) NewInboxTopChallenge(address common.Address) (arbbrid
 func (c *EthArbAuthClient) DeployChallengeTest(ctx context.Context, challengeFactory common.Address) (*ChallengeTester, error) {
 	c.auth.Lock()
 	defer c.auth.Unlock()
-	testerAddress, tx, _, err := challengetester.DeployChallengeTester(c.auth.auth, c.client, challengeFactory.ToEthAddress())
+	testerAddress, tx, _, err := challengetester.DeployChallengeTester(c.auth.getAuth(ctx), c.client, challengeFactory.ToEthAddress())
 	if err != nil {
 		return nil, err
 	}
-	if err := waitForReceipt(
-		ctx,
-		c.client,
-		c.auth.auth.From,
-		tx,
-		"DeployChallengeTester",
-	); err != nil {
+	if _, err := c.auth.waitForReceipt(ctx, tx, "DeployChallengeTester"); err != nil {
 		return nil, err
 	}
 	tester, err := NewChallengeTester(testerAddress, c.client, c.auth)
@@ -235,17 +275,11 @@ func (c *EthArbAuthClient) DeployChallengeTest(ctx context.Context, challengeFac
 func (c *EthArbAuthClient) DeployOneStepProof(ctx context.Context) (arbbridge.OneStepProof, error) {
 	c.auth.Lock()
 	defer c.auth.Unlock()
-	ospAddress, tx, _, err := executionchallenge.DeployOneStepProof(c.auth.auth, c.client)
+	ospAddress, tx, _, err := executionchallenge.DeployOneStepProof(c.auth.getAuth(ctx), c.client)
 	if err != nil {
 		return nil, err
 	}
-	if err := waitForReceipt(
-		ctx,
-		c.client,
-		c.auth.auth.From,
-		tx,
-		"DeployOneStepProof",
-	); err != nil {
+	if _, err := c.auth.waitForReceipt(ctx, tx, "DeployOneStepProof"); err != nil {
 		return nil, err
 	}
 	osp, err := c.NewOneStepProof(common.NewAddressFromEth(ospAddress))
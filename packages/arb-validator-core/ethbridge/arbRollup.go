@@ -23,7 +23,6 @@ import (
 
 	errors2 "github.com/pkg/errors"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -53,12 +52,8 @@ func newRollup(address ethcommon.Address, client *ethclient.Client, auth *Transa
 func (vm *arbRollup) PlaceStake(ctx context.Context, stakeAmount *big.Int, proof1 []common.Hash, proof2 []common.Hash) error {
 	vm.auth.Lock()
 	defer vm.auth.Unlock()
-	call := &bind.TransactOpts{
-		From:    vm.auth.auth.From,
-		Signer:  vm.auth.auth.Signer,
-		Value:   stakeAmount,
-		Context: ctx,
-	}
+	call := vm.auth.getAuth(ctx)
+	call.Value = stakeAmount
 	tx, err := vm.ArbRollup.PlaceStake(
 		call,
 		hashSliceToRaw(proof1),
@@ -404,5 +399,6 @@ func (vm *arbRollup) IsStaked(address common.Address) (bool, error) {
 //}
 
 func (vm *arbRollup) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) error {
-	return waitForReceipt(ctx, vm.Client, vm.auth.auth.From, tx, methodName)
+	_, err := vm.auth.waitForReceipt(ctx, tx, methodName)
+	return err
 }
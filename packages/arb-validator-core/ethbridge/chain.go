@@ -71,10 +71,6 @@ func getLogChainInfo(log types.Log) arbbridge.ChainInfo {
 	}
 }
 
-func waitForReceipt(ctx context.Context, client *ethclient.Client, from ethcommon.Address, tx *types.Transaction, methodName string) error {
-	_, err := WaitForReceiptWithResults(ctx, client, from, tx, methodName)
-	return err
-}
 func WaitForReceiptWithResults(ctx context.Context, client *ethclient.Client, from ethcommon.Address, tx *types.Transaction, methodName string) (*types.Receipt, error) {
 	for {
 		select {
@@ -86,28 +82,37 @@ func WaitForReceiptWithResults(ctx context.Context, client *ethclient.Client, fr
 				}
 				return nil, err
 			}
-			if receipt.Status != 1 {
-				data, err := receipt.MarshalJSON()
-				if err != nil {
-					return nil, errors.New("Failed unmarshalling receipt")
-				}
-				callMsg := ethereum.CallMsg{
-					From:     from,
-					To:       tx.To(),
-					Gas:      tx.Gas(),
-					GasPrice: tx.GasPrice(),
-					Value:    tx.Value(),
-					Data:     tx.Data(),
-				}
-				data, err = client.CallContract(ctx, callMsg, receipt.BlockNumber)
-				if err != nil {
-					return nil, fmt.Errorf("Transaction %v failed with error %v", methodName, err)
-				}
-				return nil, fmt.Errorf("Transaction %v failed with tx %v", methodName, string(data))
-			}
-			return receipt, nil
+			return checkReceipt(ctx, client, from, tx, receipt, methodName)
 		case _ = <-ctx.Done():
 			return nil, errors.New("Receipt not found")
 		}
 	}
 }
+
+// checkReceipt returns an error describing why the given transaction failed
+// if its receipt shows that it reverted
+func checkReceipt(
+	ctx context.Context,
+	client ethereum.ContractCaller,
+	from ethcommon.Address,
+	tx *types.Transaction,
+	receipt *types.Receipt,
+	methodName string,
+) (*types.Receipt, error) {
+	if receipt.Status == 1 {
+		return receipt, nil
+	}
+	callMsg := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+	data, err := client.CallContract(ctx, callMsg, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("Transaction %v failed with error %v", methodName, err)
+	}
+	return nil, fmt.Errorf("Transaction %v failed with tx %v", methodName, string(data))
+}
@@ -80,7 +80,8 @@ func (c *challenge) TimeoutChallenge(ctx context.Context) error {
 }
 
 func (c *challenge) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) error {
-	return waitForReceipt(ctx, c.client, c.auth.auth.From, tx, methodName)
+	_, err := c.auth.waitForReceipt(ctx, tx, methodName)
+	return err
 }
 
 type challengeWatcher struct {
@@ -65,7 +65,7 @@ func (con *ChallengeTester) StartChallenge(
 		return common.Address{}, nil, errors2.Wrap(err, "Failed to call to ChallengeTester.StartChallenge")
 	}
 
-	receipt, err := WaitForReceiptWithResults(ctx, con.client, con.auth.auth.From, tx, "CreateChallenge")
+	receipt, err := con.auth.waitForReceipt(ctx, tx, "CreateChallenge")
 	if err != nil {
 		return common.Address{}, nil, err
 	}
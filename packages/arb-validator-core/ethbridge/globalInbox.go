@@ -79,11 +79,16 @@ func (con *globalInbox) deliverTransactionBatch(
 		data = append(data, tx.ToBytes()...)
 	}
 	con.auth.Lock()
-	return con.GlobalInbox.DeliverTransactionBatch(
+	tx, err := con.GlobalInbox.DeliverTransactionBatch(
 		con.auth.getAuth(ctx),
 		chain.ToEthAddress(),
 		data,
 	)
+	if err != nil {
+		con.auth.Unlock()
+		return nil, err
+	}
+	return tx, nil
 }
 
 func (con *globalInbox) DeliverTransactionBatch(
@@ -105,12 +110,13 @@ func (con *globalInbox) DeliverTransactionBatchNoWait(
 	chain common.Address,
 	transactions []message.BatchTx,
 ) error {
-	_, err := con.deliverTransactionBatch(ctx, chain, transactions)
+	tx, err := con.deliverTransactionBatch(ctx, chain, transactions)
 	if err != nil {
 		return err
 	}
+	con.auth.track(tx)
 	con.auth.Unlock()
-	return err
+	return nil
 }
 
 func (con *globalInbox) DepositEthMessage(
@@ -119,15 +125,12 @@ func (con *globalInbox) DepositEthMessage(
 	destination common.Address,
 	value *big.Int,
 ) error {
-
+	con.auth.Lock()
+	defer con.auth.Unlock()
+	call := con.auth.getAuth(ctx)
+	call.Value = value
 	tx, err := con.GlobalInbox.DepositEthMessage(
-		&bind.TransactOpts{
-			From:     con.auth.auth.From,
-			Signer:   con.auth.auth.Signer,
-			GasLimit: con.auth.auth.GasLimit,
-			Value:    value,
-			Context:  ctx,
-		},
+		call,
 		vmAddress.ToEthAddress(),
 		destination.ToEthAddress(),
 	)
@@ -200,5 +203,6 @@ func (con *globalInbox) GetTokenBalance(
 }
 
 func (con *globalInbox) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) error {
-	return waitForReceipt(ctx, con.client, con.auth.auth.From, tx, methodName)
+	_, err := con.auth.waitForReceipt(ctx, tx, methodName)
+	return err
 }
@@ -0,0 +1,422 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errTxReplaced = errors.New("transaction nonce was used by another transaction")
+
+var errTxManagerStopped = errors.New("transaction manager stopped")
+
+const receiptPollInterval = time.Second
+
+// txBumpTimeout is how long a transaction can go unmined before it is
+// replaced with one paying a higher gas price
+const txBumpTimeout = time.Minute * 2
+
+// txGasBumpPercent is how much the gas price is raised by each replacement.
+// Nodes reject replacements which raise it by less than 10%
+const txGasBumpPercent = 25
+
+type txBackend interface {
+	ethereum.ChainStateReader
+	ethereum.TransactionReader
+	ethereum.TransactionSender
+	ethereum.ContractCaller
+	PendingNonceAt(ctx context.Context, account ethcommon.Address) (uint64, error)
+}
+
+// pendingTx is a nonce which we have sent a transaction with but haven't
+// seen mined yet
+type pendingTx struct {
+	nonce uint64
+	// txes holds every version of the transaction that has been broadcast,
+	// ending with the one paying the highest gas price
+	txes []*types.Transaction
+	// lastBump is when the gas price was last raised
+	lastBump time.Time
+
+	done    chan struct{}
+	receipt *types.Receipt
+	err     error
+}
+
+func (p *pendingTx) latest() *types.Transaction {
+	return p.txes[len(p.txes)-1]
+}
+
+func (p *pendingTx) includes(tx *types.Transaction) bool {
+	for _, sent := range p.txes {
+		if sent.Hash() == tx.Hash() {
+			return true
+		}
+	}
+	return false
+}
+
+// txManager tracks every transaction sent from an account so that they
+// use consecutive nonces no matter which contract they're sent to. Each
+// transaction is watched until it's mined, rebroadcasting it if it's
+// dropped and replacing it with a higher gas price if it's stuck
+type txManager struct {
+	sync.Mutex
+	client txBackend
+	auth   *bind.TransactOpts
+
+	// nonce is the next nonce to use or nil if it hasn't been loaded yet
+	nonce       *uint64
+	pending     map[uint64]*pendingTx
+	maxGasPrice *big.Int
+
+	pollInterval time.Duration
+	bumpTimeout  time.Duration
+	// path is the file where pending transactions are saved, if any
+	path string
+
+	// ctx is cancelled by stop, which then waits for the watchers to exit
+	ctx      context.Context
+	cancel   context.CancelFunc
+	watchers sync.WaitGroup
+}
+
+func newTxManager(client txBackend, auth *bind.TransactOpts) *txManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &txManager{
+		client:       client,
+		auth:         auth,
+		pending:      make(map[uint64]*pendingTx),
+		pollInterval: receiptPollInterval,
+		bumpTimeout:  txBumpTimeout,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// stop stops watching transactions and waits for the watchers to exit.
+// Transactions which haven't been mined stay saved so that they're resumed
+// by the next manager to load the same path
+func (m *txManager) stop() {
+	m.cancel()
+	m.watchers.Wait()
+}
+
+// nextNonce returns the nonce for the next transaction, or nil if it isn't
+// known. It accounts for transactions sent from the same account by anyone
+// else, and for our own transactions that the node has dropped
+func (m *txManager) nextNonce(ctx context.Context) *big.Int {
+	pendingNonce, err := m.client.PendingNonceAt(ctx, m.auth.From)
+	m.Lock()
+	defer m.Unlock()
+	if err != nil {
+		log.Println("Failed to get pending nonce", err)
+	} else if m.nonce == nil || pendingNonce > *m.nonce {
+		m.nonce = &pendingNonce
+	}
+	if m.nonce == nil {
+		return nil
+	}
+	return new(big.Int).SetUint64(*m.nonce)
+}
+
+// track starts watching a transaction which has been broadcast, returning
+// the pending entry for its nonce
+func (m *txManager) track(tx *types.Transaction) *pendingTx {
+	m.Lock()
+	defer m.Unlock()
+	p, ok := m.pending[tx.Nonce()]
+	if ok {
+		if !p.includes(tx) {
+			p.txes = append(p.txes, tx)
+			m.save()
+		}
+		return p
+	}
+	p = &pendingTx{
+		nonce:    tx.Nonce(),
+		txes:     []*types.Transaction{tx},
+		lastBump: time.Now(),
+		done:     make(chan struct{}),
+	}
+	m.add(p)
+	m.save()
+	return p
+}
+
+// add must be called with the manager locked
+func (m *txManager) add(p *pendingTx) {
+	m.pending[p.nonce] = p
+	if m.nonce == nil || p.nonce >= *m.nonce {
+		next := p.nonce + 1
+		m.nonce = &next
+	}
+	if m.ctx.Err() != nil {
+		return
+	}
+	m.watchers.Add(1)
+	go func() {
+		defer m.watchers.Done()
+		m.watch(p)
+	}()
+}
+
+// wait blocks until the transaction with the given nonce is mined and
+// returns its receipt
+func (m *txManager) wait(ctx context.Context, p *pendingTx, methodName string) (*types.Receipt, error) {
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return nil, errors.New("Receipt not found")
+	case <-m.ctx.Done():
+		return nil, errTxManagerStopped
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("Transaction %v failed with error %v", methodName, p.err)
+	}
+	var tx *types.Transaction
+	for _, sent := range p.txes {
+		if sent.Hash() == p.receipt.TxHash {
+			tx = sent
+		}
+	}
+	return checkReceipt(ctx, m.client, m.auth.From, tx, p.receipt, methodName)
+}
+
+func (m *txManager) watch(p *pendingTx) {
+	ctx := m.ctx
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.pollInterval):
+		}
+		receipt, err := m.receipt(ctx, p)
+		if err != nil {
+			log.Println("Failed to get transaction receipt", err)
+			continue
+		}
+		if receipt != nil {
+			m.finish(p, receipt, nil)
+			return
+		}
+		if err := m.resend(ctx, p); err != nil {
+			if err == errTxReplaced {
+				m.finish(p, nil, err)
+				return
+			}
+			log.Println("Failed to resend transaction", err)
+		}
+	}
+}
+
+// receipt returns the receipt of whichever version of the transaction was
+// mined, or nil if none of them have been
+func (m *txManager) receipt(ctx context.Context, p *pendingTx) (*types.Receipt, error) {
+	m.Lock()
+	txes := p.txes
+	m.Unlock()
+	for _, tx := range txes {
+		receipt, err := m.client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			return receipt, nil
+		}
+		if err.Error() != ethereum.NotFound.Error() {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// resend replaces the transaction if it's been waiting too long and
+// rebroadcasts it if the node has dropped it
+func (m *txManager) resend(ctx context.Context, p *pendingTx) error {
+	m.Lock()
+	tx := p.latest()
+	bump := time.Since(p.lastBump) > m.bumpTimeout
+	m.Unlock()
+
+	if bump {
+		bumped, err := m.bump(tx)
+		if err != nil {
+			return err
+		}
+		if bumped != nil {
+			log.Printf("Replacing transaction %v with %v at gas price %v\n", tx.Hash().Hex(), bumped.Hash().Hex(), bumped.GasPrice())
+			if err := m.client.SendTransaction(ctx, bumped); err != nil {
+				return err
+			}
+			m.Lock()
+			p.txes = append(p.txes, bumped)
+			p.lastBump = time.Now()
+			m.save()
+			m.Unlock()
+			return nil
+		}
+		m.Lock()
+		p.lastBump = time.Now()
+		m.Unlock()
+	}
+
+	_, _, err := m.client.TransactionByHash(ctx, tx.Hash())
+	if err == nil {
+		return nil
+	}
+	if err.Error() != ethereum.NotFound.Error() {
+		return err
+	}
+	nonce, err := m.client.NonceAt(ctx, m.auth.From, nil)
+	if err != nil {
+		return err
+	}
+	if nonce > p.nonce {
+		// Our transaction may have been mined since we looked for it
+		receipt, err := m.receipt(ctx, p)
+		if err != nil || receipt != nil {
+			return err
+		}
+		return errTxReplaced
+	}
+	log.Println("Rebroadcasting dropped transaction", tx.Hash().Hex())
+	return m.client.SendTransaction(ctx, tx)
+}
+
+// bump returns a copy of the transaction paying a higher gas price, or nil
+// if the gas price has already reached the maximum
+func (m *txManager) bump(tx *types.Transaction) (*types.Transaction, error) {
+	gasPrice := new(big.Int).Mul(tx.GasPrice(), big.NewInt(100+txGasBumpPercent))
+	gasPrice.Div(gasPrice, big.NewInt(100))
+	m.Lock()
+	maxGasPrice := m.maxGasPrice
+	m.Unlock()
+	if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+		gasPrice = maxGasPrice
+	}
+	if gasPrice.Cmp(tx.GasPrice()) <= 0 {
+		return nil, nil
+	}
+	var rawTx *types.Transaction
+	if tx.To() == nil {
+		rawTx = types.NewContractCreation(tx.Nonce(), tx.Value(), tx.Gas(), gasPrice, tx.Data())
+	} else {
+		rawTx = types.NewTransaction(tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), gasPrice, tx.Data())
+	}
+	// Sign the replacement the same way as the original so that it keeps
+	// the original's replay protection
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	return m.auth.Signer(signer, m.auth.From, rawTx)
+}
+
+func (m *txManager) finish(p *pendingTx, receipt *types.Receipt, err error) {
+	m.Lock()
+	defer m.Unlock()
+	p.receipt = receipt
+	p.err = err
+	delete(m.pending, p.nonce)
+	m.save()
+	close(p.done)
+}
+
+// persistedTx is the form in which a pending transaction is saved
+type persistedTx struct {
+	Transactions []*types.Transaction `json:"transactions"`
+}
+
+// save writes out the pending transactions if the manager has a path. It
+// must be called with the manager locked
+func (m *txManager) save() {
+	if m.path == "" {
+		return
+	}
+	nonces := make([]uint64, 0, len(m.pending))
+	for nonce := range m.pending {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool {
+		return nonces[i] < nonces[j]
+	})
+	txes := make([]persistedTx, 0, len(nonces))
+	for _, nonce := range nonces {
+		txes = append(txes, persistedTx{Transactions: m.pending[nonce].txes})
+	}
+	data, err := json.Marshal(txes)
+	if err != nil {
+		log.Println("Failed to encode pending transactions", err)
+		return
+	}
+	tmpPath := m.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		log.Println("Failed to save pending transactions", err)
+		return
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		log.Println("Failed to save pending transactions", err)
+	}
+}
+
+// load resumes watching the transactions saved at the given path, and saves
+// pending transactions there from now on
+func (m *txManager) load(path string) error {
+	var txes []persistedTx
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &txes); err != nil {
+			return fmt.Errorf("error parsing pending transactions: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.path = path
+	for _, saved := range txes {
+		if len(saved.Transactions) == 0 {
+			continue
+		}
+		nonce := saved.Transactions[0].Nonce()
+		if _, ok := m.pending[nonce]; ok {
+			continue
+		}
+		m.add(&pendingTx{
+			nonce:    nonce,
+			txes:     saved.Transactions,
+			lastBump: time.Now(),
+			done:     make(chan struct{}),
+		})
+	}
+	m.save()
+	return nil
+}
@@ -0,0 +1,349 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testTxBackend is a node which only mines transactions when told to
+type testTxBackend struct {
+	txBackend
+	sync.Mutex
+	nonce    uint64
+	mempool  map[ethcommon.Hash]*types.Transaction
+	receipts map[ethcommon.Hash]*types.Receipt
+	sent     int
+}
+
+func newTestTxBackend() *testTxBackend {
+	return &testTxBackend{
+		mempool:  make(map[ethcommon.Hash]*types.Transaction),
+		receipts: make(map[ethcommon.Hash]*types.Receipt),
+	}
+}
+
+func (b *testTxBackend) mine(tx *types.Transaction) {
+	b.Lock()
+	defer b.Unlock()
+	b.receipts[tx.Hash()] = &types.Receipt{
+		Status:      1,
+		TxHash:      tx.Hash(),
+		BlockNumber: big.NewInt(1),
+	}
+	delete(b.mempool, tx.Hash())
+	b.nonce = tx.Nonce() + 1
+}
+
+func (b *testTxBackend) drop() {
+	b.Lock()
+	defer b.Unlock()
+	b.mempool = make(map[ethcommon.Hash]*types.Transaction)
+}
+
+func (b *testTxBackend) pool() []*types.Transaction {
+	b.Lock()
+	defer b.Unlock()
+	txes := make([]*types.Transaction, 0, len(b.mempool))
+	for _, tx := range b.mempool {
+		txes = append(txes, tx)
+	}
+	return txes
+}
+
+func (b *testTxBackend) sendCount() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.sent
+}
+
+func (b *testTxBackend) PendingNonceAt(ctx context.Context, account ethcommon.Address) (uint64, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.nonce, nil
+}
+
+func (b *testTxBackend) NonceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (uint64, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.nonce, nil
+}
+
+func (b *testTxBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.Lock()
+	defer b.Unlock()
+	b.mempool[tx.Hash()] = tx
+	b.sent++
+	return nil
+}
+
+func (b *testTxBackend) TransactionByHash(ctx context.Context, hash ethcommon.Hash) (*types.Transaction, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	tx, ok := b.mempool[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, true, nil
+}
+
+func (b *testTxBackend) TransactionReceipt(ctx context.Context, hash ethcommon.Hash) (*types.Receipt, error) {
+	b.Lock()
+	defer b.Unlock()
+	receipt, ok := b.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func newTestTxManager(t *testing.T, backend *testTxBackend, auth *bind.TransactOpts) *txManager {
+	t.Helper()
+	if auth == nil {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth = bind.NewKeyedTransactor(key)
+	}
+	m := newTxManager(backend, auth)
+	m.pollInterval = time.Millisecond
+	m.bumpTimeout = time.Hour
+	return m
+}
+
+func sendTestTx(t *testing.T, m *txManager, backend *testTxBackend) *types.Transaction {
+	t.Helper()
+	nonce := m.nextNonce(context.Background())
+	rawTx := types.NewTransaction(nonce.Uint64(), ethcommon.Address{1}, big.NewInt(0), 21000, big.NewInt(100), nil)
+	tx, err := m.auth.Signer(types.HomesteadSigner{}, m.auth.From, rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestTxManagerNonces(t *testing.T) {
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+
+	tx1 := sendTestTx(t, m, backend)
+	p1 := m.track(tx1)
+	tx2 := sendTestTx(t, m, backend)
+	p2 := m.track(tx2)
+	if tx1.Nonce() != 0 || tx2.Nonce() != 1 {
+		t.Fatalf("expected consecutive nonces but got %v and %v", tx1.Nonce(), tx2.Nonce())
+	}
+
+	// The node losing our transactions doesn't let us reuse their nonces
+	backend.drop()
+	if nonce := m.nextNonce(context.Background()); nonce.Uint64() != 2 {
+		t.Fatalf("expected nonce 2 but got %v", nonce)
+	}
+
+	backend.mine(tx1)
+	backend.mine(tx2)
+	if _, err := m.wait(context.Background(), p1, "Test"); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := m.wait(context.Background(), p2, "Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TxHash != tx2.Hash() {
+		t.Error("got receipt for wrong transaction")
+	}
+}
+
+func TestTxManagerBump(t *testing.T) {
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+	m.bumpTimeout = time.Millisecond * 20
+	m.maxGasPrice = big.NewInt(150)
+
+	tx := sendTestTx(t, m, backend)
+	p := m.track(tx)
+
+	var replacement *types.Transaction
+	deadline := time.Now().Add(5 * time.Second)
+	for replacement == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("transaction wasn't replaced")
+		}
+		time.Sleep(time.Millisecond)
+		for _, pooled := range backend.pool() {
+			if pooled.Hash() != tx.Hash() && pooled.GasPrice().Cmp(big.NewInt(150)) == 0 {
+				replacement = pooled
+			}
+		}
+	}
+	if replacement.Nonce() != tx.Nonce() {
+		t.Error("replacement has the wrong nonce")
+	}
+
+	backend.mine(replacement)
+	receipt, err := m.wait(context.Background(), p, "Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TxHash != replacement.Hash() {
+		t.Error("got receipt for wrong transaction")
+	}
+}
+
+func TestTxManagerBumpEIP155(t *testing.T) {
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+
+	chainID := big.NewInt(1000)
+	signer := types.NewEIP155Signer(chainID)
+	rawTx := types.NewTransaction(0, ethcommon.Address{1}, big.NewInt(0), 21000, big.NewInt(100), nil)
+	tx, err := m.auth.Signer(signer, m.auth.From, rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replacement, err := m.bump(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replacement == nil {
+		t.Fatal("transaction wasn't bumped")
+	}
+	if !replacement.Protected() || replacement.ChainId().Cmp(chainID) != 0 {
+		t.Fatal("replacement lost its replay protection")
+	}
+	from, err := types.Sender(signer, replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != m.auth.From {
+		t.Error("replacement signed by", from.Hex())
+	}
+}
+
+func TestTxManagerRebroadcast(t *testing.T) {
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+
+	tx := sendTestTx(t, m, backend)
+	p := m.track(tx)
+	backend.drop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(backend.pool()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("dropped transaction wasn't rebroadcast")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if backend.sendCount() < 2 {
+		t.Error("expected transaction to be sent again")
+	}
+
+	backend.mine(tx)
+	if _, err := m.wait(context.Background(), p, "Test"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxManagerPersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pending.json")
+
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+	if err := m.load(path); err != nil {
+		t.Fatal(err)
+	}
+	tx1 := sendTestTx(t, m, backend)
+	p1 := m.track(tx1)
+	tx2 := sendTestTx(t, m, backend)
+	p2 := m.track(tx2)
+
+	// A new manager for the same account resumes the pending transactions
+	// and carries on after their nonces
+	resumed := newTestTxManager(t, backend, m.auth)
+	if err := resumed.load(path); err != nil {
+		t.Fatal(err)
+	}
+	backend.drop()
+	if nonce := resumed.nextNonce(context.Background()); nonce.Uint64() != 2 {
+		t.Fatalf("expected nonce 2 but got %v", nonce)
+	}
+	resumed.Lock()
+	resumedP1, ok1 := resumed.pending[tx1.Nonce()]
+	resumedP2, ok2 := resumed.pending[tx2.Nonce()]
+	resumed.Unlock()
+	if !ok1 || !ok2 {
+		t.Fatal("pending transactions weren't loaded")
+	}
+
+	backend.mine(tx1)
+	backend.mine(tx2)
+	for _, p := range []*pendingTx{p1, p2} {
+		if _, err := m.wait(context.Background(), p, "Test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []*pendingTx{resumedP1, resumedP2} {
+		if _, err := resumed.wait(context.Background(), p, "Test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTxManagerStop(t *testing.T) {
+	backend := newTestTxBackend()
+	m := newTestTxManager(t, backend, nil)
+
+	tx := sendTestTx(t, m, backend)
+	p := m.track(tx)
+	m.stop()
+
+	// The watcher has exited, so nothing rebroadcasts the dropped
+	// transaction
+	backend.drop()
+	sent := backend.sendCount()
+	time.Sleep(time.Millisecond * 20)
+	if backend.sendCount() != sent {
+		t.Error("transaction was rebroadcast after stopping")
+	}
+	if _, err := m.wait(context.Background(), p, "Test"); err != errTxManagerStopped {
+		t.Errorf("expected stopped error but got %v", err)
+	}
+}
@@ -207,6 +207,16 @@ func DeliveredValue(m SingleMessage) value.Value {
 }
 
 func AddToPrev(prev value.TupleValue, msg Message) value.TupleValue {
+	return addToPrev(prev, msg).(value.TupleValue)
+}
+
+// AddToPrevHash returns the hash of the VM inbox resulting from delivering msg
+// to an inbox of which only the hash is known
+func AddToPrevHash(prevHash common.Hash, msg Message) common.Hash {
+	return addToPrev(value.NewHashOnlyValue(prevHash, 1), msg).Hash()
+}
+
+func addToPrev(prev value.Value, msg Message) value.Value {
 	switch msg := msg.(type) {
 	case SingleMessage:
 		return value.NewTuple2(prev, DeliveredValue(msg))
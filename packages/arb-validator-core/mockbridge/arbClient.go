@@ -26,55 +26,152 @@ import (
 )
 
 type ArbClient struct {
-	client arbbridge.ArbClient
+	eth *MockEth
 }
 
-//func (c *ArbClient) GetClient() *ethclient.Client {
-//	return c.client
-//}
-
-func NewEthClient(ethURL string) (*ArbClient, error) {
-	// call to mockEth.go - initMock(ethURL)
-	//client, err := ethclient.Dial(ethURL)
-	return &ArbClient{nil}, nil
+func NewEthClient(eth *MockEth) *ArbClient {
+	return &ArbClient{eth}
 }
 
-func (c *ArbClient) NewArbFactory(address common.Address) (arbbridge.ArbFactory, error) {
-	return NewArbFactory(address, c.client)
+func (c *ArbClient) SubscribeBlockHeaders(ctx context.Context, startBlockId *common.BlockId) (<-chan arbbridge.MaybeBlockId, error) {
+	c.eth.Lock()
+	startBlock, err := c.eth.getBlock(startBlockId)
+	c.eth.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	blockIdChan := make(chan arbbridge.MaybeBlockId, 100)
+	blockIdChan <- arbbridge.MaybeBlockId{BlockId: startBlock.id, Timestamp: startBlock.timestamp}
+	go func() {
+		defer close(blockIdChan)
+
+		height := startBlock.id.Height.AsInt().Uint64() + 1
+		for {
+			block, newBlock := c.eth.blockAtHeight(height)
+			if block == nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-newBlock:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case blockIdChan <- arbbridge.MaybeBlockId{BlockId: block.id, Timestamp: block.timestamp}:
+			}
+			height++
+		}
+	}()
+	return blockIdChan, nil
 }
 
-func (c *ArbClient) NewRollup(address common.Address) (arbbridge.ArbRollup, error) {
-	return NewRollup(address, c.client)
+func (c *ArbClient) NewArbFactoryWatcher(address common.Address) (arbbridge.ArbFactoryWatcher, error) {
+	return newArbFactoryWatcher(address, c.eth)
 }
 
 func (c *ArbClient) NewRollupWatcher(address common.Address) (arbbridge.ArbRollupWatcher, error) {
-	return NewRollupWatcher(address, c.client)
+	return newRollupWatcher(address, c.eth)
+}
+
+func (c *ArbClient) NewExecutionChallengeWatcher(address common.Address) (arbbridge.ExecutionChallengeWatcher, error) {
+	return newChallengeWatcher(address, c.eth)
 }
 
-func (c *ArbClient) NewExecutionChallenge(address common.Address) (arbbridge.ExecutionChallenge, error) {
-	return NewExecutionChallenge(address, c.client)
+func (c *ArbClient) NewMessagesChallengeWatcher(address common.Address) (arbbridge.MessagesChallengeWatcher, error) {
+	return newChallengeWatcher(address, c.eth)
 }
 
-func (c *ArbClient) NewMessagesChallenge(address common.Address) (arbbridge.MessagesChallenge, error) {
-	return NewMessagesChallenge(address, c.client)
+func (c *ArbClient) NewInboxTopChallengeWatcher(address common.Address) (arbbridge.InboxTopChallengeWatcher, error) {
+	return newChallengeWatcher(address, c.eth)
 }
 
 func (c *ArbClient) NewOneStepProof(address common.Address) (arbbridge.OneStepProof, error) {
-	return NewOneStepProof(address, c.client)
+	return newOneStepProof(address, c.eth)
 }
 
-func (c *ArbClient) NewGlobalInbox(address common.Address) (arbbridge.GlobalInbox, error) {
-	return NewGlobalInbox(address, c.client)
+func (c *ArbClient) GetBalance(ctx context.Context, account common.Address) (*big.Int, error) {
+	c.eth.Lock()
+	defer c.eth.Unlock()
+	return new(big.Int).Set(c.eth.balance(account)), nil
 }
 
-func (c *ArbClient) NewInboxTopChallenge(address common.Address) (arbbridge.InboxTopChallenge, error) {
-	return NewInboxTopChallenge(address, c.client)
+func (c *ArbClient) CurrentBlockId(ctx context.Context) (*common.BlockId, error) {
+	c.eth.Lock()
+	defer c.eth.Unlock()
+	return c.eth.latestBlock().id.Clone(), nil
 }
 
-func (c *ArbClient) GetBalance(ctx context.Context, account common.Address) (*big.Int, error) {
-	return nil, errors.New("unimplemented")
+func (c *ArbClient) BlockIdForHeight(ctx context.Context, height *common.TimeBlocks) (*common.BlockId, error) {
+	block, _ := c.eth.blockAtHeight(height.AsInt().Uint64())
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	return block.id.Clone(), nil
 }
 
-func (c *ArbClient) CurrentBlockId(ctx context.Context) (*common.BlockId, error) {
-	return c.client.CurrentBlockId(ctx)
+type ArbAuthClient struct {
+	*ArbClient
+	address common.Address
+}
+
+func NewEthAuthClient(eth *MockEth, address common.Address) *ArbAuthClient {
+	return &ArbAuthClient{
+		ArbClient: NewEthClient(eth),
+		address:   address,
+	}
+}
+
+func (c *ArbAuthClient) Address() common.Address {
+	return c.address
+}
+
+func (c *ArbAuthClient) NewArbFactory(address common.Address) (arbbridge.ArbFactory, error) {
+	return newArbFactory(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewRollup(address common.Address) (arbbridge.ArbRollup, error) {
+	return newRollup(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewGlobalInbox(address common.Address) (arbbridge.GlobalInbox, error) {
+	return newGlobalInbox(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewChallengeFactory(address common.Address) (arbbridge.ChallengeFactory, error) {
+	return newChallengeFactory(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewExecutionChallenge(address common.Address) (arbbridge.ExecutionChallenge, error) {
+	return newExecutionChallenge(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewMessagesChallenge(address common.Address) (arbbridge.MessagesChallenge, error) {
+	return newMessagesChallenge(address, c.eth, c.address)
+}
+
+func (c *ArbAuthClient) NewInboxTopChallenge(address common.Address) (arbbridge.InboxTopChallenge, error) {
+	return newInboxTopChallenge(address, c.eth, c.address)
+}
+
+// DeployArbFactory deploys a rollup factory along with the global inbox and
+// challenge factory used by the rollups it creates
+func (c *ArbAuthClient) DeployArbFactory(ctx context.Context) (common.Address, error) {
+	var factoryAddress common.Address
+	err := c.eth.transact(c.address, nil, func(tx *mockTx) error {
+		inboxAddress := tx.createAddress()
+		tx.eth.inboxes[inboxAddress] = newGlobalInboxData()
+		challengeFactoryAddress := tx.createAddress()
+		tx.eth.challengeFactories[challengeFactoryAddress] = true
+		factoryAddress = tx.createAddress()
+		tx.eth.factories[factoryAddress] = &arbFactoryData{
+			globalInbox:      inboxAddress,
+			challengeFactory: challengeFactoryAddress,
+		}
+		return nil
+	})
+	return factoryAddress, err
 }
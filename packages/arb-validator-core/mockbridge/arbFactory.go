@@ -1,51 +1,100 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
 package mockbridge
 
 import (
 	"context"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
-
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type ArbFactory struct {
-	//contract *arbfactory.ArbFactory
-	client arbbridge.ArbClient
+type arbFactoryData struct {
+	globalInbox      common.Address
+	challengeFactory common.Address
 }
 
-func NewArbFactory(address common.Address, client arbbridge.ArbClient) (*ArbFactory, error) {
-	return &ArbFactory{client}, nil
+type arbFactory struct {
+	address common.Address
+	eth     *MockEth
+	from    common.Address
 }
 
-func (con *ArbFactory) CreateRollup(
+func newArbFactory(address common.Address, eth *MockEth, from common.Address) (*arbFactory, error) {
+	return &arbFactory{address: address, eth: eth, from: from}, nil
+}
+
+func (con *arbFactory) CreateRollup(
 	ctx context.Context,
 	vmState common.Hash,
 	params valprotocol.ChainParams,
 	owner common.Address,
 ) (common.Address, error) {
-	//tx, err := con.contract.CreateRollup(
-	//	auth,
-	//	vmState,
-	//	params.GracePeriod.Val,
-	//	new(big.Int).SetUint64(params.ArbGasSpeedLimitPerTick),
-	//	params.MaxExecutionSteps,
-	//	params.StakeRequirement,
-	//	owner,
-	//)
-	//if err != nil {
-	//	return common.Address{}, errors2.Wrap(err, "Failed to call to ChainFactory.CreateChain")
-	//}
-	//receipt, err := waitForReceiptWithResults(auth.Context, con.client, auth.From, tx, "CreateChain")
-	//if err != nil {
-	//	return common.Address{}, err
-	//}
-	//if len(receipt.Logs) != 1 {
-	//	return common.Address{}, errors2.New("Wrong receipt count")
-	//}
-	//event, err := con.contract.ParseRollupCreated(*receipt.Logs[0])
-	//if err != nil {
-	//	return common.Address{}, err
-	//}
-	return common.Address{}, nil
+	var rollupAddress common.Address
+	err := con.eth.transact(con.from, nil, func(tx *mockTx) error {
+		factory, ok := tx.eth.factories[con.address]
+		if !ok {
+			return errNoContract
+		}
+		rollupAddress = tx.createAddress()
+		tx.eth.rollups[rollupAddress] = newArbRollupData(
+			tx,
+			vmState,
+			params,
+			owner,
+			factory.globalInbox,
+			factory.challengeFactory,
+		)
+		return nil
+	})
+	return rollupAddress, err
+}
+
+type arbFactoryWatcher struct {
+	address common.Address
+	eth     *MockEth
+}
+
+func newArbFactoryWatcher(address common.Address, eth *MockEth) (*arbFactoryWatcher, error) {
+	return &arbFactoryWatcher{address: address, eth: eth}, nil
+}
+
+func (con *arbFactoryWatcher) getFactory() (*arbFactoryData, error) {
+	con.eth.Lock()
+	defer con.eth.Unlock()
+	factory, ok := con.eth.factories[con.address]
+	if !ok {
+		return nil, errNoContract
+	}
+	return factory, nil
+}
+
+func (con *arbFactoryWatcher) GlobalInboxAddress() (common.Address, error) {
+	factory, err := con.getFactory()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return factory.globalInbox, nil
+}
+
+func (con *arbFactoryWatcher) ChallengeFactoryAddress() (common.Address, error) {
+	factory, err := con.getFactory()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return factory.challengeFactory, nil
 }
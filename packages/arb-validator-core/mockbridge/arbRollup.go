@@ -17,190 +17,626 @@
 package mockbridge
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"math/big"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
-
-	"github.com/ethereum/go-ethereum/ethclient"
-
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type ArbRollup struct {
-	Client *ethclient.Client
+const rollupVersion = "2"
+
+var machineHaltHash = common.Hash{}
+var machineErrorHash = common.Hash{31: 1}
+
+type staker struct {
+	location           common.Hash
+	creationTimeBlocks *big.Int
+	inChallenge        bool
 }
 
-func NewRollup(address common.Address, client arbbridge.ArbClient) (*ArbRollup, error) {
-	//arbitrumRollupContract, err := rollup.NewArbRollup(address, client.(*ArbClient).client)
-	//if err != nil {
-	//	return nil, errors2.Wrap(err, "Failed to connect to ArbRollup")
-	//}
-	//vm := &ArbRollup{Client: client.(*ArbClient).client, ArbRollup: arbitrumRollupContract, auth: auth}
-	return &ArbRollup{
-		Client: nil,
-	}, nil
+type arbRollupData struct {
+	vmState          common.Hash
+	creation         *common.BlockId
+	params           valprotocol.ChainParams
+	owner            common.Address
+	globalInbox      common.Address
+	challengeFactory common.Address
+
+	latestConfirmed common.Hash
+	leaves          map[common.Hash]bool
+	stakers         map[common.Address]*staker
+	challenges      map[common.Address]bool
 }
 
-func (vm *ArbRollup) PlaceStake(ctx context.Context, stakeAmount *big.Int, proof1 []common.Hash, proof2 []common.Hash) error {
-	//call := &bind.TransactOpts{
-	//	From:    vm.auth.From,
-	//	Signer:  vm.auth.Signer,
-	//	Value:   stakeAmount,
-	//	Context: ctx,
-	//}
-	//tx, err := vm.ArbRollup.PlaceStake(
-	//	call,
-	//	proof1,
-	//	proof2,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "PlaceStake")
-	return nil
+func newArbRollupData(
+	tx *mockTx,
+	vmState common.Hash,
+	params valprotocol.ChainParams,
+	owner common.Address,
+	globalInbox common.Address,
+	challengeFactory common.Address,
+) *arbRollupData {
+	initialNode := childNodeHash(
+		common.Hash{},
+		big.NewInt(0),
+		common.Hash{},
+		0,
+		valprotocol.NewVMProtoData(vmState, value.NewEmptyTuple().Hash(), big.NewInt(0)).Hash(),
+	)
+	return &arbRollupData{
+		vmState:          vmState,
+		creation:         tx.block.id,
+		params:           params,
+		owner:            owner,
+		globalInbox:      globalInbox,
+		challengeFactory: challengeFactory,
+		latestConfirmed:  initialNode,
+		leaves:           map[common.Hash]bool{initialNode: true},
+		stakers:          make(map[common.Address]*staker),
+		challenges:       make(map[common.Address]bool),
+	}
 }
 
-func (vm *ArbRollup) RecoverStakeConfirmed(ctx context.Context, proof []common.Hash) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.RecoverStakeConfirmed(
-	//	vm.auth,
-	//	proof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "RecoverStakeConfirmed")
-	return nil
+func childNodeHash(
+	prevNodeHash common.Hash,
+	deadlineTicks *big.Int,
+	nodeDataHash common.Hash,
+	childType valprotocol.ChildType,
+	vmProtoStateHash common.Hash,
+) common.Hash {
+	return hashing.SoliditySHA3(
+		hashing.Bytes32(prevNodeHash),
+		hashing.Bytes32(hashing.SoliditySHA3(
+			hashing.Bytes32(vmProtoStateHash),
+			hashing.Uint256(deadlineTicks),
+			hashing.Bytes32(nodeDataHash),
+			hashing.Uint256(new(big.Int).SetUint64(uint64(childType))),
+		)),
+	)
 }
 
-func (vm *ArbRollup) RecoverStakeOld(ctx context.Context, staker common.Address, proof []common.Hash) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.RecoverStakeOld(
-	//	vm.auth,
-	//	staker,
-	//	proof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "RecoverStakeOld")
-	return nil
+func validDataHash(messagesAcc common.Hash, logsAcc common.Hash) common.Hash {
+	return hashing.SoliditySHA3(
+		hashing.Bytes32(messagesAcc),
+		hashing.Bytes32(logsAcc),
+	)
 }
 
-func (vm *ArbRollup) RecoverStakeMooted(ctx context.Context, nodeHash common.Hash, staker common.Address, latestConfirmedProof []common.Hash, stakerProof []common.Hash) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.RecoverStakeMooted(
-	//	vm.auth,
-	//	staker,
-	//	nodeHash,
-	//	latestConfirmedProof,
-	//	stakerProof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "RecoverStakeMooted")
-	return nil
+func challengeDataHash(challenge common.Hash, challengePeriod *big.Int) common.Hash {
+	return hashing.SoliditySHA3(
+		hashing.Bytes32(challenge),
+		hashing.Uint256(challengePeriod),
+	)
+}
+
+func calculatePath(from common.Hash, proof []common.Hash) common.Hash {
+	node := from
+	for _, hash := range proof {
+		node = hashing.SoliditySHA3(hashing.Bytes32(node), hashing.Bytes32(hash))
+	}
+	return node
+}
+
+func blocksToTicks(blocks *big.Int) *big.Int {
+	return common.TicksFromBlockNum(common.NewTimeBlocks(blocks)).Val
+}
+
+func (r *arbRollupData) getStaker(address common.Address) (*staker, error) {
+	s, ok := r.stakers[address]
+	if !ok {
+		return nil, errors.New("INV_STAKER")
+	}
+	return s, nil
 }
 
-func (vm *ArbRollup) RecoverStakePassedDeadline(ctx context.Context, stakerAddress common.Address, deadlineTicks *big.Int, disputableNodeHashVal common.Hash, childType uint64, vmProtoStateHash common.Hash, proof []common.Hash) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.RecoverStakePassedDeadline(
-	//	vm.auth,
-	//	stakerAddress,
-	//	deadlineTicks,
-	//	disputableNodeHashVal,
-	//	new(big.Int).SetUint64(childType),
-	//	vmProtoStateHash,
-	//	proof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "RecoverStakePassedDeadline")
+func (r *arbRollupData) createStake(tx *mockTx, rollupAddress common.Address, location common.Hash) error {
+	if tx.value.Cmp(r.params.StakeRequirement) != 0 {
+		return errors.New("STK_AMT")
+	}
+	if _, ok := r.stakers[tx.from]; ok {
+		return errors.New("ALRDY_STAKED")
+	}
+	r.stakers[tx.from] = &staker{
+		location:           location,
+		creationTimeBlocks: tx.blockNumber(),
+		inChallenge:        false,
+	}
+	tx.emit(rollupAddress, arbbridge.StakeCreatedEvent{
+		ChainInfo: tx.chainInfo(),
+		Staker:    tx.from,
+		NodeHash:  location,
+	})
 	return nil
 }
 
-func (vm *ArbRollup) MoveStake(ctx context.Context, proof1 []common.Hash, proof2 []common.Hash) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.MoveStake(
-	//	vm.auth,
-	//	proof1,
-	//	proof2,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "MoveStake")
+func (r *arbRollupData) updateStakerLocation(tx *mockTx, rollupAddress common.Address, address common.Address, location common.Hash) {
+	r.stakers[address].location = location
+	tx.emit(rollupAddress, arbbridge.StakeMovedEvent{
+		ChainInfo: tx.chainInfo(),
+		Staker:    address,
+		Location:  location,
+	})
+}
+
+func (r *arbRollupData) refundStaker(tx *mockTx, rollupAddress common.Address, address common.Address) {
+	delete(r.stakers, address)
+	tx.eth.credit(address, r.params.StakeRequirement)
+	tx.emit(rollupAddress, arbbridge.StakeRefundedEvent{
+		ChainInfo: tx.chainInfo(),
+		Staker:    address,
+	})
+}
+
+// resolveChallenge is called by a challenge created by this rollup once the
+// challenge has been decided
+func (r *arbRollupData) resolveChallenge(
+	tx *mockTx,
+	rollupAddress common.Address,
+	challengeAddress common.Address,
+	winner common.Address,
+	loser common.Address,
+) error {
+	if !r.challenges[challengeAddress] {
+		return errors.New("RES_CHAL_SENDER")
+	}
+	winningStaker, err := r.getStaker(winner)
+	if err != nil {
+		return err
+	}
+	delete(r.challenges, challengeAddress)
+	tx.eth.credit(winner, new(big.Int).Div(r.params.StakeRequirement, big.NewInt(2)))
+	winningStaker.inChallenge = false
+	delete(r.stakers, loser)
+	tx.emit(rollupAddress, arbbridge.ChallengeCompletedEvent{
+		ChainInfo:         tx.chainInfo(),
+		Winner:            winner,
+		Loser:             loser,
+		ChallengeContract: challengeAddress,
+	})
 	return nil
 }
 
-func (vm *ArbRollup) PruneLeaves(ctx context.Context, params []valprotocol.PruneParams) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.PruneLeaf(
-	//	vm.auth,
-	//	from,
-	//	proof1,
-	//	proof2,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "PruneLeaf")
+type arbRollup struct {
+	address common.Address
+	eth     *MockEth
+	from    common.Address
+}
+
+func newRollup(address common.Address, eth *MockEth, from common.Address) (*arbRollup, error) {
+	return &arbRollup{address: address, eth: eth, from: from}, nil
+}
+
+// transact runs f against the state of the rollup contract in a transaction
+// sent by the client's account
+func (vm *arbRollup) transact(value *big.Int, f func(tx *mockTx, r *arbRollupData) error) error {
+	return vm.eth.transact(vm.from, value, func(tx *mockTx) error {
+		r, ok := tx.eth.rollups[vm.address]
+		if !ok {
+			return errNoContract
+		}
+		return f(tx, r)
+	})
+}
+
+func (vm *arbRollup) PlaceStake(ctx context.Context, stakeAmount *big.Int, proof1 []common.Hash, proof2 []common.Hash) error {
+	return vm.transact(stakeAmount, func(tx *mockTx, r *arbRollupData) error {
+		location := calculatePath(r.latestConfirmed, proof1)
+		leaf := calculatePath(location, proof2)
+		if !r.leaves[leaf] {
+			return errors.New("PLACE_LEAF")
+		}
+		return r.createStake(tx, vm.address, location)
+	})
+}
+
+func (vm *arbRollup) RecoverStakeConfirmed(ctx context.Context, proof []common.Hash) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		return r.recoverStakeConfirmed(tx, vm.address, tx.from, proof)
+	})
+}
+
+func (vm *arbRollup) RecoverStakeOld(ctx context.Context, staker common.Address, proof []common.Hash) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		if len(proof) == 0 {
+			return errors.New("RECVOLD_LENGTH")
+		}
+		return r.recoverStakeConfirmed(tx, vm.address, staker, proof)
+	})
+}
+
+func (r *arbRollupData) recoverStakeConfirmed(tx *mockTx, rollupAddress common.Address, stakerAddress common.Address, proof []common.Hash) error {
+	// Like the contract, this checks the location of the sender rather than
+	// the location of the staker being refunded
+	s, err := r.getStaker(tx.from)
+	if err != nil {
+		return err
+	}
+	if calculatePath(s.location, proof) != r.latestConfirmed {
+		return errors.New("RECOV_PATH_PROOF")
+	}
+	if _, err := r.getStaker(stakerAddress); err != nil {
+		return err
+	}
+	r.refundStaker(tx, rollupAddress, stakerAddress)
 	return nil
 }
 
-func (vm *ArbRollup) MakeAssertion(
-	ctx context.Context,
+func (vm *arbRollup) RecoverStakeMooted(ctx context.Context, nodeHash common.Hash, staker common.Address, latestConfirmedProof []common.Hash, stakerProof []common.Hash) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		s, err := r.getStaker(tx.from)
+		if err != nil {
+			return err
+		}
+		if len(latestConfirmedProof) == 0 || len(stakerProof) == 0 ||
+			latestConfirmedProof[0] == stakerProof[0] ||
+			calculatePath(nodeHash, latestConfirmedProof) != r.latestConfirmed ||
+			calculatePath(nodeHash, stakerProof) != s.location {
+			return errors.New("RECOV_CONFLICT_PROOF")
+		}
+		if _, err := r.getStaker(staker); err != nil {
+			return err
+		}
+		r.refundStaker(tx, vm.address, staker)
+		return nil
+	})
+}
+
+func (vm *arbRollup) RecoverStakePassedDeadline(ctx context.Context, stakerAddress common.Address, deadlineTicks *big.Int, disputableNodeHashVal common.Hash, childType uint64, vmProtoStateHash common.Hash, proof []common.Hash) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		s, err := r.getStaker(tx.from)
+		if err != nil {
+			return err
+		}
+		nextNode := childNodeHash(
+			s.location,
+			deadlineTicks,
+			disputableNodeHashVal,
+			valprotocol.ChildType(childType),
+			vmProtoStateHash,
+		)
+		if !r.leaves[calculatePath(nextNode, proof)] {
+			return errors.New("RECOV_DEADLINE_LEAF")
+		}
+		// The contract compares the block number against the deadline
+		// converted to ticks and this mirrors that check exactly
+		if tx.blockNumber().Cmp(blocksToTicks(deadlineTicks)) < 0 {
+			return errors.New("RECOV_DEADLINE_TIME")
+		}
+		if _, err := r.getStaker(stakerAddress); err != nil {
+			return err
+		}
+		r.refundStaker(tx, vm.address, stakerAddress)
+		return nil
+	})
+}
+
+func (vm *arbRollup) MoveStake(ctx context.Context, proof1 []common.Hash, proof2 []common.Hash) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		s, err := r.getStaker(tx.from)
+		if err != nil {
+			return err
+		}
+		newLocation := calculatePath(s.location, proof1)
+		leaf := calculatePath(newLocation, proof2)
+		if !r.leaves[leaf] {
+			return errors.New("MOVE_LEAF")
+		}
+		r.updateStakerLocation(tx, vm.address, tx.from, newLocation)
+		return nil
+	})
+}
 
+func (vm *arbRollup) PruneLeaves(ctx context.Context, params []valprotocol.PruneParams) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		for _, param := range params {
+			if len(param.LeafProof) == 0 || len(param.AncProof) == 0 {
+				return errors.New("PRUNE_PROOFLEN")
+			}
+			if param.LeafProof[0] == param.AncProof[0] ||
+				calculatePath(param.AncestorHash, param.AncProof) != r.latestConfirmed {
+				return errors.New("PRUNE_CONFLICT")
+			}
+		}
+		for _, param := range params {
+			leaf := calculatePath(param.AncestorHash, param.LeafProof)
+			if r.leaves[leaf] {
+				delete(r.leaves, leaf)
+				tx.emit(vm.address, arbbridge.PrunedEvent{
+					ChainInfo: tx.chainInfo(),
+					Leaf:      leaf,
+				})
+			}
+		}
+		return nil
+	})
+}
+
+func (vm *arbRollup) MakeAssertion(
+	ctx context.Context,
 	prevPrevLeafHash common.Hash,
 	prevDataHash common.Hash,
 	prevDeadline common.TimeTicks,
 	prevChildType valprotocol.ChildType,
-
 	beforeState *valprotocol.VMProtoData,
 	assertionParams *valprotocol.AssertionParams,
 	assertionClaim *valprotocol.AssertionClaim,
 	stakerProof []common.Hash,
 ) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.MakeAssertion(
-	//	vm.auth,
-	//	[9][32]byte{
-	//		beforeState.MachineHash,
-	//		beforeState.InboxTop,
-	//		prevPrevLeafHash,
-	//		prevDataHash,
-	//		assertionClaim.AfterInboxTop,
-	//		assertionClaim.ImportedMessagesSlice,
-	//		assertionClaim.AssertionStub.AfterHashValue(),
-	//		assertionClaim.AssertionStub.LastMessageHashValue(),
-	//		assertionClaim.AssertionStub.LastLogHashValue(),
-	//	},
-	//	beforeState.InboxCount,
-	//	prevDeadline.Val,
-	//	uint32(prevChildType),
-	//	assertionParams.NumSteps,
-	//	assertionParams.TimeBounds.AsIntArray(),
-	//	assertionParams.ImportedMessageCount,
-	//	assertionClaim.AssertionStub.DidInboxInsn,
-	//	assertionClaim.AssertionStub.NumGas,
-	//	stakerProof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "MakeAssertion")
-	return nil
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		params := r.params
+		timeBounds := assertionParams.TimeBounds
+		stub := assertionClaim.AssertionStub
+
+		vmProtoHashBefore := beforeState.Hash()
+		prevLeaf := childNodeHash(
+			prevPrevLeafHash,
+			prevDeadline.Val,
+			prevDataHash,
+			prevChildType,
+			vmProtoHashBefore,
+		)
+		if !r.leaves[prevLeaf] {
+			return errors.New("MAKE_LEAF")
+		}
+		if beforeState.MachineHash == machineErrorHash || beforeState.MachineHash == machineHaltHash {
+			return errors.New("MAKE_RUN")
+		}
+		if assertionParams.NumSteps > params.MaxExecutionSteps {
+			return errors.New("MAKE_STEP")
+		}
+		maxUpperBlock := new(big.Int).Add(
+			timeBounds.LowerBoundBlock.AsInt(),
+			new(big.Int).SetUint64(params.MaxBlockBoundsWidth),
+		)
+		maxLowerTimestamp := new(big.Int).Add(
+			timeBounds.UpperBoundTimestamp,
+			new(big.Int).SetUint64(params.MaxTimestampBoundsWidth),
+		)
+		if timeBounds.UpperBoundBlock.AsInt().Cmp(maxUpperBlock) > 0 ||
+			timeBounds.LowerBoundTimestamp.Cmp(maxLowerTimestamp) > 0 {
+			return errors.New("time bounds too wide")
+		}
+		if !withinTimeBounds(tx, timeBounds) {
+			return errors.New("MAKE_TIME")
+		}
+		if assertionParams.ImportedMessageCount.Sign() != 0 && !stub.DidInboxInsn {
+			return errors.New("MAKE_MESSAGES")
+		}
+
+		inbox := tx.eth.inboxes[r.globalInbox].getInbox(vm.address)
+		availableCount := new(big.Int).Sub(inbox.count, beforeState.InboxCount)
+		if assertionParams.ImportedMessageCount.Cmp(availableCount) > 0 {
+			return errors.New("MAKE_MESSAGE_CNT")
+		}
+		if params.ArbGasSpeedLimitPerTick == 0 {
+			return errors.New("invalid arbgas speed limit")
+		}
+
+		gracePeriodTicks := params.GracePeriod.Val
+		checkTimeTicks := new(big.Int).SetUint64(stub.NumGas / params.ArbGasSpeedLimitPerTick)
+		deadlineTicks := new(big.Int).Add(tx.blockTicks(), gracePeriodTicks)
+		if deadlineTicks.Cmp(prevDeadline.Val) < 0 {
+			deadlineTicks.Set(prevDeadline.Val)
+		}
+		deadlineTicks.Add(deadlineTicks, checkTimeTicks)
+		messageChallengePeriod := new(big.Int).Add(gracePeriodTicks, blocksToTicks(big.NewInt(1)))
+
+		invalidInbox := childNodeHash(
+			prevLeaf,
+			deadlineTicks,
+			challengeDataHash(
+				valprotocol.InboxTopChallengeDataHash(
+					assertionClaim.AfterInboxTop,
+					inbox.value,
+					new(big.Int).Sub(
+						inbox.count,
+						new(big.Int).Add(beforeState.InboxCount, assertionParams.ImportedMessageCount),
+					),
+				),
+				messageChallengePeriod,
+			),
+			valprotocol.InvalidInboxTopChildType,
+			vmProtoHashBefore,
+		)
+		invalidMessages := childNodeHash(
+			prevLeaf,
+			deadlineTicks,
+			challengeDataHash(
+				valprotocol.MessageChallengeDataHash(
+					beforeState.InboxTop,
+					assertionClaim.AfterInboxTop,
+					value.NewEmptyTuple().Hash(),
+					assertionClaim.ImportedMessagesSlice,
+					assertionParams.ImportedMessageCount,
+				),
+				messageChallengePeriod,
+			),
+			valprotocol.InvalidMessagesChildType,
+			vmProtoHashBefore,
+		)
+		precondition := valprotocol.NewPrecondition(
+			beforeState.MachineHash,
+			timeBounds,
+			value.NewHashOnlyValue(assertionClaim.ImportedMessagesSlice, 1),
+		)
+		assertionStub := &valprotocol.ExecutionAssertionStub{
+			AfterHash:        stub.AfterHash,
+			DidInboxInsn:     stub.DidInboxInsn,
+			NumGas:           stub.NumGas,
+			FirstMessageHash: common.Hash{},
+			LastMessageHash:  stub.LastMessageHash,
+			FirstLogHash:     common.Hash{},
+			LastLogHash:      stub.LastLogHash,
+		}
+		invalidExec := childNodeHash(
+			prevLeaf,
+			deadlineTicks,
+			challengeDataHash(
+				valprotocol.ExecutionDataHash(
+					assertionParams.NumSteps,
+					precondition.Hash(),
+					assertionStub.Hash(),
+				),
+				new(big.Int).Add(gracePeriodTicks, checkTimeTicks),
+			),
+			valprotocol.InvalidExecutionChildType,
+			vmProtoHashBefore,
+		)
+		validHash := childNodeHash(
+			prevLeaf,
+			deadlineTicks,
+			validDataHash(stub.LastMessageHash, stub.LastLogHash),
+			valprotocol.ValidChildType,
+			valprotocol.NewVMProtoData(
+				stub.AfterHash,
+				assertionClaim.AfterInboxTop,
+				new(big.Int).Add(beforeState.InboxCount, assertionParams.ImportedMessageCount),
+			).Hash(),
+		)
+
+		s, err := r.getStaker(tx.from)
+		if err != nil {
+			return err
+		}
+		if calculatePath(s.location, stakerProof) != prevLeaf {
+			return errors.New("MAKE_STAKER_PROOF")
+		}
+
+		r.leaves[invalidInbox] = true
+		r.leaves[invalidMessages] = true
+		r.leaves[invalidExec] = true
+		r.leaves[validHash] = true
+		delete(r.leaves, prevLeaf)
+
+		tx.emit(vm.address, arbbridge.AssertedEvent{
+			ChainInfo:    tx.chainInfo(),
+			PrevLeafHash: prevLeaf,
+			Params:       assertionParams.Clone(),
+			Claim: &valprotocol.AssertionClaim{
+				AfterInboxTop:         assertionClaim.AfterInboxTop,
+				ImportedMessagesSlice: assertionClaim.ImportedMessagesSlice,
+				AssertionStub:         assertionStub,
+			},
+			MaxInboxTop:   inbox.value,
+			MaxInboxCount: new(big.Int).Set(inbox.count),
+		})
+		r.updateStakerLocation(tx, vm.address, tx.from, validHash)
+		return nil
+	})
 }
 
-func (vm *ArbRollup) Confirm(ctx context.Context, opp *valprotocol.ConfirmOpportunity) error {
-	return nil
+func withinTimeBounds(tx *mockTx, timeBounds *protocol.TimeBounds) bool {
+	return tx.blockNumber().Cmp(timeBounds.LowerBoundBlock.AsInt()) >= 0 &&
+		tx.blockNumber().Cmp(timeBounds.UpperBoundBlock.AsInt()) <= 0 &&
+		tx.timestamp().Cmp(timeBounds.LowerBoundTimestamp) >= 0 &&
+		tx.timestamp().Cmp(timeBounds.UpperBoundTimestamp) <= 0
 }
 
-func (vm *ArbRollup) StartChallenge(
+func (vm *arbRollup) Confirm(ctx context.Context, opp *valprotocol.ConfirmOpportunity) error {
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		nodeOpps := opp.Nodes
+		if len(nodeOpps) == 0 {
+			return errors.New("no nodes to confirm")
+		}
+		confNode := r.latestConfirmed
+		vmProtoStateHash := nodeOpps[0].StateHash()
+		logsAcc := make([]common.Hash, 0)
+		for _, nodeOpp := range nodeOpps {
+			var nodeDataHash common.Hash
+			switch nodeOpp := nodeOpp.(type) {
+			case valprotocol.ConfirmValidOpportunity:
+				var lastMsgHash common.Hash
+				for _, msg := range nodeOpp.Messages {
+					lastMsgHash = hashing.SoliditySHA3(
+						hashing.Bytes32(lastMsgHash),
+						hashing.Bytes32(msg.Hash()),
+					)
+				}
+				nodeDataHash = validDataHash(lastMsgHash, nodeOpp.LogsAcc)
+				vmProtoStateHash = nodeOpp.VMProtoStateHash
+				logsAcc = append(logsAcc, nodeOpp.LogsAcc)
+			case valprotocol.ConfirmInvalidOpportunity:
+				nodeDataHash = nodeOpp.ChallengeNodeData
+			default:
+				return errors.New("CONF_INV_TYPE")
+			}
+			confNode = childNodeHash(
+				confNode,
+				nodeOpp.Deadline().Val,
+				nodeDataHash,
+				nodeOpp.BranchType(),
+				vmProtoStateHash,
+			)
+		}
+
+		deadlineTicks := nodeOpps[len(nodeOpps)-1].Deadline().Val
+		if tx.blockTicks().Cmp(deadlineTicks) < 0 {
+			return errors.New("CONF_TIME")
+		}
+		activeCount, err := r.checkAlignedStakers(confNode, deadlineTicks, opp.StakerAddresses, opp.StakerProofs)
+		if err != nil {
+			return err
+		}
+		if activeCount == 0 {
+			return errors.New("CONF_HAS_STAKER")
+		}
+
+		r.latestConfirmed = confNode
+		tx.emit(vm.address, arbbridge.ConfirmedEvent{
+			ChainInfo: tx.chainInfo(),
+			NodeHash:  confNode,
+		})
+		if len(logsAcc) > 0 {
+			tx.emit(vm.address, arbbridge.ConfirmedAssertionEvent{
+				ChainInfo:   tx.chainInfo(),
+				LogsAccHash: logsAcc,
+			})
+		}
+		return nil
+	})
+}
+
+func (r *arbRollupData) checkAlignedStakers(
+	node common.Hash,
+	deadlineTicks *big.Int,
+	stakerAddresses []common.Address,
+	stakerProofs [][]common.Hash,
+) (int, error) {
+	if len(stakerAddresses) != len(r.stakers) {
+		return 0, errors.New("CHCK_COUNT")
+	}
+	if len(stakerAddresses) != len(stakerProofs) {
+		return 0, errors.New("CHCK_OFFSETS")
+	}
+	var prevStaker common.Address
+	activeCount := 0
+	for i, stakerAddress := range stakerAddresses {
+		if bytes.Compare(stakerAddress[:], prevStaker[:]) <= 0 {
+			return 0, errors.New("CHCK_ORDER")
+		}
+		s, err := r.getStaker(stakerAddress)
+		if err != nil {
+			return 0, err
+		}
+		if blocksToTicks(s.creationTimeBlocks).Cmp(deadlineTicks) < 0 {
+			if calculatePath(node, stakerProofs[i]) != s.location {
+				return 0, errors.New("CHCK_STAKER_PROOF")
+			}
+			activeCount++
+		}
+		prevStaker = stakerAddress
+	}
+	return activeCount, nil
+}
+
+func (vm *arbRollup) StartChallenge(
 	ctx context.Context,
 	asserterAddress common.Address,
 	challengerAddress common.Address,
@@ -216,76 +652,86 @@ func (vm *ArbRollup) StartChallenge(
 	challengerDataHash common.Hash,
 	challengerPeriodTicks common.TimeTicks,
 ) error {
-	//vm.auth.Context = ctx
-	//tx, err := vm.ArbRollup.StartChallenge(
-	//	vm.auth,
-	//	asserterAddress,
-	//	challengerAddress,
-	//	prevNode,
-	//	disputableDeadline,
-	//	[2]*big.Int{
-	//		new(big.Int).SetUint64(uint64(asserterPosition)),
-	//		new(big.Int).SetUint64(uint64(challengerPosition)),
-	//	},
-	//	[2][32]byte{
-	//		asserterVMProtoHash,
-	//		challengerVMProtoHash,
-	//	},
-	//	asserterProof,
-	//	challengerProof,
-	//	asserterDataHash,
-	//	asserterPeriodTicks.Val,
-	//	challengerNodeHash,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return vm.waitForReceipt(ctx, tx, "StartExecutionChallenge")
-	return nil
-}
+	return vm.transact(nil, func(tx *mockTx, r *arbRollupData) error {
+		asserter, err := r.getStaker(asserterAddress)
+		if err != nil {
+			return err
+		}
+		challenger, err := r.getStaker(challengerAddress)
+		if err != nil {
+			return err
+		}
+		if blocksToTicks(asserter.creationTimeBlocks).Cmp(disputableDeadline) >= 0 {
+			return errors.New("STK1_DEADLINE")
+		}
+		if blocksToTicks(challenger.creationTimeBlocks).Cmp(disputableDeadline) >= 0 {
+			return errors.New("STK2_DEADLINE")
+		}
+		if asserter.inChallenge {
+			return errors.New("STK1_IN_CHAL")
+		}
+		if challenger.inChallenge {
+			return errors.New("STK2_IN_CHAL")
+		}
+		if asserterPosition <= challengerPosition {
+			return errors.New("TYPE_ORDER")
+		}
+		asserterNode := childNodeHash(
+			prevNode,
+			disputableDeadline,
+			asserterNodeHash,
+			asserterPosition,
+			asserterVMProtoHash,
+		)
+		if calculatePath(asserterNode, asserterProof) != asserter.location {
+			return errors.New("ASSERT_PROOF")
+		}
+		challengerNode := childNodeHash(
+			prevNode,
+			disputableDeadline,
+			challengeDataHash(challengerDataHash, challengerPeriodTicks.Val),
+			challengerPosition,
+			challengerVMProtoHash,
+		)
+		if calculatePath(challengerNode, challengerProof) != challenger.location {
+			return errors.New("CHAL_PROOF")
+		}
+
+		challengeAddress, err := createChallenge(
+			tx,
+			r.challengeFactory,
+			vm.address,
+			asserterAddress,
+			challengerAddress,
+			challengerPeriodTicks,
+			challengerDataHash,
+			challengerPosition,
+		)
+		if err != nil {
+			return err
+		}
+		asserter.inChallenge = true
+		challenger.inChallenge = true
+		r.challenges[challengeAddress] = true
 
-func (vm *ArbRollup) IsStaked(address common.Address) (bool, error) {
-	return false, nil
+		tx.emit(vm.address, arbbridge.ChallengeStartedEvent{
+			ChainInfo:         tx.chainInfo(),
+			Asserter:          asserterAddress,
+			Challenger:        challengerAddress,
+			ChallengeType:     challengerPosition,
+			ChallengeContract: challengeAddress,
+		})
+		return nil
+	})
 }
 
-//func (vm *ArbRollup) VerifyVM(
-//	auth *bind.CallOpts,
-//	config *valmessage.VMConfiguration,
-//	machine [32]byte,
-//) error {
-//	//code, err := vm.contract.Client.CodeAt(auth.Context, vm.address, nil)
-//	// Verify that VM has correct code
-//	vmInfo, err := vm.ArbRollup.Vm(auth)
-//	if err != nil {
-//		return err
-//	}
-//
-//	if vmInfo.MachineHash != machine {
-//		return errors.New("VM has different machine hash")
-//	}
-//
-//	if config.GracePeriod != uint64(vmInfo.GracePeriod) {
-//		return errors.New("VM has different grace period")
-//	}
-//
-//	if value.NewBigIntFromBuf(config.EscrowRequired).Cmp(vmInfo.EscrowRequired) != 0 {
-//		return errors.New("VM has different escrow required")
-//	}
-//
-//	if config.MaxExecutionStepCount != vmInfo.MaxExecutionSteps {
-//		return errors.New("VM has different mxa steps")
-//	}
-//
-//	owner, err := vm.ArbRollup.Owner(auth)
-//	if err != nil {
-//		return err
-//	}
-//	if protocol.NewAddressFromBuf(config.Owner) != owner {
-//		return errors.New("VM has different owner")
-//	}
-//	return nil
-//}
-
-//func (vm *ArbRollup) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) error {
-//	return waitForReceipt(ctx, vm.Client, vm.auth.From, tx, methodName)
-//}
+func (vm *arbRollup) IsStaked(address common.Address) (bool, error) {
+	vm.eth.Lock()
+	defer vm.eth.Unlock()
+	r, ok := vm.eth.rollups[vm.address]
+	if !ok {
+		return false, errNoContract
+	}
+	_, ok = r.stakers[address]
+	return ok, nil
+}
@@ -20,217 +20,89 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
-
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type EthRollupWatcher struct {
-	client arbbridge.ArbClient
-
+type arbRollupWatcher struct {
 	address common.Address
+	eth     *MockEth
 }
 
-func NewRollupWatcher(address common.Address, client arbbridge.ArbClient) (*EthRollupWatcher, error) {
-	//vm := &EthRollupWatcher{Client: client.(*ArbClient).client, address: address}
-	//err := vm.setupContracts()
-	//return vm, err
-	return &EthRollupWatcher{client: client}, nil
+func newRollupWatcher(address common.Address, eth *MockEth) (*arbRollupWatcher, error) {
+	return &arbRollupWatcher{address: address, eth: eth}, nil
 }
 
-//func (vm *EthRollupWatcher) setupContracts() error {
-//	arbitrumRollupContract, err := rollup.NewArbRollup(vm.address, vm.Client)
-//	if err != nil {
-//		return errors2.Wrap(err, "Failed to connect to ArbRollup")
-//	}
-//
-//	globalInboxAddress, err := arbitrumRollupContract.GlobalInbox(&bind.CallOpts{
-//		Pending: false,
-//		Context: context.Background(),
-//	})
-//	if err != nil {
-//		return errors2.Wrap(err, "Failed to get GlobalInbox address")
-//	}
-//	globalInboxContract, err := rollup.NewIGlobalInbox(globalInboxAddress, vm.Client)
-//	if err != nil {
-//		return errors2.Wrap(err, "Failed to connect to GlobalInbox")
-//	}
-//
-//	vm.ArbRollup = arbitrumRollupContract
-//	vm.GlobalInbox = globalInboxContract
-//	return nil
-//}
-
-func (vm *EthRollupWatcher) GetEvents(ctx context.Context, blockId *common.BlockId, timestamp *big.Int) ([]arbbridge.Event, error) {
-	return nil, nil
+func (vm *arbRollupWatcher) getRollup() (*arbRollupData, error) {
+	vm.eth.Lock()
+	defer vm.eth.Unlock()
+	r, ok := vm.eth.rollups[vm.address]
+	if !ok {
+		return nil, errNoContract
+	}
+	return r, nil
 }
 
-//func (vm *EthRollupWatcher) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	event, err := func() (arbbridge.Event, error) {
-//		if log.Topics[0] == rollupStakeCreatedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupStakeCreated(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.StakeCreatedEvent{
-//				Staker:   eventVal.Staker,
-//				NodeHash: eventVal.NodeHash,
-//			}, nil
-//		} else if log.Topics[0] == rollupChallengeStartedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupChallengeStarted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ChallengeStartedEvent{
-//				Asserter:          eventVal.Asserter,
-//				Challenger:        eventVal.Challenger,
-//				ChallengeType:     structures.ChildType(eventVal.ChallengeType.Uint64()),
-//				ChallengeContract: eventVal.ChallengeContract,
-//			}, nil
-//		} else if log.Topics[0] == rollupChallengeCompletedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupChallengeCompleted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ChallengeCompletedEvent{
-//				Winner:            eventVal.Winner,
-//				Loser:             eventVal.Loser,
-//				ChallengeContract: eventVal.ChallengeContract,
-//			}, nil
-//		} else if log.Topics[0] == rollupRefundedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupStakeRefunded(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.StakeRefundedEvent{
-//				Staker: eventVal.Staker,
-//			}, nil
-//		} else if log.Topics[0] == rollupPrunedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupPruned(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.PrunedEvent{
-//				Leaf: eventVal.Leaf,
-//			}, nil
-//		} else if log.Topics[0] == rollupStakeMovedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupStakeMoved(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.StakeMovedEvent{
-//				Staker:   eventVal.Staker,
-//				Location: eventVal.ToNodeHash,
-//			}, nil
-//		} else if log.Topics[0] == rollupAssertedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupAsserted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.AssertedEvent{
-//				PrevLeafHash: eventVal.PrevLeaf,
-//				Params: &structures.AssertionParams{
-//					NumSteps: eventVal.NumSteps,
-//					TimeBounds: protocol.NewTimeBoundsBlocks(
-//						protocol.NewTimeBlocks(eventVal.TimeBoundsBlocks[0]),
-//						protocol.NewTimeBlocks(eventVal.TimeBoundsBlocks[1]),
-//					),
-//					ImportedMessageCount: eventVal.ImportedMessageCount,
-//				},
-//				Claim: &structures.AssertionClaim{
-//					AfterInboxTop:       eventVal.AfterInboxTop,
-//					ImportedMessagesSlice: eventVal.ImportedMessagesSlice,
-//					AssertionStub: protocol.NewExecutionAssertionStub(
-//						eventVal.AfterVMHash,
-//						eventVal.DidInboxInsn,
-//						eventVal.NumArbGas,
-//						eventVal.MessagesAccHash,
-//						eventVal.LogsAccHash,
-//					),
-//				},
-//				MaxInboxTop: eventVal.InboxValue,
-//			}, nil
-//		} else if log.Topics[0] == rollupConfirmedID {
-//			eventVal, err := vm.ArbRollup.ParseRollupConfirmed(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ConfirmedEvent{
-//				NodeHash: eventVal.NodeHash,
-//			}, nil
-//		} else if log.Topics[0] == confirmedAssertionID {
-//			eventVal, err := vm.ArbRollup.ParseConfirmedAssertion(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ConfirmedAssertionEvent{
-//				LogsAccHash: eventVal.LogsAccHash,
-//			}, nil
-//		} else if log.Topics[0] == debugEventID {
-//			//eventVal, err := vm.ArbRollup.ParseDebugData(log)
-//			//if err != nil {
-//			//	return nil, err
-//			//}
-//			//fmt.Println("Debug event")
-//			//fmt.Println("BeforeVMHash", hexutil.Encode(eventVal.BeforeVMHash[:]))
-//			//fmt.Println("TimeBounds", eventVal.TimeBoundsBlocks)
-//			//fmt.Println("Inbox hash", hexutil.Encode(eventVal.Inbox[:]))
-//			//fmt.Println("PreconditionHash", hexutil.Encode(eventVal.Precondition[:]))
-//
-//			//fmt.Println("PrevLeaf", hexutil.Encode(eventVal.PrevLeaf[:]))
-//			//fmt.Println("DeadlineTicks", eventVal.DeadlineTicks)
-//			//fmt.Println("BeforeInboxTop", hexutil.Encode(eventVal.BeforeInboxTop[:]))
-//			//fmt.Println("AfterInboxTop", hexutil.Encode(eventVal.AfterInboxTop[:]))
-//			//fmt.Println("ImportedMessagesSlice", hexutil.Encode(eventVal.ImportedMessagesSlice[:]))
-//			//fmt.Println("ImportedMessageCount", eventVal.ImportedMessageCount)
-//			//fmt.Println("ChallengePeriod", eventVal.ChallengePeriod)
-//			//fmt.Println("ChildType", eventVal.ChildType)
-//			//fmt.Println("VmProtoHashBefore", hexutil.Encode(eventVal.VmProtoHashBefore[:]))
-//			//fmt.Println("ChallengeHash", hexutil.Encode(eventVal.ChallengeHash[:]))
-//			//fmt.Println("NodeDataHash", hexutil.Encode(eventVal.NodeDataHash[:]))
-//			return nil, nil
-//		}
-//		return nil, errors2.New("unknown arbitrum event type")
-//	}()
-//
-//	if err != nil {
-//		return err
-//	}
-//	if event != nil {
-//		header, err := vm.Client.HeaderByHash(ctx, log.BlockHash)
-//		if err != nil {
-//			return err
-//		}
-//		outChan <- arbbridge.Notification{
-//			Header: header,
-//			VMID:   vm.address,
-//			Event:  event,
-//			TxHash: log.TxHash,
-//		}
-//	}
-//	return nil
-//}
+func (vm *arbRollupWatcher) GetEvents(
+	ctx context.Context,
+	blockId *common.BlockId,
+	timestamp *big.Int,
+) ([]arbbridge.Event, error) {
+	r, err := vm.getRollup()
+	if err != nil {
+		return nil, err
+	}
+	inboxLogs, err := vm.eth.getLogs(blockId, func(l mockLog) bool {
+		return l.address == r.globalInbox && l.chain == vm.address
+	})
+	if err != nil {
+		return nil, err
+	}
+	rollupLogs, err := vm.eth.getLogs(blockId, func(l mockLog) bool {
+		return l.address == vm.address
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]arbbridge.Event, 0, len(inboxLogs)+len(rollupLogs))
+	for _, l := range inboxLogs {
+		events = append(events, l.event)
+	}
+	for _, l := range rollupLogs {
+		events = append(events, l.event)
+	}
+	return events, nil
+}
 
-func (vm *EthRollupWatcher) GetParams(ctx context.Context) (valprotocol.ChainParams, error) {
-	return valprotocol.ChainParams{
-		StakeRequirement:        nil,
-		GracePeriod:             common.TimeTicks{},
-		MaxExecutionSteps:       0,
-		MaxBlockBoundsWidth:     0,
-		MaxTimestampBoundsWidth: 0,
-		ArbGasSpeedLimitPerTick: 0,
-	}, nil
+func (vm *arbRollupWatcher) GetParams(ctx context.Context) (valprotocol.ChainParams, error) {
+	r, err := vm.getRollup()
+	if err != nil {
+		return valprotocol.ChainParams{}, err
+	}
+	return r.params, nil
 }
 
-func (vm *EthRollupWatcher) InboxAddress(ctx context.Context) (common.Address, error) {
-	return common.Address{}, nil
+func (vm *arbRollupWatcher) InboxAddress(ctx context.Context) (common.Address, error) {
+	r, err := vm.getRollup()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return r.globalInbox, nil
 }
 
-func (vm *EthRollupWatcher) GetCreationInfo(ctx context.Context) (*common.BlockId, common.Hash, error) {
-	return nil, common.Hash{}, nil
+func (vm *arbRollupWatcher) GetCreationInfo(ctx context.Context) (*common.BlockId, common.Hash, error) {
+	r, err := vm.getRollup()
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return r.creation.Clone(), r.vmState, nil
 }
 
-func (vm *EthRollupWatcher) GetVersion(ctx context.Context) (string, error) {
-	return "1", nil
+func (vm *arbRollupWatcher) GetVersion(ctx context.Context) (string, error) {
+	if _, err := vm.getRollup(); err != nil {
+		return "", err
+	}
+	return rollupVersion, nil
 }
@@ -18,136 +18,63 @@ package mockbridge
 
 import (
 	"context"
+	"errors"
+	"math/big"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 )
 
-type BisectionChallenge struct {
-	*Challenge
+type bisectionChallenge struct {
+	*challenge
 }
 
-func NewBisectionChallenge(address common.Address, client arbbridge.ArbClient) (*BisectionChallenge, error) {
-	challenge, err := NewChallenge(address, client)
+func newBisectionChallenge(address common.Address, eth *MockEth, from common.Address) (*bisectionChallenge, error) {
+	challenge, err := newChallenge(address, eth, from)
 	if err != nil {
 		return nil, err
 	}
-	vm := &BisectionChallenge{
-		Challenge: challenge,
-	}
-	//err = vm.setupContracts()
-	return vm, nil
+	return &bisectionChallenge{challenge: challenge}, nil
 }
 
-//func (c *bisectionChallenge) setupContracts() error {
-//	challengeManagerContract, err := executionchallenge.NewBisectionChallenge(c.address, c.Client)
-//	if err != nil {
-//		return errors2.Wrap(err, "Failed to connect to ChallengeManager")
-//	}
-//
-//	c.bisectionChallenge = challengeManagerContract
-//	return nil
-//}
-
-func (c *BisectionChallenge) StartConnection(ctx context.Context, startHeight *common.TimeBlocks, startLogIndex uint) (<-chan arbbridge.MaybeEvent, error) {
-	//if _, _, err := c.Challenge.StartConnection(ctx, startHeight, startLogIndex, eventChan); err != nil {
-	//	return nil, nil, err
-	//}
-	//if err := c.setupContracts(); err != nil {
-	//	return err
-	//}
-	//
-	//header, err := c.Client.HeaderByNumber(ctx, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//filter := ethereum.FilterQuery{
-	//	Addresses: []common.Address{c.address},
-	//	Topics: [][]common.Hash{{
-	//		continuedChallengeID,
-	//	}},
-	//}
-	//
-	//logs, err := c.Client.FilterLogs(ctx, filter)
-	//if err != nil {
-	//	return err
-	//}
-	//for _, log := range logs {
-	//	if err := c.processEvents(ctx, log, outChan); err != nil {
-	//		return err
-	//	}
-	//}
-	//
-	//filter.FromBlock = header.Number
-	//logChan := make(chan types.Log)
-	//logSub, err := c.Client.SubscribeFilterLogs(ctx, filter, logChan)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//go func() {
-	//	defer logSub.Unsubscribe()
-	//
-	//	for {
-	//		select {
-	//		case <-ctx.Done():
-	//			break
-	//		case log := <-logChan:
-	//			if err := c.processEvents(ctx, log, outChan); err != nil {
-	//				errChan <- err
-	//				return
-	//			}
-	//		case err := <-logSub.Err():
-	//			errChan <- err
-	//			return
-	//		}
-	//	}
-	//}()
-	return nil, nil
-}
-
-//func (c *bisectionChallenge) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	header, err := c.Client.HeaderByHash(ctx, log.BlockHash)
-//	if err != nil {
-//		return err
-//	}
-//
-//	if log.Topics[0] == continuedChallengeID {
-//		contChal, err := c.bisectionChallenge.ParseContinued(log)
-//		if err != nil {
-//			return err
-//		}
-//		outChan <- arbbridge.Notification{
-//			Header: header,
-//			VMID:   c.address,
-//			Event: arbbridge.ContinueChallengeEvent{
-//				SegmentIndex: contChal.SegmentIndex,
-//				Deadline:     structures.TimeTicks{Val: contChal.DeadlineTicks},
-//			},
-//			TxHash: log.TxHash,
-//		}
-//	}
-//	return nil
-//}
-
-func (c *BisectionChallenge) ChooseSegment(
+// chooseSegment selects one of the segments from the asserter's last
+// bisection. The contract checks a merkle proof against the root of the
+// bisection which is equivalent to comparing the full list of segments
+func (c *bisectionChallenge) chooseSegment(
 	ctx context.Context,
 	segmentToChallenge uint16,
 	segments []common.Hash,
 ) error {
-	//tree := NewMerkleTree(segments)
-	//c.auth.Context = ctx
-	//tx, err := c.bisectionChallenge.ChooseSegment(
-	//	c.auth,
-	//	big.NewInt(int64(segmentToChallenge)),
-	//	tree.GetProofFlat(int(segmentToChallenge)),
-	//	tree.GetRoot(),
-	//	tree.GetNode(int(segmentToChallenge)),
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "ChooseSegment")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.challengerAction(tx); err != nil {
+			return err
+		}
+		if !hashSlicesEqual(segments, data.segments) {
+			return errors.New("CON_PREV")
+		}
+		if int(segmentToChallenge) >= len(segments) {
+			return errors.New("CON_PROOF")
+		}
+		data.bisectionState = segments[segmentToChallenge]
+		data.segments = nil
+		data.challengerResponded(tx)
+		tx.emit(c.address, arbbridge.ContinueChallengeEvent{
+			ChainInfo:    tx.chainInfo(),
+			SegmentIndex: big.NewInt(int64(segmentToChallenge)),
+			Deadline:     common.TimeTicks{Val: new(big.Int).Set(data.deadlineTicks)},
+		})
+		return nil
+	})
+}
+
+func hashSlicesEqual(a []common.Hash, b []common.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
@@ -18,161 +18,172 @@ package mockbridge
 
 import (
 	"context"
+	"errors"
+	"math/big"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type Challenge struct {
-	*ClientConnection
+type challengeState uint8
 
+const (
+	noChallenge challengeState = iota
+	asserterTurn
+	challengerTurn
+)
+
+type challengeData struct {
+	vmAddress            common.Address
+	asserter             common.Address
+	challenger           common.Address
+	challengeType        valprotocol.ChildType
+	challengePeriodTicks *big.Int
+	deadlineTicks        *big.Int
+	state                challengeState
+
+	// bisectionState is the hash of the claim currently being disputed
+	bisectionState common.Hash
+	// segments holds the hashes that the asserter committed to in its last
+	// bisection and is nil while it is the asserter's turn
+	segments []common.Hash
+}
+
+func (c *challengeData) asserterAction(tx *mockTx) error {
+	if c.state != asserterTurn {
+		return errors.New("BIS_STATE")
+	}
+	if tx.blockTicks().Cmp(c.deadlineTicks) > 0 {
+		return errors.New("BIS_DEADLINE")
+	}
+	if tx.from != c.asserter {
+		return errors.New("BIS_SENDER")
+	}
+	return nil
+}
+
+func (c *challengeData) challengerAction(tx *mockTx) error {
+	if c.state != challengerTurn {
+		return errors.New("CON_STATE")
+	}
+	if tx.blockTicks().Cmp(c.deadlineTicks) > 0 {
+		return errors.New("CON_DEADLINE")
+	}
+	if tx.from != c.challenger {
+		return errors.New("CON_SENDER")
+	}
+	return nil
+}
+
+func (c *challengeData) updateDeadline(tx *mockTx) {
+	c.deadlineTicks = new(big.Int).Add(tx.blockTicks(), c.challengePeriodTicks)
+}
+
+func (c *challengeData) asserterResponded(tx *mockTx) {
+	c.state = challengerTurn
+	c.updateDeadline(tx)
+}
+
+func (c *challengeData) challengerResponded(tx *mockTx) {
+	c.state = asserterTurn
+	c.updateDeadline(tx)
+}
+
+func (c *challengeData) requireMatchesPrevState(challengeHash common.Hash) error {
+	if challengeHash != c.bisectionState {
+		return errors.New("BIS_PREV")
+	}
+	return nil
+}
+
+func (c *challengeData) commitToSegment(hashes []common.Hash) {
+	c.segments = hashes
+}
+
+// resolve reports the result of the challenge to the rollup that created it
+// and then destroys the challenge
+func (c *challengeData) resolve(tx *mockTx, address common.Address, winner common.Address, loser common.Address) error {
+	if r, ok := tx.eth.rollups[c.vmAddress]; ok {
+		if err := r.resolveChallenge(tx, c.vmAddress, address, winner, loser); err != nil {
+			return err
+		}
+	}
+	delete(tx.eth.challenges, address)
+	return nil
+}
+
+func (c *challengeData) asserterWin(tx *mockTx, address common.Address) error {
+	return c.resolve(tx, address, c.asserter, c.challenger)
+}
+
+func (c *challengeData) challengerWin(tx *mockTx, address common.Address) error {
+	return c.resolve(tx, address, c.challenger, c.asserter)
+}
+
+type challenge struct {
 	address common.Address
-	client  arbbridge.ArbClient
-}
-
-func NewChallenge(address common.Address, client arbbridge.ArbClient) (*Challenge, error) {
-	vm := &Challenge{ClientConnection: &ClientConnection{client}, address: address}
-	//err := vm.setupContracts()
-	return vm, nil
-}
-
-//func (c *challenge) setupContracts() error {
-//	challengeManagerContract, err := executionchallenge.NewChallenge(c.address, c.Client)
-//	if err != nil {
-//		return errors2.Wrap(err, "Failed to connect to ChallengeManager")
-//	}
-//
-//	c.challenge = challengeManagerContract
-//	return nil
-//}
-
-func (c *Challenge) StartConnection(ctx context.Context, startHeight *common.TimeBlocks, startLogIndex uint) (<-chan arbbridge.MaybeEvent, error) {
-	//if err := c.setupContracts(); err != nil {
-	//	return err
-	//}
-	//headers := make(chan *types.Header)
-	//headersSub, err := c.Client.SubscribeNewHead(ctx, headers)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//header, err := c.Client.HeaderByNumber(ctx, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//filter := ethereum.FilterQuery{
-	//	Addresses: []common.Address{c.address},
-	//	Topics: [][]common.Hash{{
-	//		initiatedChallengeID,
-	//		timedOutAsserterID,
-	//		timedOutChallengerID,
-	//	}},
-	//}
-	//
-	//logs, err := c.Client.FilterLogs(ctx, filter)
-	//if err != nil {
-	//	return err
-	//}
-	//for _, log := range logs {
-	//	if err := c.processEvents(ctx, log, outChan); err != nil {
-	//		return err
-	//	}
-	//}
-	//
-	//filter.FromBlock = header.Number
-	//logChan := make(chan types.Log)
-	//logSub, err := c.Client.SubscribeFilterLogs(ctx, filter, logChan)
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//go func() {
-	//	defer headersSub.Unsubscribe()
-	//	defer logSub.Unsubscribe()
-	//
-	//	for {
-	//		select {
-	//		case <-ctx.Done():
-	//			break
-	//		case header := <-headers:
-	//			outChan <- arbbridge.Notification{
-	//				Header: header,
-	//				Event:  arbbridge.NewTimeEvent{},
-	//			}
-	//		case log := <-logChan:
-	//			if err := c.processEvents(ctx, log, outChan); err != nil {
-	//				errChan <- err
-	//				return
-	//			}
-	//		case err := <-headersSub.Err():
-	//			errChan <- err
-	//			return
-	//		case err := <-logSub.Err():
-	//			errChan <- err
-	//			return
-	//		}
-	//	}
-	//}()
-	return nil, nil
-}
-
-//func (c *challenge) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	event, err := func() (arbbridge.Event, error) {
-//		if log.Topics[0] == initiatedChallengeID {
-//			eventVal, err := c.challenge.ParseInitiatedChallenge(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.InitiateChallengeEvent{
-//				Deadline: structures.TimeTicks{Val: eventVal.DeadlineTicks},
-//			}, nil
-//		} else if log.Topics[0] == timedOutAsserterID {
-//			_, err := c.challenge.ParseAsserterTimedOut(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.AsserterTimeoutEvent{}, nil
-//		} else if log.Topics[0] == timedOutChallengerID {
-//			_, err := c.challenge.ParseChallengerTimedOut(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ChallengerTimeoutEvent{}, nil
-//		}
-//		return nil, errors2.New("unknown arbitrum event type")
-//	}()
-//	if err != nil {
-//		return err
-//	}
-//
-//	header, err := c.Client.HeaderByHash(ctx, log.BlockHash)
-//	if err != nil {
-//		return err
-//	}
-//
-//	outChan <- arbbridge.Notification{
-//		Header: header,
-//		VMID:   c.address,
-//		Event:  event,
-//		TxHash: log.TxHash,
-//	}
-//
-//	return nil
-//}
-
-func (c *Challenge) TimeoutChallenge(
+	eth     *MockEth
+	from    common.Address
+}
+
+func newChallenge(address common.Address, eth *MockEth, from common.Address) (*challenge, error) {
+	return &challenge{address: address, eth: eth, from: from}, nil
+}
+
+func (c *challenge) transact(f func(tx *mockTx, data *challengeData) error) error {
+	return c.eth.transact(c.from, nil, func(tx *mockTx) error {
+		data, ok := tx.eth.challenges[c.address]
+		if !ok {
+			return errNoContract
+		}
+		return f(tx, data)
+	})
+}
+
+func (c *challenge) TimeoutChallenge(
 	ctx context.Context,
 ) error {
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.TimeoutChallenge(c.auth)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "TimeoutChallenge")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if tx.blockTicks().Cmp(data.deadlineTicks) <= 0 {
+			return errors.New("Deadline hasn't expired")
+		}
+		if data.state == asserterTurn {
+			tx.emit(c.address, arbbridge.AsserterTimeoutEvent{
+				ChainInfo: tx.chainInfo(),
+			})
+			return data.challengerWin(tx, c.address)
+		}
+		tx.emit(c.address, arbbridge.ChallengerTimeoutEvent{
+			ChainInfo: tx.chainInfo(),
+		})
+		return data.asserterWin(tx, c.address)
+	})
+}
+
+type challengeWatcher struct {
+	address common.Address
+	eth     *MockEth
+}
+
+func newChallengeWatcher(address common.Address, eth *MockEth) (*challengeWatcher, error) {
+	return &challengeWatcher{address: address, eth: eth}, nil
 }
 
-//func (c *challenge) waitForReceipt(ctx context.Context, tx *types.Transaction, methodName string) error {
-//	return c.ClientConnection.waitForReceipt(ctx, c.auth.From, tx, methodName)
-//}
+func (c *challengeWatcher) GetEvents(
+	ctx context.Context,
+	blockId *common.BlockId,
+	timestamp *big.Int,
+) ([]arbbridge.Event, error) {
+	logs, err := c.eth.getLogs(blockId, func(l mockLog) bool {
+		return l.address == c.address
+	})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]arbbridge.Event, 0, len(logs))
+	for _, l := range logs {
+		events = append(events, l.event)
+	}
+	return events, nil
+}
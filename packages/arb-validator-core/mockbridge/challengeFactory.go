@@ -0,0 +1,103 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockbridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+// createChallenge creates a new challenge contract on behalf of vmAddress
+// which will be notified when the challenge is resolved
+func createChallenge(
+	tx *mockTx,
+	factoryAddress common.Address,
+	vmAddress common.Address,
+	asserter common.Address,
+	challenger common.Address,
+	challengePeriod common.TimeTicks,
+	challengeHash common.Hash,
+	challengeType valprotocol.ChildType,
+) (common.Address, error) {
+	if !tx.eth.challengeFactories[factoryAddress] {
+		return common.Address{}, errNoContract
+	}
+	if challengeType > valprotocol.MaxInvalidChildType {
+		return common.Address{}, errors.New("Invalid challenge type")
+	}
+	address := tx.createAddress()
+	data := &challengeData{
+		vmAddress:            vmAddress,
+		asserter:             asserter,
+		challenger:           challenger,
+		challengeType:        challengeType,
+		challengePeriodTicks: new(big.Int).Set(challengePeriod.Val),
+		state:                asserterTurn,
+		bisectionState:       challengeHash,
+	}
+	data.updateDeadline(tx)
+	tx.eth.challenges[address] = data
+	tx.emit(address, arbbridge.InitiateChallengeEvent{
+		ChainInfo: tx.chainInfo(),
+		Deadline:  common.TimeTicks{Val: new(big.Int).Set(data.deadlineTicks)},
+	})
+	return address, nil
+}
+
+type challengeFactory struct {
+	address common.Address
+	eth     *MockEth
+	from    common.Address
+}
+
+func newChallengeFactory(address common.Address, eth *MockEth, from common.Address) (*challengeFactory, error) {
+	return &challengeFactory{address: address, eth: eth, from: from}, nil
+}
+
+func (con *challengeFactory) CreateChallenge(
+	ctx context.Context,
+	asserter common.Address,
+	challenger common.Address,
+	challengePeriod common.TimeTicks,
+	challengeHash common.Hash,
+	challengeType *big.Int,
+) (common.Address, error) {
+	var challengeAddress common.Address
+	err := con.eth.transact(con.from, nil, func(tx *mockTx) error {
+		if !challengeType.IsUint64() {
+			return errors.New("Invalid challenge type")
+		}
+		var err error
+		challengeAddress, err = createChallenge(
+			tx,
+			con.address,
+			tx.from,
+			asserter,
+			challenger,
+			challengePeriod,
+			challengeHash,
+			valprotocol.ChildType(challengeType.Uint64()),
+		)
+		return err
+	})
+	return challengeAddress, err
+}
@@ -18,168 +18,158 @@ package mockbridge
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type ExecutionChallenge struct {
-	*BisectionChallenge
+type executionChallenge struct {
+	*bisectionChallenge
 }
 
-func NewExecutionChallenge(address common.Address, client arbbridge.ArbClient) (*ExecutionChallenge, error) {
-	bisectionChallenge, err := NewBisectionChallenge(address, client)
+func newExecutionChallenge(address common.Address, eth *MockEth, from common.Address) (*executionChallenge, error) {
+	bisectionChallenge, err := newBisectionChallenge(address, eth, from)
 	if err != nil {
 		return nil, err
 	}
-	vm := &ExecutionChallenge{BisectionChallenge: bisectionChallenge}
-	//err = vm.setupContracts()
-	return vm, err
+	return &executionChallenge{bisectionChallenge: bisectionChallenge}, nil
 }
 
-func (c *ExecutionChallenge) setupContracts() error {
-	//	challengeManagerContract, err := executionchallenge.NewExecutionChallenge(c.address, c.Client)
-	//	if err != nil {
-	//		return errors2.Wrap(err, "Failed to connect to ChallengeManager")
-	//	}
-	//
-	//	c.challenge = challengeManagerContract
-	return nil
+// flattenAssertions rebuilds the assertions the same way that the contract
+// does from the arrays that it is passed, so that every assertion starts with
+// the message and log accumulators that the previous one ended with
+func flattenAssertions(assertions []*valprotocol.ExecutionAssertionStub) []*valprotocol.ExecutionAssertionStub {
+	flattened := make([]*valprotocol.ExecutionAssertionStub, 0, len(assertions))
+	firstMessageHash := assertions[0].FirstMessageHash
+	firstLogHash := assertions[0].FirstLogHash
+	for _, assertion := range assertions {
+		flattened = append(flattened, &valprotocol.ExecutionAssertionStub{
+			AfterHash:        assertion.AfterHash,
+			DidInboxInsn:     assertion.DidInboxInsn,
+			NumGas:           assertion.NumGas,
+			FirstMessageHash: firstMessageHash,
+			LastMessageHash:  assertion.LastMessageHash,
+			FirstLogHash:     firstLogHash,
+			LastLogHash:      assertion.LastLogHash,
+		})
+		firstMessageHash = assertion.LastMessageHash
+		firstLogHash = assertion.LastLogHash
+	}
+	return flattened
 }
 
-func (vm *ExecutionChallenge) GetEvents(ctx context.Context, blockId *common.BlockId, timestamp *big.Int) ([]arbbridge.Event, error) {
-	return nil, nil
+func combineAssertions(assertions []*valprotocol.ExecutionAssertionStub) *valprotocol.ExecutionAssertionStub {
+	combined := &valprotocol.ExecutionAssertionStub{
+		AfterHash:        assertions[len(assertions)-1].AfterHash,
+		FirstMessageHash: assertions[0].FirstMessageHash,
+		LastMessageHash:  assertions[len(assertions)-1].LastMessageHash,
+		FirstLogHash:     assertions[0].FirstLogHash,
+		LastLogHash:      assertions[len(assertions)-1].LastLogHash,
+	}
+	for _, assertion := range assertions {
+		combined.DidInboxInsn = combined.DidInboxInsn || assertion.DidInboxInsn
+		combined.NumGas += assertion.NumGas
+	}
+	return combined
 }
 
-//func (c *ExecutionChallenge) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	event, err := func() (arbbridge.Event, error) {
-//		if log.Topics[0] == bisectedAssertionID {
-//			bisectChal, err := c.challenge.ParseBisectedAssertion(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.ExecutionBisectionEvent{
-//				Assertions: translateBisectionEvent(bisectChal),
-//				TotalSteps: bisectChal.TotalSteps,
-//				Deadline:   structures.TimeTicks{Val: bisectChal.DeadlineTicks},
-//			}, nil
-//		} else if log.Topics[0] == oneStepProofCompletedID {
-//			_, err := c.challenge.ParseOneStepProofCompleted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.OneStepProofEvent{}, nil
-//		}
-//		return nil, errors2.New("unknown arbitrum event type")
-//	}()
-//
-//	if err != nil {
-//		return err
-//	}
-//
-//	header, err := c.Client.HeaderByHash(ctx, log.BlockHash)
-//	if err != nil {
-//		return err
-//	}
-//	outChan <- arbbridge.Notification{
-//		Header: header,
-//		VMID:   c.address,
-//		Event:  event,
-//		TxHash: log.TxHash,
-//	}
-//	return nil
-//}
-
-func (c *ExecutionChallenge) BisectAssertion(
+func (c *executionChallenge) BisectAssertion(
 	ctx context.Context,
 	precondition *valprotocol.Precondition,
 	assertions []*valprotocol.ExecutionAssertionStub,
 	totalSteps uint64,
 ) error {
-	//machineHashes := make([][32]byte, 0, len(assertions)+1)
-	//didInboxInsns := make([]bool, 0, len(assertions))
-	//messageAccs := make([][32]byte, 0, len(assertions)+1)
-	//logAccs := make([][32]byte, 0, len(assertions)+1)
-	//gasses := make([]uint64, 0, len(assertions))
-	//machineHashes = append(machineHashes, precondition.BeforeHash)
-	//messageAccs = append(messageAccs, assertions[0].FirstMessageHashValue())
-	//logAccs = append(logAccs, assertions[0].FirstLogHashValue())
-	//for _, assertion := range assertions {
-	//	machineHashes = append(machineHashes, assertion.AfterHashValue())
-	//	didInboxInsns = append(didInboxInsns, assertion.DidInboxInsn)
-	//	messageAccs = append(messageAccs, assertion.LastMessageHashValue())
-	//	logAccs = append(logAccs, assertion.LastLogHashValue())
-	//	gasses = append(gasses, assertion.NumGas)
-	//}
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.BisectAssertion(
-	//	c.auth,
-	//	precondition.BeforeHash,
-	//	precondition.TimeBounds.AsIntArray(),
-	//	machineHashes,
-	//	didInboxInsns,
-	//	messageAccs,
-	//	logAccs,
-	//	gasses,
-	//	totalSteps,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "BisectAssertion")
-	return nil
+	if len(assertions) == 0 {
+		return errors.New("Can't bisect into zero segments")
+	}
+	assertions = flattenAssertions(assertions)
+	// The contract is only passed the hash of the inbox
+	precondition = valprotocol.NewPrecondition(
+		precondition.BeforeHash,
+		precondition.TimeBounds,
+		value.NewHashOnlyValue(precondition.BeforeInbox.Hash(), 1),
+	)
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if err := data.requireMatchesPrevState(valprotocol.ExecutionDataHash(
+			totalSteps,
+			precondition.Hash(),
+			combineAssertions(assertions).Hash(),
+		)); err != nil {
+			return err
+		}
+
+		preconditions := valprotocol.GeneratePreconditions(precondition, assertions)
+		hashes := make([]common.Hash, 0, len(assertions))
+		for i := range assertions {
+			stepCount := valprotocol.CalculateBisectionStepCount(uint64(i), uint64(len(assertions)), totalSteps)
+			hashes = append(
+				hashes,
+				valprotocol.ExecutionDataHash(stepCount, preconditions[i].Hash(), assertions[i].Hash()),
+			)
+		}
+		data.commitToSegment(hashes)
+		data.asserterResponded(tx)
+		tx.emit(c.address, arbbridge.ExecutionBisectionEvent{
+			ChainInfo:  tx.chainInfo(),
+			Assertions: assertions,
+			TotalSteps: totalSteps,
+			Deadline:   common.TimeTicks{Val: new(big.Int).Set(data.deadlineTicks)},
+		})
+		return nil
+	})
 }
 
-func (c *ExecutionChallenge) OneStepProof(
+// OneStepProof accepts any proof for a single step of execution since the
+// mock has no way of validating one
+func (c *executionChallenge) OneStepProof(
 	ctx context.Context,
 	precondition *valprotocol.Precondition,
 	assertion *valprotocol.ExecutionAssertionStub,
 	proof []byte,
 ) error {
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.OneStepProof(
-	//	c.auth,
-	//	precondition.BeforeHash,
-	//	precondition.BeforeInbox.Hash(),
-	//	precondition.TimeBounds.AsIntArray(),
-	//	assertion.AfterHashValue(),
-	//	assertion.DidInboxInsn,
-	//	assertion.FirstMessageHashValue(),
-	//	assertion.LastMessageHashValue(),
-	//	assertion.FirstLogHashValue(),
-	//	assertion.LastLogHashValue(),
-	//	assertion.NumGas,
-	//	proof,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "OneStepProof")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if err := data.requireMatchesPrevState(valprotocol.ExecutionDataHash(
+			1,
+			precondition.Hash(),
+			assertion.Hash(),
+		)); err != nil {
+			return err
+		}
+		tx.emit(c.address, arbbridge.OneStepProofEvent{
+			ChainInfo: tx.chainInfo(),
+		})
+		return data.asserterWin(tx, c.address)
+	})
 }
 
-func (c *ExecutionChallenge) ChooseSegment(
+func (c *executionChallenge) ChooseSegment(
 	ctx context.Context,
 	assertionToChallenge uint16,
 	preconditions []*valprotocol.Precondition,
 	assertions []*valprotocol.ExecutionAssertionStub,
 	totalSteps uint64,
 ) error {
-	//bisectionHashes := make([][32]byte, 0, len(assertions))
-	//for i := range assertions {
-	//	bisectionHash := [32]byte{}
-	//	copy(bisectionHash[:], solsha3.SoliditySHA3(
-	//		solsha3.Bytes32(preconditions[i].Hash()),
-	//		solsha3.Bytes32(assertions[i].Hash()),
-	//	))
-	//	bisectionHashes = append(bisectionHashes, bisectionHash)
-	//}
-	//return c.bisectionChallenge.ChooseSegment(
-	//	ctx,
-	//	assertionToChallenge,
-	//	bisectionHashes,
-	//)
-	return nil
+	bisectionHashes := make([]common.Hash, 0, len(assertions))
+	for i := range assertions {
+		stepCount := valprotocol.CalculateBisectionStepCount(uint64(i), uint64(len(assertions)), totalSteps)
+		bisectionHashes = append(
+			bisectionHashes,
+			valprotocol.ExecutionDataHash(stepCount, preconditions[i].Hash(), assertions[i].Hash()),
+		)
+	}
+	return c.bisectionChallenge.chooseSegment(
+		ctx,
+		assertionToChallenge,
+		bisectionHashes,
+	)
 }
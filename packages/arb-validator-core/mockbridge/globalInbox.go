@@ -1,5 +1,5 @@
 /*
- * Copyright 2019-2020, Offchain Labs, Inc.
+ * Copyright 2020, Offchain Labs, Inc.
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
@@ -20,26 +20,86 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
-
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
 )
 
-type GlobalInbox struct {
-	//GlobalInbox *globalinbox.GlobalInbox
-	client arbbridge.ArbClient
+type inbox struct {
+	value common.Hash
+	count *big.Int
+}
+
+// globalInboxData holds the inboxes of every chain along with the funds
+// deposited into them. Token deposits are credited without any token
+// contract being involved and outgoing messages are not paid out
+type globalInboxData struct {
+	inboxes      map[common.Address]*inbox
+	ethWallets   map[common.Address]*big.Int
+	erc20Wallets map[common.Address]map[common.Address]*big.Int
+}
+
+func newGlobalInboxData() *globalInboxData {
+	return &globalInboxData{
+		inboxes:      make(map[common.Address]*inbox),
+		ethWallets:   make(map[common.Address]*big.Int),
+		erc20Wallets: make(map[common.Address]map[common.Address]*big.Int),
+	}
+}
+
+func (g *globalInboxData) getInbox(chain common.Address) *inbox {
+	in, ok := g.inboxes[chain]
+	if !ok {
+		in = &inbox{count: big.NewInt(0)}
+		g.inboxes[chain] = in
+	}
+	return in
+}
+
+func (g *globalInboxData) nextMessageNum(chain common.Address) *big.Int {
+	return new(big.Int).Add(g.getInbox(chain).count, big.NewInt(1))
+}
+
+func (g *globalInboxData) deliverMessage(
+	tx *mockTx,
+	inboxAddress common.Address,
+	chain common.Address,
+	msg message.InboxMessage,
+) {
+	in := g.getInbox(chain)
+	in.value = hashing.SoliditySHA3(
+		hashing.Bytes32(in.value),
+		hashing.Bytes32(msg.CommitmentHash()),
+	)
+	in.count = new(big.Int).Add(in.count, big.NewInt(1))
+	tx.emitMessage(inboxAddress, chain, arbbridge.MessageDeliveredEvent{
+		ChainInfo: tx.chainInfo(),
+		Message:   msg,
+	})
+}
+
+type globalInbox struct {
+	address common.Address
+	eth     *MockEth
+	from    common.Address
+}
+
+func newGlobalInbox(address common.Address, eth *MockEth, from common.Address) (*globalInbox, error) {
+	return &globalInbox{address: address, eth: eth, from: from}, nil
 }
 
-func NewGlobalInbox(address common.Address, client arbbridge.ArbClient) (*GlobalInbox, error) {
-	//globalInboxContract, err := globalinbox.NewGlobalInbox(address, client)
-	//if err != nil {
-	//	return nil, errors2.Wrap(err, "Failed to connect to GlobalInbox")
-	//}
-	return &GlobalInbox{client}, nil
+func (con *globalInbox) transact(value *big.Int, f func(tx *mockTx, g *globalInboxData) error) error {
+	return con.eth.transact(con.from, value, func(tx *mockTx) error {
+		g, ok := tx.eth.inboxes[con.address]
+		if !ok {
+			return errNoContract
+		}
+		return f(tx, g)
+	})
 }
 
-func (con *GlobalInbox) SendTransactionMessage(
+func (con *globalInbox) SendTransactionMessage(
 	ctx context.Context,
 	data []byte,
 	vmAddress common.Address,
@@ -47,63 +107,152 @@ func (con *GlobalInbox) SendTransactionMessage(
 	amount *big.Int,
 	seqNumber *big.Int,
 ) error {
-	return nil
+	return con.transact(nil, func(tx *mockTx, g *globalInboxData) error {
+		g.deliverMessage(tx, con.address, vmAddress, message.DeliveredTransaction{
+			Transaction: message.Transaction{
+				Chain:       vmAddress,
+				To:          contactAddress,
+				From:        tx.from,
+				SequenceNum: seqNumber,
+				Value:       amount,
+				Data:        data,
+			},
+			BlockNum:  common.NewTimeBlocks(tx.blockNumber()),
+			Timestamp: tx.timestamp(),
+		})
+		return nil
+	})
 }
 
-func (con *GlobalInbox) DeliverTransactionBatch(
+func (con *globalInbox) DeliverTransactionBatch(
 	ctx context.Context,
 	chain common.Address,
 	transactions []message.BatchTx,
 ) error {
-	return nil
+	var data []byte
+	for _, batchTx := range transactions {
+		data = append(data, batchTx.ToBytes()...)
+	}
+	return con.transact(nil, func(tx *mockTx, g *globalInboxData) error {
+		g.deliverMessage(tx, con.address, chain, message.DeliveredTransactionBatch{
+			TransactionBatch: message.TransactionBatch{
+				Chain:  chain,
+				TxData: data,
+			},
+			BlockNum:  common.NewTimeBlocks(tx.blockNumber()),
+			Timestamp: tx.timestamp(),
+		})
+		return nil
+	})
 }
 
-func (con *GlobalInbox) DeliverTransactionBatchNoWait(
+func (con *globalInbox) DeliverTransactionBatchNoWait(
 	ctx context.Context,
 	chain common.Address,
 	transactions []message.BatchTx,
 ) error {
-	return nil
+	return con.DeliverTransactionBatch(ctx, chain, transactions)
 }
 
-func (con *GlobalInbox) DepositEthMessage(
+func (con *globalInbox) DepositEthMessage(
 	ctx context.Context,
 	vmAddress common.Address,
 	destination common.Address,
 	value *big.Int,
 ) error {
-	return nil
+	return con.transact(value, func(tx *mockTx, g *globalInboxData) error {
+		messageNum := g.nextMessageNum(vmAddress)
+		balance, ok := g.ethWallets[vmAddress]
+		if !ok {
+			balance = big.NewInt(0)
+		}
+		g.ethWallets[vmAddress] = new(big.Int).Add(balance, value)
+		g.deliverMessage(tx, con.address, vmAddress, message.DeliveredEth{
+			Eth: message.Eth{
+				To:    destination,
+				From:  tx.from,
+				Value: value,
+			},
+			BlockNum:   common.NewTimeBlocks(tx.blockNumber()),
+			Timestamp:  tx.timestamp(),
+			MessageNum: messageNum,
+		})
+		return nil
+	})
 }
 
-func (con *GlobalInbox) DepositERC20Message(
+func (con *globalInbox) DepositERC20Message(
 	ctx context.Context,
 	vmAddress common.Address,
 	tokenAddress common.Address,
 	destination common.Address,
 	value *big.Int,
 ) error {
-	return nil
+	return con.transact(nil, func(tx *mockTx, g *globalInboxData) error {
+		messageNum := g.nextMessageNum(vmAddress)
+		wallet, ok := g.erc20Wallets[vmAddress]
+		if !ok {
+			wallet = make(map[common.Address]*big.Int)
+			g.erc20Wallets[vmAddress] = wallet
+		}
+		balance, ok := wallet[tokenAddress]
+		if !ok {
+			balance = big.NewInt(0)
+		}
+		wallet[tokenAddress] = new(big.Int).Add(balance, value)
+		g.deliverMessage(tx, con.address, vmAddress, message.DeliveredERC20{
+			ERC20: message.ERC20{
+				To:           destination,
+				From:         tx.from,
+				TokenAddress: tokenAddress,
+				Value:        value,
+			},
+			BlockNum:   common.NewTimeBlocks(tx.blockNumber()),
+			Timestamp:  tx.timestamp(),
+			MessageNum: messageNum,
+		})
+		return nil
+	})
 }
 
-func (con *GlobalInbox) DepositERC721Message(
+func (con *globalInbox) DepositERC721Message(
 	ctx context.Context,
 	vmAddress common.Address,
 	tokenAddress common.Address,
 	destination common.Address,
 	value *big.Int,
 ) error {
-	return nil
+	return con.transact(nil, func(tx *mockTx, g *globalInboxData) error {
+		messageNum := g.nextMessageNum(vmAddress)
+		g.deliverMessage(tx, con.address, vmAddress, message.DeliveredERC721{
+			ERC721: message.ERC721{
+				To:           destination,
+				From:         tx.from,
+				TokenAddress: tokenAddress,
+				Id:           value,
+			},
+			BlockNum:   common.NewTimeBlocks(tx.blockNumber()),
+			Timestamp:  tx.timestamp(),
+			MessageNum: messageNum,
+		})
+		return nil
+	})
 }
 
-func (con *GlobalInbox) GetTokenBalance(
+func (con *globalInbox) GetTokenBalance(
 	ctx context.Context,
 	user common.Address,
 	tokenContract common.Address,
 ) (*big.Int, error) {
-	//return con.GlobalInbox.GetTokenBalance(
-	//	auth,
-	//	tokenContract,
-	//	user,
-	//)
-	return big.NewInt(0), nil
+	con.eth.Lock()
+	defer con.eth.Unlock()
+	g, ok := con.eth.inboxes[con.address]
+	if !ok {
+		return nil, errNoContract
+	}
+	balance, ok := g.erc20Wallets[user][tokenContract]
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).Set(balance), nil
 }
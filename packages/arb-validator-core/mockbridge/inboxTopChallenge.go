@@ -18,121 +18,110 @@ package mockbridge
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type InboxTopChallenge struct {
-	*BisectionChallenge
+type inboxTopChallenge struct {
+	*bisectionChallenge
 }
 
-func NewInboxTopChallenge(address common.Address, client arbbridge.ArbClient) (*InboxTopChallenge, error) {
-	bisectionChallenge, err := NewBisectionChallenge(address, client)
+func newInboxTopChallenge(address common.Address, eth *MockEth, from common.Address) (*inboxTopChallenge, error) {
+	bisectionChallenge, err := newBisectionChallenge(address, eth, from)
 	if err != nil {
 		return nil, err
 	}
-	vm := &InboxTopChallenge{BisectionChallenge: bisectionChallenge}
-	err = vm.setupContracts()
-	return vm, err
+	return &inboxTopChallenge{bisectionChallenge: bisectionChallenge}, nil
 }
 
-func (c *InboxTopChallenge) setupContracts() error {
-	//challengeManagerContract, err := inboxtopchallenge.NewInboxTopChallenge(c.address, c.Client)
-	//if err != nil {
-	//	return errors2.Wrap(err, "Failed to connect to MessagesChallenge")
-	//}
-	//
-	//c.challenge = challengeManagerContract
-	return nil
-}
-
-func (vm *InboxTopChallenge) GetEvents(ctx context.Context, blockId *common.BlockId, timestamp *big.Int) ([]arbbridge.Event, error) {
-	return nil, nil
+func inboxTopBisectionHashes(chainHashes []common.Hash, chainLength uint64) []common.Hash {
+	bisectionCount := uint64(len(chainHashes) - 1)
+	bisectionHashes := make([]common.Hash, 0, bisectionCount)
+	for i := uint64(0); i < bisectionCount; i++ {
+		stepCount := valprotocol.CalculateBisectionStepCount(i, bisectionCount, chainLength)
+		bisectionHashes = append(
+			bisectionHashes,
+			valprotocol.InboxTopChallengeDataHash(
+				chainHashes[i],
+				chainHashes[i+1],
+				new(big.Int).SetUint64(stepCount),
+			),
+		)
+	}
+	return bisectionHashes
 }
 
-//func (c *InboxTopChallenge) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	event, err := func() (arbbridge.Event, error) {
-//		if log.Topics[0] == inboxTopBisectedID {
-//			eventVal, err := c.challenge.ParseBisected(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.InboxTopBisectionEvent{
-//				ChainHashes: eventVal.ChainHashes,
-//				TotalLength: eventVal.TotalLength,
-//				Deadline:    structures.TimeTicks{Val: eventVal.DeadlineTicks},
-//			}, nil
-//		} else if log.Topics[0] == inboxTopOneStepProofCompletedID {
-//			_, err := c.challenge.ParseOneStepProofCompleted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.OneStepProofEvent{}, nil
-//		}
-//		return nil, errors2.New("unknown arbitrum event type")
-//	}()
-//
-//	if err != nil {
-//		return err
-//	}
-//
-//	header, err := c.Client.HeaderByHash(ctx, log.BlockHash)
-//	if err != nil {
-//		return err
-//	}
-//	outChan <- arbbridge.Notification{
-//		Header: header,
-//		VMID:   c.address,
-//		Event:  event,
-//		TxHash: log.TxHash,
-//	}
-//	return nil
-//}
-
-func (c *InboxTopChallenge) Bisect(
+func (c *inboxTopChallenge) Bisect(
 	ctx context.Context,
 	chainHashes []common.Hash,
 	chainLength *big.Int,
 ) error {
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.Bisect(
-	//	c.auth,
-	//	chainHashes,
-	//	chainLength,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "Bisect")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if chainLength.Cmp(big.NewInt(1)) <= 0 {
+			return errors.New("Can't bisect chain of less than 2")
+		}
+		if len(chainHashes) < 2 {
+			return errors.New("Can't bisect into zero segments")
+		}
+		if err := data.requireMatchesPrevState(valprotocol.InboxTopChallengeDataHash(
+			chainHashes[0],
+			chainHashes[len(chainHashes)-1],
+			chainLength,
+		)); err != nil {
+			return err
+		}
+		data.commitToSegment(inboxTopBisectionHashes(chainHashes, chainLength.Uint64()))
+		data.asserterResponded(tx)
+		tx.emit(c.address, arbbridge.InboxTopBisectionEvent{
+			ChainInfo:   tx.chainInfo(),
+			ChainHashes: chainHashes,
+			TotalLength: new(big.Int).Set(chainLength),
+			Deadline:    common.TimeTicks{Val: new(big.Int).Set(data.deadlineTicks)},
+		})
+		return nil
+	})
 }
 
-func (c *InboxTopChallenge) OneStepProof(
+func (c *inboxTopChallenge) OneStepProof(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	value common.Hash,
 ) error {
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.OneStepProof(
-	//	c.auth,
-	//	lowerHashA,
-	//	topHashA,
-	//	value,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "OneStepProof")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if err := data.requireMatchesPrevState(valprotocol.InboxTopChallengeDataHash(
+			lowerHashA,
+			hashing.SoliditySHA3(hashing.Bytes32(lowerHashA), hashing.Bytes32(value)),
+			big.NewInt(1),
+		)); err != nil {
+			return err
+		}
+		tx.emit(c.address, arbbridge.OneStepProofEvent{
+			ChainInfo: tx.chainInfo(),
+		})
+		return data.asserterWin(tx, c.address)
+	})
 }
 
-func (c *InboxTopChallenge) ChooseSegment(
+func (c *inboxTopChallenge) ChooseSegment(
 	ctx context.Context,
 	assertionToChallenge uint16,
 	chainHashes []common.Hash,
 	chainLength uint64,
 ) error {
-	return nil
+	return c.bisectionChallenge.chooseSegment(
+		ctx,
+		assertionToChallenge,
+		inboxTopBisectionHashes(chainHashes, chainLength),
+	)
 }
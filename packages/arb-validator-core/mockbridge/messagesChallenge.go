@@ -18,162 +18,179 @@ package mockbridge
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
-
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type MessagesChallenge struct {
-	*BisectionChallenge
+type messagesChallenge struct {
+	*bisectionChallenge
 }
 
-func NewMessagesChallenge(address common.Address, client arbbridge.ArbClient) (*MessagesChallenge, error) {
-	bisectionChallenge, err := NewBisectionChallenge(address, client)
+func newMessagesChallenge(address common.Address, eth *MockEth, from common.Address) (*messagesChallenge, error) {
+	bisectionChallenge, err := newBisectionChallenge(address, eth, from)
 	if err != nil {
 		return nil, err
 	}
-	vm := &MessagesChallenge{BisectionChallenge: bisectionChallenge}
-	err = vm.setupContracts()
-	return vm, err
-}
-
-func (c *MessagesChallenge) setupContracts() error {
-	//challengeManagerContract, err := messageschallenge.NewMessagesChallenge(c.address, c.Client)
-	//if err != nil {
-	//	return errors2.Wrap(err, "Failed to connect to MessagesChallenge")
-	//}
-	//
-	//c.challenge = challengeManagerContract
-	return nil
+	return &messagesChallenge{bisectionChallenge: bisectionChallenge}, nil
 }
 
-func (vm *MessagesChallenge) GetEvents(ctx context.Context, blockId *common.BlockId, timestamp *big.Int) ([]arbbridge.Event, error) {
-	return nil, nil
+func messagesBisectionHashes(
+	chainHashes []common.Hash,
+	segmentHashes []common.Hash,
+	chainLength *big.Int,
+) []common.Hash {
+	bisectionCount := uint64(len(chainHashes) - 1)
+	bisectionHashes := make([]common.Hash, 0, bisectionCount)
+	for i := uint64(0); i < bisectionCount; i++ {
+		stepCount := valprotocol.CalculateBisectionStepCount(i, bisectionCount, chainLength.Uint64())
+		bisectionHashes = append(
+			bisectionHashes,
+			valprotocol.MessageChallengeDataHash(
+				chainHashes[i],
+				chainHashes[i+1],
+				segmentHashes[i],
+				segmentHashes[i+1],
+				new(big.Int).SetUint64(stepCount),
+			),
+		)
+	}
+	return bisectionHashes
 }
 
-//func (c *MessagesChallenge) processEvents(ctx context.Context, log types.Log, outChan chan arbbridge.Notification) error {
-//	event, err := func() (arbbridge.Event, error) {
-//		if log.Topics[0] == messagesBisectedID {
-//			eventVal, err := c.challenge.ParseBisected(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.MessagesBisectionEvent{
-//				ChainHashes:   eventVal.ChainHashes,
-//				SegmentHashes: eventVal.SegmentHashes,
-//				TotalLength:   eventVal.TotalLength,
-//				Deadline:      structures.TimeTicks{Val: eventVal.DeadlineTicks},
-//			}, nil
-//		} else if log.Topics[0] == messagesOneStepProofCompletedID {
-//			_, err := c.challenge.ParseOneStepProofCompleted(log)
-//			if err != nil {
-//				return nil, err
-//			}
-//			return arbbridge.OneStepProofEvent{}, nil
-//		}
-//		return nil, errors2.New("unknown arbitrum event type")
-//	}()
-//
-//	if err != nil {
-//		return err
-//	}
-//
-//	header, err := c.Client.HeaderByHash(ctx, log.BlockHash)
-//	if err != nil {
-//		return err
-//	}
-//	outChan <- arbbridge.Notification{
-//		Header: header,
-//		VMID:   c.address,
-//		Event:  event,
-//		TxHash: log.TxHash,
-//	}
-//	return nil
-//}
-
-func (c *MessagesChallenge) Bisect(
+func (c *messagesChallenge) Bisect(
 	ctx context.Context,
 	chainHashes []common.Hash,
 	segmentHashes []common.Hash,
 	chainLength *big.Int,
 ) error {
-	//c.auth.Context = ctx
-	//tx, err := c.challenge.Bisect(
-	//	c.auth,
-	//	chainHashes,
-	//	segmentHashes,
-	//	chainLength,
-	//)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.waitForReceipt(ctx, tx, "Bisect")
-	return nil
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if len(chainHashes) != len(segmentHashes) {
+			return errors.New("HS_BIS_INPLEN")
+		}
+		if len(chainHashes) < 2 {
+			return errors.New("Can't bisect into zero segments")
+		}
+		bisectionCount := len(chainHashes) - 1
+		if err := data.requireMatchesPrevState(valprotocol.MessageChallengeDataHash(
+			chainHashes[0],
+			chainHashes[bisectionCount],
+			segmentHashes[0],
+			segmentHashes[bisectionCount],
+			chainLength,
+		)); err != nil {
+			return err
+		}
+		data.commitToSegment(messagesBisectionHashes(chainHashes, segmentHashes, chainLength))
+		data.asserterResponded(tx)
+		tx.emit(c.address, arbbridge.MessagesBisectionEvent{
+			ChainInfo:     tx.chainInfo(),
+			ChainHashes:   chainHashes,
+			SegmentHashes: segmentHashes,
+			TotalLength:   new(big.Int).Set(chainLength),
+			Deadline:      common.TimeTicks{Val: new(big.Int).Set(data.deadlineTicks)},
+		})
+		return nil
+	})
+}
+
+func (c *messagesChallenge) oneStepProof(
+	lowerHashA common.Hash,
+	lowerHashB common.Hash,
+	msg message.InboxMessage,
+) error {
+	return c.transact(func(tx *mockTx, data *challengeData) error {
+		if err := data.asserterAction(tx); err != nil {
+			return err
+		}
+		if err := data.requireMatchesPrevState(valprotocol.MessageChallengeDataHash(
+			lowerHashA,
+			hashing.SoliditySHA3(hashing.Bytes32(lowerHashA), hashing.Bytes32(msg.CommitmentHash())),
+			lowerHashB,
+			message.AddToPrevHash(lowerHashB, msg),
+			big.NewInt(1),
+		)); err != nil {
+			return err
+		}
+		tx.emit(c.address, arbbridge.OneStepProofEvent{
+			ChainInfo: tx.chainInfo(),
+		})
+		return data.asserterWin(tx, c.address)
+	})
 }
 
-func (c *MessagesChallenge) OneStepProofTransactionMessage(
+func (c *messagesChallenge) OneStepProofTransactionMessage(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredTransaction,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) OneStepProofTransactionBatchMessage(
+func (c *messagesChallenge) OneStepProofTransactionBatchMessage(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredTransactionBatch,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) OneStepProofEthMessage(
+func (c *messagesChallenge) OneStepProofEthMessage(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredEth,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) OneStepProofERC20Message(
+func (c *messagesChallenge) OneStepProofERC20Message(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredERC20,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) OneStepProofERC721Message(
+func (c *messagesChallenge) OneStepProofERC721Message(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredERC721,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) OneStepProofContractTransactionMessage(
+func (c *messagesChallenge) OneStepProofContractTransactionMessage(
 	ctx context.Context,
 	lowerHashA common.Hash,
 	lowerHashB common.Hash,
 	msg message.DeliveredContractTransaction,
 ) error {
-	return nil
+	return c.oneStepProof(lowerHashA, lowerHashB, msg)
 }
 
-func (c *MessagesChallenge) ChooseSegment(
+func (c *messagesChallenge) ChooseSegment(
 	ctx context.Context,
 	assertionToChallenge uint16,
 	chainHashes []common.Hash,
 	segmentHashes []common.Hash,
 	chainLength *big.Int,
 ) error {
-	return nil
+	return c.bisectionChallenge.chooseSegment(
+		ctx,
+		assertionToChallenge,
+		messagesBisectionHashes(chainHashes, segmentHashes, chainLength),
+	)
 }
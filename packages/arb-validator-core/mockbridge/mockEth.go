@@ -0,0 +1,289 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockbridge
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+)
+
+var errNoContract = errors.New("no contract deployed at address")
+
+// MockEth is an in-memory simulation of an Ethereum chain running the
+// Arbitrum bridge contracts. Every successful transaction is mined
+// immediately in its own block and a transaction which fails any of the
+// checks made by the corresponding contract is rejected without being mined.
+// Chain time is kept by a virtual clock which advances by one block's worth
+// of time whenever a block is mined.
+type MockEth struct {
+	sync.Mutex
+
+	blocks   []*mockBlock
+	newBlock chan struct{}
+	clock    *common.VirtualClock
+
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+
+	factories          map[common.Address]*arbFactoryData
+	rollups            map[common.Address]*arbRollupData
+	inboxes            map[common.Address]*globalInboxData
+	challengeFactories map[common.Address]bool
+	challenges         map[common.Address]*challengeData
+}
+
+type mockLog struct {
+	// address is the contract that emitted the log
+	address common.Address
+	// chain is the rollup that a message delivered to the global inbox is
+	// destined for
+	chain common.Address
+	event arbbridge.Event
+}
+
+type mockBlock struct {
+	id        *common.BlockId
+	timestamp *big.Int
+	logs      []mockLog
+}
+
+func NewMockEth() *MockEth {
+	m := &MockEth{
+		newBlock:           make(chan struct{}),
+		clock:              common.NewVirtualClock(time.Now()),
+		balances:           make(map[common.Address]*big.Int),
+		nonces:             make(map[common.Address]uint64),
+		factories:          make(map[common.Address]*arbFactoryData),
+		rollups:            make(map[common.Address]*arbRollupData),
+		inboxes:            make(map[common.Address]*globalInboxData),
+		challengeFactories: make(map[common.Address]bool),
+		challenges:         make(map[common.Address]*challengeData),
+	}
+	m.blocks = append(m.blocks, m.nextBlock(common.Hash{}))
+	return m
+}
+
+// Clock returns the clock driven by the blocks of this chain, which lets the
+// validators and challenges running against it follow chain time
+func (m *MockEth) Clock() *common.VirtualClock {
+	return m.clock
+}
+
+// AddBalance credits account with amount wei
+func (m *MockEth) AddBalance(account common.Address, amount *big.Int) {
+	m.Lock()
+	defer m.Unlock()
+	m.credit(account, amount)
+}
+
+// MineBlocks mines count empty blocks, advancing chain time
+func (m *MockEth) MineBlocks(count int) {
+	m.Lock()
+	defer m.Unlock()
+	for i := 0; i < count; i++ {
+		m.addBlock(m.nextBlock(common.Hash{}))
+	}
+}
+
+func (m *MockEth) latestBlock() *mockBlock {
+	return m.blocks[len(m.blocks)-1]
+}
+
+func (m *MockEth) getBlock(blockId *common.BlockId) (*mockBlock, error) {
+	height := blockId.Height.AsInt()
+	if !height.IsInt64() || height.Int64() < 0 || height.Int64() >= int64(len(m.blocks)) {
+		return nil, errors.New("block not found")
+	}
+	block := m.blocks[height.Int64()]
+	if block.id.HeaderHash != blockId.HeaderHash {
+		return nil, errors.New("block not found")
+	}
+	return block, nil
+}
+
+// blockAtHeight returns the block at the given height if it has been mined
+// yet. Otherwise it returns a channel which is closed when the next block
+// is mined
+func (m *MockEth) blockAtHeight(height uint64) (*mockBlock, <-chan struct{}) {
+	m.Lock()
+	defer m.Unlock()
+	if height < uint64(len(m.blocks)) {
+		return m.blocks[height], nil
+	}
+	return nil, m.newBlock
+}
+
+func (m *MockEth) nextBlock(txHash common.Hash) *mockBlock {
+	height := int64(len(m.blocks))
+	var prevHash common.Hash
+	blockTime := m.clock.Now()
+	if height > 0 {
+		prevHash = m.latestBlock().id.HeaderHash
+		// The clock reaches the block's time once the block is mined
+		blockTime = blockTime.Add(common.NewTimeBlocksInt(1).Duration())
+	}
+	timestamp := big.NewInt(blockTime.Unix())
+	return &mockBlock{
+		id: &common.BlockId{
+			Height: common.NewTimeBlocksInt(height),
+			HeaderHash: hashing.SoliditySHA3(
+				hashing.Bytes32(prevHash),
+				hashing.Uint256(big.NewInt(height)),
+				hashing.Uint256(timestamp),
+				hashing.Bytes32(txHash),
+			),
+		},
+		timestamp: timestamp,
+	}
+}
+
+func (m *MockEth) addBlock(block *mockBlock) {
+	m.blocks = append(m.blocks, block)
+	close(m.newBlock)
+	m.newBlock = make(chan struct{})
+	m.clock.AdvanceBlocks(1)
+}
+
+func (m *MockEth) balance(account common.Address) *big.Int {
+	bal, ok := m.balances[account]
+	if !ok {
+		return big.NewInt(0)
+	}
+	return bal
+}
+
+func (m *MockEth) credit(account common.Address, amount *big.Int) {
+	m.balances[account] = new(big.Int).Add(m.balance(account), amount)
+}
+
+// getLogs returns the logs in the given block accepted by filter in the
+// order they were emitted
+func (m *MockEth) getLogs(blockId *common.BlockId, filter func(l mockLog) bool) ([]mockLog, error) {
+	m.Lock()
+	defer m.Unlock()
+	block, err := m.getBlock(blockId)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]mockLog, 0)
+	for _, l := range block.logs {
+		if filter(l) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+// mockTx holds the context of a transaction while it is being executed
+type mockTx struct {
+	eth   *MockEth
+	from  common.Address
+	value *big.Int
+	hash  common.Hash
+	block *mockBlock
+
+	createCount int
+}
+
+// transact executes f as a transaction sent by from carrying value wei. If f
+// returns an error the transaction reverts and no block is mined. Functions
+// run by transact must make all of their checks before modifying any state
+func (m *MockEth) transact(from common.Address, value *big.Int, f func(tx *mockTx) error) error {
+	m.Lock()
+	defer m.Unlock()
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	if m.balance(from).Cmp(value) < 0 {
+		return errors.New("insufficient funds for transfer")
+	}
+	nonce := m.nonces[from]
+	txHash := hashing.SoliditySHA3(
+		hashing.Address(from),
+		hashing.Uint64(nonce),
+	)
+	tx := &mockTx{
+		eth:   m,
+		from:  from,
+		value: value,
+		hash:  txHash,
+		block: m.nextBlock(txHash),
+	}
+	if err := f(tx); err != nil {
+		return err
+	}
+	m.nonces[from] = nonce + 1
+	m.balances[from] = new(big.Int).Sub(m.balance(from), value)
+	m.addBlock(tx.block)
+	return nil
+}
+
+// blockNumber returns the height of the block the transaction is mined in
+func (tx *mockTx) blockNumber() *big.Int {
+	return tx.block.id.Height.AsInt()
+}
+
+// blockTicks returns the current time in ticks as seen by the transaction
+func (tx *mockTx) blockTicks() *big.Int {
+	return common.TicksFromBlockNum(tx.block.id.Height).Val
+}
+
+func (tx *mockTx) timestamp() *big.Int {
+	return tx.block.timestamp
+}
+
+// createAddress returns the address for a contract created by this
+// transaction
+func (tx *mockTx) createAddress() common.Address {
+	addressHash := hashing.SoliditySHA3(
+		hashing.Bytes32(tx.hash),
+		hashing.Uint64(uint64(tx.createCount)),
+	)
+	tx.createCount++
+	var address common.Address
+	copy(address[:], addressHash[12:])
+	return address
+}
+
+func (tx *mockTx) chainInfo() arbbridge.ChainInfo {
+	return arbbridge.ChainInfo{
+		BlockId:  tx.block.id,
+		LogIndex: uint(len(tx.block.logs)),
+		TxHash:   tx.hash,
+	}
+}
+
+func (tx *mockTx) emit(address common.Address, event arbbridge.Event) {
+	tx.block.logs = append(tx.block.logs, mockLog{
+		address: address,
+		event:   event,
+	})
+}
+
+func (tx *mockTx) emitMessage(address common.Address, chain common.Address, event arbbridge.Event) {
+	tx.block.logs = append(tx.block.logs, mockLog{
+		address: address,
+		chain:   chain,
+		event:   event,
+	})
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockbridge
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+func setupRollup(t *testing.T, eth *MockEth, owner common.Address) common.Address {
+	ctx := context.Background()
+	client := NewEthAuthClient(eth, owner)
+	factoryAddress, err := client.DeployArbFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory, err := client.NewArbFactory(factoryAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollupAddress, err := factory.CreateRollup(
+		ctx,
+		common.Hash{1, 2, 3},
+		valprotocol.ChainParams{
+			StakeRequirement:        big.NewInt(10),
+			GracePeriod:             common.TicksFromSeconds(60 * 60),
+			MaxExecutionSteps:       1000000,
+			MaxBlockBoundsWidth:     20,
+			MaxTimestampBoundsWidth: 100,
+			ArbGasSpeedLimitPerTick: 1000,
+		},
+		owner,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rollupAddress
+}
+
+func TestPlaceStake(t *testing.T) {
+	ctx := context.Background()
+	eth := NewMockEth()
+	staker := common.Address{5}
+	eth.AddBalance(staker, big.NewInt(100))
+	rollupAddress := setupRollup(t, eth, staker)
+
+	client := NewEthAuthClient(eth, staker)
+	rollup, err := client.NewRollup(rollupAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rollup.PlaceStake(ctx, big.NewInt(5), nil, nil); err == nil {
+		t.Error("placed stake with wrong amount")
+	}
+	if err := rollup.PlaceStake(ctx, big.NewInt(10), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := rollup.PlaceStake(ctx, big.NewInt(10), nil, nil); err == nil {
+		t.Error("placed stake twice")
+	}
+
+	staked, err := rollup.IsStaked(staker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !staked {
+		t.Error("staker should be staked")
+	}
+	balance, err := client.GetBalance(ctx, staker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(90)) != 0 {
+		t.Error("stake not deducted from balance", balance)
+	}
+
+	blockId, err := client.CurrentBlockId(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.NewRollupWatcher(rollupAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := watcher.GetEvents(ctx, blockId, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatal("expected a single event but got", len(events))
+	}
+	ev, ok := events[0].(arbbridge.StakeCreatedEvent)
+	if !ok {
+		t.Fatal("wrong event type", events[0])
+	}
+	if ev.Staker != staker {
+		t.Error("event had wrong staker")
+	}
+}
+
+func TestInboxEvents(t *testing.T) {
+	ctx := context.Background()
+	eth := NewMockEth()
+	sender := common.Address{5}
+	eth.AddBalance(sender, big.NewInt(100))
+	rollupAddress := setupRollup(t, eth, sender)
+
+	client := NewEthAuthClient(eth, sender)
+	watcher, err := client.NewRollupWatcher(rollupAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inboxAddress, err := watcher.InboxAddress(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := client.NewGlobalInbox(inboxAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inbox.DepositEthMessage(ctx, rollupAddress, sender, big.NewInt(25)); err != nil {
+		t.Fatal(err)
+	}
+
+	blockId, err := client.CurrentBlockId(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := watcher.GetEvents(ctx, blockId, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatal("expected a single event but got", len(events))
+	}
+	if _, ok := events[0].(arbbridge.MessageDeliveredEvent); !ok {
+		t.Fatal("wrong event type", events[0])
+	}
+}
+
+func TestChallengeTimeout(t *testing.T) {
+	ctx := context.Background()
+	eth := NewMockEth()
+	asserter := common.Address{5}
+	challenger := common.Address{6}
+	setupRollup(t, eth, asserter)
+
+	client := NewEthAuthClient(eth, challenger)
+	var challengeFactoryAddress common.Address
+	for address := range eth.challengeFactories {
+		challengeFactoryAddress = address
+	}
+	challengeFactory, err := client.NewChallengeFactory(challengeFactoryAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challengeAddress, err := challengeFactory.CreateChallenge(
+		ctx,
+		asserter,
+		challenger,
+		common.TicksFromBlockNum(common.NewTimeBlocksInt(5)),
+		common.Hash{},
+		big.NewInt(int64(valprotocol.InvalidInboxTopChildType)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := client.NewInboxTopChallenge(challengeAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := challenge.TimeoutChallenge(ctx); err == nil {
+		t.Error("timed out challenge before deadline")
+	}
+	eth.MineBlocks(5)
+	if err := challenge.TimeoutChallenge(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	blockId, err := client.CurrentBlockId(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.NewInboxTopChallengeWatcher(challengeAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := watcher.GetEvents(ctx, blockId, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatal("expected a single event but got", len(events))
+	}
+	if _, ok := events[0].(arbbridge.AsserterTimeoutEvent); !ok {
+		t.Fatal("wrong event type", events[0])
+	}
+}
@@ -21,43 +21,25 @@ import (
 	"math/big"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
-type OneStepProof struct {
-	//contract *onestepproof.OneStepProof
-	client arbbridge.ArbClient
+// oneStepProof reports every proof as valid since the mock doesn't include
+// an implementation of the one step proof contract
+type oneStepProof struct {
+	address common.Address
+	eth     *MockEth
 }
 
-func NewOneStepProof(address common.Address, client arbbridge.ArbClient) (*OneStepProof, error) {
-	//contract, err := onestepproof.NewOneStepProof(address, client)
-	//if err != nil {
-	//	return nil, errors2.Wrap(err, "Failed to connect to OneStepProof")
-	//}
-
-	return &OneStepProof{client}, nil
+func newOneStepProof(address common.Address, eth *MockEth) (*oneStepProof, error) {
+	return &oneStepProof{address: address, eth: eth}, nil
 }
 
-func (con *OneStepProof) ValidateProof(
+func (con *oneStepProof) ValidateProof(
 	ctx context.Context,
 	precondition *valprotocol.Precondition,
 	assertion *valprotocol.ExecutionAssertionStub,
 	proof []byte,
 ) (*big.Int, error) {
-	//return con.contract.ValidateProof(
-	//	auth,
-	//	precondition.BeforeHash,
-	//	precondition.TimeBounds.AsIntArray(),
-	//	precondition.BeforeInbox.Hash(),
-	//	assertion.AfterHashValue(),
-	//	assertion.DidInboxInsn,
-	//	assertion.FirstMessageHashValue(),
-	//	assertion.LastMessageHashValue(),
-	//	assertion.FirstLogHashValue(),
-	//	assertion.LastLogHashValue(),
-	//	assertion.NumGas,
-	//	proof,
-	//)
 	return big.NewInt(0), nil
 }
@@ -0,0 +1,78 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package utils
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+)
+
+type MetricsFlags struct {
+	enable *bool
+	port   *string
+}
+
+// AddMetricsFlags adds the flags controlling the metrics endpoint. The
+// metrics package enables collection by looking for a --metrics argument
+// before any flags are parsed, so the flag must keep that name
+func AddMetricsFlags(fs *flag.FlagSet) MetricsFlags {
+	enable := fs.Bool(
+		"metrics",
+		false,
+		"metrics",
+	)
+	port := fs.String(
+		"metrics-port",
+		"6070",
+		"metrics-port=Port",
+	)
+
+	return MetricsFlags{
+		enable: enable,
+		port:   port,
+	}
+}
+
+// LaunchMetrics serves the collected metrics in the Prometheus text format
+// on /metrics if they were enabled on the command line. It returns an error
+// if the metrics port can't be listened on. Metrics are only for monitoring,
+// so an error serving them later is logged rather than stopping the process
+func LaunchMetrics(args MetricsFlags) error {
+	if !*args.enable {
+		return nil
+	}
+	if !metrics.Enabled {
+		log.Println("Metrics were enabled after they were created and will not be collected")
+	}
+	listener, err := net.Listen("tcp", ":"+*args.port)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+	go func() {
+		log.Println("Stopped serving metrics", http.Serve(listener, mux))
+	}()
+	return nil
+}
+
+const MetricsArgsString = "[--metrics] [--metrics-port=Port]"
@@ -26,7 +26,20 @@ import (
 func LaunchRPC(handler http.Handler, port string) error {
 	r := mux.NewRouter()
 	r.Handle("/", handler).Methods("GET", "POST", "OPTIONS")
+	return listenWithCORS(r, port)
+}
+
+// LaunchNamespacedRPC serves each handler on its own path, so that several
+// servers of the same kind can share a port
+func LaunchNamespacedRPC(handlers map[string]http.Handler, port string) error {
+	r := mux.NewRouter()
+	for path, handler := range handlers {
+		r.Handle("/"+path, handler).Methods("GET", "POST", "OPTIONS")
+	}
+	return listenWithCORS(r, port)
+}
 
+func listenWithCORS(r *mux.Router, port string) error {
 	headersOk := handlers.AllowedHeaders(
 		[]string{"X-Requested-With", "Content-Type", "Authorization"},
 	)
@@ -32,8 +32,9 @@ import (
 )
 
 type WalletFlags struct {
-	passphrase *string
-	gasPrice   *float64
+	passphrase  *string
+	gasPrice    *float64
+	maxGasPrice *float64
 }
 
 func AddFlags(fs *flag.FlagSet) WalletFlags {
@@ -47,11 +48,28 @@ func AddFlags(fs *flag.FlagSet) WalletFlags {
 		4.5,
 		"gasprice=FloatInGwei",
 	)
+	maxGasPrice := fs.Float64(
+		"maxgasprice",
+		0,
+		"maxgasprice=FloatInGwei",
+	)
 
 	return WalletFlags{
-		passphrase: passphrase,
-		gasPrice:   gasPrice,
+		passphrase:  passphrase,
+		gasPrice:    gasPrice,
+		maxGasPrice: maxGasPrice,
+	}
+}
+
+// MaxGasPrice returns the highest gas price that stuck transactions may be
+// resubmitted with, as set by the optional "maxgasprice" argument, or nil if
+// there is no limit
+func (w WalletFlags) MaxGasPrice() *big.Int {
+	maxGasPriceAsFloat := 1e9 * (*w.maxGasPrice)
+	if maxGasPriceAsFloat <= 0 || maxGasPriceAsFloat >= math.MaxInt64 {
+		return nil
 	}
+	return big.NewInt(int64(maxGasPriceAsFloat))
 }
 
 // GetKeystore returns a transaction authorization based on an existing ethereum
@@ -122,4 +140,4 @@ func GetKeystore(
 	return auth, nil
 }
 
-const WalletArgsString = "[--password=pass] [--gasprice==FloatInGwei]"
+const WalletArgsString = "[--password=pass] [--gasprice==FloatInGwei] [--maxgasprice=FloatInGwei]"
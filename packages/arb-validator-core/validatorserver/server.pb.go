@@ -115,21 +115,63 @@ func (m *LogInfo) GetTransactionHash() string {
 	return ""
 }
 
-type FindLogsArgs struct {
-	FromHeight           string   `protobuf:"bytes,1,opt,name=fromHeight,proto3" json:"fromHeight,omitempty"`
-	ToHeight             string   `protobuf:"bytes,2,opt,name=toHeight,proto3" json:"toHeight,omitempty"`
-	Address              string   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
-	Topics               []string `protobuf:"bytes,4,rep,name=topics,proto3" json:"topics,omitempty"`
+type TopicGroup struct {
+	Topics               []string `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *TopicGroup) Reset()         { *m = TopicGroup{} }
+func (m *TopicGroup) String() string { return proto.CompactTextString(m) }
+func (*TopicGroup) ProtoMessage()    {}
+func (*TopicGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{1}
+}
+
+func (m *TopicGroup) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TopicGroup.Unmarshal(m, b)
+}
+func (m *TopicGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TopicGroup.Marshal(b, m, deterministic)
+}
+func (m *TopicGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopicGroup.Merge(m, src)
+}
+func (m *TopicGroup) XXX_Size() int {
+	return xxx_messageInfo_TopicGroup.Size(m)
+}
+func (m *TopicGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopicGroup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopicGroup proto.InternalMessageInfo
+
+func (m *TopicGroup) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+type FindLogsArgs struct {
+	FromHeight           string        `protobuf:"bytes,1,opt,name=fromHeight,proto3" json:"fromHeight,omitempty"`
+	ToHeight             string        `protobuf:"bytes,2,opt,name=toHeight,proto3" json:"toHeight,omitempty"`
+	Address              string        `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Topics               []string      `protobuf:"bytes,4,rep,name=topics,proto3" json:"topics,omitempty"`
+	Addresses            []string      `protobuf:"bytes,5,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	TopicGroups          []*TopicGroup `protobuf:"bytes,6,rep,name=topicGroups,proto3" json:"topicGroups,omitempty"`
+	BlockHash            string        `protobuf:"bytes,7,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
 func (m *FindLogsArgs) Reset()         { *m = FindLogsArgs{} }
 func (m *FindLogsArgs) String() string { return proto.CompactTextString(m) }
 func (*FindLogsArgs) ProtoMessage()    {}
 func (*FindLogsArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{1}
+	return fileDescriptor_ad098daeda4239f7, []int{2}
 }
 
 func (m *FindLogsArgs) XXX_Unmarshal(b []byte) error {
@@ -178,6 +220,27 @@ func (m *FindLogsArgs) GetTopics() []string {
 	return nil
 }
 
+func (m *FindLogsArgs) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *FindLogsArgs) GetTopicGroups() []*TopicGroup {
+	if m != nil {
+		return m.TopicGroups
+	}
+	return nil
+}
+
+func (m *FindLogsArgs) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
 type FindLogsReply struct {
 	Logs                 []*LogInfo `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
@@ -189,7 +252,7 @@ func (m *FindLogsReply) Reset()         { *m = FindLogsReply{} }
 func (m *FindLogsReply) String() string { return proto.CompactTextString(m) }
 func (*FindLogsReply) ProtoMessage()    {}
 func (*FindLogsReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{2}
+	return fileDescriptor_ad098daeda4239f7, []int{3}
 }
 
 func (m *FindLogsReply) XXX_Unmarshal(b []byte) error {
@@ -229,7 +292,7 @@ func (m *GetOutputMessageArgs) Reset()         { *m = GetOutputMessageArgs{} }
 func (m *GetOutputMessageArgs) String() string { return proto.CompactTextString(m) }
 func (*GetOutputMessageArgs) ProtoMessage()    {}
 func (*GetOutputMessageArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{3}
+	return fileDescriptor_ad098daeda4239f7, []int{4}
 }
 
 func (m *GetOutputMessageArgs) XXX_Unmarshal(b []byte) error {
@@ -276,7 +339,7 @@ func (m *GetOutputMessageReply) Reset()         { *m = GetOutputMessageReply{} }
 func (m *GetOutputMessageReply) String() string { return proto.CompactTextString(m) }
 func (*GetOutputMessageReply) ProtoMessage()    {}
 func (*GetOutputMessageReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{4}
+	return fileDescriptor_ad098daeda4239f7, []int{5}
 }
 
 func (m *GetOutputMessageReply) XXX_Unmarshal(b []byte) error {
@@ -322,7 +385,7 @@ func (m *GetMessageResultArgs) Reset()         { *m = GetMessageResultArgs{} }
 func (m *GetMessageResultArgs) String() string { return proto.CompactTextString(m) }
 func (*GetMessageResultArgs) ProtoMessage()    {}
 func (*GetMessageResultArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{5}
+	return fileDescriptor_ad098daeda4239f7, []int{6}
 }
 
 func (m *GetMessageResultArgs) XXX_Unmarshal(b []byte) error {
@@ -366,7 +429,7 @@ func (m *GetMessageResultReply) Reset()         { *m = GetMessageResultReply{} }
 func (m *GetMessageResultReply) String() string { return proto.CompactTextString(m) }
 func (*GetMessageResultReply) ProtoMessage()    {}
 func (*GetMessageResultReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{6}
+	return fileDescriptor_ad098daeda4239f7, []int{7}
 }
 
 func (m *GetMessageResultReply) XXX_Unmarshal(b []byte) error {
@@ -439,7 +502,7 @@ func (m *GetAssertionCountArgs) Reset()         { *m = GetAssertionCountArgs{} }
 func (m *GetAssertionCountArgs) String() string { return proto.CompactTextString(m) }
 func (*GetAssertionCountArgs) ProtoMessage()    {}
 func (*GetAssertionCountArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{7}
+	return fileDescriptor_ad098daeda4239f7, []int{8}
 }
 
 func (m *GetAssertionCountArgs) XXX_Unmarshal(b []byte) error {
@@ -471,7 +534,7 @@ func (m *GetAssertionCountReply) Reset()         { *m = GetAssertionCountReply{}
 func (m *GetAssertionCountReply) String() string { return proto.CompactTextString(m) }
 func (*GetAssertionCountReply) ProtoMessage()    {}
 func (*GetAssertionCountReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{8}
+	return fileDescriptor_ad098daeda4239f7, []int{9}
 }
 
 func (m *GetAssertionCountReply) XXX_Unmarshal(b []byte) error {
@@ -509,7 +572,7 @@ func (m *GetVMInfoArgs) Reset()         { *m = GetVMInfoArgs{} }
 func (m *GetVMInfoArgs) String() string { return proto.CompactTextString(m) }
 func (*GetVMInfoArgs) ProtoMessage()    {}
 func (*GetVMInfoArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{9}
+	return fileDescriptor_ad098daeda4239f7, []int{10}
 }
 
 func (m *GetVMInfoArgs) XXX_Unmarshal(b []byte) error {
@@ -541,7 +604,7 @@ func (m *GetVMInfoReply) Reset()         { *m = GetVMInfoReply{} }
 func (m *GetVMInfoReply) String() string { return proto.CompactTextString(m) }
 func (*GetVMInfoReply) ProtoMessage()    {}
 func (*GetVMInfoReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{10}
+	return fileDescriptor_ad098daeda4239f7, []int{11}
 }
 
 func (m *GetVMInfoReply) XXX_Unmarshal(b []byte) error {
@@ -582,7 +645,7 @@ func (m *CallMessageArgs) Reset()         { *m = CallMessageArgs{} }
 func (m *CallMessageArgs) String() string { return proto.CompactTextString(m) }
 func (*CallMessageArgs) ProtoMessage()    {}
 func (*CallMessageArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{11}
+	return fileDescriptor_ad098daeda4239f7, []int{12}
 }
 
 func (m *CallMessageArgs) XXX_Unmarshal(b []byte) error {
@@ -626,6 +689,7 @@ func (m *CallMessageArgs) GetData() string {
 
 type CallMessageReply struct {
 	RawVal               string   `protobuf:"bytes,1,opt,name=rawVal,proto3" json:"rawVal,omitempty"`
+	NumGas               uint64   `protobuf:"varint,2,opt,name=numGas,proto3" json:"numGas,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -635,7 +699,7 @@ func (m *CallMessageReply) Reset()         { *m = CallMessageReply{} }
 func (m *CallMessageReply) String() string { return proto.CompactTextString(m) }
 func (*CallMessageReply) ProtoMessage()    {}
 func (*CallMessageReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ad098daeda4239f7, []int{12}
+	return fileDescriptor_ad098daeda4239f7, []int{13}
 }
 
 func (m *CallMessageReply) XXX_Unmarshal(b []byte) error {
@@ -663,8 +727,667 @@ func (m *CallMessageReply) GetRawVal() string {
 	return ""
 }
 
+func (m *CallMessageReply) GetNumGas() uint64 {
+	if m != nil {
+		return m.NumGas
+	}
+	return 0
+}
+
+type NodeInfo struct {
+	Hash                 string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	PrevHash             string   `protobuf:"bytes,2,opt,name=prevHash,proto3" json:"prevHash,omitempty"`
+	Depth                uint64   `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	ChildType            string   `protobuf:"bytes,4,opt,name=childType,proto3" json:"childType,omitempty"`
+	Deadline             string   `protobuf:"bytes,5,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	MachineHash          string   `protobuf:"bytes,6,opt,name=machineHash,proto3" json:"machineHash,omitempty"`
+	InboxTop             string   `protobuf:"bytes,7,opt,name=inboxTop,proto3" json:"inboxTop,omitempty"`
+	InboxCount           string   `protobuf:"bytes,8,opt,name=inboxCount,proto3" json:"inboxCount,omitempty"`
+	StakerCount          uint64   `protobuf:"varint,9,opt,name=stakerCount,proto3" json:"stakerCount,omitempty"`
+	IsLeaf               bool     `protobuf:"varint,10,opt,name=isLeaf,proto3" json:"isLeaf,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
+func (m *NodeInfo) String() string { return proto.CompactTextString(m) }
+func (*NodeInfo) ProtoMessage()    {}
+func (*NodeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{14}
+}
+
+func (m *NodeInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeInfo.Unmarshal(m, b)
+}
+func (m *NodeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeInfo.Marshal(b, m, deterministic)
+}
+func (m *NodeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeInfo.Merge(m, src)
+}
+func (m *NodeInfo) XXX_Size() int {
+	return xxx_messageInfo_NodeInfo.Size(m)
+}
+func (m *NodeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeInfo proto.InternalMessageInfo
+
+func (m *NodeInfo) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetPrevHash() string {
+	if m != nil {
+		return m.PrevHash
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetDepth() uint64 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *NodeInfo) GetChildType() string {
+	if m != nil {
+		return m.ChildType
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetDeadline() string {
+	if m != nil {
+		return m.Deadline
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetMachineHash() string {
+	if m != nil {
+		return m.MachineHash
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetInboxTop() string {
+	if m != nil {
+		return m.InboxTop
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetInboxCount() string {
+	if m != nil {
+		return m.InboxCount
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetStakerCount() uint64 {
+	if m != nil {
+		return m.StakerCount
+	}
+	return 0
+}
+
+func (m *NodeInfo) GetIsLeaf() bool {
+	if m != nil {
+		return m.IsLeaf
+	}
+	return false
+}
+
+type StakerInfo struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Location             string   `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	LocationDepth        uint64   `protobuf:"varint,3,opt,name=locationDepth,proto3" json:"locationDepth,omitempty"`
+	CreationTime         string   `protobuf:"bytes,4,opt,name=creationTime,proto3" json:"creationTime,omitempty"`
+	InChallenge          bool     `protobuf:"varint,5,opt,name=inChallenge,proto3" json:"inChallenge,omitempty"`
+	Challenge            string   `protobuf:"bytes,6,opt,name=challenge,proto3" json:"challenge,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StakerInfo) Reset()         { *m = StakerInfo{} }
+func (m *StakerInfo) String() string { return proto.CompactTextString(m) }
+func (*StakerInfo) ProtoMessage()    {}
+func (*StakerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{15}
+}
+
+func (m *StakerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StakerInfo.Unmarshal(m, b)
+}
+func (m *StakerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StakerInfo.Marshal(b, m, deterministic)
+}
+func (m *StakerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StakerInfo.Merge(m, src)
+}
+func (m *StakerInfo) XXX_Size() int {
+	return xxx_messageInfo_StakerInfo.Size(m)
+}
+func (m *StakerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_StakerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StakerInfo proto.InternalMessageInfo
+
+func (m *StakerInfo) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *StakerInfo) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *StakerInfo) GetLocationDepth() uint64 {
+	if m != nil {
+		return m.LocationDepth
+	}
+	return 0
+}
+
+func (m *StakerInfo) GetCreationTime() string {
+	if m != nil {
+		return m.CreationTime
+	}
+	return ""
+}
+
+func (m *StakerInfo) GetInChallenge() bool {
+	if m != nil {
+		return m.InChallenge
+	}
+	return false
+}
+
+func (m *StakerInfo) GetChallenge() string {
+	if m != nil {
+		return m.Challenge
+	}
+	return ""
+}
+
+type ChallengeInfo struct {
+	Contract             string   `protobuf:"bytes,1,opt,name=contract,proto3" json:"contract,omitempty"`
+	ChallengeType        string   `protobuf:"bytes,2,opt,name=challengeType,proto3" json:"challengeType,omitempty"`
+	Asserter             string   `protobuf:"bytes,3,opt,name=asserter,proto3" json:"asserter,omitempty"`
+	Challenger           string   `protobuf:"bytes,4,opt,name=challenger,proto3" json:"challenger,omitempty"`
+	ConflictNode         string   `protobuf:"bytes,5,opt,name=conflictNode,proto3" json:"conflictNode,omitempty"`
+	BlockHash            string   `protobuf:"bytes,6,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	BlockNumber          string   `protobuf:"bytes,7,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChallengeInfo) Reset()         { *m = ChallengeInfo{} }
+func (m *ChallengeInfo) String() string { return proto.CompactTextString(m) }
+func (*ChallengeInfo) ProtoMessage()    {}
+func (*ChallengeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{16}
+}
+
+func (m *ChallengeInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChallengeInfo.Unmarshal(m, b)
+}
+func (m *ChallengeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChallengeInfo.Marshal(b, m, deterministic)
+}
+func (m *ChallengeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChallengeInfo.Merge(m, src)
+}
+func (m *ChallengeInfo) XXX_Size() int {
+	return xxx_messageInfo_ChallengeInfo.Size(m)
+}
+func (m *ChallengeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChallengeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChallengeInfo proto.InternalMessageInfo
+
+func (m *ChallengeInfo) GetContract() string {
+	if m != nil {
+		return m.Contract
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetChallengeType() string {
+	if m != nil {
+		return m.ChallengeType
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetAsserter() string {
+	if m != nil {
+		return m.Asserter
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetChallenger() string {
+	if m != nil {
+		return m.Challenger
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetConflictNode() string {
+	if m != nil {
+		return m.ConflictNode
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *ChallengeInfo) GetBlockNumber() string {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return ""
+}
+
+type GetNodesArgs struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetNodesArgs) Reset()         { *m = GetNodesArgs{} }
+func (m *GetNodesArgs) String() string { return proto.CompactTextString(m) }
+func (*GetNodesArgs) ProtoMessage()    {}
+func (*GetNodesArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{17}
+}
+
+func (m *GetNodesArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetNodesArgs.Unmarshal(m, b)
+}
+func (m *GetNodesArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetNodesArgs.Marshal(b, m, deterministic)
+}
+func (m *GetNodesArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetNodesArgs.Merge(m, src)
+}
+func (m *GetNodesArgs) XXX_Size() int {
+	return xxx_messageInfo_GetNodesArgs.Size(m)
+}
+func (m *GetNodesArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetNodesArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetNodesArgs proto.InternalMessageInfo
+
+type GetNodesReply struct {
+	Nodes                []*NodeInfo `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetNodesReply) Reset()         { *m = GetNodesReply{} }
+func (m *GetNodesReply) String() string { return proto.CompactTextString(m) }
+func (*GetNodesReply) ProtoMessage()    {}
+func (*GetNodesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{18}
+}
+
+func (m *GetNodesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetNodesReply.Unmarshal(m, b)
+}
+func (m *GetNodesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetNodesReply.Marshal(b, m, deterministic)
+}
+func (m *GetNodesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetNodesReply.Merge(m, src)
+}
+func (m *GetNodesReply) XXX_Size() int {
+	return xxx_messageInfo_GetNodesReply.Size(m)
+}
+func (m *GetNodesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetNodesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetNodesReply proto.InternalMessageInfo
+
+func (m *GetNodesReply) GetNodes() []*NodeInfo {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+type GetLeavesArgs struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLeavesArgs) Reset()         { *m = GetLeavesArgs{} }
+func (m *GetLeavesArgs) String() string { return proto.CompactTextString(m) }
+func (*GetLeavesArgs) ProtoMessage()    {}
+func (*GetLeavesArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{19}
+}
+
+func (m *GetLeavesArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLeavesArgs.Unmarshal(m, b)
+}
+func (m *GetLeavesArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLeavesArgs.Marshal(b, m, deterministic)
+}
+func (m *GetLeavesArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLeavesArgs.Merge(m, src)
+}
+func (m *GetLeavesArgs) XXX_Size() int {
+	return xxx_messageInfo_GetLeavesArgs.Size(m)
+}
+func (m *GetLeavesArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLeavesArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLeavesArgs proto.InternalMessageInfo
+
+type GetLeavesReply struct {
+	Leaves               []*NodeInfo `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetLeavesReply) Reset()         { *m = GetLeavesReply{} }
+func (m *GetLeavesReply) String() string { return proto.CompactTextString(m) }
+func (*GetLeavesReply) ProtoMessage()    {}
+func (*GetLeavesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{20}
+}
+
+func (m *GetLeavesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLeavesReply.Unmarshal(m, b)
+}
+func (m *GetLeavesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLeavesReply.Marshal(b, m, deterministic)
+}
+func (m *GetLeavesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLeavesReply.Merge(m, src)
+}
+func (m *GetLeavesReply) XXX_Size() int {
+	return xxx_messageInfo_GetLeavesReply.Size(m)
+}
+func (m *GetLeavesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLeavesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLeavesReply proto.InternalMessageInfo
+
+func (m *GetLeavesReply) GetLeaves() []*NodeInfo {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+type GetStakersArgs struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStakersArgs) Reset()         { *m = GetStakersArgs{} }
+func (m *GetStakersArgs) String() string { return proto.CompactTextString(m) }
+func (*GetStakersArgs) ProtoMessage()    {}
+func (*GetStakersArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{21}
+}
+
+func (m *GetStakersArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStakersArgs.Unmarshal(m, b)
+}
+func (m *GetStakersArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStakersArgs.Marshal(b, m, deterministic)
+}
+func (m *GetStakersArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStakersArgs.Merge(m, src)
+}
+func (m *GetStakersArgs) XXX_Size() int {
+	return xxx_messageInfo_GetStakersArgs.Size(m)
+}
+func (m *GetStakersArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStakersArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStakersArgs proto.InternalMessageInfo
+
+type GetStakersReply struct {
+	Stakers              []*StakerInfo `protobuf:"bytes,1,rep,name=stakers,proto3" json:"stakers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetStakersReply) Reset()         { *m = GetStakersReply{} }
+func (m *GetStakersReply) String() string { return proto.CompactTextString(m) }
+func (*GetStakersReply) ProtoMessage()    {}
+func (*GetStakersReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{22}
+}
+
+func (m *GetStakersReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStakersReply.Unmarshal(m, b)
+}
+func (m *GetStakersReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStakersReply.Marshal(b, m, deterministic)
+}
+func (m *GetStakersReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStakersReply.Merge(m, src)
+}
+func (m *GetStakersReply) XXX_Size() int {
+	return xxx_messageInfo_GetStakersReply.Size(m)
+}
+func (m *GetStakersReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStakersReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStakersReply proto.InternalMessageInfo
+
+func (m *GetStakersReply) GetStakers() []*StakerInfo {
+	if m != nil {
+		return m.Stakers
+	}
+	return nil
+}
+
+type GetChallengesArgs struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetChallengesArgs) Reset()         { *m = GetChallengesArgs{} }
+func (m *GetChallengesArgs) String() string { return proto.CompactTextString(m) }
+func (*GetChallengesArgs) ProtoMessage()    {}
+func (*GetChallengesArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{23}
+}
+
+func (m *GetChallengesArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChallengesArgs.Unmarshal(m, b)
+}
+func (m *GetChallengesArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChallengesArgs.Marshal(b, m, deterministic)
+}
+func (m *GetChallengesArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChallengesArgs.Merge(m, src)
+}
+func (m *GetChallengesArgs) XXX_Size() int {
+	return xxx_messageInfo_GetChallengesArgs.Size(m)
+}
+func (m *GetChallengesArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChallengesArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChallengesArgs proto.InternalMessageInfo
+
+type GetChallengesReply struct {
+	Challenges           []*ChallengeInfo `protobuf:"bytes,1,rep,name=challenges,proto3" json:"challenges,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *GetChallengesReply) Reset()         { *m = GetChallengesReply{} }
+func (m *GetChallengesReply) String() string { return proto.CompactTextString(m) }
+func (*GetChallengesReply) ProtoMessage()    {}
+func (*GetChallengesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{24}
+}
+
+func (m *GetChallengesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChallengesReply.Unmarshal(m, b)
+}
+func (m *GetChallengesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChallengesReply.Marshal(b, m, deterministic)
+}
+func (m *GetChallengesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChallengesReply.Merge(m, src)
+}
+func (m *GetChallengesReply) XXX_Size() int {
+	return xxx_messageInfo_GetChallengesReply.Size(m)
+}
+func (m *GetChallengesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChallengesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChallengesReply proto.InternalMessageInfo
+
+func (m *GetChallengesReply) GetChallenges() []*ChallengeInfo {
+	if m != nil {
+		return m.Challenges
+	}
+	return nil
+}
+
+type GetChainStateArgs struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetChainStateArgs) Reset()         { *m = GetChainStateArgs{} }
+func (m *GetChainStateArgs) String() string { return proto.CompactTextString(m) }
+func (*GetChainStateArgs) ProtoMessage()    {}
+func (*GetChainStateArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{25}
+}
+
+func (m *GetChainStateArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChainStateArgs.Unmarshal(m, b)
+}
+func (m *GetChainStateArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChainStateArgs.Marshal(b, m, deterministic)
+}
+func (m *GetChainStateArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChainStateArgs.Merge(m, src)
+}
+func (m *GetChainStateArgs) XXX_Size() int {
+	return xxx_messageInfo_GetChainStateArgs.Size(m)
+}
+func (m *GetChainStateArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChainStateArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChainStateArgs proto.InternalMessageInfo
+
+type GetChainStateReply struct {
+	LatestConfirmed      *NodeInfo `protobuf:"bytes,1,opt,name=latestConfirmed,proto3" json:"latestConfirmed,omitempty"`
+	CalculatedValid      *NodeInfo `protobuf:"bytes,2,opt,name=calculatedValid,proto3" json:"calculatedValid,omitempty"`
+	BlockHash            string    `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	BlockNumber          string    `protobuf:"bytes,4,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *GetChainStateReply) Reset()         { *m = GetChainStateReply{} }
+func (m *GetChainStateReply) String() string { return proto.CompactTextString(m) }
+func (*GetChainStateReply) ProtoMessage()    {}
+func (*GetChainStateReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{26}
+}
+
+func (m *GetChainStateReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetChainStateReply.Unmarshal(m, b)
+}
+func (m *GetChainStateReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetChainStateReply.Marshal(b, m, deterministic)
+}
+func (m *GetChainStateReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetChainStateReply.Merge(m, src)
+}
+func (m *GetChainStateReply) XXX_Size() int {
+	return xxx_messageInfo_GetChainStateReply.Size(m)
+}
+func (m *GetChainStateReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetChainStateReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetChainStateReply proto.InternalMessageInfo
+
+func (m *GetChainStateReply) GetLatestConfirmed() *NodeInfo {
+	if m != nil {
+		return m.LatestConfirmed
+	}
+	return nil
+}
+
+func (m *GetChainStateReply) GetCalculatedValid() *NodeInfo {
+	if m != nil {
+		return m.CalculatedValid
+	}
+	return nil
+}
+
+func (m *GetChainStateReply) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *GetChainStateReply) GetBlockNumber() string {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*LogInfo)(nil), "validatorserver.LogInfo")
+	proto.RegisterType((*TopicGroup)(nil), "validatorserver.TopicGroup")
 	proto.RegisterType((*FindLogsArgs)(nil), "validatorserver.FindLogsArgs")
 	proto.RegisterType((*FindLogsReply)(nil), "validatorserver.FindLogsReply")
 	proto.RegisterType((*GetOutputMessageArgs)(nil), "validatorserver.GetOutputMessageArgs")
@@ -677,55 +1400,103 @@ func init() {
 	proto.RegisterType((*GetVMInfoReply)(nil), "validatorserver.GetVMInfoReply")
 	proto.RegisterType((*CallMessageArgs)(nil), "validatorserver.CallMessageArgs")
 	proto.RegisterType((*CallMessageReply)(nil), "validatorserver.CallMessageReply")
+	proto.RegisterType((*NodeInfo)(nil), "validatorserver.NodeInfo")
+	proto.RegisterType((*StakerInfo)(nil), "validatorserver.StakerInfo")
+	proto.RegisterType((*ChallengeInfo)(nil), "validatorserver.ChallengeInfo")
+	proto.RegisterType((*GetNodesArgs)(nil), "validatorserver.GetNodesArgs")
+	proto.RegisterType((*GetNodesReply)(nil), "validatorserver.GetNodesReply")
+	proto.RegisterType((*GetLeavesArgs)(nil), "validatorserver.GetLeavesArgs")
+	proto.RegisterType((*GetLeavesReply)(nil), "validatorserver.GetLeavesReply")
+	proto.RegisterType((*GetStakersArgs)(nil), "validatorserver.GetStakersArgs")
+	proto.RegisterType((*GetStakersReply)(nil), "validatorserver.GetStakersReply")
+	proto.RegisterType((*GetChallengesArgs)(nil), "validatorserver.GetChallengesArgs")
+	proto.RegisterType((*GetChallengesReply)(nil), "validatorserver.GetChallengesReply")
+	proto.RegisterType((*GetChainStateArgs)(nil), "validatorserver.GetChainStateArgs")
+	proto.RegisterType((*GetChainStateReply)(nil), "validatorserver.GetChainStateReply")
 }
 
 func init() { proto.RegisterFile("server.proto", fileDescriptor_ad098daeda4239f7) }
 
 var fileDescriptor_ad098daeda4239f7 = []byte{
-	// 714 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0x5b, 0x4f, 0xdb, 0x4a,
-	0x10, 0x56, 0xc8, 0x85, 0x64, 0xb8, 0x04, 0x56, 0x1c, 0x8e, 0x15, 0x9d, 0xc3, 0xc9, 0xb1, 0x28,
-	0x8d, 0x10, 0x24, 0x12, 0x7d, 0xae, 0x54, 0x4a, 0x5b, 0x40, 0x0a, 0xb4, 0xb2, 0xaa, 0x3c, 0xf4,
-	0xa9, 0x1b, 0x7b, 0xe3, 0x58, 0x6c, 0xbc, 0xd1, 0xee, 0x9a, 0x52, 0xa9, 0x2f, 0xfd, 0x3b, 0xfd,
-	0x37, 0xfd, 0x47, 0x95, 0x67, 0x1d, 0xc7, 0x97, 0x50, 0xa4, 0xbe, 0x79, 0xbe, 0x99, 0x9d, 0xcb,
-	0x37, 0xdf, 0xae, 0x61, 0x53, 0x31, 0x79, 0xcf, 0x64, 0x7f, 0x2e, 0x85, 0x16, 0xa4, 0x7d, 0x4f,
-	0x79, 0xe0, 0x51, 0x2d, 0xa4, 0x81, 0xed, 0xef, 0x6b, 0xb0, 0x3e, 0x14, 0xfe, 0x75, 0x38, 0x11,
-	0xc4, 0x82, 0x75, 0xea, 0x79, 0x92, 0x29, 0x65, 0x55, 0xba, 0x95, 0x5e, 0xcb, 0x59, 0x98, 0xe4,
-	0x1f, 0x68, 0x8d, 0xb9, 0x70, 0xef, 0xae, 0xa8, 0x9a, 0x5a, 0x6b, 0xe8, 0x5b, 0x02, 0xa4, 0x0b,
-	0x1b, 0x68, 0xdc, 0x46, 0xb3, 0x31, 0x93, 0x56, 0x15, 0xfd, 0x59, 0x88, 0x10, 0xa8, 0x79, 0x54,
-	0x53, 0xab, 0x86, 0x2e, 0xfc, 0x26, 0x1d, 0x68, 0xf2, 0xb8, 0xb0, 0xc7, 0x1e, 0xac, 0x3a, 0xe2,
-	0xa9, 0x4d, 0xf6, 0xa1, 0xa1, 0xc5, 0x3c, 0x70, 0x95, 0xd5, 0xe8, 0x56, 0x7b, 0x2d, 0x27, 0xb1,
-	0xc8, 0x31, 0xec, 0x68, 0x49, 0x43, 0x45, 0x5d, 0x1d, 0x88, 0xd0, 0x9c, 0x5d, 0xc7, 0xb3, 0x25,
-	0x9c, 0xf4, 0xa0, 0x9d, 0xc1, 0xb0, 0xf3, 0x26, 0x86, 0x16, 0x61, 0xfb, 0x1b, 0x6c, 0xbe, 0x0b,
-	0x42, 0x6f, 0x28, 0x7c, 0x75, 0x2e, 0x7d, 0x45, 0x0e, 0x00, 0x26, 0x52, 0xcc, 0xae, 0x58, 0xe0,
-	0x4f, 0x75, 0x42, 0x45, 0x06, 0x89, 0x3b, 0xd7, 0x22, 0xf1, 0x1a, 0x32, 0x52, 0x3b, 0xcb, 0x61,
-	0x35, 0xcf, 0xe1, 0x72, 0xa6, 0x5a, 0x76, 0x26, 0xfb, 0x25, 0x6c, 0x2d, 0xaa, 0x3b, 0x6c, 0xce,
-	0xbf, 0x92, 0x13, 0xa8, 0x71, 0xe1, 0x9b, 0xb0, 0x8d, 0x33, 0xab, 0x5f, 0x58, 0x59, 0x3f, 0x59,
-	0x97, 0x83, 0x51, 0xf6, 0x67, 0xd8, 0xbb, 0x64, 0xfa, 0x7d, 0xa4, 0xe7, 0x91, 0xbe, 0x61, 0x4a,
-	0x51, 0x9f, 0xe1, 0x10, 0x27, 0xb0, 0x7b, 0xae, 0x14, 0x93, 0xf1, 0x94, 0xb7, 0xc2, 0x63, 0x48,
-	0x80, 0x99, 0xa5, 0xec, 0x88, 0x47, 0xba, 0x51, 0xc9, 0x32, 0x92, 0x91, 0x16, 0xb6, 0xfd, 0x16,
-	0xfe, 0x2a, 0x56, 0x30, 0x8d, 0xee, 0x41, 0x7d, 0x22, 0xa2, 0xd0, 0xc3, 0xb4, 0x4d, 0xc7, 0x18,
-	0xf1, 0x9c, 0x92, 0x7e, 0x19, 0x51, 0x9e, 0x24, 0x4a, 0x2c, 0xbb, 0x8f, 0x8d, 0xa6, 0x09, 0x54,
-	0xc4, 0x35, 0x36, 0x1a, 0xf3, 0xf2, 0x90, 0xe9, 0x2e, 0xb1, 0xec, 0x9f, 0x15, 0xac, 0x9b, 0x3b,
-	0xf0, 0x07, 0x75, 0xe3, 0x6d, 0x72, 0xe1, 0x7f, 0x90, 0x86, 0x01, 0xb3, 0x94, 0x0c, 0x12, 0xab,
-	0x37, 0xb6, 0x84, 0xd2, 0x18, 0x60, 0x24, 0x9a, 0x85, 0x88, 0x0d, 0x9b, 0x5c, 0xf8, 0x23, 0xca,
-	0x63, 0x8b, 0x29, 0xab, 0x8e, 0xfb, 0xcb, 0x61, 0xe4, 0x10, 0xb6, 0x44, 0x78, 0x31, 0xa5, 0x41,
-	0xf8, 0xd1, 0x0c, 0xd3, 0xc0, 0x3c, 0x79, 0xd0, 0xfe, 0x1b, 0x47, 0x4a, 0xe9, 0xbf, 0x10, 0x51,
-	0x88, 0x24, 0xd8, 0xaf, 0x60, 0xbf, 0xe4, 0x30, 0xc3, 0x1e, 0xc1, 0x36, 0xcd, 0xc1, 0x38, 0x75,
-	0xdd, 0x29, 0xa0, 0x76, 0x1b, 0xb6, 0x2e, 0x99, 0x1e, 0xdd, 0xc4, 0xd2, 0xc0, 0x94, 0x87, 0xb0,
-	0x9d, 0x02, 0x26, 0x15, 0x81, 0xda, 0xfd, 0xec, 0xfa, 0x4d, 0xc2, 0x33, 0x7e, 0xdb, 0x3e, 0xb4,
-	0x2f, 0x28, 0xe7, 0x59, 0xe5, 0xf4, 0xa0, 0xed, 0x8a, 0x50, 0x4b, 0xea, 0xea, 0xf3, 0xdc, 0x73,
-	0x50, 0x84, 0x63, 0xca, 0x15, 0x0b, 0x3d, 0x26, 0x17, 0x94, 0x1b, 0x2b, 0xbd, 0xee, 0xd5, 0xe5,
-	0x75, 0xb7, 0x8f, 0x61, 0x27, 0x53, 0xc8, 0x34, 0xb4, 0x5c, 0x59, 0x25, 0xbb, 0xb2, 0xb3, 0x1f,
-	0x35, 0x68, 0x3b, 0x82, 0xf3, 0x68, 0x3e, 0x5a, 0x68, 0x9f, 0x50, 0xd8, 0x29, 0xaa, 0x90, 0x3c,
-	0x2b, 0xdd, 0x8d, 0x55, 0x57, 0xa1, 0x73, 0xf4, 0x64, 0x98, 0x69, 0xc7, 0x94, 0xc8, 0x09, 0x6e,
-	0x75, 0x89, 0x92, 0x88, 0x57, 0x97, 0x58, 0x21, 0x5d, 0x07, 0x36, 0x32, 0x2c, 0x90, 0x6e, 0xe9,
-	0x58, 0x61, 0x19, 0x9d, 0xff, 0x7f, 0x17, 0x61, 0x72, 0x5e, 0x43, 0x73, 0xf1, 0x80, 0x90, 0x7f,
-	0x4b, 0xe1, 0xd9, 0x97, 0xad, 0x73, 0xf0, 0xa8, 0xdb, 0xa4, 0xf2, 0x60, 0xb7, 0x24, 0x43, 0xb2,
-	0x72, 0xb6, 0xb2, 0x86, 0x3b, 0xcf, 0x9f, 0x8e, 0x33, 0x55, 0x86, 0xd0, 0x4a, 0x95, 0x49, 0x0e,
-	0x56, 0x9d, 0x5a, 0xca, 0xb8, 0xf3, 0xdf, 0xe3, 0x7e, 0xcc, 0xf6, 0xfa, 0xf6, 0xd3, 0xd0, 0x0f,
-	0xf4, 0x34, 0x1a, 0xf7, 0x5d, 0x31, 0x1b, 0x88, 0xc9, 0xc4, 0x8d, 0x2f, 0x1c, 0xa7, 0x63, 0x35,
-	0xa0, 0x72, 0x1c, 0x68, 0x19, 0xcd, 0x06, 0x73, 0xea, 0xde, 0x51, 0x9f, 0x21, 0x72, 0x9a, 0xe6,
-	0x3b, 0x75, 0x85, 0x64, 0x83, 0x42, 0xfa, 0x71, 0x03, 0xff, 0x94, 0x2f, 0x7e, 0x05, 0x00, 0x00,
-	0xff, 0xff, 0xa6, 0x04, 0xa3, 0xfe, 0x39, 0x07, 0x00, 0x00,
+	// 1269 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0xcf, 0x6e, 0xdb, 0xc6,
+	0x13, 0x86, 0x6c, 0x59, 0x7f, 0x46, 0xb6, 0xe5, 0xec, 0x2f, 0xc9, 0x4f, 0x65, 0x53, 0xc7, 0x65,
+	0xd2, 0xd4, 0x28, 0x12, 0x1b, 0x75, 0xd1, 0x63, 0x8a, 0x3a, 0x4a, 0x2b, 0x1b, 0x90, 0x9d, 0x82,
+	0x11, 0x8c, 0xa2, 0xa7, 0xae, 0xc8, 0x15, 0x45, 0x98, 0xe2, 0x0a, 0xbb, 0x4b, 0xd7, 0x39, 0xf6,
+	0x55, 0xfa, 0x34, 0x3d, 0xf4, 0xd2, 0x17, 0xe8, 0x3b, 0xf4, 0xd4, 0x6b, 0xb1, 0x7f, 0x48, 0x2e,
+	0x49, 0xd9, 0x02, 0x7a, 0xd3, 0x7c, 0x3b, 0xfb, 0xcd, 0xce, 0xcc, 0xb7, 0xb3, 0x14, 0x6c, 0x73,
+	0xc2, 0x6e, 0x08, 0x3b, 0x5a, 0x32, 0x2a, 0x28, 0xea, 0xdf, 0xe0, 0x38, 0x0a, 0xb0, 0xa0, 0x4c,
+	0xc3, 0xee, 0xaf, 0x1b, 0xd0, 0x1e, 0xd3, 0xf0, 0x3c, 0x99, 0x51, 0x34, 0x80, 0x36, 0x0e, 0x02,
+	0x46, 0x38, 0x1f, 0x34, 0x0e, 0x1a, 0x87, 0x5d, 0x2f, 0x33, 0xd1, 0x13, 0xe8, 0x4e, 0x63, 0xea,
+	0x5f, 0x9f, 0x61, 0x3e, 0x1f, 0x6c, 0xa8, 0xb5, 0x02, 0x40, 0x07, 0xd0, 0x53, 0xc6, 0x65, 0xba,
+	0x98, 0x12, 0x36, 0xd8, 0x54, 0xeb, 0x36, 0x84, 0x10, 0x34, 0x03, 0x2c, 0xf0, 0xa0, 0xa9, 0x96,
+	0xd4, 0x6f, 0xe4, 0x40, 0x27, 0x96, 0x81, 0x03, 0x72, 0x3b, 0xd8, 0x52, 0x78, 0x6e, 0xa3, 0xc7,
+	0xd0, 0x12, 0x74, 0x19, 0xf9, 0x7c, 0xd0, 0x3a, 0xd8, 0x3c, 0xec, 0x7a, 0xc6, 0x42, 0x5f, 0xc0,
+	0x9e, 0x60, 0x38, 0xe1, 0xd8, 0x17, 0x11, 0x4d, 0xf4, 0xde, 0xb6, 0xda, 0x5b, 0xc3, 0xd1, 0x21,
+	0xf4, 0x2d, 0x4c, 0x9d, 0xbc, 0xa3, 0x5c, 0xab, 0xb0, 0xfb, 0x1c, 0x60, 0x22, 0xf9, 0x47, 0x8c,
+	0xa6, 0x4b, 0x2b, 0x76, 0xc3, 0x8e, 0xed, 0xfe, 0xd3, 0x80, 0xed, 0xef, 0xa3, 0x24, 0x18, 0xd3,
+	0x90, 0x9f, 0xb2, 0x90, 0xa3, 0x7d, 0x80, 0x19, 0xa3, 0x8b, 0x33, 0x12, 0x85, 0x73, 0x61, 0x2a,
+	0x66, 0x21, 0x32, 0x41, 0x41, 0xcd, 0xaa, 0xae, 0x59, 0x6e, 0xdb, 0xa5, 0xde, 0x2c, 0x97, 0xba,
+	0x08, 0xdf, 0x2c, 0xa5, 0xfe, 0x04, 0xba, 0xc6, 0x85, 0xf0, 0xc1, 0x96, 0x5a, 0x2a, 0x00, 0xf4,
+	0x1a, 0x7a, 0x22, 0x4f, 0x41, 0x57, 0xad, 0x77, 0xf2, 0xf1, 0x51, 0xa5, 0xdb, 0x47, 0x45, 0x9a,
+	0x9e, 0xed, 0x5f, 0xee, 0x6f, 0xbb, 0xd2, 0x5f, 0xf7, 0x35, 0xec, 0x64, 0x89, 0x7b, 0x64, 0x19,
+	0x7f, 0x40, 0x2f, 0xa1, 0x19, 0xd3, 0x50, 0x9f, 0xb0, 0x77, 0x32, 0xa8, 0x85, 0x31, 0x82, 0xf2,
+	0x94, 0x97, 0xfb, 0x33, 0x3c, 0x1c, 0x11, 0xf1, 0x2e, 0x15, 0xcb, 0x54, 0x5c, 0x10, 0xce, 0x71,
+	0x48, 0x54, 0xfd, 0x5e, 0xc2, 0x83, 0x53, 0xce, 0x09, 0x93, 0x7d, 0xb8, 0xa4, 0x01, 0x51, 0xc1,
+	0x75, 0x19, 0xeb, 0x0b, 0xb2, 0x9a, 0x17, 0xdc, 0xc8, 0xc5, 0x54, 0x33, 0xb3, 0xdd, 0xef, 0xe0,
+	0x51, 0x35, 0x82, 0x3e, 0xe8, 0x43, 0xd8, 0x9a, 0xd1, 0x34, 0x09, 0x14, 0x6d, 0xc7, 0xd3, 0x86,
+	0x2c, 0x31, 0xc3, 0xbf, 0x5c, 0xe1, 0xd8, 0x10, 0x19, 0xcb, 0x3d, 0x52, 0x07, 0xcd, 0x09, 0x78,
+	0x1a, 0x0b, 0x75, 0x50, 0xd9, 0x92, 0x5b, 0xeb, 0x74, 0xc6, 0x72, 0xff, 0x6c, 0xc0, 0xa3, 0xea,
+	0x86, 0xff, 0x10, 0x57, 0x0a, 0x29, 0xa6, 0xe1, 0x0f, 0x4c, 0x57, 0x40, 0xeb, 0xc1, 0x42, 0xe4,
+	0xfd, 0x92, 0x16, 0xe5, 0x42, 0x39, 0xe8, 0x4b, 0x64, 0x43, 0xc8, 0x85, 0xed, 0x98, 0x86, 0x57,
+	0x38, 0x96, 0x56, 0xae, 0x8f, 0x12, 0x86, 0x9e, 0xc3, 0x0e, 0x4d, 0x86, 0x73, 0x1c, 0x25, 0x13,
+	0x9d, 0x4c, 0x4b, 0xf1, 0x94, 0x41, 0xf7, 0xff, 0x2a, 0xa5, 0xbc, 0xfc, 0x43, 0x9a, 0x26, 0xaa,
+	0x08, 0xee, 0xb7, 0xf0, 0xb8, 0xb6, 0xa0, 0x93, 0x7d, 0x01, 0xbb, 0xb8, 0x04, 0xab, 0xac, 0xb7,
+	0xbc, 0x0a, 0xea, 0xf6, 0x61, 0x67, 0x44, 0xc4, 0xd5, 0x85, 0x94, 0x86, 0xa2, 0x7c, 0x0e, 0xbb,
+	0x39, 0xa0, 0xa9, 0x10, 0x34, 0x6f, 0x16, 0xe7, 0x6f, 0x4d, 0x9d, 0xd5, 0x6f, 0x37, 0x84, 0xfe,
+	0x10, 0xc7, 0xb1, 0xad, 0x9c, 0x43, 0xe8, 0xfb, 0x34, 0x11, 0x0c, 0xfb, 0xe2, 0xb4, 0x34, 0xb0,
+	0xaa, 0xb0, 0x2c, 0x39, 0x27, 0x49, 0x40, 0x58, 0x56, 0x72, 0x6d, 0xe5, 0x03, 0x69, 0xb3, 0x18,
+	0x48, 0xee, 0x1b, 0xd8, 0xb3, 0x02, 0xe9, 0x03, 0x15, 0x2d, 0x6b, 0x94, 0x5a, 0xf6, 0x18, 0x5a,
+	0x49, 0xba, 0x18, 0x61, 0xae, 0x78, 0x9b, 0x9e, 0xb1, 0xdc, 0xdf, 0x36, 0xa0, 0x23, 0x25, 0xab,
+	0xe6, 0x29, 0x82, 0xe6, 0xbc, 0x50, 0x4d, 0x73, 0x6e, 0x64, 0xbc, 0x64, 0xe4, 0xc6, 0x1a, 0xa4,
+	0xb9, 0x2d, 0x55, 0x13, 0x90, 0xa5, 0xd0, 0x12, 0x68, 0x7a, 0xda, 0x90, 0x77, 0xd3, 0x9f, 0x47,
+	0x71, 0x30, 0xf9, 0xb0, 0x24, 0xa6, 0xf7, 0x05, 0x20, 0xf9, 0x02, 0x82, 0x83, 0x38, 0x4a, 0x48,
+	0x36, 0x45, 0x33, 0x5b, 0xea, 0x66, 0x81, 0xfd, 0x79, 0x94, 0x10, 0xab, 0xdf, 0x36, 0x24, 0x77,
+	0x47, 0xc9, 0x94, 0xde, 0x4e, 0xe8, 0xd2, 0x5c, 0xfb, 0xdc, 0x96, 0xaa, 0x54, 0xbf, 0x75, 0x4b,
+	0xf5, 0xe8, 0xb4, 0x10, 0xc9, 0xce, 0x05, 0xbe, 0x26, 0x4c, 0x3b, 0x74, 0xd5, 0x99, 0x6d, 0x48,
+	0x16, 0x29, 0xe2, 0x63, 0x82, 0x67, 0x03, 0x50, 0xd7, 0xc0, 0x58, 0xee, 0x1f, 0x0d, 0x80, 0xf7,
+	0xca, 0x6f, 0xcd, 0xb3, 0xa3, 0x9e, 0x08, 0x1f, 0x4b, 0x09, 0x65, 0xc5, 0xca, 0x6c, 0x29, 0xe7,
+	0xec, 0xf7, 0x5b, 0xab, 0x68, 0x65, 0x50, 0x5e, 0x0c, 0x9f, 0x11, 0x05, 0x4c, 0xa2, 0x45, 0x56,
+	0xbf, 0x12, 0x26, 0x13, 0x89, 0xe4, 0x1d, 0x88, 0x63, 0x92, 0x84, 0xba, 0x8a, 0x1d, 0xcf, 0x86,
+	0x74, 0x0b, 0xb2, 0xf5, 0x56, 0xd6, 0x02, 0x03, 0xb8, 0x7f, 0x37, 0x60, 0x27, 0xf7, 0x55, 0x19,
+	0x39, 0xd0, 0xc9, 0x84, 0x68, 0x52, 0xca, 0x6d, 0x79, 0xee, 0x7c, 0xab, 0x6a, 0xa9, 0x4e, 0xac,
+	0x0c, 0x4a, 0x06, 0x7d, 0x7b, 0xf2, 0xf7, 0x34, 0xb7, 0x65, 0x63, 0x72, 0x67, 0x66, 0x32, 0xb2,
+	0x10, 0x95, 0x33, 0x4d, 0x66, 0x71, 0xe4, 0x0b, 0x29, 0x45, 0x23, 0x8b, 0x12, 0x56, 0x1e, 0xf8,
+	0xad, 0x35, 0x0f, 0x7a, 0xbb, 0xf6, 0xa0, 0xbb, 0xbb, 0xb0, 0x3d, 0x22, 0x8a, 0x8a, 0x9b, 0xe9,
+	0xb0, 0x93, 0xd9, 0xfa, 0xe2, 0x1c, 0xc3, 0x56, 0x22, 0x2d, 0xf5, 0x88, 0xf6, 0x4e, 0x3e, 0xaa,
+	0xbd, 0x11, 0xd9, 0x2d, 0xf1, 0xb4, 0x9f, 0x99, 0x0e, 0x63, 0x82, 0x6f, 0x0c, 0xe5, 0x10, 0x76,
+	0x73, 0x40, 0x73, 0x7e, 0x09, 0xad, 0x58, 0x99, 0xeb, 0x49, 0x8d, 0xa3, 0xbb, 0xa7, 0x48, 0xb4,
+	0xd8, 0x34, 0xed, 0x19, 0xf4, 0x0b, 0x44, 0xf3, 0x7e, 0x0d, 0x6d, 0x2d, 0xdb, 0x8c, 0xb8, 0xfe,
+	0x70, 0x16, 0x72, 0xf5, 0x32, 0x5f, 0xf7, 0x7f, 0xf0, 0x60, 0x44, 0x44, 0xde, 0x79, 0x4d, 0x3f,
+	0x01, 0x54, 0x02, 0x75, 0x84, 0x6f, 0xac, 0x96, 0x65, 0x41, 0xf6, 0x6b, 0x41, 0x4a, 0x22, 0xb2,
+	0x5a, 0x6a, 0x85, 0x8a, 0x92, 0xf7, 0x02, 0x0b, 0x35, 0x05, 0xdd, 0xbf, 0x1a, 0x80, 0x4a, 0xa8,
+	0x8e, 0x35, 0x84, 0x7e, 0x8c, 0x05, 0xe1, 0x62, 0x48, 0x93, 0x59, 0xc4, 0x16, 0x44, 0xbf, 0x42,
+	0xf7, 0x96, 0xab, 0xba, 0x43, 0x92, 0xf8, 0x38, 0xf6, 0x53, 0x89, 0x07, 0x57, 0x72, 0xdb, 0x60,
+	0x63, 0x2d, 0x49, 0x65, 0x47, 0x59, 0x64, 0x9b, 0x6b, 0x44, 0xd6, 0xac, 0x89, 0xec, 0xe4, 0xf7,
+	0x36, 0xf4, 0x3d, 0x1a, 0xc7, 0xe9, 0xf2, 0x2a, 0x8b, 0x89, 0x30, 0xec, 0x55, 0x9f, 0x7a, 0xf4,
+	0x59, 0xed, 0x4c, 0xab, 0xbe, 0x37, 0x9c, 0x17, 0x6b, 0xdd, 0x74, 0x01, 0x75, 0x88, 0xd2, 0xab,
+	0xbe, 0x3a, 0x44, 0xed, 0x4b, 0xc1, 0x79, 0xb1, 0xd6, 0x4d, 0x87, 0xf0, 0xa0, 0x67, 0x3d, 0x35,
+	0xe8, 0xa0, 0x2e, 0x85, 0xf2, 0x8b, 0xe7, 0x7c, 0x7a, 0x9f, 0x87, 0xe6, 0x3c, 0x87, 0x4e, 0xf6,
+	0x95, 0x86, 0x3e, 0xa9, 0xb9, 0xdb, 0x5f, 0xae, 0xce, 0xfe, 0x9d, 0xcb, 0x9a, 0x2a, 0x80, 0x07,
+	0xb5, 0xb7, 0x1e, 0xad, 0xcc, 0xad, 0xfe, 0xa1, 0xe0, 0x7c, 0xbe, 0xde, 0x4f, 0x47, 0x19, 0x43,
+	0x37, 0x7f, 0xfe, 0xd1, 0xfe, 0xaa, 0x5d, 0xc5, 0xb7, 0x82, 0xf3, 0xf4, 0xee, 0xf5, 0x3c, 0xfd,
+	0x6c, 0x02, 0xad, 0x48, 0xdf, 0x1e, 0x56, 0xce, 0xfe, 0x9d, 0xcb, 0xf6, 0xc1, 0xf4, 0xe4, 0x59,
+	0x7d, 0xb0, 0x62, 0x4c, 0x39, 0x4f, 0xef, 0x5e, 0xd7, 0x6c, 0xef, 0x00, 0x8a, 0x81, 0x83, 0x56,
+	0xba, 0x5b, 0xf3, 0xc9, 0x39, 0xb8, 0xc7, 0x41, 0x13, 0xfe, 0xa8, 0x26, 0x65, 0x31, 0x62, 0x90,
+	0xbb, 0x6a, 0x4b, 0x79, 0x2e, 0x39, 0xcf, 0xee, 0xf7, 0xa9, 0x30, 0x9b, 0x81, 0x72, 0x27, 0xb3,
+	0x35, 0x86, 0x9c, 0x67, 0xf7, 0xfb, 0x28, 0xe6, 0x37, 0x97, 0x3f, 0x8d, 0xc3, 0x48, 0xcc, 0xd3,
+	0xe9, 0x91, 0x4f, 0x17, 0xc7, 0x74, 0x36, 0xf3, 0xa5, 0x43, 0x8c, 0xa7, 0xfc, 0x18, 0xb3, 0x69,
+	0x24, 0x58, 0xba, 0x38, 0x5e, 0x62, 0xff, 0x1a, 0x87, 0x44, 0x21, 0xaf, 0x72, 0xce, 0x57, 0x3e,
+	0x65, 0xe4, 0xb8, 0x12, 0x62, 0xda, 0x52, 0x7f, 0x67, 0xbf, 0xfa, 0x77, 0x00, 0x79, 0x55, 0xe7,
+	0x0e, 0xde, 0x0e, 0x00, 0x00,
 }
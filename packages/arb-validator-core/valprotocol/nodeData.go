@@ -38,6 +38,21 @@ const (
 	MaxChildType        ChildType = 3
 )
 
+func (c ChildType) String() string {
+	switch c {
+	case InvalidInboxTopChildType:
+		return "InvalidInboxTop"
+	case InvalidMessagesChildType:
+		return "InvalidMessages"
+	case InvalidExecutionChildType:
+		return "InvalidExecution"
+	case ValidChildType:
+		return "Valid"
+	default:
+		return fmt.Sprintf("ChildType(%d)", uint(c))
+	}
+}
+
 type VMProtoData struct {
 	MachineHash common.Hash
 	InboxTop    common.Hash
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package alerts delivers notifications about assertions an observer has
+// found to be invalid
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// InvalidAssertion describes an assertion which contradicts the observer's
+// own execution of the chain
+type InvalidAssertion struct {
+	Rollup common.Address
+	// Node is the node the assertion claims is valid
+	Node common.Hash
+	// Prev is the node the assertion was made on top of
+	Prev  common.Hash
+	Depth uint64
+	// Reason is the part of the claim which is wrong, which is one of
+	// InvalidInboxTop, InvalidMessages or InvalidExecution
+	Reason          string
+	AssertionTxHash common.Hash
+	Stakers         []common.Address
+	Time            time.Time
+}
+
+type invalidAssertionJSON struct {
+	Rollup          string    `json:"rollup"`
+	Node            string    `json:"node"`
+	Prev            string    `json:"prev"`
+	Depth           uint64    `json:"depth"`
+	Reason          string    `json:"reason"`
+	AssertionTxHash string    `json:"assertionTxHash"`
+	Stakers         []string  `json:"stakers"`
+	Time            time.Time `json:"time"`
+}
+
+func (a *InvalidAssertion) MarshalJSON() ([]byte, error) {
+	stakers := make([]string, 0, len(a.Stakers))
+	for _, staker := range a.Stakers {
+		stakers = append(stakers, staker.Hex())
+	}
+	return json.Marshal(invalidAssertionJSON{
+		Rollup:          a.Rollup.Hex(),
+		Node:            a.Node.String(),
+		Prev:            a.Prev.String(),
+		Depth:           a.Depth,
+		Reason:          a.Reason,
+		AssertionTxHash: a.AssertionTxHash.String(),
+		Stakers:         stakers,
+		Time:            a.Time,
+	})
+}
+
+func (a *InvalidAssertion) String() string {
+	return fmt.Sprintf(
+		"invalid assertion %v at depth %v on %v (%v, tx %v, stakers %v)",
+		a.Node.ShortString(),
+		a.Depth,
+		a.Prev.ShortString(),
+		a.Reason,
+		a.AssertionTxHash.ShortString(),
+		a.Stakers,
+	)
+}
+
+// Sink delivers alerts somewhere an operator will see them
+type Sink interface {
+	SendAlert(alert *InvalidAssertion) error
+}
+
+// LogSink writes alerts to the standard logger
+type LogSink struct{}
+
+func (LogSink) SendAlert(alert *InvalidAssertion) error {
+	log.Println("ALERT:", alert)
+	return nil
+}
+
+// WebhookSink posts each alert as JSON to an HTTP endpoint
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) SendAlert(alert *InvalidAssertion) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %v", resp.Status)
+	}
+	return nil
+}
+
+// FileSink appends each alert to a file as a line of JSON
+type FileSink struct {
+	sync.Mutex
+	Path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{Path: path}
+}
+
+func (s *FileSink) SendAlert(alert *InvalidAssertion) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MultiSink sends each alert to all of its sinks, continuing past sinks
+// which fail
+type MultiSink []Sink
+
+func (ms MultiSink) SendAlert(alert *InvalidAssertion) error {
+	var firstErr error
+	for _, sink := range ms {
+		if err := sink.SendAlert(alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alerts
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func testAlert() *InvalidAssertion {
+	return &InvalidAssertion{
+		Rollup:          common.Address{1},
+		Node:            common.Hash{2},
+		Prev:            common.Hash{3},
+		Depth:           4,
+		Reason:          "InvalidExecution",
+		AssertionTxHash: common.Hash{5},
+		Stakers:         []common.Address{{6}},
+		Time:            time.Unix(1000, 0).UTC(),
+	}
+}
+
+func checkAlertJSON(t *testing.T, data []byte) {
+	t.Helper()
+	var decoded invalidAssertionJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	alert := testAlert()
+	if decoded.Node != alert.Node.String() ||
+		decoded.Rollup != alert.Rollup.Hex() ||
+		decoded.Depth != alert.Depth ||
+		decoded.Reason != alert.Reason ||
+		len(decoded.Stakers) != 1 ||
+		decoded.Stakers[0] != alert.Stakers[0].Hex() {
+		t.Errorf("unexpected alert %+v", decoded)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := NewFileSink(filepath.Join(dir, "alerts.json"))
+	for i := 0; i < 2; i++ {
+		if err := sink.SendAlert(testAlert()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(sink.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		checkAlertJSON(t, scanner.Bytes())
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 alerts but file has %v", lines)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		received <- data
+	}))
+	defer server.Close()
+
+	if err := NewWebhookSink(server.URL).SendAlert(testAlert()); err != nil {
+		t.Fatal(err)
+	}
+	checkAlertJSON(t, <-received)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewWebhookSink(failing.URL).SendAlert(testAlert()); err == nil {
+		t.Error("expected failing webhook to return an error")
+	}
+}
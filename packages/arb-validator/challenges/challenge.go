@@ -74,17 +74,19 @@ func getNextEvent(eventChan <-chan arbbridge.Event) (arbbridge.Event, ChallengeS
 
 func getNextEventWithTimeout(
 	ctx context.Context,
+	clock common.Clock,
 	eventChan <-chan arbbridge.Event,
 	deadline common.TimeTicks,
 	contract arbbridge.Challenge,
 	client arbbridge.ArbClient,
 ) (arbbridge.Event, ChallengeState, error) {
-	ticker := time.NewTicker(common.NewTimeBlocksInt(2).Duration())
+	ticker := clock.NewTicker(common.NewTimeBlocksInt(2).Duration())
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil, 0, errors.New("context cancelled while waiting for event")
-		case <-ticker.C:
+		case <-ticker.Chan():
 			blockId, err := client.CurrentBlockId(ctx)
 			if err != nil {
 				return nil, 0, err
@@ -105,7 +107,7 @@ func getNextEventWithTimeout(
 	}
 }
 
-func getNextEventIfExists(ctx context.Context, eventChan <-chan arbbridge.Event, timeout time.Duration) (bool, arbbridge.Event, ChallengeState, error) {
+func getNextEventIfExists(ctx context.Context, clock common.Clock, eventChan <-chan arbbridge.Event, timeout time.Duration) (bool, arbbridge.Event, ChallengeState, error) {
 	for {
 		select {
 		case event, ok := <-eventChan:
@@ -113,7 +115,7 @@ func getNextEventIfExists(ctx context.Context, eventChan <-chan arbbridge.Event,
 				return false, nil, 0, challengeNoEvents
 			}
 			return false, event, getAfterState(event), nil
-		case <-time.After(timeout):
+		case <-clock.After(timeout):
 			return true, nil, 0, nil
 		case <-ctx.Done():
 			return false, nil, 0, errors.New("context cancelled while waiting for event")
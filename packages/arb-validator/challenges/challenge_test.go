@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/mockbridge"
+)
+
+type timeoutRecorder struct {
+	client   arbbridge.ArbClient
+	timedOut chan *common.BlockId
+}
+
+func (r *timeoutRecorder) TimeoutChallenge(ctx context.Context) error {
+	blockId, err := r.client.CurrentBlockId(ctx)
+	if err != nil {
+		return err
+	}
+	r.timedOut <- blockId
+	return nil
+}
+
+func TestTimeoutAfterDeadline(t *testing.T) {
+	eth := mockbridge.NewMockEth()
+	client := mockbridge.NewEthClient(eth)
+	clock := eth.Clock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start, err := client.CurrentBlockId(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadlineHeight := common.NewTimeBlocks(new(big.Int).Add(start.Height.AsInt(), big.NewInt(10)))
+	contract := &timeoutRecorder{client: client, timedOut: make(chan *common.BlockId, 1)}
+	eventChan := make(chan arbbridge.Event)
+	stateChan := make(chan ChallengeState, 1)
+	go func() {
+		_, state, err := getNextEventWithTimeout(
+			ctx,
+			clock,
+			eventChan,
+			common.TicksFromBlockNum(deadlineHeight),
+			contract,
+			client,
+		)
+		if err != nil {
+			t.Error(err)
+		}
+		stateChan <- state
+	}()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Only mined blocks move the clock, so the challenge can't time out
+	// however long the test takes
+	eth.MineBlocks(9)
+	time.Sleep(time.Millisecond * 10)
+	select {
+	case blockId := <-contract.timedOut:
+		t.Fatalf("timed out at block %v before the deadline", blockId.Height)
+	default:
+	}
+
+	// Ticks which arrive while the last one is handled are dropped, so keep
+	// mining until one is seen
+	var timedOutAt *common.BlockId
+	for i := 0; i < 100 && timedOutAt == nil; i++ {
+		eth.MineBlocks(2)
+		select {
+		case timedOutAt = <-contract.timedOut:
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	if timedOutAt == nil {
+		t.Fatal("challenge didn't time out")
+	}
+	if timedOutAt.Height.Cmp(deadlineHeight) < 0 {
+		t.Errorf("timed out at block %v before the deadline %v", timedOutAt.Height, deadlineHeight)
+	}
+
+	eventChan <- arbbridge.AsserterTimeoutEvent{}
+	if state := <-stateChan; state != ChallengeAsserterTimedOut {
+		t.Errorf("challenge ended in state %v", state)
+	}
+}
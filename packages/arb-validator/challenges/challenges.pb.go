@@ -0,0 +1,528 @@
+//
+// Copyright 2020, Offchain Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.23.0
+// 	protoc        v3.10.1
+// source: challenges.proto
+
+package challenges
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	common "github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	protocol "github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type InboxTopChallengeStateBuf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartState   *common.HashBuf `protobuf:"bytes,1,opt,name=startState,proto3" json:"startState,omitempty"`
+	MessageCount uint64          `protobuf:"varint,2,opt,name=messageCount,proto3" json:"messageCount,omitempty"`
+}
+
+func (x *InboxTopChallengeStateBuf) Reset() {
+	*x = InboxTopChallengeStateBuf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_challenges_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InboxTopChallengeStateBuf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboxTopChallengeStateBuf) ProtoMessage() {}
+
+func (x *InboxTopChallengeStateBuf) ProtoReflect() protoreflect.Message {
+	mi := &file_challenges_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboxTopChallengeStateBuf.ProtoReflect.Descriptor instead.
+func (*InboxTopChallengeStateBuf) Descriptor() ([]byte, []int) {
+	return file_challenges_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *InboxTopChallengeStateBuf) GetStartState() *common.HashBuf {
+	if x != nil {
+		return x.StartState
+	}
+	return nil
+}
+
+func (x *InboxTopChallengeStateBuf) GetMessageCount() uint64 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+type MessagesChallengeStateBuf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartInbox      *common.HashBuf `protobuf:"bytes,1,opt,name=startInbox,proto3" json:"startInbox,omitempty"`
+	StartMessages   *common.HashBuf `protobuf:"bytes,2,opt,name=startMessages,proto3" json:"startMessages,omitempty"`
+	InboxStartCount uint64          `protobuf:"varint,3,opt,name=inboxStartCount,proto3" json:"inboxStartCount,omitempty"`
+	MessageCount    uint64          `protobuf:"varint,4,opt,name=messageCount,proto3" json:"messageCount,omitempty"`
+}
+
+func (x *MessagesChallengeStateBuf) Reset() {
+	*x = MessagesChallengeStateBuf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_challenges_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MessagesChallengeStateBuf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessagesChallengeStateBuf) ProtoMessage() {}
+
+func (x *MessagesChallengeStateBuf) ProtoReflect() protoreflect.Message {
+	mi := &file_challenges_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessagesChallengeStateBuf.ProtoReflect.Descriptor instead.
+func (*MessagesChallengeStateBuf) Descriptor() ([]byte, []int) {
+	return file_challenges_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MessagesChallengeStateBuf) GetStartInbox() *common.HashBuf {
+	if x != nil {
+		return x.StartInbox
+	}
+	return nil
+}
+
+func (x *MessagesChallengeStateBuf) GetStartMessages() *common.HashBuf {
+	if x != nil {
+		return x.StartMessages
+	}
+	return nil
+}
+
+func (x *MessagesChallengeStateBuf) GetInboxStartCount() uint64 {
+	if x != nil {
+		return x.InboxStartCount
+	}
+	return 0
+}
+
+func (x *MessagesChallengeStateBuf) GetMessageCount() uint64 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+type ExecutionChallengeStateBuf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MachineHash     *common.HashBuf               `protobuf:"bytes,1,opt,name=machineHash,proto3" json:"machineHash,omitempty"`
+	BeforeHash      *common.HashBuf               `protobuf:"bytes,2,opt,name=beforeHash,proto3" json:"beforeHash,omitempty"`
+	TimeBounds      *protocol.TimeBoundsBlocksBuf `protobuf:"bytes,3,opt,name=timeBounds,proto3" json:"timeBounds,omitempty"`
+	BeforeInboxHash *common.HashBuf               `protobuf:"bytes,4,opt,name=beforeInboxHash,proto3" json:"beforeInboxHash,omitempty"`
+	NumSteps        uint64                        `protobuf:"varint,5,opt,name=numSteps,proto3" json:"numSteps,omitempty"`
+}
+
+func (x *ExecutionChallengeStateBuf) Reset() {
+	*x = ExecutionChallengeStateBuf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_challenges_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecutionChallengeStateBuf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionChallengeStateBuf) ProtoMessage() {}
+
+func (x *ExecutionChallengeStateBuf) ProtoReflect() protoreflect.Message {
+	mi := &file_challenges_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionChallengeStateBuf.ProtoReflect.Descriptor instead.
+func (*ExecutionChallengeStateBuf) Descriptor() ([]byte, []int) {
+	return file_challenges_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExecutionChallengeStateBuf) GetMachineHash() *common.HashBuf {
+	if x != nil {
+		return x.MachineHash
+	}
+	return nil
+}
+
+func (x *ExecutionChallengeStateBuf) GetBeforeHash() *common.HashBuf {
+	if x != nil {
+		return x.BeforeHash
+	}
+	return nil
+}
+
+func (x *ExecutionChallengeStateBuf) GetTimeBounds() *protocol.TimeBoundsBlocksBuf {
+	if x != nil {
+		return x.TimeBounds
+	}
+	return nil
+}
+
+func (x *ExecutionChallengeStateBuf) GetBeforeInboxHash() *common.HashBuf {
+	if x != nil {
+		return x.BeforeInboxHash
+	}
+	return nil
+}
+
+func (x *ExecutionChallengeStateBuf) GetNumSteps() uint64 {
+	if x != nil {
+		return x.NumSteps
+	}
+	return 0
+}
+
+type ChallengeCheckpointBuf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId   *common.BlockIdBuf          `protobuf:"bytes,1,opt,name=blockId,proto3" json:"blockId,omitempty"`
+	LogIndex  uint64                      `protobuf:"varint,2,opt,name=logIndex,proto3" json:"logIndex,omitempty"`
+	Deadline  *common.TimeTicksBuf        `protobuf:"bytes,3,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	InboxTop  *InboxTopChallengeStateBuf  `protobuf:"bytes,4,opt,name=inboxTop,proto3" json:"inboxTop,omitempty"`
+	Messages  *MessagesChallengeStateBuf  `protobuf:"bytes,5,opt,name=messages,proto3" json:"messages,omitempty"`
+	Execution *ExecutionChallengeStateBuf `protobuf:"bytes,6,opt,name=execution,proto3" json:"execution,omitempty"`
+}
+
+func (x *ChallengeCheckpointBuf) Reset() {
+	*x = ChallengeCheckpointBuf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_challenges_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChallengeCheckpointBuf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChallengeCheckpointBuf) ProtoMessage() {}
+
+func (x *ChallengeCheckpointBuf) ProtoReflect() protoreflect.Message {
+	mi := &file_challenges_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChallengeCheckpointBuf.ProtoReflect.Descriptor instead.
+func (*ChallengeCheckpointBuf) Descriptor() ([]byte, []int) {
+	return file_challenges_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChallengeCheckpointBuf) GetBlockId() *common.BlockIdBuf {
+	if x != nil {
+		return x.BlockId
+	}
+	return nil
+}
+
+func (x *ChallengeCheckpointBuf) GetLogIndex() uint64 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *ChallengeCheckpointBuf) GetDeadline() *common.TimeTicksBuf {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *ChallengeCheckpointBuf) GetInboxTop() *InboxTopChallengeStateBuf {
+	if x != nil {
+		return x.InboxTop
+	}
+	return nil
+}
+
+func (x *ChallengeCheckpointBuf) GetMessages() *MessagesChallengeStateBuf {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ChallengeCheckpointBuf) GetExecution() *ExecutionChallengeStateBuf {
+	if x != nil {
+		return x.Execution
+	}
+	return nil
+}
+
+var File_challenges_proto protoreflect.FileDescriptor
+
+var file_challenges_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0a, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x73, 0x1a, 0x13,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x70, 0x0a, 0x19,
+	0x49, 0x6e, 0x62, 0x6f, 0x78, 0x54, 0x6f, 0x70, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67,
+	0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x75, 0x66, 0x12, 0x2f, 0x0a, 0x0a, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0a,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xd1,
+	0x01, 0x0a, 0x19, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x43, 0x68, 0x61, 0x6c, 0x6c,
+	0x65, 0x6e, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x75, 0x66, 0x12, 0x2f, 0x0a, 0x0a,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42, 0x75,
+	0x66, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x12, 0x35, 0x0a,
+	0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61,
+	0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x69, 0x6e, 0x62, 0x6f, 0x78, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x69,
+	0x6e, 0x62, 0x6f, 0x78, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22,
+	0x0a, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x22, 0x96, 0x02, 0x0a, 0x1a, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x75,
+	0x66, 0x12, 0x31, 0x0a, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x48, 0x61, 0x73, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x48, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
+	0x48, 0x61, 0x73, 0x68, 0x12, 0x2f, 0x0a, 0x0a, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x48, 0x61,
+	0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0a, 0x62, 0x65, 0x66, 0x6f, 0x72,
+	0x65, 0x48, 0x61, 0x73, 0x68, 0x12, 0x3d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x6f, 0x75,
+	0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x42, 0x75, 0x66, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x6f,
+	0x75, 0x6e, 0x64, 0x73, 0x12, 0x39, 0x0a, 0x0f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x49, 0x6e,
+	0x62, 0x6f, 0x78, 0x48, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0f,
+	0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d, 0x53, 0x74, 0x65, 0x70, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x53, 0x74, 0x65, 0x70, 0x73, 0x22, 0xe0, 0x02, 0x0a, 0x16,
+	0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x42, 0x75, 0x66, 0x12, 0x2c, 0x0a, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x42, 0x75, 0x66, 0x52, 0x07, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6c, 0x6f, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x30, 0x0a, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x54, 0x69, 0x63, 0x6b, 0x73, 0x42, 0x75, 0x66, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x41, 0x0a, 0x08, 0x69, 0x6e, 0x62, 0x6f, 0x78, 0x54, 0x6f, 0x70, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65,
+	0x73, 0x2e, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x54, 0x6f, 0x70, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65,
+	0x6e, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x75, 0x66, 0x52, 0x08, 0x69, 0x6e, 0x62,
+	0x6f, 0x78, 0x54, 0x6f, 0x70, 0x12, 0x41, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65,
+	0x6e, 0x67, 0x65, 0x73, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x43, 0x68, 0x61,
+	0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x75, 0x66, 0x52, 0x08,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x44, 0x0a, 0x09, 0x65, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x63, 0x68,
+	0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x42, 0x75, 0x66, 0x52, 0x09, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x44,
+	0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x66, 0x66,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x61, 0x72, 0x62, 0x69, 0x74, 0x72,
+	0x75, 0x6d, 0x2f, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x61, 0x72, 0x62, 0x2d,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65,
+	0x6e, 0x67, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_challenges_proto_rawDescOnce sync.Once
+	file_challenges_proto_rawDescData = file_challenges_proto_rawDesc
+)
+
+func file_challenges_proto_rawDescGZIP() []byte {
+	file_challenges_proto_rawDescOnce.Do(func() {
+		file_challenges_proto_rawDescData = protoimpl.X.CompressGZIP(file_challenges_proto_rawDescData)
+	})
+	return file_challenges_proto_rawDescData
+}
+
+var file_challenges_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_challenges_proto_goTypes = []interface{}{
+	(*InboxTopChallengeStateBuf)(nil),    // 0: challenges.InboxTopChallengeStateBuf
+	(*MessagesChallengeStateBuf)(nil),    // 1: challenges.MessagesChallengeStateBuf
+	(*ExecutionChallengeStateBuf)(nil),   // 2: challenges.ExecutionChallengeStateBuf
+	(*ChallengeCheckpointBuf)(nil),       // 3: challenges.ChallengeCheckpointBuf
+	(*common.HashBuf)(nil),               // 4: common.HashBuf
+	(*protocol.TimeBoundsBlocksBuf)(nil), // 5: protocol.TimeBoundsBlocksBuf
+	(*common.BlockIdBuf)(nil),            // 6: common.BlockIdBuf
+	(*common.TimeTicksBuf)(nil),          // 7: common.TimeTicksBuf
+}
+var file_challenges_proto_depIdxs = []int32{
+	4,  // 0: challenges.InboxTopChallengeStateBuf.startState:type_name -> common.HashBuf
+	4,  // 1: challenges.MessagesChallengeStateBuf.startInbox:type_name -> common.HashBuf
+	4,  // 2: challenges.MessagesChallengeStateBuf.startMessages:type_name -> common.HashBuf
+	4,  // 3: challenges.ExecutionChallengeStateBuf.machineHash:type_name -> common.HashBuf
+	4,  // 4: challenges.ExecutionChallengeStateBuf.beforeHash:type_name -> common.HashBuf
+	5,  // 5: challenges.ExecutionChallengeStateBuf.timeBounds:type_name -> protocol.TimeBoundsBlocksBuf
+	4,  // 6: challenges.ExecutionChallengeStateBuf.beforeInboxHash:type_name -> common.HashBuf
+	6,  // 7: challenges.ChallengeCheckpointBuf.blockId:type_name -> common.BlockIdBuf
+	7,  // 8: challenges.ChallengeCheckpointBuf.deadline:type_name -> common.TimeTicksBuf
+	0,  // 9: challenges.ChallengeCheckpointBuf.inboxTop:type_name -> challenges.InboxTopChallengeStateBuf
+	1,  // 10: challenges.ChallengeCheckpointBuf.messages:type_name -> challenges.MessagesChallengeStateBuf
+	2,  // 11: challenges.ChallengeCheckpointBuf.execution:type_name -> challenges.ExecutionChallengeStateBuf
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_challenges_proto_init() }
+func file_challenges_proto_init() {
+	if File_challenges_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_challenges_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InboxTopChallengeStateBuf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_challenges_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MessagesChallengeStateBuf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_challenges_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecutionChallengeStateBuf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_challenges_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChallengeCheckpointBuf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_challenges_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_challenges_proto_goTypes,
+		DependencyIndexes: file_challenges_proto_depIdxs,
+		MessageInfos:      file_challenges_proto_msgTypes,
+	}.Build()
+	File_challenges_proto = out.File
+	file_challenges_proto_rawDesc = nil
+	file_challenges_proto_goTypes = nil
+	file_challenges_proto_depIdxs = nil
+}
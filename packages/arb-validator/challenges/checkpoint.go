@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+)
+
+var errWrongChallengeCheckpoint = errors.New("checkpoint is for a different kind of challenge")
+
+// challengeCheckpointer saves the progress of a challenge every time it
+// moves on to a new segment, so that a restarted validator can resume the
+// challenge from its latest segment rather than replaying it from the start.
+// A nil checkpointer disables checkpointing
+type challengeCheckpointer struct {
+	cp      checkpointing.ChallengeCheckpointer
+	address common.Address
+	name    string
+
+	// resumed is set if the challenge was restored from a checkpoint, in
+	// which case the event initiating the challenge has already been handled
+	resumed  bool
+	deadline common.TimeTicks
+}
+
+func newChallengeCheckpointer(cp checkpointing.ChallengeCheckpointer, address common.Address, name string) *challengeCheckpointer {
+	return &challengeCheckpointer{cp: cp, address: address, name: name}
+}
+
+// restore loads the latest checkpoint of the challenge and passes it to
+// unmarshalFunc. It returns the position in the event log to watch the
+// challenge from, which is just after the last event handled before the
+// checkpoint if there was one and the given start otherwise
+func (c *challengeCheckpointer) restore(
+	ctx context.Context,
+	client arbbridge.ChainTimeGetter,
+	startBlockId *common.BlockId,
+	startLogIndex uint,
+	unmarshalFunc func(*ChallengeCheckpointBuf, checkpointing.RestoreContext) error,
+) (*common.BlockId, uint) {
+	if c.cp == nil {
+		return startBlockId, startLogIndex
+	}
+	var buf *ChallengeCheckpointBuf
+	err := c.cp.RestoreChallengeState(c.address, func(contents []byte, restoreCtx checkpointing.RestoreContext) error {
+		buf = &ChallengeCheckpointBuf{}
+		if err := proto.Unmarshal(contents, buf); err != nil {
+			return err
+		}
+		blockId := buf.BlockId.Unmarshal()
+		onchainId, err := client.BlockIdForHeight(ctx, blockId.Height)
+		if err != nil {
+			return err
+		}
+		if !onchainId.Equals(blockId) {
+			return fmt.Errorf("checkpointed block %v was reorged out", blockId)
+		}
+		return unmarshalFunc(buf, restoreCtx)
+	})
+	if err != nil {
+		// Without a usable checkpoint the challenge is replayed from its
+		// start as before
+		return startBlockId, startLogIndex
+	}
+	log.Println("Resuming", c.name, "from checkpoint")
+	c.resumed = true
+	c.deadline = buf.Deadline.Unmarshal()
+	return buf.BlockId.Unmarshal(), uint(buf.LogIndex) + 1
+}
+
+// initiate returns the deadline of the first move of the challenge, waiting
+// for the event initiating the challenge unless it was restored from a
+// checkpoint
+func (c *challengeCheckpointer) initiate(eventChan <-chan arbbridge.Event) (common.TimeTicks, error) {
+	if c.resumed {
+		return c.deadline, nil
+	}
+	event, ok := <-eventChan
+	if !ok {
+		return common.TimeTicks{}, challengeNoEvents
+	}
+	ev, ok := event.(arbbridge.InitiateChallengeEvent)
+	if !ok {
+		return common.TimeTicks{}, fmt.Errorf("%v expected InitiateChallengeEvent but got %T", c.name, event)
+	}
+	return ev.Deadline, nil
+}
+
+// save checkpoints the state of the challenge after handling ev.
+// marshalFunc fills in the state specific to the kind of challenge. Failing
+// to save a checkpoint doesn't stop the challenge, so errors are only logged
+func (c *challengeCheckpointer) save(
+	ev arbbridge.ContinueChallengeEvent,
+	marshalFunc func(*checkpointing.CheckpointContext, *ChallengeCheckpointBuf),
+) {
+	if c.cp == nil {
+		return
+	}
+	ckpCtx := checkpointing.NewCheckpointContext()
+	buf := &ChallengeCheckpointBuf{
+		BlockId:  ev.BlockId.MarshalToBuf(),
+		LogIndex: uint64(ev.LogIndex),
+		Deadline: ev.Deadline.MarshalToBuf(),
+	}
+	if marshalFunc != nil {
+		marshalFunc(ckpCtx, buf)
+	}
+	contents, err := proto.Marshal(buf)
+	if err == nil {
+		err = c.cp.SaveChallengeState(c.address, contents, ckpCtx)
+	}
+	if err != nil {
+		log.Println("Failed to checkpoint", c.name, err)
+	}
+}
+
+// finish deletes the checkpoint of the challenge once it has been decided.
+// Challenges which stopped because of an error keep their checkpoint so
+// they can be resumed
+func (c *challengeCheckpointer) finish(state ChallengeState, err error) (ChallengeState, error) {
+	if c.cp != nil && err == nil && state != ChallengeContinuing {
+		if err := c.cp.DeleteChallengeState(c.address); err != nil {
+			log.Println("Failed to delete checkpoint of", c.name, err)
+		}
+	}
+	return state, err
+}
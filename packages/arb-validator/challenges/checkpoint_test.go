@@ -0,0 +1,153 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+)
+
+type memoryChallengeCheckpointer struct {
+	contents map[common.Address][]byte
+	contexts map[common.Address]*checkpointing.CheckpointContext
+}
+
+func newMemoryChallengeCheckpointer() *memoryChallengeCheckpointer {
+	return &memoryChallengeCheckpointer{
+		contents: make(map[common.Address][]byte),
+		contexts: make(map[common.Address]*checkpointing.CheckpointContext),
+	}
+}
+
+func (m *memoryChallengeCheckpointer) SaveChallengeState(challenge common.Address, contents []byte, cpCtx *checkpointing.CheckpointContext) error {
+	m.contents[challenge] = contents
+	m.contexts[challenge] = cpCtx
+	return nil
+}
+
+func (m *memoryChallengeCheckpointer) RestoreChallengeState(challenge common.Address, unmarshalFunc func([]byte, checkpointing.RestoreContext) error) error {
+	contents, ok := m.contents[challenge]
+	if !ok {
+		return errors.New("no checkpoint")
+	}
+	return unmarshalFunc(contents, m.contexts[challenge])
+}
+
+func (m *memoryChallengeCheckpointer) DeleteChallengeState(challenge common.Address) error {
+	delete(m.contents, challenge)
+	delete(m.contexts, challenge)
+	return nil
+}
+
+type testChain map[uint64]common.Hash
+
+func (c testChain) CurrentBlockId(ctx context.Context) (*common.BlockId, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c testChain) BlockIdForHeight(ctx context.Context, height *common.TimeBlocks) (*common.BlockId, error) {
+	return &common.BlockId{Height: height, HeaderHash: c[height.AsInt().Uint64()]}, nil
+}
+
+func TestChallengeCheckpointer(t *testing.T) {
+	mach, pre, numSteps := traceTestMachine(t)
+	defender := NewAssertionDefender(pre, numSteps, mach).Segment(numSteps/2, numSteps/4)
+
+	address := common.Address{1}
+	chain := testChain{10: common.Hash{10}}
+	ev := arbbridge.ContinueChallengeEvent{
+		ChainInfo: arbbridge.ChainInfo{
+			BlockId:  &common.BlockId{Height: common.NewTimeBlocks(big.NewInt(10)), HeaderHash: common.Hash{10}},
+			LogIndex: 3,
+		},
+		SegmentIndex: big.NewInt(1),
+		Deadline:     common.TimeTicks{Val: big.NewInt(500)},
+	}
+
+	cp := newMemoryChallengeCheckpointer()
+	ccp := newChallengeCheckpointer(cp, address, "test challenge")
+	ccp.save(ev, func(ckpCtx *checkpointing.CheckpointContext, buf *ChallengeCheckpointBuf) {
+		buf.Execution = marshalExecutionStateForCheckpoint(
+			ckpCtx,
+			defender.GetPrecondition(),
+			defender.NumSteps(),
+			defender.GetMachineState(),
+		)
+	})
+
+	startBlockId := &common.BlockId{Height: common.NewTimeBlocks(big.NewInt(2)), HeaderHash: common.Hash{2}}
+	restore := func(ccp *challengeCheckpointer) (*common.BlockId, uint, uint64) {
+		var restoredSteps uint64
+		blockId, logIndex := ccp.restore(context.Background(), chain, startBlockId, 0, func(buf *ChallengeCheckpointBuf, restoreCtx checkpointing.RestoreContext) error {
+			if buf.Execution == nil {
+				return errWrongChallengeCheckpoint
+			}
+			restoredPre, steps, restoredMach, err := buf.Execution.unmarshalFromCheckpoint(restoreCtx)
+			if err != nil {
+				return err
+			}
+			if !restoredPre.Equals(defender.GetPrecondition()) {
+				t.Error("restored the wrong precondition")
+			}
+			if restoredMach.Hash() != defender.GetMachineState().Hash() {
+				t.Error("restored the wrong machine")
+			}
+			restoredSteps = steps
+			return nil
+		})
+		return blockId, logIndex, restoredSteps
+	}
+
+	resumed := newChallengeCheckpointer(cp, address, "test challenge")
+	blockId, logIndex, steps := restore(resumed)
+	if !resumed.resumed || !blockId.Equals(ev.BlockId) || logIndex != ev.LogIndex+1 {
+		t.Errorf("resumed from block %v log %v instead of after the checkpointed event", blockId, logIndex)
+	}
+	if steps != defender.NumSteps() {
+		t.Errorf("restored %v steps instead of %v", steps, defender.NumSteps())
+	}
+	deadline, err := resumed.initiate(nil)
+	if err != nil || deadline.Cmp(ev.Deadline) != 0 {
+		t.Errorf("resumed challenge has deadline %v instead of %v", deadline, ev.Deadline)
+	}
+
+	// A checkpoint in a block which is no longer part of the chain is ignored
+	chain[10] = common.Hash{11}
+	reorged := newChallengeCheckpointer(cp, address, "test challenge")
+	blockId, logIndex, _ = restore(reorged)
+	if reorged.resumed || blockId != startBlockId || logIndex != 0 {
+		t.Error("resumed from a reorged checkpoint")
+	}
+	chain[10] = common.Hash{10}
+
+	// The checkpoint is kept while the challenge continues and deleted once
+	// it has been decided
+	ccp.finish(ChallengeContinuing, nil)
+	if _, ok := cp.contents[address]; !ok {
+		t.Error("checkpoint deleted before the challenge finished")
+	}
+	ccp.finish(ChallengeAsserterWon, nil)
+	if _, ok := cp.contents[address]; ok {
+		t.Error("checkpoint kept after the challenge finished")
+	}
+}
@@ -20,18 +20,22 @@ import (
 	"errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
-	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 )
 
+// AssertionDefender defends an assertion of numSteps steps starting at
+// step start of an execution trace. Defenders of bisected segments share
+// the trace of the assertion they were bisected from
 type AssertionDefender struct {
 	precondition *valprotocol.Precondition
 	numSteps     uint64
-	initState    machine.Machine
+	trace        *ExecutionTrace
+	start        uint64
 }
 
 func NewAssertionDefender(precondition *valprotocol.Precondition, numSteps uint64, initState machine.Machine) AssertionDefender {
-	return AssertionDefender{precondition, numSteps, initState.Clone()}
+	trace := NewExecutionTrace(precondition, initState, traceInterval(numSteps))
+	return AssertionDefender{precondition, numSteps, trace, 0}
 }
 
 func (ad AssertionDefender) NumSteps() uint64 {
@@ -42,8 +46,11 @@ func (ad AssertionDefender) GetPrecondition() *valprotocol.Precondition {
 	return ad.precondition
 }
 
+// GetMachineState returns a copy of the machine at the start of the
+// assertion
 func (ad AssertionDefender) GetMachineState() machine.Machine {
-	return ad.initState
+	mach, _ := ad.trace.StateAt(ad.start)
+	return mach
 }
 
 func (ad AssertionDefender) NBisect(slices uint64) ([]AssertionDefender, []*valprotocol.ExecutionAssertionStub) {
@@ -53,56 +60,62 @@ func (ad AssertionDefender) NBisect(slices uint64) ([]AssertionDefender, []*valp
 	}
 	defenders := make([]AssertionDefender, 0, slices)
 	assertions := make([]*valprotocol.ExecutionAssertionStub, 0, slices)
-	m := ad.initState.Clone()
 
 	pre := ad.precondition
+	start := ad.start
 	for i := uint64(0); i < slices; i++ {
 		steps := valprotocol.CalculateBisectionStepCount(i, slices, nsteps)
-		initState := m.Clone()
-
-		assertion, numSteps := m.ExecuteAssertion(
-			steps,
-			pre.TimeBounds,
-			pre.BeforeInbox.(value.TupleValue),
-			0,
-		)
-		defenders = append(defenders, NewAssertionDefender(
-			pre,
-			numSteps,
-			initState,
-		))
+		assertion, numSteps := ad.trace.Execute(start, steps)
+		defenders = append(defenders, AssertionDefender{
+			precondition: pre,
+			numSteps:     numSteps,
+			trace:        ad.trace,
+			start:        start,
+		})
 		stub := valprotocol.NewExecutionAssertionStubFromAssertion(assertion)
 		assertions = append(assertions, stub)
 		pre = pre.GeneratePostcondition(stub)
+		start += numSteps
 	}
 	return defenders, assertions
 }
 
+// Segment returns the defender of the numSteps steps beginning offset steps
+// into the assertion
+func (ad AssertionDefender) Segment(offset uint64, numSteps uint64) AssertionDefender {
+	_, pre := ad.trace.StateAt(ad.start + offset)
+	return AssertionDefender{
+		precondition: pre,
+		numSteps:     numSteps,
+		trace:        ad.trace,
+		start:        ad.start + offset,
+	}
+}
+
+// discardOtherSegments frees the snapshots of the trace outside of the
+// assertion once it's the only segment still being defended
+func (ad AssertionDefender) discardOtherSegments() {
+	ad.trace.restrict(ad.start, ad.start+ad.numSteps)
+}
+
 func (ad AssertionDefender) SolidityOneStepProof() ([]byte, error) {
-	return ad.initState.MarshalForProof()
+	return ad.GetMachineState().MarshalForProof()
 }
 
+// ChooseAssertionToChallenge returns the index of the first assertion of
+// the bisection which doesn't match the execution of m along with the
+// machine at the start of it
 func ChooseAssertionToChallenge(
 	m machine.Machine,
 	pre *valprotocol.Precondition,
 	assertions []*valprotocol.ExecutionAssertionStub,
 	totalSteps uint64,
 ) (uint16, machine.Machine, error) {
-	assertionCount := uint64(len(assertions))
-	for i := range assertions {
-		steps := valprotocol.CalculateBisectionStepCount(uint64(i), assertionCount, totalSteps)
-		initState := m.Clone()
-		generatedAssertion, numSteps := m.ExecuteAssertion(
-			steps,
-			pre.TimeBounds,
-			pre.BeforeInbox.(value.TupleValue),
-			0,
-		)
-		stub := valprotocol.NewExecutionAssertionStubFromAssertion(generatedAssertion)
-		if uint64(numSteps) != steps || !stub.Equals(assertions[i]) {
-			return uint16(i), initState, nil
-		}
-		pre = pre.GeneratePostcondition(stub)
+	trace := NewExecutionTrace(pre, m, traceInterval(totalSteps))
+	index, start, ok := trace.chooseSegment(0, assertions, totalSteps)
+	if !ok {
+		return 0, nil, errors.New("all segments in false ExecutionAssertion are valid")
 	}
-	return 0, nil, errors.New("all segments in false ExecutionAssertion are valid")
+	mach, _ := trace.StateAt(start)
+	return index, mach, nil
 }
@@ -18,6 +18,7 @@ package challenges
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -27,6 +28,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
 )
 
 func DefendExecutionClaim(
@@ -39,12 +41,27 @@ func DefendExecutionClaim(
 	startMachine machine.Machine,
 	numSteps uint64,
 	bisectionCount uint32,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewExecutionChallengeWatcher(address)
 	if err != nil {
 		return 0, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "ExecutionChallenge defender")
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(buf *ChallengeCheckpointBuf, restoreCtx checkpointing.RestoreContext) error {
+		if buf.Execution == nil {
+			return errWrongChallengeCheckpoint
+		}
+		pre, steps, mach, err := buf.Execution.unmarshalFromCheckpoint(restoreCtx)
+		if err != nil {
+			return err
+		}
+		precondition, numSteps, startMachine = pre, steps, mach
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewExecutionChallenge(address)
@@ -55,18 +72,20 @@ func DefendExecutionClaim(
 	if startMachine == nil {
 		log.Fatal("nil startMachine in DefendExecutionClaim")
 	}
-	return defendExecution(
+	return ccp.finish(defendExecution(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		NewAssertionDefender(
 			precondition,
 			numSteps,
 			startMachine,
 		),
 		bisectionCount,
-	)
+	))
 }
 
 func ChallengeExecutionClaim(
@@ -78,12 +97,27 @@ func ChallengeExecutionClaim(
 	startPrecondition *valprotocol.Precondition,
 	startMachine machine.Machine,
 	challengeEverything bool,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewExecutionChallengeWatcher(address)
 	if err != nil {
 		return 0, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "ExecutionChallenge challenger")
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(buf *ChallengeCheckpointBuf, restoreCtx checkpointing.RestoreContext) error {
+		if buf.Execution == nil {
+			return errWrongChallengeCheckpoint
+		}
+		pre, _, mach, err := buf.Execution.unmarshalFromCheckpoint(restoreCtx)
+		if err != nil {
+			return err
+		}
+		startPrecondition, startMachine = pre, mach
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewExecutionChallenge(address)
@@ -91,15 +125,17 @@ func ChallengeExecutionClaim(
 		return 0, err
 	}
 
-	return challengeExecution(
+	return ccp.finish(challengeExecution(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		startMachine,
 		startPrecondition,
 		challengeEverything,
-	)
+	))
 }
 
 func defendExecution(
@@ -107,23 +143,20 @@ func defendExecution(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.ExecutionChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	startDefender AssertionDefender,
 	bisectionCount uint32,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	_, ok = event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("ExecutionChallenge expected InitiateChallengeEvent but got %T", event)
+	if _, err := ccp.initiate(eventChan); err != nil {
+		return 0, err
 	}
 
 	defender := startDefender
 
 	for {
 		if defender.NumSteps() == 1 {
-			timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+			timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 			if timedOut {
 				proof, err := defender.SolidityOneStepProof()
 				if err != nil {
@@ -151,13 +184,13 @@ func defendExecution(
 			if err != nil || state != ChallengeContinuing {
 				return state, err
 			}
-			_, ok = event.(arbbridge.OneStepProofEvent)
+			_, ok := event.(arbbridge.OneStepProofEvent)
 			if !ok {
 				return 0, fmt.Errorf("ExecutionChallenge defender expected OneStepProof but got %T", event)
 			}
 			return ChallengeAsserterWon, nil
 		}
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		var defenders []AssertionDefender = nil
 		if timedOut {
 			var assertions []*valprotocol.ExecutionAssertionStub
@@ -179,6 +212,7 @@ func defendExecution(
 
 		event, state, err = getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			ev.Deadline,
 			contract,
@@ -192,30 +226,26 @@ func defendExecution(
 			return 0, fmt.Errorf("ExecutionChallenge defender expected ContinueChallengeEvent but got %T", event)
 		}
 
+		segmentCount := uint64(len(ev.Assertions))
 		if timedOut {
 			// Freshly bisected assertion
 			defender = defenders[contEv.SegmentIndex.Uint64()]
 		} else {
 			// Replayed from existing event
-			totalSteps := uint64(0)
-			for i := uint64(0); i < contEv.SegmentIndex.Uint64(); i++ {
-				totalSteps += valprotocol.CalculateBisectionStepCount(i, uint64(len(ev.Assertions)), ev.TotalSteps)
-			}
-
-			mach := defender.initState
-			pre := defender.precondition
-			// Update mach, precondition, deadline
-			assertion, _ := mach.ExecuteAssertion(
-				totalSteps,
-				pre.TimeBounds,
-				pre.BeforeInbox.(value.TupleValue),
-				0,
+			defender = defender.Segment(
+				segmentStart(0, contEv.SegmentIndex.Uint64(), segmentCount, ev.TotalSteps),
+				valprotocol.CalculateBisectionStepCount(contEv.SegmentIndex.Uint64(), segmentCount, ev.TotalSteps),
 			)
-			pre = pre.GeneratePostcondition(valprotocol.NewExecutionAssertionStubFromAssertion(assertion))
-
-			steps := valprotocol.CalculateBisectionStepCount(contEv.SegmentIndex.Uint64(), uint64(len(ev.Assertions)), ev.TotalSteps)
-			defender = NewAssertionDefender(pre, steps, mach)
 		}
+		defender.discardOtherSegments()
+		ccp.save(contEv, func(ckpCtx *checkpointing.CheckpointContext, buf *ChallengeCheckpointBuf) {
+			buf.Execution = marshalExecutionStateForCheckpoint(
+				ckpCtx,
+				defender.GetPrecondition(),
+				defender.NumSteps(),
+				defender.GetMachineState(),
+			)
+		})
 	}
 }
 
@@ -224,25 +254,26 @@ func challengeExecution(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.ExecutionChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	startMachine machine.Machine,
 	startPrecondition *valprotocol.Precondition,
 	challengeEverything bool,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	ev, ok := event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("ExecutionChallenge challenger expected InitiateChallengeEvent but got %T", event)
+	deadline, err := ccp.initiate(eventChan)
+	if err != nil {
+		return 0, err
 	}
 
-	mach := startMachine
+	// The trace is created once the length of the execution is known from
+	// the first bisection
+	var trace *ExecutionTrace
+	start := uint64(0)
 	precondition := startPrecondition
-	deadline := ev.Deadline
 	for {
 		event, state, err := getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			deadline,
 			contract,
@@ -260,31 +291,19 @@ func challengeExecution(
 		if !ok {
 			return 0, fmt.Errorf("ExecutionChallenge challenger expected ExecutionBisectionEvent but got %T", event)
 		}
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		if trace == nil {
+			trace = NewExecutionTrace(startPrecondition, startMachine, traceInterval(ev.TotalSteps))
+		}
+		segmentCount := uint64(len(ev.Assertions))
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		var preconditions []*valprotocol.Precondition
-		var m machine.Machine
 		if timedOut {
-			var challengedAssertionNum uint16
-			challengedAssertionNum, m, err = ChooseAssertionToChallenge(mach.Clone(), precondition, ev.Assertions, ev.TotalSteps)
-			if err != nil && challengeEverything {
-				pre := precondition
-				cMach := mach.Clone()
-				challengedAssertionNum = uint16(rand.Int31n(int32(len(ev.Assertions))))
-				for i := 0; i < len(ev.Assertions); i++ {
-					stepCount := valprotocol.CalculateBisectionStepCount(uint64(i), uint64(len(ev.Assertions)), ev.TotalSteps)
-					m = cMach.Clone()
-					assertion, _ := cMach.ExecuteAssertion(
-						stepCount,
-						pre.TimeBounds,
-						pre.BeforeInbox.(value.TupleValue),
-						0,
-					)
-					pre = pre.GeneratePostcondition(valprotocol.NewExecutionAssertionStubFromAssertion(assertion))
+			challengedAssertionNum, _, found := trace.chooseSegment(start, ev.Assertions, ev.TotalSteps)
+			if !found {
+				if !challengeEverything {
+					return 0, errors.New("all segments in false ExecutionAssertion are valid")
 				}
-				err = nil
-			}
-			if err != nil {
-				return 0, err
+				challengedAssertionNum = uint16(rand.Int31n(int32(segmentCount)))
 			}
 			preconditions = valprotocol.GeneratePreconditions(precondition, ev.Assertions)
 			err = contract.ChooseSegment(
@@ -294,6 +313,9 @@ func challengeExecution(
 				ev.Assertions,
 				ev.TotalSteps,
 			)
+			if err != nil {
+				return 0, err
+			}
 			event, state, err = getNextEvent(eventChan)
 		}
 
@@ -305,25 +327,21 @@ func challengeExecution(
 			return 0, fmt.Errorf("ExecutionChallenge challenger expected ContinueChallengeEvent but got %T", event)
 		}
 
-		// Update mach, precondition, deadline
+		// Update start, precondition, deadline
+		segmentIndex := contEv.SegmentIndex.Uint64()
+		start = segmentStart(start, segmentIndex, segmentCount, ev.TotalSteps)
 		if timedOut {
 			// Freshly bisected assertion
-			mach = m
-			precondition = preconditions[contEv.SegmentIndex.Uint64()]
+			precondition = preconditions[segmentIndex]
 		} else {
 			// Replayed from existing event
-			totalSteps := uint64(0)
-			for i := uint64(0); i < contEv.SegmentIndex.Uint64(); i++ {
-				totalSteps += valprotocol.CalculateBisectionStepCount(i, uint64(len(ev.Assertions)), ev.TotalSteps)
-			}
-			assertion, _ := mach.ExecuteAssertion(
-				totalSteps,
-				startPrecondition.TimeBounds,
-				startPrecondition.BeforeInbox.(value.TupleValue),
-				0,
-			)
-			precondition = precondition.GeneratePostcondition(valprotocol.NewExecutionAssertionStubFromAssertion(assertion))
+			_, precondition = trace.StateAt(start)
 		}
+		trace.restrict(start, start+valprotocol.CalculateBisectionStepCount(segmentIndex, segmentCount, ev.TotalSteps))
 		deadline = contEv.Deadline
+		startMachineState, _ := trace.StateAt(start)
+		ccp.save(contEv, func(ckpCtx *checkpointing.CheckpointContext, buf *ChallengeCheckpointBuf) {
+			buf.Execution = marshalExecutionStateForCheckpoint(ckpCtx, precondition, 0, startMachineState)
+		})
 	}
 }
@@ -59,7 +59,7 @@ func testExecutionChallenge(t *testing.T) {
 		challengeHash,
 		"9af1e691e3db692cc9cad4e87b6490e099eb291e3b434a0d3f014dfd2bb747cc",
 		"27e926925fb5903ee038c894d9880f74d3dd6518e23ab5e5651de93327c7dffa",
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return DefendExecutionClaim(
 				context.Background(),
 				client,
@@ -70,9 +70,11 @@ func testExecutionChallenge(t *testing.T) {
 				mach.Clone(),
 				numSteps,
 				4,
+				nil,
+				clock,
 			)
 		},
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return ChallengeExecutionClaim(
 				context.Background(),
 				client,
@@ -82,6 +84,8 @@ func testExecutionChallenge(t *testing.T) {
 				precondition,
 				mach.Clone(),
 				true,
+				nil,
+				clock,
 			)
 		},
 	); err != nil {
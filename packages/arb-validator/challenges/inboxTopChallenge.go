@@ -26,6 +26,7 @@ import (
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/structures"
 	errors2 "github.com/pkg/errors"
 )
@@ -40,12 +41,26 @@ func DefendInboxTopClaim(
 	afterInboxTop common.Hash,
 	messageCount *big.Int,
 	bisectionCount uint64,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewInboxTopChallengeWatcher(address)
 	if err != nil {
 		return 0, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "InboxTopChallenge defender")
+	startState := afterInboxTop
+	count := messageCount.Uint64()
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(buf *ChallengeCheckpointBuf, _ checkpointing.RestoreContext) error {
+		if buf.InboxTop == nil {
+			return errWrongChallengeCheckpoint
+		}
+		startState = buf.InboxTop.StartState.Unmarshal()
+		count = buf.InboxTop.MessageCount
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewInboxTopChallenge(address)
@@ -54,16 +69,18 @@ func DefendInboxTopClaim(
 	}
 	log.Println("=======> defending inbox top claim")
 
-	return defendInboxTop(
+	return ccp.finish(defendInboxTop(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		inbox,
-		afterInboxTop,
-		messageCount.Uint64(),
+		startState,
+		count,
 		bisectionCount,
-	)
+	))
 }
 
 func ChallengeInboxTopClaim(
@@ -74,12 +91,19 @@ func ChallengeInboxTopClaim(
 	startLogIndex uint,
 	inbox *structures.MessageStack,
 	challengeEverything bool,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewInboxTopChallengeWatcher(address)
 	if err != nil {
 		return 0, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "InboxTopChallenge challenger")
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(*ChallengeCheckpointBuf, checkpointing.RestoreContext) error {
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewInboxTopChallenge(address)
@@ -87,14 +111,16 @@ func ChallengeInboxTopClaim(
 		return 0, err
 	}
 	log.Println("=======> challenging inbox top claim")
-	return challengeInboxTop(
+	return ccp.finish(challengeInboxTop(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		inbox,
 		challengeEverything,
-	)
+	))
 }
 
 func defendInboxTop(
@@ -102,25 +128,20 @@ func defendInboxTop(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.InboxTopChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	inbox *structures.MessageStack,
-	afterInboxTop common.Hash,
+	startState common.Hash,
 	messageCount uint64,
 	bisectionCount uint64,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	_, ok = event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("InboxTopChallenge defender expected InitiateChallengeEvent but got %T", event)
+	if _, err := ccp.initiate(eventChan); err != nil {
+		return 0, err
 	}
 
-	startState := afterInboxTop
-
 	for {
 		if messageCount == 1 {
-			timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+			timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 			if timedOut {
 				msg, err := inbox.GenerateOneStepProof(startState)
 				if err != nil {
@@ -136,14 +157,14 @@ func defendInboxTop(
 			if err != nil || state != ChallengeContinuing {
 				return state, err
 			}
-			_, ok = event.(arbbridge.OneStepProofEvent)
+			_, ok := event.(arbbridge.OneStepProofEvent)
 			if !ok {
 				return 0, fmt.Errorf("InboxTopChallenge defender expected OneStepProof but got %T", event)
 			}
 			return ChallengeAsserterWon, nil
 		}
 
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		if timedOut {
 			chainHashes, err := inbox.GenerateBisection(startState, bisectionCount, messageCount)
 			if err != nil {
@@ -166,6 +187,7 @@ func defendInboxTop(
 
 		event, state, err = getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			ev.Deadline,
 			contract,
@@ -180,6 +202,9 @@ func defendInboxTop(
 		}
 		startState = ev.ChainHashes[contEv.SegmentIndex.Uint64()]
 		messageCount = getSegmentCount(messageCount, uint64(len(ev.ChainHashes))-1, contEv.SegmentIndex.Uint64())
+		ccp.save(contEv, func(_ *checkpointing.CheckpointContext, buf *ChallengeCheckpointBuf) {
+			buf.InboxTop = marshalInboxTopStateForCheckpoint(startState, messageCount)
+		})
 	}
 }
 
@@ -188,22 +213,20 @@ func challengeInboxTop(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.InboxTopChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	inbox *structures.MessageStack,
 	challengeEverything bool,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	ev, ok := event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("InboxTopChallenge challenger expected InitiateChallengeEvent but got %T", event)
+	deadline, err := ccp.initiate(eventChan)
+	if err != nil {
+		return 0, err
 	}
 
-	deadline := ev.Deadline
 	for {
 		event, state, err := getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			deadline,
 			contract,
@@ -223,7 +246,7 @@ func challengeInboxTop(
 		}
 
 		// Wait to check if we've already chosen a segment
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		if timedOut {
 			err = nil
 			segments, err := inbox.GenerateBisection(ev.ChainHashes[0], uint64(len(ev.ChainHashes))-1, ev.TotalLength.Uint64())
@@ -257,5 +280,6 @@ func challengeInboxTop(
 			return 0, fmt.Errorf("InboxTopChallenge challenger expected ContinueChallengeEvent but got %T", event)
 		}
 		deadline = contEv.Deadline
+		ccp.save(contEv, nil)
 	}
 }
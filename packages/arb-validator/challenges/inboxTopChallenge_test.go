@@ -94,7 +94,7 @@ func testInboxTopChallenge(t *testing.T) {
 		challengeHash,
 		"ffb2b26161e081f0cdf9db67200ee0ce25499d5ee683180a9781e6cceb791c39",
 		"979f020f6f6f71577c09db93ba944c89945f10fade64cfc7eb26137d5816fb76",
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return DefendInboxTopClaim(
 				context.Background(),
 				client,
@@ -105,9 +105,11 @@ func testInboxTopChallenge(t *testing.T) {
 				bottomHash,
 				messageCount,
 				2,
+				nil,
+				clock,
 			)
 		},
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return ChallengeInboxTopClaim(
 				context.Background(),
 				client,
@@ -116,6 +118,8 @@ func testInboxTopChallenge(t *testing.T) {
 				0,
 				messageStack,
 				true,
+				nil,
+				clock,
 			)
 		},
 	); err != nil {
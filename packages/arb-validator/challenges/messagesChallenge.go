@@ -30,6 +30,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/structures"
 )
 
@@ -43,12 +44,29 @@ func DefendMessagesClaim(
 	beforeInbox common.Hash,
 	messageCount *big.Int,
 	bisectionCount uint64,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewMessagesChallengeWatcher(address)
 	if err != nil {
 		return 0, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "MessagesChallenge defender")
+	segment := messagesSegment{
+		startInbox:      beforeInbox,
+		startMessages:   value.NewEmptyTuple().Hash(),
+		inboxStartCount: 0,
+		messageCount:    messageCount.Uint64(),
+	}
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(buf *ChallengeCheckpointBuf, _ checkpointing.RestoreContext) error {
+		if buf.Messages == nil {
+			return errWrongChallengeCheckpoint
+		}
+		segment = buf.Messages.unmarshal()
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewMessagesChallenge(address)
@@ -56,16 +74,19 @@ func DefendMessagesClaim(
 		return 0, err
 	}
 
-	return defendMessages(
+	return ccp.finish(defendMessages(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		inbox,
 		beforeInbox,
 		messageCount.Uint64(),
+		segment,
 		bisectionCount,
-	)
+	))
 }
 
 func ChallengeMessagesClaim(
@@ -78,12 +99,19 @@ func ChallengeMessagesClaim(
 	beforeInbox common.Hash,
 	messageCount *big.Int,
 	challengeEverything bool,
+	cp checkpointing.ChallengeCheckpointer,
+	clock common.Clock,
 ) (ChallengeState, error) {
 	contractWatcher, err := client.NewMessagesChallengeWatcher(address)
 	if err != nil {
 		return ChallengeContinuing, err
 	}
 
+	ccp := newChallengeCheckpointer(cp, address, "MessagesChallenge challenger")
+	startBlockId, startLogIndex = ccp.restore(ctx, client, startBlockId, startLogIndex, func(*ChallengeCheckpointBuf, checkpointing.RestoreContext) error {
+		return nil
+	})
+
 	reorgCtx, eventChan := arbbridge.HandleBlockchainEvents(ctx, client, startBlockId, startLogIndex, contractWatcher)
 
 	contract, err := client.NewMessagesChallenge(address)
@@ -91,16 +119,18 @@ func ChallengeMessagesClaim(
 		return 0, err
 	}
 
-	return challengeMessages(
+	return ccp.finish(challengeMessages(
 		reorgCtx,
 		eventChan,
 		contract,
 		client,
+		clock,
+		ccp,
 		inbox,
 		beforeInbox,
 		messageCount.Uint64(),
 		challengeEverything,
-	)
+	))
 }
 
 func defendMessages(
@@ -108,21 +138,19 @@ func defendMessages(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.MessagesChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	inbox *structures.MessageStack,
 	beforeInbox common.Hash,
-	messageCount uint64,
+	totalMessageCount uint64,
+	segment messagesSegment,
 	bisectionCount uint64,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	_, ok = event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("MessagesChallenge defender expected InitiateChallengeEvent but got %T", event)
+	if _, err := ccp.initiate(eventChan); err != nil {
+		return 0, err
 	}
 
-	vmInbox, err := inbox.GenerateVMInbox(beforeInbox, messageCount)
+	vmInbox, err := inbox.GenerateVMInbox(beforeInbox, totalMessageCount)
 	if err != nil {
 		return 0, err
 	}
@@ -130,14 +158,15 @@ func defendMessages(
 	log.Println("Inbox", inbox)
 	log.Println("VM inbox", vmInbox)
 
-	startInbox := beforeInbox
-	startMessages := value.NewEmptyTuple().Hash()
-	inboxStartCount := uint64(0)
+	startInbox := segment.startInbox
+	startMessages := segment.startMessages
+	inboxStartCount := segment.inboxStartCount
+	messageCount := segment.messageCount
 
 	for {
 		log.Println(inboxStartCount, messageCount)
 		if messageCount == 1 {
-			timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+			timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 			if timedOut {
 				msg, err := inbox.GenerateOneStepProof(startInbox)
 				if err != nil {
@@ -167,14 +196,14 @@ func defendMessages(
 			if err != nil || state != ChallengeContinuing {
 				return state, err
 			}
-			_, ok = event.(arbbridge.OneStepProofEvent)
+			_, ok := event.(arbbridge.OneStepProofEvent)
 			if !ok {
 				return 0, fmt.Errorf("MessagesChallenge defender expected OneStepProof but got %T", event)
 			}
 			return ChallengeAsserterWon, nil
 		}
 
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		if timedOut {
 			chainHashes, err := inbox.GenerateBisection(startInbox, bisectionCount, messageCount)
 			inboxHashes, err := vmInbox.GenerateBisection(inboxStartCount, bisectionCount, messageCount)
@@ -203,6 +232,7 @@ func defendMessages(
 
 		event, state, err = getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			ev.Deadline,
 			contract,
@@ -220,6 +250,14 @@ func defendMessages(
 		inboxStartCount += getSegmentStart(messageCount, uint64(len(ev.ChainHashes))-1, contEv.SegmentIndex.Uint64())
 		log.Println("messageCount", messageCount, uint64(len(ev.ChainHashes))-1, contEv.SegmentIndex.Uint64())
 		messageCount = getSegmentCount(messageCount, uint64(len(ev.ChainHashes))-1, contEv.SegmentIndex.Uint64())
+		ccp.save(contEv, func(_ *checkpointing.CheckpointContext, buf *ChallengeCheckpointBuf) {
+			buf.Messages = messagesSegment{
+				startInbox:      startInbox,
+				startMessages:   startMessages,
+				inboxStartCount: inboxStartCount,
+				messageCount:    messageCount,
+			}.marshalToBuf()
+		})
 	}
 }
 
@@ -228,18 +266,16 @@ func challengeMessages(
 	eventChan <-chan arbbridge.Event,
 	contract arbbridge.MessagesChallenge,
 	client arbbridge.ArbClient,
+	clock common.Clock,
+	ccp *challengeCheckpointer,
 	inbox *structures.MessageStack,
 	beforeInbox common.Hash,
 	messageCount uint64,
 	challengeEverything bool,
 ) (ChallengeState, error) {
-	event, ok := <-eventChan
-	if !ok {
-		return 0, challengeNoEvents
-	}
-	ev, ok := event.(arbbridge.InitiateChallengeEvent)
-	if !ok {
-		return 0, fmt.Errorf("MessagesChallenge challenger expected InitiateChallengeEvent but got %T", event)
+	deadline, err := ccp.initiate(eventChan)
+	if err != nil {
+		return 0, err
 	}
 
 	vmInbox, err := inbox.GenerateVMInbox(beforeInbox, messageCount)
@@ -249,10 +285,10 @@ func challengeMessages(
 
 	startInbox := uint64(0)
 
-	deadline := ev.Deadline
 	for {
 		event, state, err := getNextEventWithTimeout(
 			ctx,
+			clock,
 			eventChan,
 			deadline,
 			contract,
@@ -271,7 +307,7 @@ func challengeMessages(
 			return 0, fmt.Errorf("MessagesChallenge challenger expected MessagesBisectionEvent but got %T", event)
 		}
 
-		timedOut, event, state, err := getNextEventIfExists(ctx, eventChan, replayTimeout)
+		timedOut, event, state, err := getNextEventIfExists(ctx, clock, eventChan, replayTimeout)
 		if timedOut {
 			inboxSegments, err := inbox.GenerateBisection(ev.ChainHashes[0], uint64(len(ev.ChainHashes))-1, ev.TotalLength.Uint64())
 			if err != nil {
@@ -321,5 +357,6 @@ func challengeMessages(
 			return 0, fmt.Errorf("MessagesChallenge challenger expected ContinueChallengeEvent but got %T", event)
 		}
 		deadline = contEv.Deadline
+		ccp.save(contEv, nil)
 	}
 }
@@ -77,7 +77,7 @@ func testMessagesChallenge(t *testing.T) {
 		challengeHash,
 		"d26a199ae5b6bed1992439d1840f7cb400d0a55a0c9f796fa67d7c571fbb180e",
 		"af5c2984cb1e2f668ae3fd5bbfe0471f68417efd012493538dcd42692299155b",
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return DefendMessagesClaim(
 				context.Background(),
 				client,
@@ -88,9 +88,11 @@ func testMessagesChallenge(t *testing.T) {
 				beforeInbox,
 				new(big.Int).SetUint64(messageCount),
 				2,
+				nil,
+				clock,
 			)
 		},
-		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId) (ChallengeState, error) {
+		func(challengeAddress common.Address, client *ethbridge.EthArbAuthClient, blockId *common.BlockId, clock common.Clock) (ChallengeState, error) {
 			return ChallengeMessagesClaim(
 				context.Background(),
 				client,
@@ -101,6 +103,8 @@ func testMessagesChallenge(t *testing.T) {
 				beforeInbox,
 				new(big.Int).SetUint64(messageCount),
 				true,
+				nil,
+				clock,
 			)
 		},
 	); err != nil {
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+//go:generate bash -c "protoc -I$(go list -f '{{ .Dir }}' -m github.com/offchainlabs/arbitrum/packages/arb-util) -I. --go_out=paths=source_relative:. *.proto"
+
+import (
+	"errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+)
+
+func marshalExecutionStateForCheckpoint(
+	ctx *checkpointing.CheckpointContext,
+	pre *valprotocol.Precondition,
+	numSteps uint64,
+	mach machine.Machine,
+) *ExecutionChallengeStateBuf {
+	ctx.AddMachine(mach)
+	ctx.AddValue(pre.BeforeInbox)
+	return &ExecutionChallengeStateBuf{
+		MachineHash:     mach.Hash().MarshalToBuf(),
+		BeforeHash:      pre.BeforeHash.MarshalToBuf(),
+		TimeBounds:      pre.TimeBounds.MarshalToBuf(),
+		BeforeInboxHash: pre.BeforeInbox.Hash().MarshalToBuf(),
+		NumSteps:        numSteps,
+	}
+}
+
+func (x *ExecutionChallengeStateBuf) unmarshalFromCheckpoint(
+	ctx checkpointing.RestoreContext,
+) (*valprotocol.Precondition, uint64, machine.Machine, error) {
+	beforeInbox := ctx.GetValue(x.BeforeInboxHash.Unmarshal())
+	if beforeInbox == nil {
+		return nil, 0, nil, errors.New("challenge checkpoint is missing its inbox")
+	}
+	mach := ctx.GetMachine(x.MachineHash.Unmarshal())
+	if mach == nil {
+		return nil, 0, nil, errors.New("challenge checkpoint is missing its machine")
+	}
+	pre := valprotocol.NewPrecondition(x.BeforeHash.Unmarshal(), x.TimeBounds.Unmarshal(), beforeInbox)
+	return pre, x.NumSteps, mach, nil
+}
+
+func marshalInboxTopStateForCheckpoint(startState common.Hash, messageCount uint64) *InboxTopChallengeStateBuf {
+	return &InboxTopChallengeStateBuf{
+		StartState:   startState.MarshalToBuf(),
+		MessageCount: messageCount,
+	}
+}
+
+// messagesSegment is the part of the inbox a messages challenge defender is
+// defending
+type messagesSegment struct {
+	startInbox      common.Hash
+	startMessages   common.Hash
+	inboxStartCount uint64
+	messageCount    uint64
+}
+
+func (s messagesSegment) marshalToBuf() *MessagesChallengeStateBuf {
+	return &MessagesChallengeStateBuf{
+		StartInbox:      s.startInbox.MarshalToBuf(),
+		StartMessages:   s.startMessages.MarshalToBuf(),
+		InboxStartCount: s.inboxStartCount,
+		MessageCount:    s.messageCount,
+	}
+}
+
+func (x *MessagesChallengeStateBuf) unmarshal() messagesSegment {
+	return messagesSegment{
+		startInbox:      x.StartInbox.Unmarshal(),
+		startMessages:   x.StartMessages.Unmarshal(),
+		inboxStartCount: x.InboxStartCount,
+		messageCount:    x.MessageCount,
+	}
+}
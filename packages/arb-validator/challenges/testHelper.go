@@ -39,7 +39,11 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/test"
 )
 
-type ChallengeFunc func(common.Address, *ethbridge.EthArbAuthClient, *common.BlockId) (ChallengeState, error)
+type ChallengeFunc func(common.Address, *ethbridge.EthArbAuthClient, *common.BlockId, common.Clock) (ChallengeState, error)
+
+// clockStep is how often testChallenge moves the shared clock forward by
+// replayTimeout, so the parties stop replaying after clockStep of real time
+const clockStep = 100 * time.Millisecond
 
 func testChallenge(
 	challengeType valprotocol.ChildType,
@@ -116,6 +120,22 @@ func testChallenge(
 		return errors2.Wrap(err, "Error starting challenge")
 	}
 
+	clock := common.NewVirtualClock(time.Now())
+	clockDone := make(chan struct{})
+	defer close(clockDone)
+	go func() {
+		ticker := time.NewTicker(clockStep)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-clockDone:
+				return
+			case <-ticker.C:
+				clock.Advance(replayTimeout)
+			}
+		}
+	}()
+
 	asserterEndChan := make(chan ChallengeState)
 	asserterErrChan := make(chan error)
 	challengerEndChan := make(chan ChallengeState)
@@ -125,7 +145,7 @@ func testChallenge(
 		cBlockId := blockId.MarshalToBuf().Unmarshal()
 		tryCount := 0
 		for {
-			endState, err := asserterFunc(challengeAddress, client1, cBlockId)
+			endState, err := asserterFunc(challengeAddress, client1, cBlockId, clock)
 			if err == nil {
 				asserterEndChan <- endState
 				return
@@ -149,7 +169,7 @@ func testChallenge(
 		cBlockId := blockId.MarshalToBuf().Unmarshal()
 		tryCount := 0
 		for {
-			endState, err := challengerFunc(challengeAddress, client2, cBlockId)
+			endState, err := challengerFunc(challengeAddress, client2, cBlockId, clock)
 			if err == nil {
 				asserterEndChan <- endState
 				return
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+import (
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+const (
+	// maxTraceSnapshots bounds the number of interval snapshots taken
+	// across an execution
+	maxTraceSnapshots = 64
+	// minTraceInterval keeps short executions from being snapshotted more
+	// often than it's worth
+	minTraceInterval = 1000
+)
+
+// ExecutionTrace caches machine states along an execution so that
+// bisection rounds can start from a nearby snapshot rather than executing
+// from the beginning of the assertion again. A snapshot is taken every
+// interval steps while executing, as well as at every step whose state is
+// requested, which includes the start of every bisected segment
+type ExecutionTrace struct {
+	interval uint64
+	// snapshots is sorted by step and always contains at least one entry
+	snapshots []traceSnapshot
+}
+
+type traceSnapshot struct {
+	step uint64
+	mach machine.Machine
+	pre  *valprotocol.Precondition
+}
+
+// NewExecutionTrace creates a trace of the execution of mach from pre.
+// Steps are counted from the start of the trace. An interval of 0 disables
+// interval snapshots
+func NewExecutionTrace(pre *valprotocol.Precondition, mach machine.Machine, interval uint64) *ExecutionTrace {
+	return &ExecutionTrace{
+		interval:  interval,
+		snapshots: []traceSnapshot{{step: 0, mach: mach.Clone(), pre: pre}},
+	}
+}
+
+// traceInterval chooses the snapshot interval for an execution of numSteps
+// steps
+func traceInterval(numSteps uint64) uint64 {
+	interval := numSteps / maxTraceSnapshots
+	if interval < minTraceInterval {
+		interval = minTraceInterval
+	}
+	return interval
+}
+
+// nearest returns the index of the last snapshot at or before step
+func (t *ExecutionTrace) nearest(step uint64) int {
+	i := sort.Search(len(t.snapshots), func(i int) bool {
+		return t.snapshots[i].step > step
+	})
+	if i == 0 {
+		return 0
+	}
+	return i - 1
+}
+
+func (t *ExecutionTrace) record(step uint64, mach machine.Machine, pre *valprotocol.Precondition) {
+	i := sort.Search(len(t.snapshots), func(i int) bool {
+		return t.snapshots[i].step >= step
+	})
+	if i < len(t.snapshots) && t.snapshots[i].step == step {
+		return
+	}
+	t.snapshots = append(t.snapshots, traceSnapshot{})
+	copy(t.snapshots[i+1:], t.snapshots[i:])
+	t.snapshots[i] = traceSnapshot{step: step, mach: mach.Clone(), pre: pre}
+}
+
+// StateAt returns a copy of the machine after step steps of the trace along
+// with the precondition for executing from that point
+func (t *ExecutionTrace) StateAt(step uint64) (machine.Machine, *valprotocol.Precondition) {
+	snapshot := t.snapshots[t.nearest(step)]
+	mach := snapshot.mach.Clone()
+	pre := snapshot.pre
+	if snapshot.step < step {
+		var executed uint64
+		_, pre, executed = t.run(mach, pre, snapshot.step, step-snapshot.step)
+		t.record(snapshot.step+executed, mach, pre)
+	}
+	return mach, pre
+}
+
+// Execute runs the machine for up to numSteps steps from step start and
+// returns the resulting assertion and the number of steps executed
+func (t *ExecutionTrace) Execute(start uint64, numSteps uint64) (*protocol.ExecutionAssertion, uint64) {
+	mach, pre := t.StateAt(start)
+	assertion, _, steps := t.run(mach, pre, start, numSteps)
+	return assertion, steps
+}
+
+// run executes mach in pieces ending at interval boundaries so that a
+// snapshot can be taken at each of them. The assertions of the pieces are
+// joined into the assertion a single execution would have produced
+func (t *ExecutionTrace) run(
+	mach machine.Machine,
+	pre *valprotocol.Precondition,
+	step uint64,
+	numSteps uint64,
+) (*protocol.ExecutionAssertion, *valprotocol.Precondition, uint64) {
+	var assertion *protocol.ExecutionAssertion
+	executed := uint64(0)
+	for {
+		chunk := numSteps - executed
+		if t.interval > 0 {
+			nextSnapshot := (step/t.interval + 1) * t.interval
+			if nextSnapshot-step < chunk {
+				chunk = nextSnapshot - step
+			}
+		}
+		chunkAssertion, chunkSteps := mach.ExecuteAssertion(
+			chunk,
+			pre.TimeBounds,
+			pre.BeforeInbox.(value.TupleValue),
+			0,
+		)
+		assertion = joinAssertions(assertion, chunkAssertion)
+		pre = pre.GeneratePostcondition(valprotocol.NewExecutionAssertionStubFromAssertion(chunkAssertion))
+		step += chunkSteps
+		executed += chunkSteps
+		if t.interval > 0 && chunkSteps > 0 && step%t.interval == 0 {
+			t.record(step, mach, pre)
+		}
+		if executed == numSteps || chunkSteps < chunk {
+			return assertion, pre, executed
+		}
+	}
+}
+
+func joinAssertions(a, b *protocol.ExecutionAssertion) *protocol.ExecutionAssertion {
+	if a == nil {
+		return b
+	}
+	return protocol.NewExecutionAssertion(
+		b.AfterHash,
+		a.DidInboxInsn || b.DidInboxInsn,
+		a.NumGas+b.NumGas,
+		append(a.OutMsgs, b.OutMsgs...),
+		append(a.Logs, b.Logs...),
+	)
+}
+
+// restrict discards the snapshots which aren't needed to reach steps from
+// start to end
+func (t *ExecutionTrace) restrict(start, end uint64) {
+	first := t.nearest(start)
+	last := t.nearest(end)
+	t.snapshots = append([]traceSnapshot{}, t.snapshots[first:last+1]...)
+}
+
+// chooseSegment finds the first of the given assertions bisecting the
+// execution from step start which doesn't match the trace. It returns the
+// index of that assertion and the step at which it starts
+func (t *ExecutionTrace) chooseSegment(
+	start uint64,
+	assertions []*valprotocol.ExecutionAssertionStub,
+	totalSteps uint64,
+) (uint16, uint64, bool) {
+	assertionCount := uint64(len(assertions))
+	for i := range assertions {
+		steps := valprotocol.CalculateBisectionStepCount(uint64(i), assertionCount, totalSteps)
+		generatedAssertion, numSteps := t.Execute(start, steps)
+		stub := valprotocol.NewExecutionAssertionStubFromAssertion(generatedAssertion)
+		if numSteps != steps || !stub.Equals(assertions[i]) {
+			return uint16(i), start, true
+		}
+		start += steps
+	}
+	return 0, 0, false
+}
+
+// segmentStart returns the step at which segment index of a bisection of
+// totalSteps steps from start begins
+func segmentStart(start uint64, index uint64, segmentCount uint64, totalSteps uint64) uint64 {
+	for i := uint64(0); i < index; i++ {
+		start += valprotocol.CalculateBisectionStepCount(i, segmentCount, totalSteps)
+	}
+	return start
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenges
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/vm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+func codePoint(pc int64) value.CodePointValue {
+	return value.CodePointValue{InsnNum: pc, Op: value.BasicOperation{Op: code.NOP}}
+}
+
+func traceTestMachine(t *testing.T) (machine.Machine, *valprotocol.Precondition, uint64) {
+	// Count down from 200, logging the counter on every iteration
+	insns := []value.Operation{
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(200)},
+		value.BasicOperation{Op: code.DUP0},
+		value.BasicOperation{Op: code.LOG},
+		value.BasicOperation{Op: code.DUP0},
+		value.BasicOperation{Op: code.ISZERO},
+		value.ImmediateOperation{Op: code.CJUMP, Val: codePoint(11)},
+		value.ImmediateOperation{Op: code.NOP, Val: value.NewInt64Value(1)},
+		value.BasicOperation{Op: code.SWAP1},
+		value.BasicOperation{Op: code.SUB},
+		value.ImmediateOperation{Op: code.JUMP, Val: codePoint(1)},
+		value.BasicOperation{Op: code.HALT},
+		value.BasicOperation{Op: code.HALT},
+	}
+	mach := vm.NewMachine(insns, value.NewEmptyTuple(), false, 1000)
+	timeBounds := &protocol.TimeBounds{
+		LowerBoundBlock:     common.NewTimeBlocks(big.NewInt(100)),
+		UpperBoundBlock:     common.NewTimeBlocks(big.NewInt(120)),
+		LowerBoundTimestamp: big.NewInt(100),
+		UpperBoundTimestamp: big.NewInt(120),
+	}
+	pre := valprotocol.NewPrecondition(mach.Hash(), timeBounds, value.NewEmptyTuple())
+	_, numSteps := mach.Clone().ExecuteAssertion(10000, timeBounds, value.NewEmptyTuple(), 0)
+	if numSteps < 1000 {
+		t.Fatalf("test machine only ran for %v steps", numSteps)
+	}
+	return mach, pre, numSteps
+}
+
+// executeFrom runs numSteps steps from step start without using a trace
+func executeFrom(mach machine.Machine, pre *valprotocol.Precondition, start, numSteps uint64) (*valprotocol.ExecutionAssertionStub, uint64) {
+	mach = mach.Clone()
+	assertion, _ := mach.ExecuteAssertion(start, pre.TimeBounds, pre.BeforeInbox.(value.TupleValue), 0)
+	pre = pre.GeneratePostcondition(valprotocol.NewExecutionAssertionStubFromAssertion(assertion))
+	assertion, steps := mach.ExecuteAssertion(numSteps, pre.TimeBounds, pre.BeforeInbox.(value.TupleValue), 0)
+	return valprotocol.NewExecutionAssertionStubFromAssertion(assertion), steps
+}
+
+func TestExecutionTrace(t *testing.T) {
+	mach, pre, numSteps := traceTestMachine(t)
+	for _, interval := range []uint64{0, 1, 7, numSteps / 3, numSteps * 2} {
+		trace := NewExecutionTrace(pre, mach, interval)
+		segments := [][2]uint64{
+			{0, numSteps},
+			{numSteps / 2, numSteps / 4},
+			{1, numSteps / 2},
+			{numSteps / 3, 1},
+			{numSteps - 1, 5},
+		}
+		for _, segment := range segments {
+			assertion, steps := trace.Execute(segment[0], segment[1])
+			expected, expectedSteps := executeFrom(mach, pre, segment[0], segment[1])
+			if steps != expectedSteps {
+				t.Errorf("interval %v: segment %v ran %v steps instead of %v", interval, segment, steps, expectedSteps)
+			}
+			if stub := valprotocol.NewExecutionAssertionStubFromAssertion(assertion); !stub.Equals(expected) {
+				t.Errorf("interval %v: segment %v gave assertion %v instead of %v", interval, segment, stub, expected)
+			}
+		}
+		if interval > 0 && interval < numSteps && len(trace.snapshots) < int(numSteps/interval) {
+			t.Errorf("interval %v: expected at least %v snapshots but trace has %v", interval, numSteps/interval, len(trace.snapshots))
+		}
+	}
+}
+
+func TestNBisect(t *testing.T) {
+	mach, pre, numSteps := traceTestMachine(t)
+	defender := NewAssertionDefender(pre, numSteps, mach)
+	for defender.NumSteps() > 1 {
+		defenders, assertions := defender.NBisect(4)
+		start := defender.start
+		for i, child := range defenders {
+			expected, _ := executeFrom(mach, pre, start, child.NumSteps())
+			if !assertions[i].Equals(expected) {
+				t.Fatalf("segment %v of %v gave assertion %v instead of %v", i, start, assertions[i], expected)
+			}
+			if child.GetMachineState().Hash() != child.GetPrecondition().BeforeHash {
+				t.Fatalf("segment %v of %v starts at the wrong machine", i, start)
+			}
+			start += child.NumSteps()
+		}
+		defender = defenders[len(defenders)/2]
+		defender.discardOtherSegments()
+	}
+
+	// A challenger disagreeing with the last segment picks it
+	defenders, assertions := NewAssertionDefender(pre, numSteps, mach).NBisect(3)
+	assertions[2] = assertions[1]
+	index, challengedMachine, err := ChooseAssertionToChallenge(mach, pre, assertions, numSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 2 || challengedMachine.Hash() != defenders[2].GetPrecondition().BeforeHash {
+		t.Errorf("challenger chose segment %v", index)
+	}
+}
@@ -25,6 +25,13 @@ import (
 
 type RollupCheckpointerFactory interface {
 	New(ctx context.Context) RollupCheckpointer
+	// Close flushes any checkpoint which hasn't been written yet and
+	// releases the underlying storage. Checkpointers created by the factory
+	// can't be used afterwards
+	Close() error
+	// IndexStorage returns the storage for indexes kept alongside the
+	// checkpoints
+	IndexStorage() IndexStorage
 }
 
 type RollupCheckpointer interface {
@@ -32,6 +39,27 @@ type RollupCheckpointer interface {
 	RestoreLatestState(context.Context, arbbridge.ChainTimeGetter, func([]byte, RestoreContext) error) error
 	GetInitialMachine() (machine.Machine, error)
 	AsyncSaveCheckpoint(blockId *common.BlockId, contents []byte, cpCtx *CheckpointContext)
+	ChallengeCheckpointer
+}
+
+// ChallengeCheckpointer stores the latest state of each running challenge.
+// Unlike rollup checkpoints, challenge state is saved synchronously and
+// isn't tied to a block, so there is only ever one checkpoint per challenge
+type ChallengeCheckpointer interface {
+	SaveChallengeState(challenge common.Address, contents []byte, cpCtx *CheckpointContext) error
+	RestoreChallengeState(challenge common.Address, unmarshalFunc func([]byte, RestoreContext) error) error
+	DeleteChallengeState(challenge common.Address) error
+}
+
+// IndexStorage holds data derived from the chain, such as the transaction
+// and log index of the validator's RPC server. Entries are never removed by
+// checkpoint cleanup, so users must delete any which are invalidated by a
+// reorg themselves
+type IndexStorage interface {
+	SaveIndexData(key []byte, data []byte) error
+	// GetIndexData returns nil if there is no entry for key
+	GetIndexData(key []byte) []byte
+	DeleteIndexData(key []byte) error
 }
 
 const checkpointDatabasePathBase = "/tmp/arb-validator-checkpoint-"
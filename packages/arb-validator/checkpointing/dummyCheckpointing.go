@@ -24,24 +24,64 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 	"log"
+	"sync"
 )
 
 type DummyCheckpointerFactory struct {
 	initialMachine machine.Machine
+	index          IndexStorage
 }
 
 func NewDummyCheckpointerFactory(arbitrumCodefilePath string) RollupCheckpointerFactory {
-	theMachine, err := loader.LoadMachineFromFile(arbitrumCodefilePath, true, "test")
+	theMachine, err := loader.LoadMachineFromFile(arbitrumCodefilePath, true, loader.TestVMType)
 	if err != nil {
 		log.Fatal("newDummyCheckpointer: error loading ", arbitrumCodefilePath)
 	}
-	return &DummyCheckpointerFactory{theMachine}
+	return &DummyCheckpointerFactory{theMachine, NewMemoryIndexStorage()}
 }
 
 func (fac *DummyCheckpointerFactory) New(context.Context) RollupCheckpointer {
 	return &DummyCheckpointer{fac}
 }
 
+func (fac *DummyCheckpointerFactory) Close() error {
+	return nil
+}
+
+func (fac *DummyCheckpointerFactory) IndexStorage() IndexStorage {
+	return fac.index
+}
+
+type memoryIndexStorage struct {
+	sync.Mutex
+	entries map[string][]byte
+}
+
+// NewMemoryIndexStorage creates index storage which isn't persisted
+func NewMemoryIndexStorage() IndexStorage {
+	return &memoryIndexStorage{entries: make(map[string][]byte)}
+}
+
+func (s *memoryIndexStorage) SaveIndexData(key []byte, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.entries[string(key)] = append([]byte{}, data...)
+	return nil
+}
+
+func (s *memoryIndexStorage) GetIndexData(key []byte) []byte {
+	s.Lock()
+	defer s.Unlock()
+	return s.entries[string(key)]
+}
+
+func (s *memoryIndexStorage) DeleteIndexData(key []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.entries, string(key))
+	return nil
+}
+
 type DummyCheckpointer struct {
 	fac *DummyCheckpointerFactory
 }
@@ -59,3 +99,15 @@ func (dcp *DummyCheckpointer) GetInitialMachine() (machine.Machine, error) {
 }
 
 func (dcp *DummyCheckpointer) AsyncSaveCheckpoint(_ *common.BlockId, _ []byte, _ *CheckpointContext) {}
+
+func (dcp *DummyCheckpointer) SaveChallengeState(common.Address, []byte, *CheckpointContext) error {
+	return nil
+}
+
+func (dcp *DummyCheckpointer) RestoreChallengeState(common.Address, func([]byte, RestoreContext) error) error {
+	return errors.New("no checkpoints in database")
+}
+
+func (dcp *DummyCheckpointer) DeleteChallengeState(common.Address) error {
+	return nil
+}
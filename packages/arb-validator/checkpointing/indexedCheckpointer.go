@@ -25,45 +25,78 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/metrics"
 	"google.golang.org/protobuf/proto"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 )
 
 var errNoCheckpoint = errors.New("cannot restore because no checkpoint exists")
 var errNoMatchingCheckpoint = errors.New("cannot restore because no matching checkpoint exists")
+var errCheckpointerClosed = errors.New("checkpointer is closed")
+
+var (
+	checkpointWriteTimer = metrics.NewRegisteredTimer("arb/checkpoint/write", nil)
+	checkpointSizeGauge  = metrics.NewRegisteredGauge("arb/checkpoint/size", nil)
+	checkpointFailures   = metrics.NewRegisteredCounter("arb/checkpoint/failures", nil)
+)
 
 type IndexedCheckpointer struct {
 	*sync.Mutex
 	db                    machine.CheckpointStorage
 	nextCheckpointToWrite *writableCheckpoint
+	closed                bool
+
+	// dbLock serialises changes to db, since checkpoints share reference
+	// counted values and machines. It's taken after the checkpointer's own
+	// lock when both are held
+	dbLock sync.Mutex
+
+	// stopDaemons and daemons are only set if the checkpointer's reading and
+	// writing threads have been launched
+	stopDaemons context.CancelFunc
+	daemons     *sync.WaitGroup
 }
 
+// NewIndexedCheckpointerFactory creates a checkpointer which saves to the
+// checkpoint storage of the given machine implementation, as accepted by
+// loader.CreateCheckpointStorage
 func NewIndexedCheckpointerFactory(
 	rollupAddr common.Address,
 	arbitrumCodeFilePath string,
 	databasePath string,
+	vmType string,
 	maxReorgHeight *big.Int,
 	forceFreshStart bool,
-) RollupCheckpointerFactory {
+) (RollupCheckpointerFactory, error) {
 	ret, err := newIndexedCheckpointerFactory(
 		rollupAddr,
 		arbitrumCodeFilePath,
 		databasePath,
+		vmType,
 		forceFreshStart,
 	)
-
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	go ret.writeDaemon()
-	go cleanupDaemon(ret.db, maxReorgHeight)
-	return ret
+	ctx, cancel := context.WithCancel(context.Background())
+	ret.stopDaemons = cancel
+	ret.daemons = &sync.WaitGroup{}
+	ret.daemons.Add(2)
+	go func() {
+		defer ret.daemons.Done()
+		ret.writeDaemon(ctx)
+	}()
+	go func() {
+		defer ret.daemons.Done()
+		ret.cleanupDaemon(ctx, maxReorgHeight)
+	}()
+	return ret, nil
 }
 
 // newIndexedCheckpointerFactory creates the checkpoint factory, but doesn't
@@ -73,6 +106,7 @@ func newIndexedCheckpointerFactory(
 	rollupAddr common.Address,
 	arbitrumCodeFilePath string,
 	databasePath string,
+	vmType string,
 	forceFreshStart bool,
 ) (*IndexedCheckpointer, error) {
 	if databasePath == "" {
@@ -84,15 +118,14 @@ func newIndexedCheckpointerFactory(
 			return nil, err
 		}
 	}
-	cCheckpointer, err := cmachine.NewCheckpoint(databasePath, arbitrumCodeFilePath)
+	storage, err := loader.CreateCheckpointStorage(databasePath, arbitrumCodeFilePath, vmType)
 	if err != nil {
 		return nil, err
 	}
 
 	return &IndexedCheckpointer{
-		new(sync.Mutex),
-		cCheckpointer,
-		nil,
+		Mutex: new(sync.Mutex),
+		db:    storage,
 	}, nil
 }
 
@@ -105,6 +138,81 @@ func (cp *IndexedCheckpointer) New(_ context.Context) RollupCheckpointer {
 	return cp
 }
 
+// Close stops the checkpointer's threads, writes out the latest checkpoint
+// if it's still waiting to be written and closes the database
+func (cp *IndexedCheckpointer) Close() error {
+	if cp.stopDaemons != nil {
+		cp.stopDaemons()
+		cp.daemons.Wait()
+	}
+
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return nil
+	}
+	cp.closed = true
+
+	var err error
+	if cp.nextCheckpointToWrite != nil {
+		cp.dbLock.Lock()
+		err = writeCheckpoint(cp.db, cp.nextCheckpointToWrite)
+		cp.dbLock.Unlock()
+		cp.nextCheckpointToWrite = nil
+	}
+	if !cp.db.CloseCheckpointStorage() && err == nil {
+		err = errors.New("failed to close checkpoint db")
+	}
+	return err
+}
+
+// IndexStorage returns the checkpointer itself, which stores index entries
+// in the checkpoint database under their own prefix
+func (cp *IndexedCheckpointer) IndexStorage() IndexStorage {
+	return cp
+}
+
+func indexKey(key []byte) []byte {
+	return append([]byte("index:"), key...)
+}
+
+func (cp *IndexedCheckpointer) SaveIndexData(key []byte, data []byte) error {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return errCheckpointerClosed
+	}
+	cp.dbLock.Lock()
+	defer cp.dbLock.Unlock()
+	if ok := cp.db.SaveData(indexKey(key), data); !ok {
+		return errors.New("failed to write index entry to checkpoint db")
+	}
+	return nil
+}
+
+func (cp *IndexedCheckpointer) GetIndexData(key []byte) []byte {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return nil
+	}
+	return cp.db.GetData(indexKey(key))
+}
+
+func (cp *IndexedCheckpointer) DeleteIndexData(key []byte) error {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return errCheckpointerClosed
+	}
+	cp.dbLock.Lock()
+	defer cp.dbLock.Unlock()
+	if ok := cp.db.DeleteData(indexKey(key)); !ok {
+		return errors.New("failed to delete index entry from checkpoint db")
+	}
+	return nil
+}
+
 func (cp *IndexedCheckpointer) HasCheckpointedState() bool {
 	return !cp.db.IsBlockStoreEmpty()
 }
@@ -126,6 +234,9 @@ func (cp *IndexedCheckpointer) AsyncSaveCheckpoint(
 ) {
 	cp.Lock()
 	defer cp.Unlock()
+	if cp.closed {
+		return
+	}
 
 	cp.nextCheckpointToWrite = &writableCheckpoint{
 		blockId:  blockId,
@@ -167,59 +278,157 @@ func restoreLatestState(ctx context.Context, db machine.CheckpointStorage, clnt
 	return errNoMatchingCheckpoint
 }
 
-func (cp *IndexedCheckpointer) writeDaemon() {
+func (cp *IndexedCheckpointer) writeDaemon(ctx context.Context) {
 	ticker := time.NewTicker(common.NewTimeBlocksInt(2).Duration())
 	defer ticker.Stop()
 	for {
-		<-ticker.C
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 		cp.Lock()
 		checkpoint := cp.nextCheckpointToWrite
 		cp.nextCheckpointToWrite = nil
 		cp.Unlock()
 		if checkpoint != nil {
+			start := time.Now()
+			cp.dbLock.Lock()
 			err := writeCheckpoint(cp.db, checkpoint)
+			cp.dbLock.Unlock()
 			if err != nil {
+				checkpointFailures.Inc(1)
 				log.Println("Error writing checkpoint: {}", err)
+				continue
 			}
+			checkpointWriteTimer.UpdateSince(start)
 		}
 	}
 }
 
 func writeCheckpoint(db machine.CheckpointStorage, wc *writableCheckpoint) error {
+	bytesBuf, err := saveCheckpointContents(db, wc.contents, wc.ckpCtx)
+	if err != nil {
+		return err
+	}
+	if err := db.PutBlock(wc.blockId, bytesBuf); err != nil {
+		return errors.New("failed to write checkpoint to checkpoint db")
+	}
+	checkpointSizeGauge.Update(int64(len(bytesBuf)))
+
+	return nil
+}
+
+// saveCheckpointContents saves the values and machines referenced by a
+// checkpoint and returns the serialized checkpoint
+func saveCheckpointContents(db machine.CheckpointStorage, contents []byte, ckpCtx *CheckpointContext) ([]byte, error) {
 	// save values and machines
-	for _, val := range wc.ckpCtx.Values() {
+	for _, val := range ckpCtx.Values() {
 		if ok := db.SaveValue(val); !ok {
-			return errors.New("failed to write value to checkpoint db")
+			return nil, errors.New("failed to write value to checkpoint db")
 		}
 	}
-	for _, mach := range wc.ckpCtx.Machines() {
+	for _, mach := range ckpCtx.Machines() {
 		if ok := mach.Checkpoint(db); !ok {
-			return errors.New("failed to write machine to checkpoint db")
+			return nil, errors.New("failed to write machine to checkpoint db")
 		}
 	}
 
 	// save main checkpoint data
 	ckpWithMan := &CheckpointWithManifest{
-		Contents: wc.contents,
-		Manifest: wc.ckpCtx.Manifest(),
+		Contents: contents,
+		Manifest: ckpCtx.Manifest(),
+	}
+	return proto.Marshal(ckpWithMan)
+}
+
+func challengeCheckpointKey(challenge common.Address) []byte {
+	return append([]byte("challenge:"), challenge[:]...)
+}
+
+func (cp *IndexedCheckpointer) SaveChallengeState(
+	challenge common.Address,
+	contents []byte,
+	cpCtx *CheckpointContext,
+) error {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return errCheckpointerClosed
 	}
-	bytesBuf, err := proto.Marshal(ckpWithMan)
+
+	cp.dbLock.Lock()
+	defer cp.dbLock.Unlock()
+	key := challengeCheckpointKey(challenge)
+	prev := cp.db.GetData(key)
+	bytesBuf, err := saveCheckpointContents(cp.db, contents, cpCtx)
 	if err != nil {
 		return err
 	}
-	if err := db.PutBlock(wc.blockId, bytesBuf); err != nil {
-		return errors.New("failed to write checkpoint to checkpoint db")
+	if ok := cp.db.SaveData(key, bytesBuf); !ok {
+		return errors.New("failed to write challenge checkpoint to checkpoint db")
+	}
+	if prev != nil {
+		// The values and machines of the previous checkpoint are only
+		// released once the new checkpoint has replaced it
+		_ = deleteCheckpointContents(cp.db, prev)
 	}
-
 	return nil
 }
 
-func cleanupDaemon(db machine.CheckpointStorage, maxReorgHeight *big.Int) {
+func (cp *IndexedCheckpointer) RestoreChallengeState(
+	challenge common.Address,
+	unmarshalFunc func([]byte, RestoreContext) error,
+) error {
+	cp.Lock()
+	if cp.closed {
+		cp.Unlock()
+		return errCheckpointerClosed
+	}
+	data := cp.db.GetData(challengeCheckpointKey(challenge))
+	cp.Unlock()
+	if data == nil {
+		return errNoCheckpoint
+	}
+	ckpWithMan := &CheckpointWithManifest{}
+	if err := proto.Unmarshal(data, ckpWithMan); err != nil {
+		return err
+	}
+	return unmarshalFunc(ckpWithMan.Contents, &restoreContextLocked{cp.db})
+}
+
+func (cp *IndexedCheckpointer) DeleteChallengeState(challenge common.Address) error {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.closed {
+		return errCheckpointerClosed
+	}
+
+	cp.dbLock.Lock()
+	defer cp.dbLock.Unlock()
+	key := challengeCheckpointKey(challenge)
+	data := cp.db.GetData(key)
+	if data == nil {
+		return nil
+	}
+	if ok := cp.db.DeleteData(key); !ok {
+		return errors.New("failed to delete challenge checkpoint")
+	}
+	return deleteCheckpointContents(cp.db, data)
+}
+
+func (cp *IndexedCheckpointer) cleanupDaemon(ctx context.Context, maxReorgHeight *big.Int) {
 	ticker := time.NewTicker(common.NewTimeBlocksInt(25).Duration())
 	defer ticker.Stop()
 	for {
-		<-ticker.C
-		cleanup(db, maxReorgHeight)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cp.dbLock.Lock()
+			cleanup(cp.db, maxReorgHeight)
+			cp.dbLock.Unlock()
+		}
 	}
 }
 
@@ -251,17 +460,32 @@ func deleteCheckpointForKey(db machine.CheckpointStorage, id *common.BlockId) er
 		return err
 	}
 	_ = db.DeleteBlock(id) // ignore error
-	if ckp.Manifest != nil {
-		for _, hbuf := range ckp.Manifest.Values {
+	deleteManifest(db, ckp.Manifest)
+	return nil
+}
+
+// deleteCheckpointContents releases the values and machines referenced by a
+// serialized checkpoint
+func deleteCheckpointContents(db machine.CheckpointStorage, data []byte) error {
+	ckp := &CheckpointWithManifest{}
+	if err := proto.Unmarshal(data, ckp); err != nil {
+		return err
+	}
+	deleteManifest(db, ckp.Manifest)
+	return nil
+}
+
+func deleteManifest(db machine.CheckpointStorage, manifest *CheckpointManifest) {
+	if manifest != nil {
+		for _, hbuf := range manifest.Values {
 			h := hbuf.Unmarshal()
 			_ = db.DeleteValue(h) // ignore error
 		}
-		for _, hbuf := range ckp.Manifest.Machines {
+		for _, hbuf := range manifest.Machines {
 			h := hbuf.Unmarshal()
 			_ = db.DeleteCheckpoint(h) // ignore error
 		}
 	}
-	return nil
 }
 
 type restoreContextLocked struct {
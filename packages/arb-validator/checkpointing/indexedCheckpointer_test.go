@@ -28,6 +28,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 )
 
 var contractPath = "../contract.ao"
@@ -85,7 +86,7 @@ func TestMain(m *testing.M) {
 
 func TestEmpty(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -98,7 +99,7 @@ func TestEmpty(t *testing.T) {
 
 func TestWriteCheckpoint(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -157,7 +158,7 @@ func TestWriteCheckpoint(t *testing.T) {
 
 func TestRestoreEmpty(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -172,7 +173,7 @@ func TestRestoreEmpty(t *testing.T) {
 
 func TestRestoreSingleCheckpoint(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -224,7 +225,7 @@ func TestRestoreSingleCheckpoint(t *testing.T) {
 
 func TestRestoreReorg(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -305,7 +306,7 @@ func TestRestoreReorg(t *testing.T) {
 
 func TestCleanup(t *testing.T) {
 	var rollupAddr common.Address
-	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, true)
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -354,3 +355,37 @@ func TestCleanup(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestCloseWritesPendingCheckpoint(t *testing.T) {
+	var rollupAddr common.Address
+	cp, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp.AsyncSaveCheckpoint(initialEntryBlockId, checkpointData, NewCheckpointContext())
+	if err := cp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.SaveChallengeState(common.Address{1}, checkpointData, NewCheckpointContext()); err != errCheckpointerClosed {
+		t.Error("expected closed checkpointer to reject challenge state but got", err)
+	}
+
+	reopened, err := newIndexedCheckpointerFactory(rollupAddr, contractPath, dbPath, loader.DefaultVMType, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.db.CloseCheckpointStorage()
+
+	blockData, err := reopened.db.GetBlock(initialEntryBlockId)
+	if err != nil {
+		t.Fatal("pending checkpoint wasn't written on close", err)
+	}
+	ckpWithMan := &CheckpointWithManifest{}
+	if err := proto.Unmarshal(blockData, ckpWithMan); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ckpWithMan.Contents, checkpointData) {
+		t.Error("block data didn't match. Got:", ckpWithMan.Contents, "wanted:", checkpointData)
+	}
+}
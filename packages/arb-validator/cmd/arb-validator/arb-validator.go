@@ -51,6 +51,14 @@ func main() {
 		if err := cmdhelper.ValidateRollupChain("arb-validator", createManager); err != nil {
 			log.Fatal(err)
 		}
+	case "validate-chains":
+		if err := cmdhelper.ValidateRollupChains("arb-validator", createManager); err != nil {
+			log.Fatal(err)
+		}
+	case "observe":
+		if err := cmdhelper.ObserveRollupChain("arb-validator"); err != nil {
+			log.Fatal(err)
+		}
 	default:
 	}
 }
@@ -52,17 +52,22 @@ func main() {
 }
 
 func createEvilManager(rollupAddress common.Address, client arbbridge.ArbAuthClient, contractFile string, dbPath string) (*rollupmanager.Manager, error) {
+	ckpFac, err := rolluptest.NewEvilRollupCheckpointerFactory(
+		rollupAddress,
+		contractFile,
+		dbPath,
+		big.NewInt(100),
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
 	return rollupmanager.CreateManagerAdvanced(
 		context.Background(),
 		rollupAddress,
 		true,
 		client,
-		rolluptest.NewEvilRollupCheckpointerFactory(
-			rollupAddress,
-			contractFile,
-			dbPath,
-			big.NewInt(100),
-			false,
-		),
+		ckpFac,
+		common.NewRealClock(),
 	)
 }
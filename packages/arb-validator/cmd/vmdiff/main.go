@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// vmdiff runs random programs on the Go and C++ AVMs and saves the programs
+// on which they disagree
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/vmdiff"
+)
+
+func main() {
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed")
+	iterations := flag.Int("iterations", 1000, "number of programs to run, or 0 to run forever")
+	size := flag.Int("size", vmdiff.DefaultGeneratorConfig.MaxCode, "maximum number of instructions in a program")
+	steps := flag.Uint64("steps", 1000, "maximum number of steps to run each program")
+	out := flag.String("out", "vmdiff-regressions", "directory to save divergent programs in")
+	replay := flag.String("replay", "", "rerun the regression files in a directory instead of generating programs")
+	flag.Parse()
+
+	differ := vmdiff.NewGoCppDiffer()
+	differ.MaxSteps = *steps
+
+	if *replay != "" {
+		if err := replayRegressions(differ, *replay); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Using seed", *seed)
+	rng := rand.New(rand.NewSource(*seed))
+	config := vmdiff.DefaultGeneratorConfig
+	config.MaxCode = *size
+	divergences := 0
+	for i := 0; *iterations == 0 || i < *iterations; i++ {
+		tc := vmdiff.Generate(rng, config)
+		div, err := differ.Check(tc)
+		if err != nil {
+			log.Fatalf("failed to run generated program: %v\n%v", err, tc.Source())
+		}
+		if div == nil {
+			continue
+		}
+		divergences++
+		minimized, minimizedDiv := differ.Minimize(tc, div)
+		path, err := vmdiff.WriteRegression(*out, minimized, minimizedDiv)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Program %v: %v\nSaved %v instruction program to %v\n", i, minimizedDiv, len(minimized.Code), path)
+	}
+	log.Printf("Found %v divergences", divergences)
+	if divergences > 0 {
+		os.Exit(1)
+	}
+}
+
+func replayRegressions(differ *vmdiff.Differ, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.ao"))
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, file := range files {
+		program, err := goloader.ReadProgramFromFile(file)
+		if err != nil {
+			return err
+		}
+		div, err := differ.CheckProgram(program)
+		if err != nil {
+			return fmt.Errorf("%v: %v", file, err)
+		}
+		if div != nil {
+			failed++
+			fmt.Printf("%v: %v\n", file, div)
+		} else {
+			fmt.Printf("%v: ok\n", file)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v programs still diverge", failed, len(files))
+	}
+	return nil
+}
@@ -18,11 +18,15 @@ package cmdhelper
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/utils"
@@ -34,11 +38,20 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/alerts"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollupmanager"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollupvalidator"
 )
 
+// ethRPCPort is where the Ethereum JSON-RPC interface is served. It isn't
+// 8545 so that it doesn't collide with a local L1 node
+const ethRPCPort = "8547"
+
+// shutdownTimeout is how long the validator waits for its managers to write
+// out their checkpoints when it's asked to exit
+const shutdownTimeout = 30 * time.Second
+
 // ValidateRollupChain creates a validator given the managerCreationFunc.
 // This allows for the abstraction of the manager setup away from command line
 // parsing and initialization of common structures and behavior
@@ -54,12 +67,19 @@ func ValidateRollupChain(
 
 	validateCmd := flag.NewFlagSet("validate", flag.ExitOnError)
 	walletVars := utils.AddFlags(validateCmd)
+	metricsVars := utils.AddMetricsFlags(validateCmd)
 	rpcEnable := validateCmd.Bool("rpc", false, "rpc")
+	ethRPCEnable := validateCmd.Bool("ethrpc", false, "serve the Ethereum JSON-RPC interface")
 	blocktime := validateCmd.Int64(
 		"blocktime",
 		2,
 		"blocktime=NumSeconds",
 	)
+	strategyName := validateCmd.String(
+		"strategy",
+		"aggressive",
+		"strategy=aggressive|defensive",
+	)
 	err := validateCmd.Parse(os.Args[2:])
 	if err != nil {
 		return err
@@ -67,15 +87,21 @@ func ValidateRollupChain(
 
 	if validateCmd.NArg() != 3 {
 		return fmt.Errorf(
-			"usage: %v validate %v [--rpc] [--blocktime=NumSeconds] %v",
+			"usage: %v validate %v [--rpc] [--ethrpc] [--blocktime=NumSeconds] [--strategy=aggressive|defensive] %v %v",
 			execName,
 			utils.WalletArgsString,
+			utils.MetricsArgsString,
 			utils.RollupArgsString,
 		)
 	}
 
 	common.SetDurationPerBlock(time.Duration(*blocktime) * time.Second)
 
+	strategy, err := rollup.NewStrategy(*strategyName)
+	if err != nil {
+		return err
+	}
+
 	rollupArgs := utils.ParseRollupCommand(validateCmd, 0)
 
 	auth, err := utils.GetKeystore(
@@ -93,6 +119,14 @@ func ValidateRollupChain(
 		return err
 	}
 	client := ethbridge.NewEthAuthClient(ethclint, auth)
+	client.SetMaxGasPrice(walletVars.MaxGasPrice())
+	if err := client.PersistTransactions(
+		filepath.Join(rollupArgs.ValidatorFolder, "pending_transactions.json"),
+	); err != nil {
+		return err
+	}
+	// Closed after the manager has stopped sending transactions
+	defer client.Close()
 
 	if err := arbbridge.WaitForNonZeroBalance(
 		context.Background(),
@@ -102,56 +136,457 @@ func ValidateRollupChain(
 		return err
 	}
 
-	rollupActor, err := client.NewRollup(rollupArgs.Address)
+	contractFile := filepath.Join(rollupArgs.ValidatorFolder, "contract.ao")
+	dbPath := filepath.Join(rollupArgs.ValidatorFolder, "checkpoint_db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	manager, err := startValidator(
+		client,
+		rollupArgs.Address,
+		strategy,
+		contractFile,
+		dbPath,
+		"",
+		managerCreationFunc,
+	)
 	if err != nil {
 		return err
 	}
 
-	validatorListener := rollup.NewValidatorChainListener(
+	if err := utils.LaunchMetrics(metricsVars); err != nil {
+		log.Println("Not serving metrics:", err)
+	}
+
+	serves, err := chainServers(
+		ctx,
+		manager,
+		client,
+		ethclint,
+		*rpcEnable,
+		*ethRPCEnable,
+	)
+	if err != nil {
+		stopManagers([]*rollupmanager.Manager{manager})
+		return err
+	}
+	return runUntilShutdown([]*rollupmanager.Manager{manager}, serves...)
+}
+
+// ValidateRollupChains validates every chain listed in a config file from a
+// single process. All of the chains stake from the same wallet, sharing its
+// nonce, and follow L1 using a single header subscription. Each chain keeps
+// its checkpoint database in its own folder inside the validator folder and
+// serves RPC under its own name
+func ValidateRollupChains(
+	execName string,
+	managerCreationFunc func(
+		rollupAddress common.Address,
+		client arbbridge.ArbAuthClient,
+		contractFile string, dbPath string,
+	) (*rollupmanager.Manager, error),
+) error {
+	validateCmd := flag.NewFlagSet("validate-chains", flag.ExitOnError)
+	walletVars := utils.AddFlags(validateCmd)
+	metricsVars := utils.AddMetricsFlags(validateCmd)
+	rpcEnable := validateCmd.Bool("rpc", false, "rpc")
+	ethRPCEnable := validateCmd.Bool("ethrpc", false, "serve the Ethereum JSON-RPC interface")
+	blocktime := validateCmd.Int64(
+		"blocktime",
+		2,
+		"blocktime=NumSeconds",
+	)
+	err := validateCmd.Parse(os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if validateCmd.NArg() != 3 {
+		return fmt.Errorf(
+			"usage: %v validate-chains %v [--rpc] [--ethrpc] [--blocktime=NumSeconds] %v <validator_folder> <ethURL> <config_file>",
+			execName,
+			utils.WalletArgsString,
+			utils.MetricsArgsString,
+		)
+	}
+
+	common.SetDurationPerBlock(time.Duration(*blocktime) * time.Second)
+
+	validatorFolder := validateCmd.Arg(0)
+	ethURL := validateCmd.Arg(1)
+	config, err := LoadValidatorConfig(validateCmd.Arg(2), validatorFolder)
+	if err != nil {
+		return err
+	}
+
+	auth, err := utils.GetKeystore(
+		validatorFolder,
+		walletVars,
+		validateCmd,
+	)
+	if err != nil {
+		return err
+	}
+
+	ethclint, err := ethclient.Dial(ethURL)
+	if err != nil {
+		return err
+	}
+	authClient := ethbridge.NewEthAuthClient(ethclint, auth)
+	authClient.SetMaxGasPrice(walletVars.MaxGasPrice())
+	if err := authClient.PersistTransactions(
+		filepath.Join(validatorFolder, "pending_transactions.json"),
+	); err != nil {
+		return err
+	}
+	// Closed after the managers have stopped sending transactions
+	defer authClient.Close()
+
+	if err := arbbridge.WaitForNonZeroBalance(
 		context.Background(),
-		rollupArgs.Address,
+		authClient,
+		common.NewAddressFromEth(auth.From),
+	); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headers := arbbridge.NewHeaderBroadcaster(ctx, authClient)
+	client := headers.AuthClient(authClient)
+
+	managers := make([]*rollupmanager.Manager, 0, len(config.Chains))
+	rpcHandlers := make(map[string]http.Handler)
+	ethRPCHandlers := make(map[string]http.Handler)
+	for _, chain := range config.Chains {
+		strategy, err := rollup.NewStrategy(chain.Strategy)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(chain.Folder(validatorFolder), 0755); err != nil {
+			return err
+		}
+		log.Println("Validating chain", chain.Name, "at", chain.RollupAddress)
+		manager, err := startValidator(
+			client,
+			chain.Address(),
+			strategy,
+			chain.Contract,
+			filepath.Join(chain.Folder(validatorFolder), "checkpoint_db"),
+			"["+chain.Name+"]",
+			managerCreationFunc,
+		)
+		if err != nil {
+			stopManagers(managers)
+			return err
+		}
+		managers = append(managers, manager)
+
+		if !*rpcEnable && !*ethRPCEnable {
+			continue
+		}
+		server, err := rollupvalidator.NewServer(manager, time.Second*60)
+		if err != nil {
+			stopManagers(managers)
+			return err
+		}
+		if *rpcEnable {
+			s, err := newRPCServer(
+				&rollupvalidator.RPCServer{Server: server},
+				"Validator",
+			)
+			if err != nil {
+				stopManagers(managers)
+				return err
+			}
+			rpcHandlers[chain.Name] = s
+		}
+		if *ethRPCEnable {
+			s, err := newEthRPCServer(ctx, server, client, ethclint)
+			if err != nil {
+				stopManagers(managers)
+				return err
+			}
+			ethRPCHandlers[chain.Name] = s
+		}
+	}
+
+	if err := utils.LaunchMetrics(metricsVars); err != nil {
+		log.Println("Not serving metrics:", err)
+	}
+
+	var serves []func() error
+	if *rpcEnable {
+		serves = append(serves, func() error {
+			return utils.LaunchNamespacedRPC(rpcHandlers, "1235")
+		})
+	}
+	if *ethRPCEnable {
+		serves = append(serves, func() error {
+			return utils.LaunchNamespacedRPC(ethRPCHandlers, ethRPCPort)
+		})
+	}
+	return runUntilShutdown(managers, serves...)
+}
+
+// chainServers creates the RPC servers enabled for a single chain, returning
+// functions which run them
+func chainServers(
+	ctx context.Context,
+	manager *rollupmanager.Manager,
+	client arbbridge.ArbClient,
+	ethclint *ethclient.Client,
+	rpcEnable bool,
+	ethRPCEnable bool,
+) ([]func() error, error) {
+	if !rpcEnable && !ethRPCEnable {
+		return nil, nil
+	}
+	server, err := rollupvalidator.NewServer(manager, time.Second*60)
+	if err != nil {
+		return nil, err
+	}
+	var serves []func() error
+	if rpcEnable {
+		validatorServer := &rollupvalidator.RPCServer{Server: server}
+		serves = append(serves, func() error {
+			return launchRPC(
+				validatorServer,
+				"Validator",
+				"1235",
+			)
+		})
+	}
+	if ethRPCEnable {
+		ethServer, err := newEthRPCServer(ctx, server, client, ethclint)
+		if err != nil {
+			return nil, err
+		}
+		serves = append(serves, func() error {
+			return utils.LaunchRPC(ethServer, ethRPCPort)
+		})
+	}
+	return serves, nil
+}
+
+// newEthRPCServer creates the Ethereum JSON-RPC server for the chain
+// validated by server, which broadcasts raw transactions through ethclint
+func newEthRPCServer(
+	ctx context.Context,
+	server *rollupvalidator.Server,
+	client arbbridge.ArbClient,
+	ethclint *ethclient.Client,
+) (http.Handler, error) {
+	watcher, err := client.NewRollupWatcher(server.RollupAddress())
+	if err != nil {
+		return nil, err
+	}
+	inboxAddress, err := watcher.InboxAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rollupvalidator.NewEthRPCServer(server, ethclint, inboxAddress)
+}
+
+// runUntilShutdown runs each of the RPC servers in serves until the process
+// receives SIGINT or SIGTERM, one of the managers stops on its own or a
+// server fails. It then stops every manager so that their latest
+// checkpoints are written before the process exits
+func runUntilShutdown(managers []*rollupmanager.Manager, serves ...func() error) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	serveErr := make(chan error, len(serves))
+	for _, serve := range serves {
+		go func(serve func() error) {
+			serveErr <- serve()
+		}(serve)
+	}
+
+	stopped := make(chan *rollupmanager.Manager, len(managers))
+	for _, manager := range managers {
+		go func(manager *rollupmanager.Manager) {
+			<-manager.Done()
+			stopped <- manager
+		}(manager)
+	}
+
+	var err error
+	select {
+	case sig := <-sigs:
+		log.Println("Received", sig, "shutting down")
+	case err = <-serveErr:
+		log.Println("RPC server stopped", err)
+	case manager := <-stopped:
+		log.Println("Validator for", manager.RollupAddress.Hex(), "stopped")
+	}
+	if stopErr := stopManagers(managers); err == nil {
+		err = stopErr
+	}
+	return err
+}
+
+// stopManagers stops each manager, returning the first error
+func stopManagers(managers []*rollupmanager.Manager) error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	var err error
+	for _, manager := range managers {
+		if stopErr := manager.Stop(ctx); stopErr != nil {
+			log.Println("Error stopping validator for", manager.RollupAddress.Hex(), stopErr)
+			if err == nil {
+				err = stopErr
+			}
+		}
+	}
+	return err
+}
+
+// startValidator creates a manager for the given rollup which stakes and
+// asserts using client
+func startValidator(
+	client arbbridge.ArbAuthClient,
+	rollupAddress common.Address,
+	strategy rollup.StakingStrategy,
+	contractFile string,
+	dbPath string,
+	logPrefix string,
+	managerCreationFunc func(
+		rollupAddress common.Address,
+		client arbbridge.ArbAuthClient,
+		contractFile string, dbPath string,
+	) (*rollupmanager.Manager, error),
+) (*rollupmanager.Manager, error) {
+	rollupActor, err := client.NewRollup(rollupAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorListener := rollup.NewValidatorChainListener(
+		rollupAddress,
 		rollupActor,
+		strategy,
+		common.NewRealClock(),
 	)
 	err = validatorListener.AddStaker(client)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := managerCreationFunc(
+		rollupAddress,
+		client,
+		contractFile,
+		dbPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	manager.AddListener(&rollup.AnnouncerListener{Prefix: logPrefix})
+	manager.AddListener(validatorListener)
+	return manager, nil
+}
+
+// ObserveRollupChain follows a rollup chain and forms opinions about its
+// assertions without staking. It needs no wallet, and raises an alert on
+// each configured sink whenever an assertion is invalid
+func ObserveRollupChain(execName string) error {
+	observeCmd := flag.NewFlagSet("observe", flag.ExitOnError)
+	metricsVars := utils.AddMetricsFlags(observeCmd)
+	rpcEnable := observeCmd.Bool("rpc", false, "rpc")
+	ethRPCEnable := observeCmd.Bool("ethrpc", false, "serve the Ethereum JSON-RPC interface")
+	blocktime := observeCmd.Int64(
+		"blocktime",
+		2,
+		"blocktime=NumSeconds",
+	)
+	alertLog := observeCmd.Bool("alert-log", true, "log alerts")
+	alertWebhook := observeCmd.String("alert-webhook", "", "alert-webhook=URL to post alerts to")
+	alertFile := observeCmd.String("alert-file", "", "alert-file=Path to append alerts to")
+	err := observeCmd.Parse(os.Args[2:])
 	if err != nil {
 		return err
 	}
 
+	if observeCmd.NArg() != 3 {
+		return fmt.Errorf(
+			"usage: %v observe [--rpc] [--ethrpc] [--blocktime=NumSeconds] [--alert-log] [--alert-webhook=URL] [--alert-file=Path] %v %v",
+			execName,
+			utils.MetricsArgsString,
+			utils.RollupArgsString,
+		)
+	}
+
+	common.SetDurationPerBlock(time.Duration(*blocktime) * time.Second)
+
+	rollupArgs := utils.ParseRollupCommand(observeCmd, 0)
+
+	var sink alerts.MultiSink
+	if *alertLog {
+		sink = append(sink, alerts.LogSink{})
+	}
+	if *alertWebhook != "" {
+		sink = append(sink, alerts.NewWebhookSink(*alertWebhook))
+	}
+	if *alertFile != "" {
+		sink = append(sink, alerts.NewFileSink(*alertFile))
+	}
+	if len(sink) == 0 {
+		return errors.New("observer must have at least one alert sink")
+	}
+
+	ethclint, err := ethclient.Dial(rollupArgs.EthURL)
+	if err != nil {
+		return err
+	}
+	client := ethbridge.NewEthClient(ethclint)
+
 	contractFile := filepath.Join(rollupArgs.ValidatorFolder, "contract.ao")
 	dbPath := filepath.Join(rollupArgs.ValidatorFolder, "checkpoint_db")
 
-	manager, err := managerCreationFunc(
+	manager, err := rollupmanager.CreateManager(
 		rollupArgs.Address,
 		client,
 		contractFile,
 		dbPath,
 	)
-
 	if err != nil {
 		return err
 	}
 	manager.AddListener(&rollup.AnnouncerListener{})
-	manager.AddListener(validatorListener)
+	manager.AddListener(rollup.NewAlertListener(sink))
 
-	if *rpcEnable {
-		validatorServer := rollupvalidator.NewRPCServer(manager, time.Second*60)
-
-		if err := launchRPC(
-			validatorServer,
-			"Validator",
-			"1235",
-		); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		wait := make(chan bool)
-		<-wait
+	if err := utils.LaunchMetrics(metricsVars); err != nil {
+		log.Println("Not serving metrics:", err)
+	}
+
+	serves, err := chainServers(
+		context.Background(),
+		manager,
+		client,
+		ethclint,
+		*rpcEnable,
+		*ethRPCEnable,
+	)
+	if err != nil {
+		stopManagers([]*rollupmanager.Manager{manager})
+		return err
 	}
-	return nil
+	return runUntilShutdown([]*rollupmanager.Manager{manager}, serves...)
 }
 
 func launchRPC(receiver interface{}, name string, port string) error {
-	// Run server
+	s, err := newRPCServer(receiver, name)
+	if err != nil {
+		return err
+	}
+	return utils.LaunchRPC(s, port)
+}
+
+func newRPCServer(receiver interface{}, name string) (*rpc.Server, error) {
 	s := rpc.NewServer()
 	s.RegisterCodec(
 		json.NewCodec(),
@@ -163,8 +598,7 @@ func launchRPC(receiver interface{}, name string, port string) error {
 	)
 
 	if err := s.RegisterService(receiver, name); err != nil {
-		return err
+		return nil, err
 	}
-
-	return utils.LaunchRPC(s, port)
+	return s, nil
 }
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// ChainConfig describes one of the rollup chains validated by a process.
+// Name identifies the chain in its data directory and RPC path and defaults
+// to the rollup address. Contract defaults to contract.ao in the chain's
+// data directory and Strategy defaults to aggressive
+type ChainConfig struct {
+	Name          string `json:"name"`
+	RollupAddress string `json:"rollupAddress"`
+	Contract      string `json:"contract"`
+	Strategy      string `json:"strategy"`
+}
+
+type ValidatorConfig struct {
+	Chains []ChainConfig `json:"chains"`
+}
+
+// LoadValidatorConfig reads a config file listing the chains to validate,
+// filling in defaults relative to validatorFolder
+func LoadValidatorConfig(path string, validatorFolder string) (*ValidatorConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &ValidatorConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing validator config: %v", err)
+	}
+	if len(config.Chains) == 0 {
+		return nil, errors.New("validator config has no chains")
+	}
+	names := make(map[string]bool)
+	addresses := make(map[common.Address]bool)
+	for i := range config.Chains {
+		chain := &config.Chains[i]
+		if !ethcommon.IsHexAddress(chain.RollupAddress) {
+			return nil, fmt.Errorf("invalid rollup address %v", chain.RollupAddress)
+		}
+		address := chain.Address()
+		if addresses[address] {
+			return nil, fmt.Errorf("rollup %v is listed more than once", chain.RollupAddress)
+		}
+		addresses[address] = true
+
+		if chain.Name == "" {
+			chain.Name = address.Hex()
+		}
+		if strings.ContainsAny(chain.Name, "/\\") {
+			return nil, fmt.Errorf("chain name %v can't contain a path separator", chain.Name)
+		}
+		if names[chain.Name] {
+			return nil, fmt.Errorf("chain name %v is used more than once", chain.Name)
+		}
+		names[chain.Name] = true
+
+		if chain.Contract == "" {
+			chain.Contract = filepath.Join(chain.Folder(validatorFolder), "contract.ao")
+		}
+		if chain.Strategy == "" {
+			chain.Strategy = "aggressive"
+		}
+	}
+	return config, nil
+}
+
+func (c ChainConfig) Address() common.Address {
+	return common.HexToAddress(c.RollupAddress)
+}
+
+// Folder is the directory holding the chain's checkpoint database
+func (c ChainConfig) Folder(validatorFolder string) string {
+	return filepath.Join(validatorFolder, c.Name)
+}
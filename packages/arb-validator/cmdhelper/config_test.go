@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir string, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadValidatorConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validatorconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeConfig(t, dir, `{"chains": [
+		{"name": "first", "rollupAddress": "0x1000000000000000000000000000000000000001", "strategy": "defensive"},
+		{"rollupAddress": "0x2000000000000000000000000000000000000002", "contract": "/contracts/second.ao"}
+	]}`)
+	config, err := LoadValidatorConfig(path, "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Chains) != 2 {
+		t.Fatalf("expected 2 chains but got %v", len(config.Chains))
+	}
+
+	first := config.Chains[0]
+	if first.Folder("/data") != filepath.Join("/data", "first") ||
+		first.Contract != filepath.Join("/data", "first", "contract.ao") ||
+		first.Strategy != "defensive" {
+		t.Errorf("unexpected config for first chain %+v", first)
+	}
+
+	second := config.Chains[1]
+	if second.Name != second.Address().Hex() ||
+		second.Contract != "/contracts/second.ao" ||
+		second.Strategy != "aggressive" {
+		t.Errorf("unexpected config for second chain %+v", second)
+	}
+}
+
+func TestLoadValidatorConfigInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validatorconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configs := map[string]string{
+		"empty":            `{"chains": []}`,
+		"bad address":      `{"chains": [{"rollupAddress": "0x1234"}]}`,
+		"duplicate name":   `{"chains": [{"name": "a", "rollupAddress": "0x1000000000000000000000000000000000000001"}, {"name": "a", "rollupAddress": "0x2000000000000000000000000000000000000002"}]}`,
+		"duplicate rollup": `{"chains": [{"name": "a", "rollupAddress": "0x1000000000000000000000000000000000000001"}, {"name": "b", "rollupAddress": "0x1000000000000000000000000000000000000001"}]}`,
+		"path in name":     `{"chains": [{"name": "../a", "rollupAddress": "0x1000000000000000000000000000000000000001"}]}`,
+	}
+	for name, contents := range configs {
+		if _, err := LoadValidatorConfig(writeConfig(t, dir, contents), dir); err == nil {
+			t.Errorf("expected %v config to fail", name)
+		}
+	}
+}
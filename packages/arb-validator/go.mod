@@ -11,6 +11,7 @@ require (
 	github.com/gorilla/rpc v1.2.0
 	github.com/offchainlabs/arbitrum/packages/arb-avm-cpp v0.5.0
 	github.com/offchainlabs/arbitrum/packages/arb-avm-go v0.5.0
+	github.com/offchainlabs/arbitrum/packages/arb-provider-go v0.5.0
 	github.com/offchainlabs/arbitrum/packages/arb-util v0.5.0
 	github.com/offchainlabs/arbitrum/packages/arb-validator-core v0.5.0
 	github.com/pkg/errors v0.9.1
@@ -22,6 +23,8 @@ replace github.com/offchainlabs/arbitrum/packages/arb-avm-go => ../arb-avm-go
 
 replace github.com/offchainlabs/arbitrum/packages/arb-avm-cpp => ../arb-avm-cpp
 
+replace github.com/offchainlabs/arbitrum/packages/arb-provider-go => ../arb-provider-go
+
 replace github.com/offchainlabs/arbitrum/packages/arb-util => ../arb-util
 
 replace github.com/offchainlabs/arbitrum/packages/arb-validator-core => ../arb-validator-core
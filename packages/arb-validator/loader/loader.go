@@ -20,24 +20,29 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/checkpoint"
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
-	"github.com/offchainlabs/arbitrum/packages/arb-validator/testmachine"
 )
 
 func LoadMachineFromFile(fileName string, warnMode bool, vmtype string) (machine.Machine, error) {
 	if strings.EqualFold(vmtype, "go") {
 		return goloader.LoadMachineFromFile(fileName, warnMode)
 	} else if strings.EqualFold(vmtype, "cpp") {
-		return cmachine.New(fileName)
+		return loadCppMachine(fileName)
 	} else if strings.EqualFold(vmtype, "test") {
-		return testmachine.New(fileName, warnMode)
+		return loadTestMachine(fileName, warnMode)
 	} else {
 		return nil, fmt.Errorf("invalid machine type specified %v", vmtype)
 	}
 }
 
-func CreateCheckpointStorage(dbPath string, contractFile string) (machine.CheckpointStorage, error) {
-	return cmachine.NewCheckpoint(dbPath, contractFile)
+func CreateCheckpointStorage(dbPath string, contractFile string, vmtype string) (machine.CheckpointStorage, error) {
+	if strings.EqualFold(vmtype, "go") {
+		return checkpoint.NewCheckpointStorage(dbPath, contractFile)
+	} else if strings.EqualFold(vmtype, "cpp") {
+		return createCppCheckpointStorage(dbPath, contractFile)
+	} else {
+		return nil, fmt.Errorf("invalid checkpoint storage type specified %v", vmtype)
+	}
 }
@@ -0,0 +1,46 @@
+//go:build cgo
+// +build cgo
+
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/testmachine"
+)
+
+// DefaultVMType is the machine and checkpoint storage implementation used
+// when none is configured
+const DefaultVMType = "cpp"
+
+// TestVMType is the machine implementation used by tests and the dummy
+// checkpointer
+const TestVMType = "test"
+
+func loadCppMachine(fileName string) (machine.Machine, error) {
+	return cmachine.New(fileName)
+}
+
+func loadTestMachine(fileName string, warnMode bool) (machine.Machine, error) {
+	return testmachine.New(fileName, warnMode)
+}
+
+func createCppCheckpointStorage(dbPath string, contractFile string) (machine.CheckpointStorage, error) {
+	return cmachine.NewCheckpoint(dbPath, contractFile)
+}
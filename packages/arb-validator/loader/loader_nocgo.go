@@ -0,0 +1,49 @@
+//go:build !cgo
+// +build !cgo
+
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// DefaultVMType is the machine and checkpoint storage implementation used
+// when none is configured. Only the Go implementation is available in builds
+// without cgo
+const DefaultVMType = "go"
+
+// TestVMType is the machine implementation used by tests and the dummy
+// checkpointer
+const TestVMType = "go"
+
+var errNoCgo = errors.New("the C++ machine requires building with cgo")
+
+func loadCppMachine(string) (machine.Machine, error) {
+	return nil, errNoCgo
+}
+
+func loadTestMachine(string, bool) (machine.Machine, error) {
+	return nil, errNoCgo
+}
+
+func createCppCheckpointStorage(string, string) (machine.CheckpointStorage, error) {
+	return nil, errNoCgo
+}
@@ -0,0 +1,124 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/alerts"
+)
+
+// AlertListener watches the opinions formed by an opinionated chain observer
+// and raises an alert whenever an assertion turns out to be invalid. It never
+// acts on the chain itself
+type AlertListener struct {
+	sync.Mutex
+	sink    alerts.Sink
+	alerted map[common.Hash]bool
+}
+
+func NewAlertListener(sink alerts.Sink) *AlertListener {
+	return &AlertListener{
+		sink:    sink,
+		alerted: make(map[common.Hash]bool),
+	}
+}
+
+func (al *AlertListener) StartedChain(context.Context, *ChainObserver) {}
+func (al *AlertListener) StakeCreated(context.Context, *ChainObserver, arbbridge.StakeCreatedEvent) {
+}
+func (al *AlertListener) StakeRemoved(context.Context, *ChainObserver, arbbridge.StakeRefundedEvent) {
+}
+func (al *AlertListener) StakeMoved(context.Context, *ChainObserver, arbbridge.StakeMovedEvent) {}
+func (al *AlertListener) StartedChallenge(context.Context, *ChainObserver, *Challenge) {
+}
+func (al *AlertListener) ResumedChallenge(context.Context, *ChainObserver, *Challenge) {
+}
+func (al *AlertListener) CompletedChallenge(context.Context, *ChainObserver, arbbridge.ChallengeCompletedEvent) {
+}
+func (al *AlertListener) SawAssertion(context.Context, *ChainObserver, arbbridge.AssertedEvent) {
+}
+func (al *AlertListener) ConfirmedNode(context.Context, *ChainObserver, arbbridge.ConfirmedEvent) {
+}
+func (al *AlertListener) PrunedLeaf(context.Context, *ChainObserver, arbbridge.PrunedEvent) {}
+func (al *AlertListener) MessageDelivered(context.Context, *ChainObserver, arbbridge.MessageDeliveredEvent) {
+}
+
+func (al *AlertListener) AssertionPrepared(context.Context, *ChainObserver, *preparedAssertion) {}
+func (al *AlertListener) ConfirmableNodes(context.Context, *ChainObserver, *valprotocol.ConfirmOpportunity) {
+}
+func (al *AlertListener) PrunableLeafs(context.Context, *ChainObserver, []valprotocol.PruneParams) {
+}
+func (al *AlertListener) MootableStakes(context.Context, *ChainObserver, []recoverStakeMootedParams) {
+}
+func (al *AlertListener) OldStakes(context.Context, *ChainObserver, []recoverStakeOldParams) {}
+
+// AdvancedCalculatedValidNode is called with the chain read locked once the
+// observer has decided which successor of a node is correct. If it isn't the
+// valid successor, the assertion made on that node was wrong
+func (al *AlertListener) AdvancedCalculatedValidNode(ctx context.Context, chain *ChainObserver, nodeHash common.Hash) {
+	node, ok := chain.nodeGraph.nodeFromHash[nodeHash]
+	if !ok || node.prev == nil || node.linkType == valprotocol.ValidChildType {
+		return
+	}
+	invalidNode, ok := chain.nodeGraph.nodeFromHash[node.prev.successorHashes[valprotocol.ValidChildType]]
+	if !ok {
+		return
+	}
+
+	al.Lock()
+	defer al.Unlock()
+	if al.alerted[invalidNode.hash] {
+		// Opinions are formed again after the observer restarts
+		return
+	}
+	al.alerted[invalidNode.hash] = true
+
+	var stakers []common.Address
+	// Stakers built on top of the invalid node are just as wrong as the
+	// stakers placed on it
+	chain.nodeGraph.stakers.forall(func(s *Staker) {
+		if isDescendant(s.location, invalidNode) {
+			stakers = append(stakers, s.address)
+		}
+	})
+	alert := &alerts.InvalidAssertion{
+		Rollup:          chain.rollupAddr,
+		Node:            invalidNode.hash,
+		Prev:            node.prev.hash,
+		Depth:           invalidNode.depth,
+		Reason:          node.linkType.String(),
+		AssertionTxHash: invalidNode.assertionTxHash,
+		Stakers:         stakers,
+		Time:            chain.clock.Now(),
+	}
+	// Sinks may be slow, so don't hold up the opinion thread
+	go func() {
+		if err := al.sink.SendAlert(alert); err != nil {
+			log.Println("Failed to send alert", alert, err)
+		}
+	}()
+}
+
+func (al *AlertListener) AdvancedKnownAssertion(context.Context, *ChainObserver, *protocol.ExecutionAssertion, common.Hash, common.Hash) {
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/alerts"
+)
+
+type chanSink chan *alerts.InvalidAssertion
+
+func (s chanSink) SendAlert(alert *alerts.InvalidAssertion) error {
+	s <- alert
+	return nil
+}
+
+func TestAlertOnInvalidNode(t *testing.T) {
+	chain, err := setUpChain(common.Address{5}, "dummy", contractPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := chain.nodeGraph.latestConfirmed
+	doAnAssertion(chain, base)
+	invalidNode := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.ValidChildType)
+	correctNode := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.InvalidExecutionChildType)
+	doAnAssertion(chain, invalidNode)
+	builtOnInvalid := invalidNode.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.ValidChildType)
+
+	onInvalid := common.Address{1}
+	onSuccessor := common.Address{2}
+	onCorrect := common.Address{3}
+	createOneStaker(chain, onInvalid, invalidNode.hash)
+	createOneStaker(chain, onSuccessor, builtOnInvalid.hash)
+	createOneStaker(chain, onCorrect, correctNode.hash)
+
+	chain.clock.(*common.VirtualClock).AdvanceBlocks(5)
+	sink := make(chanSink, 1)
+	listener := NewAlertListener(sink)
+	listener.AdvancedCalculatedValidNode(context.Background(), chain, correctNode.hash)
+
+	var alert *alerts.InvalidAssertion
+	select {
+	case alert = <-sink:
+	case <-time.After(time.Second * 5):
+		t.Fatal("no alert sent for invalid node")
+	}
+	if alert.Node != invalidNode.hash || alert.Prev != base.hash {
+		t.Errorf("alert for wrong node %v", alert)
+	}
+	if alert.Reason != valprotocol.InvalidExecutionChildType.String() {
+		t.Errorf("alert has reason %v", alert.Reason)
+	}
+	if !alert.Time.Equal(chain.clock.Now()) {
+		t.Errorf("alert has time %v rather than chain time %v", alert.Time, chain.clock.Now())
+	}
+	stakers := make(map[common.Address]bool)
+	for _, staker := range alert.Stakers {
+		stakers[staker] = true
+	}
+	if len(alert.Stakers) != 2 || !stakers[onInvalid] || !stakers[onSuccessor] {
+		t.Errorf("alert has stakers %v", alert.Stakers)
+	}
+
+	listener.AdvancedCalculatedValidNode(context.Background(), chain, correctNode.hash)
+	select {
+	case alert := <-sink:
+		t.Error("sent second alert for the same node", alert)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
@@ -39,6 +39,10 @@ func (al *AnnouncerListener) StakeRemoved(ctx context.Context, observer *ChainOb
 	log.Printf("%v Staker %v removed\n", al.Prefix, ev.Staker)
 }
 
+func (al *AnnouncerListener) StartedChain(ctx context.Context, observer *ChainObserver) {
+	log.Println(al.Prefix, "StartedChain at", observer.latestBlockId)
+}
+
 func (al *AnnouncerListener) StakeMoved(ctx context.Context, observer *ChainObserver, ev arbbridge.StakeMovedEvent) {
 	log.Printf("%v Staker %v moved to location: %v\n", al.Prefix, ev.Staker, ev.Location)
 }
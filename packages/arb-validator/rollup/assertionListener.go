@@ -23,6 +23,7 @@ import (
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 )
 
@@ -30,10 +31,69 @@ type FinalizedAssertion struct {
 	Assertion     *protocol.ExecutionAssertion // Disputable assertion
 	OnChainTxHash common.Hash                  // Disputable assertion on-chain Tx hash
 	NodeHash      common.Hash
+	Block         *common.BlockId // L1 block containing the disputable assertion
+}
+
+func (fa FinalizedAssertion) MarshalToBuf() *FinalizedAssertionBuf {
+	logs := make([][]byte, 0, len(fa.Assertion.Logs))
+	for _, logVal := range fa.Assertion.Logs {
+		logs = append(logs, value.MarshalValueToBytes(logVal))
+	}
+	outMsgs := make([][]byte, 0, len(fa.Assertion.OutMsgs))
+	for _, msg := range fa.Assertion.OutMsgs {
+		outMsgs = append(outMsgs, value.MarshalValueToBytes(msg))
+	}
+	return &FinalizedAssertionBuf{
+		Logs:          logs,
+		OutMsgs:       outMsgs,
+		OnChainTxHash: fa.OnChainTxHash.MarshalToBuf(),
+		NodeHash:      fa.NodeHash.MarshalToBuf(),
+		Block:         fa.Block.MarshalToBuf(),
+	}
+}
+
+// Unmarshal restores the logs and messages of the assertion, which are the
+// only parts of the assertion which are saved
+func (m *FinalizedAssertionBuf) Unmarshal() (FinalizedAssertion, error) {
+	logs := make([]value.Value, 0, len(m.Logs))
+	for _, logData := range m.Logs {
+		logVal, err := value.UnmarshalValueFromBytes(logData)
+		if err != nil {
+			return FinalizedAssertion{}, err
+		}
+		logs = append(logs, logVal)
+	}
+	outMsgs := make([]value.Value, 0, len(m.OutMsgs))
+	for _, msgData := range m.OutMsgs {
+		msg, err := value.UnmarshalValueFromBytes(msgData)
+		if err != nil {
+			return FinalizedAssertion{}, err
+		}
+		outMsgs = append(outMsgs, msg)
+	}
+	return FinalizedAssertion{
+		Assertion: &protocol.ExecutionAssertion{
+			Logs:    logs,
+			OutMsgs: outMsgs,
+		},
+		OnChainTxHash: m.OnChainTxHash.Unmarshal(),
+		NodeHash:      m.NodeHash.Unmarshal(),
+		Block:         m.Block.Unmarshal(),
+	}, nil
 }
 
 type AssertionListener struct {
 	CompletedAssertionChan chan FinalizedAssertion
+	// StartedChan receives the latest block of the chain observer whenever
+	// it starts, if it's set. Finalized assertions after that block may have
+	// been reorged out and will be finalized again if they're still valid
+	StartedChan chan *common.BlockId
+}
+
+func (al *AssertionListener) StartedChain(ctx context.Context, chain *ChainObserver) {
+	if al.StartedChan != nil {
+		al.StartedChan <- chain.latestBlockId.Clone()
+	}
 }
 
 func (al *AssertionListener) StakeCreated(context.Context, *ChainObserver, arbbridge.StakeCreatedEvent) {
@@ -68,9 +128,16 @@ func (al *AssertionListener) OldStakes(context.Context, *ChainObserver, []recove
 func (al *AssertionListener) AdvancedCalculatedValidNode(context.Context, *ChainObserver, common.Hash) {
 }
 func (al *AssertionListener) AdvancedKnownAssertion(ctx context.Context, chain *ChainObserver, assertion *protocol.ExecutionAssertion, txHash common.Hash, validNodeHash common.Hash) {
+	// Nodes restored from checkpoints written before the assertion block was
+	// recorded fall back to the block the assertion was validated at
+	block := chain.latestBlockId
+	if node, ok := chain.nodeGraph.nodeFromHash[validNodeHash]; ok && node.assertionBlock != nil {
+		block = node.assertionBlock
+	}
 	al.CompletedAssertionChan <- FinalizedAssertion{
 		Assertion:     assertion,
 		OnChainTxHash: txHash,
 		NodeHash:      validNodeHash,
+		Block:         block.Clone(),
 	}
 }
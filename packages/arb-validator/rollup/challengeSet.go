@@ -54,6 +54,10 @@ func (cs *ChallengeSet) Get(addr common.Address) *Challenge {
 	return cs.idx[addr]
 }
 
+func (cs *ChallengeSet) NumChallenges() int {
+	return len(cs.idx)
+}
+
 func (cs *ChallengeSet) forall(f func(*Challenge)) {
 	for _, v := range cs.idx {
 		f(v)
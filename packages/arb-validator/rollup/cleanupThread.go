@@ -18,7 +18,6 @@ package rollup
 
 import (
 	"context"
-	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 )
@@ -36,14 +35,16 @@ type recoverStakeMootedParams struct {
 }
 
 func (chain *ChainObserver) startCleanupThread(ctx context.Context) {
+	chain.threads.Add(1)
 	go func() {
-		ticker := time.NewTicker(common.NewTimeBlocksInt(2).Duration())
+		defer chain.threads.Done()
+		ticker := chain.clock.NewTicker(common.NewTimeBlocksInt(2).Duration())
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
+			case <-ticker.Chan():
 				chain.RLock()
 				if !chain.atHead {
 					chain.RUnlock()
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+type confirmRecorder struct {
+	ChainListener
+	confirmable chan *valprotocol.ConfirmOpportunity
+}
+
+func (r *confirmRecorder) ConfirmableNodes(
+	ctx context.Context,
+	chain *ChainObserver,
+	conf *valprotocol.ConfirmOpportunity,
+) {
+	select {
+	case r.confirmable <- conf:
+	default:
+	}
+}
+
+func TestConfirmAfterDeadline(t *testing.T) {
+	chain, err := setUpChain(common.Address{5}, "dummy", contractPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := chain.clock.(*common.VirtualClock)
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	base := chain.nodeGraph.latestConfirmed
+	doAnAssertion(chain, base)
+	invalidNode := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.InvalidExecutionChildType)
+	createOneStaker(chain, common.Address{1}, invalidNode.hash)
+
+	recorder := &confirmRecorder{confirmable: make(chan *valprotocol.ConfirmOpportunity, 1)}
+	chain.AddListener(recorder)
+	chain.NowAtHead()
+
+	lastTicks := new(big.Int).Sub(invalidNode.deadline.Val, big.NewInt(1))
+	height := new(big.Int).Div(lastTicks, big.NewInt(common.TicksPerBlock))
+	chain.NotifyNewBlock(&common.BlockId{Height: common.NewTimeBlocks(height)})
+	clock.AdvanceBlocks(2)
+	select {
+	case conf := <-recorder.confirmable:
+		t.Fatal("node confirmable before its deadline", conf)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// Passing the deadline on L1 isn't enough until the clock ticks
+	height.Add(height, big.NewInt(1))
+	chain.NotifyNewBlock(&common.BlockId{Height: common.NewTimeBlocks(height)})
+	select {
+	case conf := <-recorder.confirmable:
+		t.Fatal("confirmed without the clock advancing", conf)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	var conf *valprotocol.ConfirmOpportunity
+	for conf == nil {
+		clock.AdvanceBlocks(2)
+		select {
+		case conf = <-recorder.confirmable:
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	if len(conf.Nodes) != 1 {
+		t.Fatalf("confirmed %v nodes", len(conf.Nodes))
+	}
+	if _, ok := conf.Nodes[0].(valprotocol.ConfirmInvalidOpportunity); !ok {
+		t.Errorf("confirmed wrong node %v", conf.Nodes[0])
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"log"
 	"math/big"
 	"sync"
-	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 
@@ -37,6 +36,9 @@ const (
 )
 
 type ChainListener interface {
+	// StartedChain is called when the chain observer starts, either from
+	// scratch or restored from a checkpoint after a restart or reorg
+	StartedChain(context.Context, *ChainObserver)
 	StakeCreated(context.Context, *ChainObserver, arbbridge.StakeCreatedEvent)
 	StakeRemoved(context.Context, *ChainObserver, arbbridge.StakeRefundedEvent)
 	StakeMoved(context.Context, *ChainObserver, arbbridge.StakeMovedEvent)
@@ -67,53 +69,65 @@ type ValidatorChainListener struct {
 	sync.Mutex
 	actor                  arbbridge.ArbRollup
 	rollupAddress          common.Address
+	strategy               StakingStrategy
+	clock                  common.Clock
 	stakingKeys            map[common.Address]*StakingKey
 	broadcastAssertions    map[common.Hash]*valprotocol.AssertionParams
 	broadcastConfirmations map[common.Hash]bool
 	broadcastLeafPrunes    map[common.Hash]bool
 	broadcastCreateStakes  map[common.Address]*common.TimeBlocks
+	// pendingChallenges holds the asserter and challenger of every challenge
+	// which has been sent but hasn't yet been seen on chain
+	pendingChallenges map[common.Address]common.Address
 }
 
-func NewValidatorChainListener(ctx context.Context, rollupAddress common.Address, actor arbbridge.ArbRollup) *ValidatorChainListener {
-	ret := &ValidatorChainListener{
+func NewValidatorChainListener(
+	rollupAddress common.Address,
+	actor arbbridge.ArbRollup,
+	strategy StakingStrategy,
+	clock common.Clock,
+) *ValidatorChainListener {
+	return &ValidatorChainListener{
 		actor:                  actor,
 		rollupAddress:          rollupAddress,
+		strategy:               strategy,
+		clock:                  clock,
 		stakingKeys:            make(map[common.Address]*StakingKey),
 		broadcastAssertions:    make(map[common.Hash]*valprotocol.AssertionParams),
 		broadcastConfirmations: make(map[common.Hash]bool),
 		broadcastLeafPrunes:    make(map[common.Hash]bool),
 		broadcastCreateStakes:  make(map[common.Address]*common.TimeBlocks),
+		pendingChallenges:      make(map[common.Address]common.Address),
 	}
-	go func() {
-		ticker := time.NewTicker(common.NewTimeBlocksInt(30).Duration())
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				ret.Lock()
-				ret.broadcastAssertions = make(map[common.Hash]*valprotocol.AssertionParams)
-				ret.broadcastConfirmations = make(map[common.Hash]bool)
-				ret.broadcastLeafPrunes = make(map[common.Hash]bool)
-				ret.broadcastCreateStakes = make(map[common.Address]*common.TimeBlocks)
-				ret.Unlock()
-			}
-		}
-	}()
-	return ret
 }
 
-func stakeLatestValid(ctx context.Context, chain *ChainObserver, stakingKey *StakingKey) error {
-	location := chain.knownValidNode
+func placeStake(ctx context.Context, chain *ChainObserver, stakingKey *StakingKey, location *Node) error {
 	proof1 := GeneratePathProof(chain.nodeGraph.latestConfirmed, location)
 	proof2 := GeneratePathProof(location, chain.nodeGraph.getLeaf(location))
 	stakeAmount := chain.nodeGraph.params.StakeRequirement
 
-	log.Println("Placing stake for", stakingKey.client.Address())
+	log.Println("Placing stake for", stakingKey.client.Address(), "on", location.hash)
 	return stakingKey.contract.PlaceStake(ctx, stakeAmount, proof1, proof2)
 }
 
+// stakeLocation returns the node the strategy wants new stakes placed on, or
+// nil if the validator shouldn't stake
+func (lis *ValidatorChainListener) stakeLocation(chain *ChainObserver) *Node {
+	locationHash, ok := lis.strategy.StakeLocation(
+		chain.nodeGraph.nodeInfo(chain.nodeGraph.latestConfirmed),
+		chain.nodeGraph.nodeInfo(chain.knownValidNode),
+	)
+	if !ok {
+		return nil
+	}
+	location, ok := chain.nodeGraph.nodeFromHash[locationHash]
+	if !ok || GeneratePathProof(chain.nodeGraph.latestConfirmed, location) == nil {
+		log.Println("Strategy picked stake location", locationHash, "which isn't a descendant of the latest confirmed node")
+		return nil
+	}
+	return location
+}
+
 func (lis *ValidatorChainListener) AddStaker(client arbbridge.ArbAuthClient) error {
 	contract, err := client.NewRollup(lis.rollupAddress)
 	if err != nil {
@@ -158,35 +172,41 @@ func (lis *ValidatorChainListener) AssertionPrepared(ctx context.Context, chain
 		return
 	}
 
-	for stakingAddress, stakingKey := range lis.stakingKeys {
-		stakerPos := chain.nodeGraph.stakers.Get(stakingAddress)
-		if stakerPos == nil {
-			// stakingKey is not staked
-			continue
-		}
-		proof := GeneratePathProof(stakerPos.location, leaf)
-		if proof == nil {
-			// staker can't move to new asertion
-			continue
-		}
-		lis.Lock()
-		lis.broadcastAssertions[prepared.leafHash] = prepared.params
-		lis.Unlock()
-		log.Printf("%v is making an assertion\n", stakingAddress)
-		go func() {
-			err := makeAssertion(ctx, stakingKey.contract, prepared.Clone(), proof)
-			if err != nil {
-				log.Println("Error making assertion", err)
-				lis.Lock()
-				delete(lis.broadcastAssertions, prepared.leafHash)
-				lis.Unlock()
-			} else {
-				log.Println("Successfully made assertion")
+	if lis.strategy.ShouldAssert(prepared.params, prepared.claim) {
+		for stakingAddress, stakingKey := range lis.stakingKeys {
+			stakerPos := chain.nodeGraph.stakers.Get(stakingAddress)
+			if stakerPos == nil {
+				// stakingKey is not staked
+				continue
 			}
-		}()
-		return
+			proof := GeneratePathProof(stakerPos.location, leaf)
+			if proof == nil {
+				// staker can't move to new asertion
+				continue
+			}
+			lis.Lock()
+			lis.broadcastAssertions[prepared.leafHash] = prepared.params
+			lis.Unlock()
+			log.Printf("%v is making an assertion\n", stakingAddress)
+			chain.launchThread(func() {
+				err := makeAssertion(ctx, stakingKey.contract, prepared.Clone(), proof)
+				if err != nil {
+					log.Println("Error making assertion", err)
+					lis.Lock()
+					delete(lis.broadcastAssertions, prepared.leafHash)
+					lis.Unlock()
+				} else {
+					log.Println("Successfully made assertion")
+				}
+			})
+			return
+		}
 	}
 
+	location := lis.stakeLocation(chain)
+	if location == nil {
+		return
+	}
 	log.Println("Maybe putting down stake")
 	for stakingAddress, stakingKey := range lis.stakingKeys {
 		stakerPos := chain.nodeGraph.stakers.Get(stakingAddress)
@@ -204,15 +224,15 @@ func (lis *ValidatorChainListener) AssertionPrepared(ctx context.Context, chain
 			log.Println("No stake is currently down, so setting up a stake")
 			lis.Unlock()
 			// Put down new stake so that we can assert next time
-			go func() {
-				err := stakeLatestValid(ctx, chain, stakingKey)
+			chain.launchThread(func() {
+				err := placeStake(ctx, chain, stakingKey, location)
 				if err != nil {
 					lis.Lock()
 					delete(lis.broadcastCreateStakes, stakingAddress)
 					lis.Unlock()
 					log.Println("Error placing stake", err)
 				}
-			}()
+			})
 			return
 		} else {
 			lis.Unlock()
@@ -239,6 +259,68 @@ func (lis *ValidatorChainListener) initiateChallenge(ctx context.Context, opp *c
 	)
 }
 
+// openChallenges counts the challenges which the validator's stakers are in,
+// including ones which have been sent but haven't reached the chain yet.
+// lis must be locked by the caller
+func (lis *ValidatorChainListener) openChallenges(chain *ChainObserver) int {
+	count := len(lis.pendingChallenges)
+	chain.nodeGraph.challenges.forall(func(c *Challenge) {
+		_, isAsserter := lis.stakingKeys[c.asserter]
+		_, isChallenger := lis.stakingKeys[c.challenger]
+		if isAsserter || isChallenger {
+			count++
+		}
+	})
+	return count
+}
+
+// challengeIfWanted starts the given challenge if the strategy allows it and
+// returns whether it did
+func (lis *ValidatorChainListener) challengeIfWanted(ctx context.Context, chain *ChainObserver, opp *challengeOpportunity) bool {
+	asserter := chain.nodeGraph.stakers.Get(opp.asserter)
+	challenger := chain.nodeGraph.stakers.Get(opp.challenger)
+	if asserter == nil || challenger == nil {
+		return false
+	}
+	lis.Lock()
+	defer lis.Unlock()
+	if lis.isPendingChallenge(opp.asserter) || lis.isPendingChallenge(opp.challenger) {
+		return false
+	}
+	if !lis.strategy.ShouldChallenge(
+		chain.nodeGraph.nodeInfo(chain.nodeGraph.latestConfirmed),
+		stakerInfo(asserter),
+		stakerInfo(challenger),
+		lis.openChallenges(chain),
+	) {
+		return false
+	}
+	lis.pendingChallenges[opp.asserter] = opp.challenger
+	chain.launchThread(func() {
+		err := lis.initiateChallenge(ctx, opp)
+		if err != nil {
+			log.Println("Failed to initiate challenge", err)
+			lis.Lock()
+			delete(lis.pendingChallenges, opp.asserter)
+			lis.Unlock()
+		} else {
+			log.Println("Successfully initiated challenge")
+		}
+	})
+	return true
+}
+
+// isPendingChallenge returns whether the given staker is in a challenge which
+// has been sent but not yet seen on chain. lis must be locked by the caller
+func (lis *ValidatorChainListener) isPendingChallenge(staker common.Address) bool {
+	for asserter, challenger := range lis.pendingChallenges {
+		if asserter == staker || challenger == staker {
+			return true
+		}
+	}
+	return false
+}
+
 func (lis *ValidatorChainListener) StakeCreated(ctx context.Context, chain *ChainObserver, ev arbbridge.StakeCreatedEvent) {
 	_, ok := lis.stakingKeys[ev.Staker]
 	if ok {
@@ -248,20 +330,35 @@ func (lis *ValidatorChainListener) StakeCreated(ctx context.Context, chain *Chai
 		}
 		opp := chain.nodeGraph.checkChallengeOpportunityAny(staker)
 		if opp != nil {
-			go func() {
-				err := lis.initiateChallenge(ctx, opp)
-				if err != nil {
-					log.Println("Failed to initiate challenge", err)
-				} else {
-					log.Println("Successfully initiated challenge")
-				}
-			}()
+			lis.challengeIfWanted(ctx, chain, opp)
 		}
 	} else {
 		lis.challengeStakerIfPossible(ctx, chain, ev.Staker)
 	}
 }
 
+// StartedChain forgets the transactions broadcast for the previous chain
+// every 30 blocks so that any which were dropped get resent
+func (lis *ValidatorChainListener) StartedChain(ctx context.Context, chain *ChainObserver) {
+	chain.launchThread(func() {
+		ticker := lis.clock.NewTicker(common.NewTimeBlocksInt(30).Duration())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.Chan():
+				lis.Lock()
+				lis.broadcastAssertions = make(map[common.Hash]*valprotocol.AssertionParams)
+				lis.broadcastConfirmations = make(map[common.Hash]bool)
+				lis.broadcastLeafPrunes = make(map[common.Hash]bool)
+				lis.broadcastCreateStakes = make(map[common.Address]*common.TimeBlocks)
+				lis.Unlock()
+			}
+		}
+	})
+}
+
 func (lis *ValidatorChainListener) StakeMoved(ctx context.Context, chain *ChainObserver, ev arbbridge.StakeMovedEvent) {
 	lis.challengeStakerIfPossible(ctx, chain, ev.Staker)
 }
@@ -285,35 +382,22 @@ func (lis *ValidatorChainListener) challengeStakerIfPossible(ctx context.Context
 			continue
 		}
 		opp := chain.nodeGraph.checkChallengeOpportunityPair(newStaker, meAsStaker)
-		if opp != nil {
-			go func() {
-				err := lis.initiateChallenge(ctx, opp)
-				if err != nil {
-					log.Println("Failed to initiate challenge", err)
-				} else {
-					log.Println("Successfully initiated challenge")
-				}
-			}()
+		if opp != nil && lis.challengeIfWanted(ctx, chain, opp) {
 			return
 		}
 	}
 	opp := chain.nodeGraph.checkChallengeOpportunityAny(newStaker)
 	if opp != nil {
-		go func() {
-			err := lis.initiateChallenge(ctx, opp)
-			if err != nil {
-				log.Println("Failed to initiate challenge", err)
-			} else {
-				log.Println("Successfully initiated challenge")
-			}
-		}()
-		return
+		lis.challengeIfWanted(ctx, chain, opp)
 	}
 }
 
 // All functions below are either only called if you have a stake down, or don't require a stake
 
 func (lis *ValidatorChainListener) StartedChallenge(ctx context.Context, chain *ChainObserver, chal *Challenge) {
+	lis.Lock()
+	delete(lis.pendingChallenges, chal.asserter)
+	lis.Unlock()
 	lis.launchChallenge(ctx, chain, chal)
 }
 
@@ -329,7 +413,7 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 	if ok {
 		switch chal.conflictNode.linkType {
 		case valprotocol.InvalidInboxTopChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.DefendInboxTopClaim(
 					ctx,
 					asserterKey.client,
@@ -343,15 +427,17 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 						new(big.Int).Add(chal.conflictNode.prev.vmProtoData.InboxCount, chal.conflictNode.disputable.AssertionParams.ImportedMessageCount),
 					),
 					100,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed defending inbox top claim", err)
 				} else {
 					log.Println("Completed defending inbox top claim", res)
 				}
-			}()
+			})
 		case valprotocol.InvalidMessagesChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.DefendMessagesClaim(
 					ctx,
 					asserterKey.client,
@@ -362,15 +448,17 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 					chal.conflictNode.vmProtoData.InboxTop,
 					chal.conflictNode.disputable.AssertionParams.ImportedMessageCount,
 					100,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed defending messages claim", err)
 				} else {
 					log.Println("Completed defending messages claim", res)
 				}
-			}()
+			})
 		case valprotocol.InvalidExecutionChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.DefendExecutionClaim(
 					ctx,
 					asserterKey.client,
@@ -381,13 +469,15 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 					chal.conflictNode.prev.machine,
 					chal.conflictNode.disputable.AssertionParams.NumSteps,
 					50,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed defending execution claim", err)
 				} else {
 					log.Println("Completed defending execution claim", res)
 				}
-			}()
+			})
 		default:
 			log.Fatal("unexpected challenge type")
 		}
@@ -397,7 +487,7 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 	if ok {
 		switch chal.conflictNode.linkType {
 		case valprotocol.InvalidInboxTopChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.ChallengeInboxTopClaim(
 					ctx,
 					challenger.client,
@@ -406,15 +496,17 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 					startLogIndex,
 					chain.inbox.MessageStack,
 					false,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed challenging inbox top claim", err)
 				} else {
 					log.Println("Completed challenging inbox top claim", res)
 				}
-			}()
+			})
 		case valprotocol.InvalidMessagesChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.ChallengeMessagesClaim(
 					ctx,
 					challenger.client,
@@ -425,15 +517,17 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 					chal.conflictNode.vmProtoData.InboxTop,
 					chal.conflictNode.disputable.AssertionParams.ImportedMessageCount,
 					false,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed challenging messages claim", err)
 				} else {
 					log.Println("Completed challenging messages claim", res)
 				}
-			}()
+			})
 		case valprotocol.InvalidExecutionChildType:
-			go func() {
+			chain.launchThread(func() {
 				res, err := challenges.ChallengeExecutionClaim(
 					ctx,
 					challenger.client,
@@ -443,13 +537,15 @@ func (lis *ValidatorChainListener) launchChallenge(ctx context.Context, chain *C
 					chain.executionPrecondition(chal.conflictNode),
 					chal.conflictNode.prev.machine,
 					false,
+					chain.checkpointer,
+					lis.clock,
 				)
 				if err != nil {
 					log.Println("Failed challenging execution claim", err)
 				} else {
 					log.Println("Completed challenging execution claim", res)
 				}
-			}()
+			})
 		default:
 			log.Fatal("unexpected challenge type")
 		}
@@ -473,6 +569,9 @@ func (lis *ValidatorChainListener) CompletedChallenge(ctx context.Context, chain
 func (lis *ValidatorChainListener) ConfirmableNodes(ctx context.Context, observer *ChainObserver, conf *valprotocol.ConfirmOpportunity) {
 	// Anyone confirm a node
 	// No need to have your own stake
+	if !lis.strategy.ShouldConfirm() {
+		return
+	}
 	lis.Lock()
 	_, alreadySent := lis.broadcastConfirmations[conf.CurrentLatestConfirmed]
 	if alreadySent {
@@ -483,7 +582,7 @@ func (lis *ValidatorChainListener) ConfirmableNodes(ctx context.Context, observe
 	lis.Unlock()
 	confClone := conf.Clone()
 
-	go func() {
+	observer.launchThread(func() {
 		err := lis.actor.Confirm(ctx, confClone)
 		if err != nil {
 			log.Println("Failed to confirm valid node", err)
@@ -491,11 +590,14 @@ func (lis *ValidatorChainListener) ConfirmableNodes(ctx context.Context, observe
 			delete(lis.broadcastConfirmations, confClone.CurrentLatestConfirmed)
 			lis.Unlock()
 		}
-	}()
+	})
 }
 
 func (lis *ValidatorChainListener) PrunableLeafs(ctx context.Context, observer *ChainObserver, params []valprotocol.PruneParams) {
 	// Anyone can prune a leaf
+	if !lis.strategy.ShouldPrune() {
+		return
+	}
 	leavesToPrune := make([]valprotocol.PruneParams, 0, len(params))
 	lis.Lock()
 	totalSize := 0
@@ -512,7 +614,7 @@ func (lis *ValidatorChainListener) PrunableLeafs(ctx context.Context, observer *
 		}
 	}
 	lis.Unlock()
-	go func() {
+	observer.launchThread(func() {
 		err := lis.actor.PruneLeaves(ctx, leavesToPrune)
 		if err != nil {
 			log.Println("Failed pruning leaves", err)
@@ -522,13 +624,16 @@ func (lis *ValidatorChainListener) PrunableLeafs(ctx context.Context, observer *
 			}
 			lis.Unlock()
 		}
-	}()
+	})
 }
 
 func (lis *ValidatorChainListener) MootableStakes(ctx context.Context, observer *ChainObserver, params []recoverStakeMootedParams) {
 	// Anyone can moot any stake
+	if !lis.strategy.ShouldRecoverStakes() {
+		return
+	}
 	for _, moot := range params {
-		go func() {
+		observer.launchThread(func() {
 			lis.actor.RecoverStakeMooted(
 				ctx,
 				moot.ancestorHash,
@@ -536,20 +641,23 @@ func (lis *ValidatorChainListener) MootableStakes(ctx context.Context, observer
 				moot.lcProof,
 				moot.stProof,
 			)
-		}()
+		})
 	}
 }
 
 func (lis *ValidatorChainListener) OldStakes(ctx context.Context, observer *ChainObserver, params []recoverStakeOldParams) {
 	// Anyone can remove an old stake
+	if !lis.strategy.ShouldRecoverStakes() {
+		return
+	}
 	for _, old := range params {
-		go func() {
+		observer.launchThread(func() {
 			lis.actor.RecoverStakeOld(
 				ctx,
 				old.addr,
 				old.proof,
 			)
-		}()
+		})
 	}
 }
 
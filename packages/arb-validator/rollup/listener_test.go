@@ -0,0 +1,97 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+type challengeRecorder struct {
+	arbbridge.ArbRollup
+	started chan [2]common.Address
+}
+
+func (r *challengeRecorder) StartChallenge(
+	ctx context.Context,
+	asserterAddress common.Address,
+	challengerAddress common.Address,
+	prevNode common.Hash,
+	disputableDeadline *big.Int,
+	asserterPosition valprotocol.ChildType,
+	challengerPosition valprotocol.ChildType,
+	asserterVMProtoHash common.Hash,
+	challengerVMProtoHash common.Hash,
+	asserterProof []common.Hash,
+	challengerProof []common.Hash,
+	asserterNodeHash common.Hash,
+	challengerDataHash common.Hash,
+	challengerPeriodTicks common.TimeTicks,
+) error {
+	r.started <- [2]common.Address{asserterAddress, challengerAddress}
+	return nil
+}
+
+func TestSingleChallengeLaunched(t *testing.T) {
+	chain, err := setUpChain(common.Address{5}, "dummy", contractPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := chain.nodeGraph.latestConfirmed
+	doAnAssertion(chain, base)
+	validNode := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.ValidChildType)
+	invalidExecution := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.InvalidExecutionChildType)
+	invalidMessages := base.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.InvalidMessagesChildType)
+
+	recorder := &challengeRecorder{started: make(chan [2]common.Address, 2)}
+	listener := NewValidatorChainListener(
+		chain.rollupAddr,
+		recorder,
+		NewDefensiveStrategy(),
+		common.NewVirtualClock(time.Unix(0, 0)),
+	)
+	me := common.Address{1}
+	listener.stakingKeys[me] = &StakingKey{contract: recorder}
+	createOneStaker(chain, me, validNode.hash)
+	chain.AddListener(listener)
+
+	// Both stakers conflict with ours, but the first challenge hasn't reached
+	// the chain when the second staker appears
+	createOneStaker(chain, common.Address{2}, invalidExecution.hash)
+	createOneStaker(chain, common.Address{3}, invalidMessages.hash)
+
+	select {
+	case <-recorder.started:
+	case <-time.After(time.Second * 5):
+		t.Fatal("no challenge started")
+	}
+	select {
+	case pair := <-recorder.started:
+		t.Errorf("started a second challenge %v", pair)
+	case <-time.After(time.Millisecond * 100):
+	}
+	if open := listener.openChallenges(chain); open != 1 {
+		t.Errorf("counted %v open challenges", open)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Metrics are only collected when the process is started with --metrics,
+// otherwise these are all no-ops
+var (
+	headBlockGauge            = metrics.NewRegisteredGauge("arb/validator/chain/head", nil)
+	confirmedDepthGauge       = metrics.NewRegisteredGauge("arb/validator/chain/confirmed/depth", nil)
+	knownValidDepthGauge      = metrics.NewRegisteredGauge("arb/validator/chain/knownvalid/depth", nil)
+	calculatedValidDepthGauge = metrics.NewRegisteredGauge("arb/validator/chain/calculatedvalid/depth", nil)
+	stakersGauge              = metrics.NewRegisteredGauge("arb/validator/chain/stakers", nil)
+	leavesGauge               = metrics.NewRegisteredGauge("arb/validator/chain/leaves", nil)
+	challengesGauge           = metrics.NewRegisteredGauge("arb/validator/chain/challenges", nil)
+
+	assertionPrepareTimer   = metrics.NewRegisteredTimer("arb/validator/assertion/prepare", nil)
+	assertionStepsHistogram = metrics.NewRegisteredHistogram(
+		"arb/validator/assertion/steps",
+		nil,
+		metrics.NewExpDecaySample(1028, 0.015),
+	)
+)
+
+// updateMetrics records the current shape of the chain. It must be called
+// with the chain at least read locked
+func (chain *ChainObserver) updateMetrics() {
+	headBlockGauge.Update(chain.latestBlockId.Height.AsInt().Int64())
+	confirmedDepthGauge.Update(int64(chain.nodeGraph.latestConfirmed.depth))
+	knownValidDepthGauge.Update(int64(chain.knownValidNode.depth))
+	calculatedValidDepthGauge.Update(int64(chain.calculatedValidNode.depth))
+	stakersGauge.Update(int64(chain.nodeGraph.stakers.NumStakers()))
+	leavesGauge.Update(int64(chain.nodeGraph.leaves.NumLeaves()))
+	challengesGauge.Update(int64(chain.nodeGraph.challenges.NumChallenges()))
+}
@@ -47,6 +47,9 @@ type Node struct {
 	innerHash       common.Hash
 	hash            common.Hash
 	assertionTxHash common.Hash
+	// assertionBlock is the L1 block containing the assertion, which is nil
+	// for the initial node and for nodes restored from older checkpoints
+	assertionBlock *common.BlockId
 
 	successorHashes [valprotocol.MaxChildType + 1]common.Hash
 	numStakers      uint64
@@ -262,7 +265,7 @@ func (node *Node) MarshalForCheckpoint(ctx *checkpointing.CheckpointContext) *No
 	if node.assertion != nil {
 		assertion = structures.MarshalAssertionForCheckpoint(ctx, node.assertion)
 	}
-	return &NodeBuf{
+	buf := &NodeBuf{
 		PrevHash:        prevHashBuf,
 		Deadline:        node.deadline.MarshalToBuf(),
 		DisputableNode:  disputableNodeBuf,
@@ -276,6 +279,10 @@ func (node *Node) MarshalForCheckpoint(ctx *checkpointing.CheckpointContext) *No
 		Hash:            node.hash.MarshalToBuf(),
 		AssertionTxHash: node.assertionTxHash.MarshalToBuf(),
 	}
+	if node.assertionBlock != nil {
+		buf.AssertionBlockId = node.assertionBlock.MarshalToBuf()
+	}
+	return buf
 }
 
 func (m *NodeBuf) UnmarshalFromCheckpoint(ctx checkpointing.RestoreContext, chain *NodeGraph) *Node {
@@ -298,6 +305,9 @@ func (m *NodeBuf) UnmarshalFromCheckpoint(ctx checkpointing.RestoreContext, chai
 		assertionTxHash: m.AssertionTxHash.Unmarshal(),
 		numStakers:      0,
 	}
+	if m.AssertionBlockId != nil {
+		node.assertionBlock = m.AssertionBlockId.Unmarshal()
+	}
 
 	if m.MachineHash != nil {
 		node.machine = ctx.GetMachine(m.MachineHash.Unmarshal())
@@ -348,6 +358,14 @@ func (node *Node) EqualsFull(n2 *Node) bool {
 		node.numStakers == n2.numStakers
 }
 
+// isDescendant returns true if node is ancestor or is a successor of it
+func isDescendant(node, ancestor *Node) bool {
+	for node != nil && node.depth > ancestor.depth {
+		node = node.prev
+	}
+	return node == ancestor
+}
+
 func CommonAncestor(n1, n2 *Node) *Node {
 	n1, _, _ = GetConflictAncestor(n1, n2)
 	return n1.prev
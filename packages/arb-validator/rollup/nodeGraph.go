@@ -209,7 +209,7 @@ func (chain *NodeGraph) getLeaf(node *Node) *Node {
 func (chain *NodeGraph) CreateNodesOnAssert(
 	prevNode *Node,
 	dispNode *valprotocol.DisputableNode,
-	currentTime *common.TimeBlocks,
+	assertionBlock *common.BlockId,
 	assertionTxHash common.Hash,
 ) {
 	if !chain.leaves.IsLeaf(prevNode) {
@@ -219,12 +219,14 @@ func (chain *NodeGraph) CreateNodesOnAssert(
 
 	// create nodes for invalid branches
 	for kind := valprotocol.ChildType(0); kind <= valprotocol.MaxInvalidChildType; kind++ {
-		newNode := NewNodeFromInvalidPrev(prevNode, dispNode, kind, chain.params, currentTime, assertionTxHash)
+		newNode := NewNodeFromInvalidPrev(prevNode, dispNode, kind, chain.params, assertionBlock.Height, assertionTxHash)
+		newNode.assertionBlock = assertionBlock
 		chain.nodeFromHash[newNode.hash] = newNode
 		chain.leaves.Add(newNode)
 	}
 
-	newNode := NewNodeFromValidPrev(prevNode, dispNode, chain.params, currentTime, assertionTxHash)
+	newNode := NewNodeFromValidPrev(prevNode, dispNode, chain.params, assertionBlock.Height, assertionTxHash)
+	newNode.assertionBlock = assertionBlock
 	chain.nodeFromHash[newNode.hash] = newNode
 	chain.leaves.Add(newNode)
 }
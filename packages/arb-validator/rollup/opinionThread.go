@@ -59,8 +59,11 @@ func (pa *preparedAssertion) Clone() *preparedAssertion {
 }
 
 func (chain *ChainObserver) startOpinionUpdateThread(ctx context.Context) {
+	chain.threads.Add(1)
 	go func() {
-		ticker := time.NewTicker(common.NewTimeBlocksInt(2).Duration())
+		defer chain.threads.Done()
+		ticker := chain.clock.NewTicker(common.NewTimeBlocksInt(2).Duration())
+		defer ticker.Stop()
 		assertionPreparedChan := make(chan *preparedAssertion, 20)
 		preparingAssertions := make(map[common.Hash]bool)
 		preparedAssertions := make(map[common.Hash]*preparedAssertion)
@@ -159,7 +162,7 @@ func (chain *ChainObserver) startOpinionUpdateThread(ctx context.Context) {
 				return
 			case prepped := <-assertionPreparedChan:
 				preparedAssertions[prepped.leafHash] = prepped
-			case <-ticker.C:
+			case <-ticker.Chan():
 				chain.RLock()
 				// Catch up to current head
 				for !chain.nodeGraph.leaves.IsLeaf(chain.calculatedValidNode) {
@@ -213,6 +216,7 @@ func (chain *ChainObserver) startOpinionUpdateThread(ctx context.Context) {
 }
 
 func (chain *ChainObserver) prepareAssertion() *preparedAssertion {
+	startTime := time.Now()
 	chain.RLock()
 	currentOpinion := chain.calculatedValidNode
 	currentOpinionHash := currentOpinion.hash
@@ -249,6 +253,9 @@ func (chain *ChainObserver) prepareAssertion() *preparedAssertion {
 
 	blockReason := mach.IsBlocked(currentHeight, false)
 
+	assertionPrepareTimer.UpdateSince(startTime)
+	assertionStepsHistogram.Update(int64(stepsRun))
+
 	log.Printf(
 		"Prepared assertion of %v steps, from %v to %v with block reason %v and timebounds [%v, %v] on top of leaf %v\n",
 		stepsRun,
@@ -0,0 +1,168 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+// NodeInfo is a read-only snapshot of a node in the chain
+type NodeInfo struct {
+	Hash        common.Hash
+	PrevHash    common.Hash
+	Depth       uint64
+	ChildType   valprotocol.ChildType
+	Deadline    common.TimeTicks
+	VMProtoData *valprotocol.VMProtoData
+	NumStakers  uint64
+	IsLeaf      bool
+}
+
+// StakerInfo is a read-only snapshot of a staker. Challenge is the zero
+// address if the staker isn't in a challenge
+type StakerInfo struct {
+	Address       common.Address
+	Location      common.Hash
+	LocationDepth uint64
+	CreationTime  common.TimeTicks
+	Challenge     common.Address
+}
+
+func (s StakerInfo) InChallenge() bool {
+	return !s.Challenge.IsZero()
+}
+
+// ChallengeInfo is a read-only snapshot of an open challenge. Type is the
+// kind of invalidity the challenger is claiming
+type ChallengeInfo struct {
+	Contract     common.Address
+	Type         valprotocol.ChildType
+	Asserter     common.Address
+	Challenger   common.Address
+	ConflictNode common.Hash
+	BlockId      *common.BlockId
+}
+
+func (chain *NodeGraph) nodeInfo(node *Node) NodeInfo {
+	return NodeInfo{
+		Hash:        node.hash,
+		PrevHash:    node.PrevHash(),
+		Depth:       node.depth,
+		ChildType:   node.linkType,
+		Deadline:    node.deadline.Clone(),
+		VMProtoData: node.vmProtoData.Clone(),
+		NumStakers:  node.numStakers,
+		IsLeaf:      chain.leaves.IsLeaf(node),
+	}
+}
+
+func stakerInfo(s *Staker) StakerInfo {
+	return StakerInfo{
+		Address:       s.address,
+		Location:      s.location.hash,
+		LocationDepth: s.location.depth,
+		CreationTime:  s.creationTime.Clone(),
+		Challenge:     s.challenge,
+	}
+}
+
+func sortNodeInfos(nodes []NodeInfo) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return bytes.Compare(nodes[i].Hash[:], nodes[j].Hash[:]) < 0
+	})
+}
+
+// Nodes returns all nodes the chain is tracking ordered by depth
+func (chain *ChainObserver) Nodes() []NodeInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	nodes := make([]NodeInfo, 0, len(chain.nodeGraph.nodeFromHash))
+	for _, node := range chain.nodeGraph.nodeFromHash {
+		nodes = append(nodes, chain.nodeGraph.nodeInfo(node))
+	}
+	sortNodeInfos(nodes)
+	return nodes
+}
+
+// Leaves returns the leaves of the node graph ordered by depth
+func (chain *ChainObserver) Leaves() []NodeInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	leaves := make([]NodeInfo, 0, chain.nodeGraph.leaves.NumLeaves())
+	chain.nodeGraph.leaves.forall(func(node *Node) {
+		leaves = append(leaves, chain.nodeGraph.nodeInfo(node))
+	})
+	sortNodeInfos(leaves)
+	return leaves
+}
+
+// Stakers returns all stakers ordered by address
+func (chain *ChainObserver) Stakers() []StakerInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	var stakers []StakerInfo
+	chain.nodeGraph.stakers.forall(func(s *Staker) {
+		stakers = append(stakers, stakerInfo(s))
+	})
+	sort.Slice(stakers, func(i, j int) bool {
+		return bytes.Compare(stakers[i].Address[:], stakers[j].Address[:]) < 0
+	})
+	return stakers
+}
+
+// Challenges returns all open challenges ordered by contract address
+func (chain *ChainObserver) Challenges() []ChallengeInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	var challenges []ChallengeInfo
+	chain.nodeGraph.challenges.forall(func(c *Challenge) {
+		challenges = append(challenges, ChallengeInfo{
+			Contract:     c.contract,
+			Type:         c.conflictNode.linkType,
+			Asserter:     c.asserter,
+			Challenger:   c.challenger,
+			ConflictNode: c.conflictNode.hash,
+			BlockId:      c.blockId.Clone(),
+		})
+	})
+	sort.Slice(challenges, func(i, j int) bool {
+		return bytes.Compare(challenges[i].Contract[:], challenges[j].Contract[:]) < 0
+	})
+	return challenges
+}
+
+// LatestConfirmedNode returns the most recently confirmed node
+func (chain *ChainObserver) LatestConfirmedNode() NodeInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	return chain.nodeGraph.nodeInfo(chain.nodeGraph.latestConfirmed)
+}
+
+// CalculatedValidNode returns the latest node this validator has calculated
+// to be valid
+func (chain *ChainObserver) CalculatedValidNode() NodeInfo {
+	chain.RLock()
+	defer chain.RUnlock()
+	return chain.nodeGraph.nodeInfo(chain.calculatedValidNode)
+}
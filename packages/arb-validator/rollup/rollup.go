@@ -22,7 +22,6 @@ import (
 	"log"
 	"math/big"
 	"sync"
-	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -49,6 +48,10 @@ type ChainObserver struct {
 	checkpointer        checkpointing.RollupCheckpointer
 	isOpinionated       bool
 	atHead              bool
+	clock               common.Clock
+	// threads tracks the goroutines launched by Start and by listeners
+	// reacting to the chain
+	threads sync.WaitGroup
 }
 
 func NewChain(
@@ -57,6 +60,7 @@ func NewChain(
 	vmParams valprotocol.ChainParams,
 	updateOpinion bool,
 	startBlockId *common.BlockId,
+	clock common.Clock,
 ) (*ChainObserver, error) {
 	mach, err := checkpointer.GetInitialMachine()
 	if err != nil {
@@ -75,6 +79,7 @@ func NewChain(
 		checkpointer:        checkpointer,
 		isOpinionated:       false,
 		atHead:              false,
+		clock:               clock,
 	}
 	ret.Lock()
 	defer ret.Unlock()
@@ -85,6 +90,9 @@ func NewChain(
 }
 
 func (chain *ChainObserver) Start(ctx context.Context) {
+	for _, listener := range chain.listeners {
+		listener.StartedChain(ctx, chain)
+	}
 	chain.nodeGraph.challenges.forall(func(c *Challenge) {
 		for _, listener := range chain.listeners {
 			listener.ResumedChallenge(ctx, chain, c)
@@ -98,6 +106,22 @@ func (chain *ChainObserver) Start(ctx context.Context) {
 	}
 }
 
+// Wait blocks until the threads launched by Start have exited after their
+// context was cancelled
+func (chain *ChainObserver) Wait() {
+	chain.threads.Wait()
+}
+
+// launchThread runs f in a goroutine which Wait waits for. f must return
+// once the context the chain was started with is cancelled
+func (chain *ChainObserver) launchThread(f func()) {
+	chain.threads.Add(1)
+	go func() {
+		defer chain.threads.Done()
+		f()
+	}()
+}
+
 func (chain *ChainObserver) AddListener(listener ChainListener) {
 	chain.Lock()
 	chain.listeners = append(chain.listeners, listener)
@@ -131,6 +155,7 @@ func (m *ChainObserverBuf) UnmarshalFromCheckpoint(
 	ctx context.Context,
 	restoreCtx checkpointing.RestoreContext,
 	checkpointer checkpointing.RollupCheckpointer,
+	clock common.Clock,
 ) (*ChainObserver, error) {
 	nodeGraph := m.StakedNodeGraph.UnmarshalFromCheckpoint(restoreCtx)
 	inbox, err := m.Inbox.UnmarshalFromCheckpoint(restoreCtx)
@@ -149,6 +174,7 @@ func (m *ChainObserverBuf) UnmarshalFromCheckpoint(
 		checkpointer:        checkpointer,
 		isOpinionated:       m.IsOpinionated,
 		atHead:              false,
+		clock:               clock,
 	}, nil
 }
 
@@ -184,12 +210,14 @@ func (chain *ChainObserver) HandleNotification(ctx context.Context, event arbbri
 	case arbbridge.ConfirmedEvent:
 		chain.confirmNode(ctx, ev)
 	}
+	chain.updateMetrics()
 }
 
 func (chain *ChainObserver) NotifyNewBlock(blockId *common.BlockId) {
 	chain.Lock()
 	defer chain.Unlock()
 	chain.latestBlockId = blockId
+	chain.updateMetrics()
 	ckptCtx := checkpointing.NewCheckpointContext()
 	buf, err := chain.marshalToBytes(ckptCtx)
 	if err != nil {
@@ -333,7 +361,7 @@ func (chain *ChainObserver) notifyAssert(ctx context.Context, ev arbbridge.Asser
 	chain.nodeGraph.CreateNodesOnAssert(
 		chain.nodeGraph.nodeFromHash[ev.PrevLeafHash],
 		disputableNode,
-		ev.BlockId.Height,
+		ev.BlockId,
 		ev.TxHash,
 	)
 	for _, listener := range chain.listeners {
@@ -361,7 +389,7 @@ func (chain *ChainObserver) executionPrecondition(node *Node) *valprotocol.Preco
 func (chain *ChainObserver) currentTimeBounds() *protocol.TimeBounds {
 	latestBlock := chain.latestBlockId.Height
 	// Start timestamp slightly in the past to avoid it being invalid
-	latestTimestamp := time.Now().Unix() - 60
+	latestTimestamp := chain.clock.Now().Unix() - 60
 	return &protocol.TimeBounds{
 		LowerBoundBlock:     latestBlock,
 		UpperBoundBlock:     common.NewTimeBlocks(new(big.Int).Add(latestBlock.AsInt(), big.NewInt(int64(chain.nodeGraph.params.MaxBlockBoundsWidth)))),
@@ -48,18 +48,19 @@ type NodeBuf struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PrevHash        *common.HashBuf                   `protobuf:"bytes,1,opt,name=prevHash,proto3" json:"prevHash,omitempty"`
-	Deadline        *common.TimeTicksBuf              `protobuf:"bytes,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
-	DisputableNode  *valprotocol.DisputableNodeBuf    `protobuf:"bytes,3,opt,name=disputableNode,proto3" json:"disputableNode,omitempty"`
-	LinkType        uint32                            `protobuf:"varint,4,opt,name=linkType,proto3" json:"linkType,omitempty"`
-	VmProtoData     *valprotocol.VMProtoDataBuf       `protobuf:"bytes,5,opt,name=vmProtoData,proto3" json:"vmProtoData,omitempty"`
-	MachineHash     *common.HashBuf                   `protobuf:"bytes,6,opt,name=machineHash,proto3" json:"machineHash,omitempty"`
-	Assertion       *structures.ExecutionAssertionBuf `protobuf:"bytes,7,opt,name=assertion,proto3" json:"assertion,omitempty"`
-	Depth           uint64                            `protobuf:"varint,8,opt,name=depth,proto3" json:"depth,omitempty"`
-	NodeDataHash    *common.HashBuf                   `protobuf:"bytes,9,opt,name=nodeDataHash,proto3" json:"nodeDataHash,omitempty"`
-	InnerHash       *common.HashBuf                   `protobuf:"bytes,10,opt,name=innerHash,proto3" json:"innerHash,omitempty"`
-	Hash            *common.HashBuf                   `protobuf:"bytes,11,opt,name=hash,proto3" json:"hash,omitempty"`
-	AssertionTxHash *common.HashBuf                   `protobuf:"bytes,12,opt,name=assertionTxHash,proto3" json:"assertionTxHash,omitempty"`
+	PrevHash         *common.HashBuf                   `protobuf:"bytes,1,opt,name=prevHash,proto3" json:"prevHash,omitempty"`
+	Deadline         *common.TimeTicksBuf              `protobuf:"bytes,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	DisputableNode   *valprotocol.DisputableNodeBuf    `protobuf:"bytes,3,opt,name=disputableNode,proto3" json:"disputableNode,omitempty"`
+	LinkType         uint32                            `protobuf:"varint,4,opt,name=linkType,proto3" json:"linkType,omitempty"`
+	VmProtoData      *valprotocol.VMProtoDataBuf       `protobuf:"bytes,5,opt,name=vmProtoData,proto3" json:"vmProtoData,omitempty"`
+	MachineHash      *common.HashBuf                   `protobuf:"bytes,6,opt,name=machineHash,proto3" json:"machineHash,omitempty"`
+	Assertion        *structures.ExecutionAssertionBuf `protobuf:"bytes,7,opt,name=assertion,proto3" json:"assertion,omitempty"`
+	Depth            uint64                            `protobuf:"varint,8,opt,name=depth,proto3" json:"depth,omitempty"`
+	NodeDataHash     *common.HashBuf                   `protobuf:"bytes,9,opt,name=nodeDataHash,proto3" json:"nodeDataHash,omitempty"`
+	InnerHash        *common.HashBuf                   `protobuf:"bytes,10,opt,name=innerHash,proto3" json:"innerHash,omitempty"`
+	Hash             *common.HashBuf                   `protobuf:"bytes,11,opt,name=hash,proto3" json:"hash,omitempty"`
+	AssertionTxHash  *common.HashBuf                   `protobuf:"bytes,12,opt,name=assertionTxHash,proto3" json:"assertionTxHash,omitempty"`
+	AssertionBlockId *common.BlockIdBuf                `protobuf:"bytes,13,opt,name=assertionBlockId,proto3" json:"assertionBlockId,omitempty"`
 }
 
 func (x *NodeBuf) Reset() {
@@ -178,6 +179,13 @@ func (x *NodeBuf) GetAssertionTxHash() *common.HashBuf {
 	return nil
 }
 
+func (x *NodeBuf) GetAssertionBlockId() *common.BlockIdBuf {
+	if x != nil {
+		return x.AssertionBlockId
+	}
+	return nil
+}
+
 type NodeGraphBuf struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -573,6 +581,85 @@ func (x *ChallengeBuf) GetConflictNodeHash() *common.HashBuf {
 	return nil
 }
 
+type FinalizedAssertionBuf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Logs          [][]byte           `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	OutMsgs       [][]byte           `protobuf:"bytes,2,rep,name=outMsgs,proto3" json:"outMsgs,omitempty"`
+	OnChainTxHash *common.HashBuf    `protobuf:"bytes,3,opt,name=onChainTxHash,proto3" json:"onChainTxHash,omitempty"`
+	NodeHash      *common.HashBuf    `protobuf:"bytes,4,opt,name=nodeHash,proto3" json:"nodeHash,omitempty"`
+	Block         *common.BlockIdBuf `protobuf:"bytes,5,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (x *FinalizedAssertionBuf) Reset() {
+	*x = FinalizedAssertionBuf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rollup_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FinalizedAssertionBuf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizedAssertionBuf) ProtoMessage() {}
+
+func (x *FinalizedAssertionBuf) ProtoReflect() protoreflect.Message {
+	mi := &file_rollup_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizedAssertionBuf.ProtoReflect.Descriptor instead.
+func (*FinalizedAssertionBuf) Descriptor() ([]byte, []int) {
+	return file_rollup_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FinalizedAssertionBuf) GetLogs() [][]byte {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *FinalizedAssertionBuf) GetOutMsgs() [][]byte {
+	if x != nil {
+		return x.OutMsgs
+	}
+	return nil
+}
+
+func (x *FinalizedAssertionBuf) GetOnChainTxHash() *common.HashBuf {
+	if x != nil {
+		return x.OnChainTxHash
+	}
+	return nil
+}
+
+func (x *FinalizedAssertionBuf) GetNodeHash() *common.HashBuf {
+	if x != nil {
+		return x.NodeHash
+	}
+	return nil
+}
+
+func (x *FinalizedAssertionBuf) GetBlock() *common.BlockIdBuf {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
 var File_rollup_proto protoreflect.FileDescriptor
 
 var file_rollup_proto_rawDesc = []byte{
@@ -582,7 +669,7 @@ var file_rollup_proto_rawDesc = []byte{
 	0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x73, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72,
 	0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x76, 0x61, 0x6c, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x76, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
-	0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd9, 0x04, 0x0a, 0x07, 0x4e, 0x6f, 0x64, 0x65,
+	0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x99, 0x05, 0x0a, 0x07, 0x4e, 0x6f, 0x64, 0x65,
 	0x42, 0x75, 0x66, 0x12, 0x2b, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x48, 0x61, 0x73, 0x68, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48,
 	0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x48, 0x61, 0x73, 0x68,
@@ -620,7 +707,11 @@ var file_rollup_proto_rawDesc = []byte{
 	0x72, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x18, 0x0c, 0x20, 0x01, 0x28,
 	0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x42,
 	0x75, 0x66, 0x52, 0x0f, 0x61, 0x73, 0x73, 0x65, 0x72, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x78, 0x48,
-	0x61, 0x73, 0x68, 0x22, 0x97, 0x02, 0x0a, 0x0c, 0x4e, 0x6f, 0x64, 0x65, 0x47, 0x72, 0x61, 0x70,
+	0x61, 0x73, 0x68, 0x12, 0x3e, 0x0a, 0x10, 0x61, 0x73, 0x73, 0x65, 0x72, 0x74, 0x69, 0x6f, 0x6e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x42, 0x75,
+	0x66, 0x52, 0x10, 0x61, 0x73, 0x73, 0x65, 0x72, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x64, 0x22, 0x97, 0x02, 0x0a, 0x0c, 0x4e, 0x6f, 0x64, 0x65, 0x47, 0x72, 0x61, 0x70,
 	0x68, 0x42, 0x75, 0x66, 0x12, 0x25, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20,
 	0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x2e, 0x4e, 0x6f, 0x64,
 	0x65, 0x42, 0x75, 0x66, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x0e, 0x6f,
@@ -707,11 +798,25 @@ var file_rollup_proto_rawDesc = []byte{
 	0x6c, 0x69, 0x63, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x48, 0x61, 0x73, 0x68, 0x18, 0x06, 0x20, 0x01,
 	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68,
 	0x42, 0x75, 0x66, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x4e, 0x6f, 0x64,
-	0x65, 0x48, 0x61, 0x73, 0x68, 0x42, 0x40, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x66, 0x66, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x6c, 0x61, 0x62, 0x73,
-	0x2f, 0x61, 0x72, 0x62, 0x69, 0x74, 0x72, 0x75, 0x6d, 0x2f, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
-	0x65, 0x73, 0x2f, 0x61, 0x72, 0x62, 0x2d, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
-	0x2f, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x48, 0x61, 0x73, 0x68, 0x22, 0xd3, 0x01, 0x0a, 0x15, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69,
+	0x7a, 0x65, 0x64, 0x41, 0x73, 0x73, 0x65, 0x72, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x75, 0x66, 0x12,
+	0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x04, 0x6c,
+	0x6f, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0c, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x73, 0x12, 0x35, 0x0a,
+	0x0d, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x48, 0x61,
+	0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x0d, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x78,
+	0x48, 0x61, 0x73, 0x68, 0x12, 0x2b, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x48, 0x61, 0x73, 0x68,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x48, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x52, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x28, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x64, 0x42, 0x75, 0x66, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x40, 0x5a, 0x3e, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x66, 0x66, 0x63, 0x68, 0x61,
+	0x69, 0x6e, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x61, 0x72, 0x62, 0x69, 0x74, 0x72, 0x75, 0x6d, 0x2f,
+	0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x61, 0x72, 0x62, 0x2d, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -726,7 +831,7 @@ func file_rollup_proto_rawDescGZIP() []byte {
 	return file_rollup_proto_rawDescData
 }
 
-var file_rollup_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_rollup_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_rollup_proto_goTypes = []interface{}{
 	(*NodeBuf)(nil),                          // 0: rollup.NodeBuf
 	(*NodeGraphBuf)(nil),                     // 1: rollup.NodeGraphBuf
@@ -734,55 +839,60 @@ var file_rollup_proto_goTypes = []interface{}{
 	(*ChainObserverBuf)(nil),                 // 3: rollup.ChainObserverBuf
 	(*StakerBuf)(nil),                        // 4: rollup.StakerBuf
 	(*ChallengeBuf)(nil),                     // 5: rollup.ChallengeBuf
-	(*common.HashBuf)(nil),                   // 6: common.HashBuf
-	(*common.TimeTicksBuf)(nil),              // 7: common.TimeTicksBuf
-	(*valprotocol.DisputableNodeBuf)(nil),    // 8: valprotocol.DisputableNodeBuf
-	(*valprotocol.VMProtoDataBuf)(nil),       // 9: valprotocol.VMProtoDataBuf
-	(*structures.ExecutionAssertionBuf)(nil), // 10: structures.ExecutionAssertionBuf
-	(*valprotocol.ChainParamsBuf)(nil),       // 11: valprotocol.ChainParamsBuf
-	(*common.AddressBuf)(nil),                // 12: common.AddressBuf
-	(*structures.InboxBuf)(nil),              // 13: structures.InboxBuf
-	(*common.BlockIdBuf)(nil),                // 14: common.BlockIdBuf
+	(*FinalizedAssertionBuf)(nil),            // 6: rollup.FinalizedAssertionBuf
+	(*common.HashBuf)(nil),                   // 7: common.HashBuf
+	(*common.TimeTicksBuf)(nil),              // 8: common.TimeTicksBuf
+	(*valprotocol.DisputableNodeBuf)(nil),    // 9: valprotocol.DisputableNodeBuf
+	(*valprotocol.VMProtoDataBuf)(nil),       // 10: valprotocol.VMProtoDataBuf
+	(*structures.ExecutionAssertionBuf)(nil), // 11: structures.ExecutionAssertionBuf
+	(*common.BlockIdBuf)(nil),                // 12: common.BlockIdBuf
+	(*valprotocol.ChainParamsBuf)(nil),       // 13: valprotocol.ChainParamsBuf
+	(*common.AddressBuf)(nil),                // 14: common.AddressBuf
+	(*structures.InboxBuf)(nil),              // 15: structures.InboxBuf
 }
 var file_rollup_proto_depIdxs = []int32{
-	6,  // 0: rollup.NodeBuf.prevHash:type_name -> common.HashBuf
-	7,  // 1: rollup.NodeBuf.deadline:type_name -> common.TimeTicksBuf
-	8,  // 2: rollup.NodeBuf.disputableNode:type_name -> valprotocol.DisputableNodeBuf
-	9,  // 3: rollup.NodeBuf.vmProtoData:type_name -> valprotocol.VMProtoDataBuf
-	6,  // 4: rollup.NodeBuf.machineHash:type_name -> common.HashBuf
-	10, // 5: rollup.NodeBuf.assertion:type_name -> structures.ExecutionAssertionBuf
-	6,  // 6: rollup.NodeBuf.nodeDataHash:type_name -> common.HashBuf
-	6,  // 7: rollup.NodeBuf.innerHash:type_name -> common.HashBuf
-	6,  // 8: rollup.NodeBuf.hash:type_name -> common.HashBuf
-	6,  // 9: rollup.NodeBuf.assertionTxHash:type_name -> common.HashBuf
-	0,  // 10: rollup.NodeGraphBuf.nodes:type_name -> rollup.NodeBuf
-	6,  // 11: rollup.NodeGraphBuf.oldestNodeHash:type_name -> common.HashBuf
-	6,  // 12: rollup.NodeGraphBuf.latestConfirmedHash:type_name -> common.HashBuf
-	6,  // 13: rollup.NodeGraphBuf.leafHashes:type_name -> common.HashBuf
-	11, // 14: rollup.NodeGraphBuf.params:type_name -> valprotocol.ChainParamsBuf
-	1,  // 15: rollup.StakedNodeGraphBuf.nodeGraph:type_name -> rollup.NodeGraphBuf
-	4,  // 16: rollup.StakedNodeGraphBuf.stakers:type_name -> rollup.StakerBuf
-	5,  // 17: rollup.StakedNodeGraphBuf.challenges:type_name -> rollup.ChallengeBuf
-	2,  // 18: rollup.ChainObserverBuf.stakedNodeGraph:type_name -> rollup.StakedNodeGraphBuf
-	12, // 19: rollup.ChainObserverBuf.contractAddress:type_name -> common.AddressBuf
-	13, // 20: rollup.ChainObserverBuf.inbox:type_name -> structures.InboxBuf
-	6,  // 21: rollup.ChainObserverBuf.knownValidNode:type_name -> common.HashBuf
-	6,  // 22: rollup.ChainObserverBuf.calculatedValidNode:type_name -> common.HashBuf
-	14, // 23: rollup.ChainObserverBuf.latestBlockId:type_name -> common.BlockIdBuf
-	12, // 24: rollup.StakerBuf.address:type_name -> common.AddressBuf
-	6,  // 25: rollup.StakerBuf.location:type_name -> common.HashBuf
-	7,  // 26: rollup.StakerBuf.creationTime:type_name -> common.TimeTicksBuf
-	12, // 27: rollup.StakerBuf.challengeAddr:type_name -> common.AddressBuf
-	14, // 28: rollup.ChallengeBuf.blockId:type_name -> common.BlockIdBuf
-	12, // 29: rollup.ChallengeBuf.asserter:type_name -> common.AddressBuf
-	12, // 30: rollup.ChallengeBuf.challenger:type_name -> common.AddressBuf
-	12, // 31: rollup.ChallengeBuf.contract:type_name -> common.AddressBuf
-	6,  // 32: rollup.ChallengeBuf.conflictNodeHash:type_name -> common.HashBuf
-	33, // [33:33] is the sub-list for method output_type
-	33, // [33:33] is the sub-list for method input_type
-	33, // [33:33] is the sub-list for extension type_name
-	33, // [33:33] is the sub-list for extension extendee
-	0,  // [0:33] is the sub-list for field type_name
+	7,  // 0: rollup.NodeBuf.prevHash:type_name -> common.HashBuf
+	8,  // 1: rollup.NodeBuf.deadline:type_name -> common.TimeTicksBuf
+	9,  // 2: rollup.NodeBuf.disputableNode:type_name -> valprotocol.DisputableNodeBuf
+	10, // 3: rollup.NodeBuf.vmProtoData:type_name -> valprotocol.VMProtoDataBuf
+	7,  // 4: rollup.NodeBuf.machineHash:type_name -> common.HashBuf
+	11, // 5: rollup.NodeBuf.assertion:type_name -> structures.ExecutionAssertionBuf
+	7,  // 6: rollup.NodeBuf.nodeDataHash:type_name -> common.HashBuf
+	7,  // 7: rollup.NodeBuf.innerHash:type_name -> common.HashBuf
+	7,  // 8: rollup.NodeBuf.hash:type_name -> common.HashBuf
+	7,  // 9: rollup.NodeBuf.assertionTxHash:type_name -> common.HashBuf
+	12, // 10: rollup.NodeBuf.assertionBlockId:type_name -> common.BlockIdBuf
+	0,  // 11: rollup.NodeGraphBuf.nodes:type_name -> rollup.NodeBuf
+	7,  // 12: rollup.NodeGraphBuf.oldestNodeHash:type_name -> common.HashBuf
+	7,  // 13: rollup.NodeGraphBuf.latestConfirmedHash:type_name -> common.HashBuf
+	7,  // 14: rollup.NodeGraphBuf.leafHashes:type_name -> common.HashBuf
+	13, // 15: rollup.NodeGraphBuf.params:type_name -> valprotocol.ChainParamsBuf
+	1,  // 16: rollup.StakedNodeGraphBuf.nodeGraph:type_name -> rollup.NodeGraphBuf
+	4,  // 17: rollup.StakedNodeGraphBuf.stakers:type_name -> rollup.StakerBuf
+	5,  // 18: rollup.StakedNodeGraphBuf.challenges:type_name -> rollup.ChallengeBuf
+	2,  // 19: rollup.ChainObserverBuf.stakedNodeGraph:type_name -> rollup.StakedNodeGraphBuf
+	14, // 20: rollup.ChainObserverBuf.contractAddress:type_name -> common.AddressBuf
+	15, // 21: rollup.ChainObserverBuf.inbox:type_name -> structures.InboxBuf
+	7,  // 22: rollup.ChainObserverBuf.knownValidNode:type_name -> common.HashBuf
+	7,  // 23: rollup.ChainObserverBuf.calculatedValidNode:type_name -> common.HashBuf
+	12, // 24: rollup.ChainObserverBuf.latestBlockId:type_name -> common.BlockIdBuf
+	14, // 25: rollup.StakerBuf.address:type_name -> common.AddressBuf
+	7,  // 26: rollup.StakerBuf.location:type_name -> common.HashBuf
+	8,  // 27: rollup.StakerBuf.creationTime:type_name -> common.TimeTicksBuf
+	14, // 28: rollup.StakerBuf.challengeAddr:type_name -> common.AddressBuf
+	12, // 29: rollup.ChallengeBuf.blockId:type_name -> common.BlockIdBuf
+	14, // 30: rollup.ChallengeBuf.asserter:type_name -> common.AddressBuf
+	14, // 31: rollup.ChallengeBuf.challenger:type_name -> common.AddressBuf
+	14, // 32: rollup.ChallengeBuf.contract:type_name -> common.AddressBuf
+	7,  // 33: rollup.ChallengeBuf.conflictNodeHash:type_name -> common.HashBuf
+	7,  // 34: rollup.FinalizedAssertionBuf.onChainTxHash:type_name -> common.HashBuf
+	7,  // 35: rollup.FinalizedAssertionBuf.nodeHash:type_name -> common.HashBuf
+	12, // 36: rollup.FinalizedAssertionBuf.block:type_name -> common.BlockIdBuf
+	37, // [37:37] is the sub-list for method output_type
+	37, // [37:37] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_rollup_proto_init() }
@@ -863,6 +973,18 @@ func file_rollup_proto_init() {
 				return nil
 			}
 		}
+		file_rollup_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FinalizedAssertionBuf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -870,7 +992,7 @@ func file_rollup_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_rollup_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
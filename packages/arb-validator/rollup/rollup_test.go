@@ -28,6 +28,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 )
 
 var dummyAddress common.Address
@@ -58,7 +59,7 @@ func testCreateEmptyChain(rollupAddress common.Address, checkpointType string, c
 func tryMarshalUnmarshal(chain *ChainObserver, t *testing.T) {
 	ctx := checkpointing.NewCheckpointContext()
 	chainBuf := chain.marshalForCheckpoint(ctx)
-	chain2, err := chainBuf.UnmarshalFromCheckpoint(context.TODO(), ctx, nil)
+	chain2, err := chainBuf.UnmarshalFromCheckpoint(context.TODO(), ctx, nil, chain.clock)
 	if err != nil {
 		t.Error(err)
 	}
@@ -137,6 +138,65 @@ func testChallenge(dummyRollupAddress common.Address, checkpointType string, con
 	tryMarshalUnmarshal(chain, t)
 }
 
+func TestQueries(t *testing.T) {
+	chain, err := setUpChain(dummyRollupAddress1, "dummy", contractPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doAnAssertion(chain, chain.nodeGraph.latestConfirmed)
+	validTip := chain.nodeGraph.latestConfirmed.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.ValidChildType)
+	invalidTip := chain.nodeGraph.latestConfirmed.GetSuccessor(chain.nodeGraph.NodeGraph, valprotocol.InvalidExecutionChildType)
+	asserter := common.Address{1}
+	challenger := common.Address{2}
+	createOneStaker(chain, asserter, validTip.hash)
+	createOneStaker(chain, challenger, invalidTip.hash)
+	chain.nodeGraph.NewChallenge(&Challenge{
+		blockId:      chain.latestBlockId,
+		logIndex:     0,
+		asserter:     asserter,
+		challenger:   challenger,
+		contract:     common.Address{3},
+		conflictNode: invalidTip,
+	})
+
+	nodes := chain.Nodes()
+	if len(nodes) != len(chain.nodeGraph.nodeFromHash) {
+		t.Fatalf("expected %v nodes but got %v", len(chain.nodeGraph.nodeFromHash), len(nodes))
+	}
+	if nodes[0].Hash != chain.nodeGraph.latestConfirmed.hash || nodes[0].Depth != 0 {
+		t.Error("nodes aren't ordered by depth")
+	}
+	for _, node := range nodes {
+		if node.Hash == validTip.hash && (node.ChildType != valprotocol.ValidChildType || node.NumStakers != 1 || !node.IsLeaf) {
+			t.Errorf("unexpected info for valid tip %v", node)
+		}
+	}
+	if len(chain.Leaves()) != chain.nodeGraph.leaves.NumLeaves() {
+		t.Error("unexpected leaf count")
+	}
+
+	stakers := chain.Stakers()
+	if len(stakers) != 2 || stakers[0].Address != asserter || stakers[0].Location != validTip.hash {
+		t.Fatalf("unexpected stakers %v", stakers)
+	}
+	if !stakers[0].InChallenge() || !stakers[1].InChallenge() {
+		t.Error("stakers should be in a challenge")
+	}
+
+	challenges := chain.Challenges()
+	if len(challenges) != 1 || challenges[0].Type != valprotocol.InvalidExecutionChildType || challenges[0].Challenger != challenger {
+		t.Fatalf("unexpected challenges %v", challenges)
+	}
+
+	if chain.LatestConfirmedNode().Hash != chain.nodeGraph.latestConfirmed.hash {
+		t.Error("wrong latest confirmed node")
+	}
+	if chain.CalculatedValidNode().Hash != chain.calculatedValidNode.hash {
+		t.Error("wrong calculated valid node")
+	}
+}
+
 func doAnAssertion(chain *ChainObserver, baseNode *Node) {
 	theMachine := baseNode.machine
 	timeBounds := &protocol.TimeBounds{
@@ -177,7 +237,7 @@ func doAnAssertion(chain *ChainObserver, baseNode *Node) {
 	chain.nodeGraph.CreateNodesOnAssert(
 		baseNode,
 		disputableNode,
-		common.NewTimeBlocks(big.NewInt(10)),
+		&common.BlockId{Height: common.NewTimeBlocks(big.NewInt(10))},
 		common.Hash{},
 	)
 	chain.nodeGraph.nodeFromHash[baseNode.successorHashes[3]].machine = theMachine
@@ -207,7 +267,10 @@ func setUpChain(rollupAddress common.Address, checkpointType string, contractPat
 		checkpointFac := checkpointing.NewDummyCheckpointerFactory(contractPath)
 		checkpointer = checkpointFac.New(context.TODO())
 	case "fresh_rocksdb":
-		checkpointFac := checkpointing.NewIndexedCheckpointerFactory(rollupAddress, contractPath, "", big.NewInt(1000000), true)
+		checkpointFac, err := checkpointing.NewIndexedCheckpointerFactory(rollupAddress, contractPath, "", loader.DefaultVMType, big.NewInt(1000000), true)
+		if err != nil {
+			return nil, err
+		}
 		checkpointer = checkpointFac.New(context.TODO())
 	}
 	chain, err := NewChain(
@@ -226,6 +289,7 @@ func setUpChain(rollupAddress common.Address, checkpointType string, contractPat
 			Height:     common.NewTimeBlocks(big.NewInt(10)),
 			HeaderHash: common.Hash{},
 		},
+		common.NewVirtualClock(time.Now()),
 	)
 	if err != nil {
 		return nil, err
@@ -55,6 +55,10 @@ func (sl *StakerSet) Get(addr common.Address) *Staker {
 	return sl.idx[addr]
 }
 
+func (sl *StakerSet) NumStakers() int {
+	return len(sl.idx)
+}
+
 func (sl *StakerSet) forall(f func(*Staker)) {
 	for _, v := range sl.idx {
 		f(v)
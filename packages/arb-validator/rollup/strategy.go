@@ -0,0 +1,152 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"fmt"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+// StakingStrategy decides which actions a ValidatorChainListener takes on
+// chain. Its methods are called with the chain locked, so they are given
+// snapshots of the state they need rather than the chain itself
+type StakingStrategy interface {
+	// StakeLocation returns the node a new stake should be placed on, which
+	// must be between the latest confirmed node and the latest node known to
+	// be valid. Returning false means the validator doesn't stake
+	StakeLocation(latestConfirmed NodeInfo, knownValid NodeInfo) (common.Hash, bool)
+
+	// ShouldChallenge decides whether to start a challenge between two
+	// conflicting stakers. openChallenges is the number of challenges the
+	// validator's own stakers are already in
+	ShouldChallenge(latestConfirmed NodeInfo, asserter StakerInfo, challenger StakerInfo, openChallenges int) bool
+
+	// ShouldAssert decides whether a prepared assertion is worth making
+	ShouldAssert(params *valprotocol.AssertionParams, claim *valprotocol.AssertionClaim) bool
+
+	ShouldConfirm() bool
+	ShouldPrune() bool
+	ShouldRecoverStakes() bool
+}
+
+// ConfigurableStrategy is a StakingStrategy driven by a fixed set of options
+type ConfigurableStrategy struct {
+	Stake bool
+	// StakeOnConfirmed places new stakes on the latest confirmed node rather
+	// than the latest node known to be valid
+	StakeOnConfirmed bool
+
+	Challenge bool
+	// MaxConcurrentChallenges limits how many challenges the validator's
+	// stakers can be in at once. Zero means no limit
+	MaxConcurrentChallenges int
+	// MaxChallengeDepth skips challenges against stakers which are more
+	// than this many nodes past the latest confirmed node. Zero means no
+	// limit
+	MaxChallengeDepth uint64
+
+	Assert            bool
+	MinAssertionSteps uint64
+	MinAssertionGas   uint64
+
+	Confirm       bool
+	Prune         bool
+	RecoverStakes bool
+}
+
+// NewAggressiveStrategy stakes, asserts, challenges every conflict it finds
+// and keeps the chain moving by confirming, pruning and recovering stakes
+func NewAggressiveStrategy() *ConfigurableStrategy {
+	return &ConfigurableStrategy{
+		Stake:         true,
+		Challenge:     true,
+		Assert:        true,
+		Confirm:       true,
+		Prune:         true,
+		RecoverStakes: true,
+	}
+}
+
+// NewDefensiveStrategy only stakes so that it is able to challenge invalid
+// stakers. It never asserts or spends gas maintaining the chain, and takes
+// on a single challenge at a time
+func NewDefensiveStrategy() *ConfigurableStrategy {
+	return &ConfigurableStrategy{
+		Stake:                   true,
+		Challenge:               true,
+		MaxConcurrentChallenges: 1,
+	}
+}
+
+// NewStrategy returns the named strategy, which is either aggressive or
+// defensive
+func NewStrategy(name string) (*ConfigurableStrategy, error) {
+	switch name {
+	case "aggressive":
+		return NewAggressiveStrategy(), nil
+	case "defensive":
+		return NewDefensiveStrategy(), nil
+	default:
+		return nil, fmt.Errorf("unknown staking strategy %v", name)
+	}
+}
+
+func (s *ConfigurableStrategy) StakeLocation(latestConfirmed NodeInfo, knownValid NodeInfo) (common.Hash, bool) {
+	if !s.Stake {
+		return common.Hash{}, false
+	}
+	if s.StakeOnConfirmed {
+		return latestConfirmed.Hash, true
+	}
+	return knownValid.Hash, true
+}
+
+func (s *ConfigurableStrategy) ShouldChallenge(latestConfirmed NodeInfo, asserter StakerInfo, challenger StakerInfo, openChallenges int) bool {
+	if !s.Challenge {
+		return false
+	}
+	if s.MaxConcurrentChallenges > 0 && openChallenges >= s.MaxConcurrentChallenges {
+		return false
+	}
+	if s.MaxChallengeDepth > 0 {
+		maxDepth := latestConfirmed.Depth + s.MaxChallengeDepth
+		if asserter.LocationDepth > maxDepth || challenger.LocationDepth > maxDepth {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ConfigurableStrategy) ShouldAssert(params *valprotocol.AssertionParams, claim *valprotocol.AssertionClaim) bool {
+	return s.Assert &&
+		params.NumSteps >= s.MinAssertionSteps &&
+		claim.AssertionStub.NumGas >= s.MinAssertionGas
+}
+
+func (s *ConfigurableStrategy) ShouldConfirm() bool {
+	return s.Confirm
+}
+
+func (s *ConfigurableStrategy) ShouldPrune() bool {
+	return s.Prune
+}
+
+func (s *ConfigurableStrategy) ShouldRecoverStakes() bool {
+	return s.RecoverStakes
+}
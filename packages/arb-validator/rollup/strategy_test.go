@@ -0,0 +1,107 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+)
+
+func TestStakeLocation(t *testing.T) {
+	confirmed := NodeInfo{Hash: common.Hash{1}, Depth: 5}
+	valid := NodeInfo{Hash: common.Hash{2}, Depth: 8}
+
+	location, ok := NewAggressiveStrategy().StakeLocation(confirmed, valid)
+	if !ok || location != valid.Hash {
+		t.Error("aggressive strategy should stake on the latest valid node")
+	}
+
+	strategy := NewAggressiveStrategy()
+	strategy.StakeOnConfirmed = true
+	location, ok = strategy.StakeLocation(confirmed, valid)
+	if !ok || location != confirmed.Hash {
+		t.Error("strategy should stake on the latest confirmed node")
+	}
+
+	strategy.Stake = false
+	if _, ok := strategy.StakeLocation(confirmed, valid); ok {
+		t.Error("strategy shouldn't stake")
+	}
+}
+
+func TestShouldChallenge(t *testing.T) {
+	confirmed := NodeInfo{Depth: 10}
+	shallow := StakerInfo{LocationDepth: 12}
+	deep := StakerInfo{LocationDepth: 20}
+
+	aggressive := NewAggressiveStrategy()
+	if !aggressive.ShouldChallenge(confirmed, deep, shallow, 5) {
+		t.Error("aggressive strategy should always challenge")
+	}
+
+	defensive := NewDefensiveStrategy()
+	if !defensive.ShouldChallenge(confirmed, deep, shallow, 0) {
+		t.Error("defensive strategy should challenge when it has no open challenges")
+	}
+	if defensive.ShouldChallenge(confirmed, deep, shallow, 1) {
+		t.Error("defensive strategy should only be in one challenge at a time")
+	}
+
+	defensive.MaxChallengeDepth = 5
+	if !defensive.ShouldChallenge(confirmed, shallow, shallow, 0) {
+		t.Error("strategy should challenge stakers within the depth limit")
+	}
+	if defensive.ShouldChallenge(confirmed, deep, shallow, 0) {
+		t.Error("strategy shouldn't challenge stakers past the depth limit")
+	}
+}
+
+func TestShouldAssert(t *testing.T) {
+	params := &valprotocol.AssertionParams{NumSteps: 100}
+	claim := &valprotocol.AssertionClaim{
+		AssertionStub: &valprotocol.ExecutionAssertionStub{NumGas: 1000},
+	}
+
+	strategy := NewAggressiveStrategy()
+	if !strategy.ShouldAssert(params, claim) {
+		t.Error("aggressive strategy should assert")
+	}
+	strategy.MinAssertionSteps = 101
+	if strategy.ShouldAssert(params, claim) {
+		t.Error("strategy shouldn't make assertions with too few steps")
+	}
+	strategy.MinAssertionSteps = 0
+	strategy.MinAssertionGas = 1001
+	if strategy.ShouldAssert(params, claim) {
+		t.Error("strategy shouldn't make assertions using too little gas")
+	}
+
+	if NewDefensiveStrategy().ShouldAssert(params, claim) {
+		t.Error("defensive strategy shouldn't assert")
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	if _, err := NewStrategy("defensive"); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewStrategy("reckless"); err == nil {
+		t.Error("expected unknown strategy to fail")
+	}
+}
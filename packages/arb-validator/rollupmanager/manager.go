@@ -18,11 +18,14 @@ package rollupmanager
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/metrics"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -30,6 +33,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
 )
 
@@ -46,192 +50,285 @@ type Manager struct {
 	listenerAddChan chan rollup.ChainListener
 	actionChan      chan func(*rollup.ChainObserver)
 	ckpFac          checkpointing.RollupCheckpointerFactory
+	clock           common.Clock
+
+	cancel context.CancelFunc
+	// done is closed when the manager's thread exits, after which err holds
+	// the reason if it didn't exit because of Stop
+	done chan struct{}
+	err  error
 }
 
 const defaultMaxReorgDepth = 100
 
+var (
+	l1HeadGauge = metrics.NewRegisteredGauge("arb/validator/l1/head", nil)
+	// headLagGauge is the number of L1 blocks the chain observer has yet to
+	// process
+	headLagGauge = metrics.NewRegisteredGauge("arb/validator/chain/lag", nil)
+)
+
+// ErrManagerStopped is returned by requests to a manager which has stopped
+var ErrManagerStopped = errors.New("manager stopped")
+
 func CreateManager(
 	rollupAddr common.Address,
 	clnt arbbridge.ArbClient,
 	aoFilePath string,
 	dbPath string,
 ) (*Manager, error) {
+	ckpFac, err := checkpointing.NewIndexedCheckpointerFactory(
+		rollupAddr,
+		aoFilePath,
+		dbPath,
+		loader.DefaultVMType,
+		big.NewInt(defaultMaxReorgDepth),
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
 	return CreateManagerAdvanced(
 		context.Background(),
 		rollupAddr,
 		true,
 		clnt,
-		checkpointing.NewIndexedCheckpointerFactory(
-			rollupAddr,
-			aoFilePath,
-			dbPath,
-			big.NewInt(defaultMaxReorgDepth),
-			false,
-		),
+		ckpFac,
+		common.NewRealClock(),
 	)
 }
 
+// CreateManagerAdvanced creates a manager whose chain observer and
+// challenges wait on the given clock, so that tests can run the protocol in
+// virtual time
 func CreateManagerAdvanced(
 	ctx context.Context,
 	rollupAddr common.Address,
 	updateOpinion bool,
 	clnt arbbridge.ArbClient,
 	ckpFac checkpointing.RollupCheckpointerFactory,
+	clock common.Clock,
 ) (*Manager, error) {
+	ctx, cancelFunc := context.WithCancel(ctx)
 	man := &Manager{
 		RollupAddress:   rollupAddr,
 		client:          clnt,
 		listenerAddChan: make(chan rollup.ChainListener, 10),
 		actionChan:      make(chan func(*rollup.ChainObserver), 10),
 		ckpFac:          ckpFac,
+		clock:           clock,
+		cancel:          cancelFunc,
+		done:            make(chan struct{}),
 	}
 	go func() {
+		defer close(man.done)
 		for {
-			runCtx, cancelFunc := context.WithCancel(ctx)
+			restartDelay, err := man.runChain(ctx, updateOpinion)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println("Manager stopped with error", err)
+				man.Lock()
+				man.err = err
+				man.Unlock()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(restartDelay):
+			}
+		}
+	}()
 
-			checkpointer := man.ckpFac.New(runCtx)
+	return man, nil
+}
 
-			var chain *rollup.ChainObserver
+// runChain restores the chain observer from the latest checkpoint and feeds
+// it new blocks until it hits a problem which requires restarting it, such
+// as a reorg, returning how long to wait before the restart. It returns an
+// error if the chain can't be validated at all. The chain observer's threads
+// have all exited by the time it returns
+func (man *Manager) runChain(ctx context.Context, updateOpinion bool) (time.Duration, error) {
+	runCtx, cancelFunc := context.WithCancel(ctx)
+	var chain *rollup.ChainObserver
+	defer func() {
+		cancelFunc()
+		if chain != nil {
+			chain.Wait()
+		}
+	}()
 
-			watcher, err := clnt.NewRollupWatcher(rollupAddr)
-			if err != nil {
-				log.Fatal(err)
-			}
+	clnt := man.client
+	rollupAddr := man.RollupAddress
+	checkpointer := man.ckpFac.New(runCtx)
 
-			ethbridgeVersion, err := watcher.GetVersion(runCtx)
-			if err != nil {
-				log.Fatal(err)
-			}
+	watcher, err := clnt.NewRollupWatcher(rollupAddr)
+	if err != nil {
+		return 0, err
+	}
 
-			if ethbridgeVersion != ValidEthBridgeVersion {
-				log.Fatalf("VM has EthBridge version %v, but validator implements version %v."+
-					" To find a validator version which supports your EthBridge, visit "+
-					"https://offchainlabs.com/ethbridge-version-support",
-					ethbridgeVersion, ValidEthBridgeVersion)
-			}
+	ethbridgeVersion, err := watcher.GetVersion(runCtx)
+	if err != nil {
+		return 0, err
+	}
 
-			blockId, initialVMHash, err := watcher.GetCreationInfo(runCtx)
-			if err != nil {
-				log.Fatal(err)
-			}
+	if ethbridgeVersion != ValidEthBridgeVersion {
+		return 0, fmt.Errorf("VM has EthBridge version %v, but validator implements version %v."+
+			" To find a validator version which supports your EthBridge, visit "+
+			"https://offchainlabs.com/ethbridge-version-support",
+			ethbridgeVersion, ValidEthBridgeVersion)
+	}
 
-			initialMachine, err := checkpointer.GetInitialMachine()
-			if err != nil {
-				log.Fatal(err)
-			}
+	blockId, initialVMHash, err := watcher.GetCreationInfo(runCtx)
+	if err != nil {
+		return 0, err
+	}
 
-			if initialMachine.Hash() != initialVMHash {
-				log.Fatal("ArbChain was initialized with different VM")
-			}
+	initialMachine, err := checkpointer.GetInitialMachine()
+	if err != nil {
+		return 0, err
+	}
+
+	if initialMachine.Hash() != initialVMHash {
+		return 0, errors.New("ArbChain was initialized with different VM")
+	}
 
-			if checkpointer.HasCheckpointedState() {
-				err := checkpointer.RestoreLatestState(runCtx, clnt, func(chainObserverBytes []byte, restoreCtx checkpointing.RestoreContext) error {
-					chainObserverBuf := &rollup.ChainObserverBuf{}
-					if err := proto.Unmarshal(chainObserverBytes, chainObserverBuf); err != nil {
-						log.Fatal(err)
-					}
-					var err error
-					chain, err = chainObserverBuf.UnmarshalFromCheckpoint(runCtx, restoreCtx, checkpointer)
-					return err
-				})
-				if err != nil {
-					log.Fatal(err)
-				}
-			} else {
-				params, err := watcher.GetParams(ctx)
-				if err != nil {
-					log.Fatal(err)
-				}
-				chain, err = rollup.NewChain(rollupAddr, checkpointer, params, updateOpinion, blockId)
-				if err != nil {
-					log.Fatal(err)
-				}
+	if checkpointer.HasCheckpointedState() {
+		err := checkpointer.RestoreLatestState(runCtx, clnt, func(chainObserverBytes []byte, restoreCtx checkpointing.RestoreContext) error {
+			chainObserverBuf := &rollup.ChainObserverBuf{}
+			if err := proto.Unmarshal(chainObserverBytes, chainObserverBuf); err != nil {
+				return err
 			}
+			var err error
+			chain, err = chainObserverBuf.UnmarshalFromCheckpoint(runCtx, restoreCtx, checkpointer, man.clock)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		params, err := watcher.GetParams(runCtx)
+		if err != nil {
+			return 0, err
+		}
+		chain, err = rollup.NewChain(rollupAddr, checkpointer, params, updateOpinion, blockId, man.clock)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	log.Println("Starting validator from", chain.CurrentBlockId())
+
+	man.Lock()
+	// Clear pending listeners
+	for len(man.listenerAddChan) > 0 {
+		<-man.listenerAddChan
+	}
+	// Add manager's listeners
+	for _, listener := range man.listeners {
+		chain.AddListener(listener)
+	}
+	man.Unlock()
 
-			log.Println("Starting validator from", chain.CurrentBlockId())
+	chain.Start(runCtx)
+
+	current, err := clnt.CurrentBlockId(runCtx)
+	if err != nil {
+		log.Println("Error getting current block", err)
+		return 10 * time.Second, nil
+	}
 
-			man.Lock()
-			// Clear pending listeners
-			for len(man.listenerAddChan) > 0 {
-				<-man.listenerAddChan
+	headersChan, err := clnt.SubscribeBlockHeaders(runCtx, chain.CurrentBlockId())
+	if err != nil {
+		log.Println("Error subscribing to block headers", chain.CurrentBlockId().HeaderHash, chain.CurrentBlockId().Height.AsInt(), err)
+		return 2 * time.Second, nil
+	}
+	reachedHead := false
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, nil
+		case maybeBlockId, ok := <-headersChan:
+			if !ok {
+				log.Println("Manager stopped receiving headers")
+				// give time for things to settle, post-reorg, before restarting stuff
+				return 10 * time.Second, nil
 			}
-			// Add manager's listeners
-			for _, listener := range man.listeners {
-				chain.AddListener(listener)
+			if maybeBlockId.Err != nil {
+				log.Println("Error getting new header", maybeBlockId.Err)
+				return 10 * time.Second, nil
 			}
-			man.Unlock()
 
-			chain.Start(runCtx)
+			blockId := maybeBlockId.BlockId
+			timestamp := maybeBlockId.Timestamp
 
-			current, err := clnt.CurrentBlockId(runCtx)
-			if err != nil {
-				log.Fatal(err)
+			if blockId.Height.Cmp(current.Height) > 0 {
+				current = blockId
+			}
+			l1HeadGauge.Update(current.Height.AsInt().Int64())
+			headLagGauge.Update(new(big.Int).Sub(current.Height.AsInt(), blockId.Height.AsInt()).Int64())
+
+			if !reachedHead && blockId.Height.Cmp(current.Height) >= 0 {
+				log.Println("Reached head")
+				reachedHead = true
+				chain.NowAtHead()
+				log.Println("Now at head")
 			}
 
-			headersChan, err := clnt.SubscribeBlockHeaders(runCtx, chain.CurrentBlockId())
+			chain.NotifyNewBlock(blockId.Clone())
+			log.Print(chain.DebugString("== "))
+
+			events, err := watcher.GetEvents(runCtx, blockId, timestamp)
 			if err != nil {
-				blockId, err := clnt.BlockIdForHeight(ctx, common.NewTimeBlocks(big.NewInt(0)))
-				if err != nil {
-					panic(err)
-				}
-				log.Println("Error subscribing to block headers", chain.CurrentBlockId().HeaderHash, chain.CurrentBlockId().Height.AsInt(), blockId.HeaderHash, blockId.Height.AsInt(), err)
-
-				cancelFunc()
-				time.Sleep(2 * time.Second)
-				continue
+				log.Println("Manager hit error getting events", err)
+				return 10 * time.Second, nil
 			}
-			reachedHead := false
-		runLoop:
-			for {
-				select {
-				case maybeBlockId, ok := <-headersChan:
-					if !ok {
-						log.Println("Manager stopped receiving headers")
-						break runLoop
-					}
-					if maybeBlockId.Err != nil {
-						log.Println("Error getting new header", maybeBlockId.Err)
-						break runLoop
-					}
-
-					blockId := maybeBlockId.BlockId
-					timestamp := maybeBlockId.Timestamp
-
-					if !reachedHead && blockId.Height.Cmp(current.Height) >= 0 {
-						log.Println("Reached head")
-						reachedHead = true
-						chain.NowAtHead()
-						log.Println("Now at head")
-					}
-
-					chain.NotifyNewBlock(blockId.Clone())
-					log.Print(chain.DebugString("== "))
-
-					events, err := watcher.GetEvents(runCtx, blockId, timestamp)
-					if err != nil {
-						log.Println("Manager hit error getting events", err)
-						break runLoop
-					}
-					for _, event := range events {
-						chain.HandleNotification(runCtx, event)
-					}
-				case action := <-man.actionChan:
-					action(chain)
-				}
+			for _, event := range events {
+				chain.HandleNotification(runCtx, event)
 			}
+		case action := <-man.actionChan:
+			action(chain)
+		}
+	}
+}
+
+// Stop shuts down the chain observer, waiting for its threads to exit, then
+// writes out the latest checkpoint and closes the checkpoint database. If
+// ctx expires first the checkpointer is left open. Stop returns the error
+// which stopped the manager if it had already exited on its own
+func (man *Manager) Stop(ctx context.Context) error {
+	man.cancel()
+	select {
+	case <-man.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	closeErr := man.ckpFac.Close()
+	if err := man.Err(); err != nil {
+		return err
+	}
+	return closeErr
+}
 
-			cancelFunc()
+// Done returns a channel which is closed once the manager has stopped,
+// either because Stop was called or because it hit an unrecoverable error
+func (man *Manager) Done() <-chan struct{} {
+	return man.done
+}
 
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				time.Sleep(10 * time.Second) // give time for things to settle, post-reorg, before restarting stuff
-			}
-		}
-	}()
+// Err returns the error which stopped the manager, if any
+func (man *Manager) Err() error {
+	man.Lock()
+	defer man.Unlock()
+	return man.err
+}
 
-	return man, nil
+// IndexStorage returns the storage for indexes of the chain which is kept
+// alongside its checkpoints
+func (man *Manager) IndexStorage() checkpointing.IndexStorage {
+	return man.ckpFac.IndexStorage()
 }
 
 func (man *Manager) AddListener(listener rollup.ChainListener) {
@@ -241,15 +338,26 @@ func (man *Manager) AddListener(listener rollup.ChainListener) {
 	man.Unlock()
 }
 
-func (man *Manager) ExecuteCall(messages value.TupleValue, maxTime time.Duration) (*protocol.ExecutionAssertion, uint64) {
+// runAction queues action to run on the manager's event loop, failing if
+// the manager stops first
+func (man *Manager) runAction(action func(*rollup.ChainObserver)) error {
+	select {
+	case man.actionChan <- action:
+		return nil
+	case <-man.done:
+		return ErrManagerStopped
+	}
+}
+
+func (man *Manager) ExecuteCall(messages value.TupleValue, maxTime time.Duration) (*protocol.ExecutionAssertion, uint64, error) {
 	retChan := make(chan struct {
 		*protocol.ExecutionAssertion
 		uint64
 	}, 1)
-	man.actionChan <- func(chain *rollup.ChainObserver) {
+	err := man.runAction(func(chain *rollup.ChainObserver) {
 		mach := chain.LatestKnownValidMachine()
 		latestBlock := chain.CurrentBlockId().Height
-		latestTime := big.NewInt(time.Now().Unix())
+		latestTime := big.NewInt(man.clock.Now().Unix())
 		timeBounds := &protocol.TimeBounds{latestBlock, latestBlock, latestTime, latestTime}
 		go func() {
 			assertion, numSteps := mach.ExecuteAssertion(
@@ -264,15 +372,51 @@ func (man *Manager) ExecuteCall(messages value.TupleValue, maxTime time.Duration
 				uint64
 			}{assertion, numSteps}
 		}()
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	select {
+	case ret := <-retChan:
+		return ret.ExecutionAssertion, ret.uint64, nil
+	case <-man.done:
+		return nil, 0, ErrManagerStopped
 	}
-	ret := <-retChan
-	return ret.ExecutionAssertion, ret.uint64
 }
 
-func (man *Manager) CurrentBlockId() *common.BlockId {
+func (man *Manager) CurrentBlockId() (*common.BlockId, error) {
 	retChan := make(chan *common.BlockId, 1)
-	man.actionChan <- func(chain *rollup.ChainObserver) {
+	err := man.runAction(func(chain *rollup.ChainObserver) {
 		retChan <- chain.CurrentBlockId()
+	})
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case blockId := <-retChan:
+		return blockId, nil
+	case <-man.done:
+		return nil, ErrManagerStopped
+	}
+}
+
+// QueryChain runs query against the current chain observer and waits for it
+// to finish. The query runs on the manager's event loop, so it never sees a
+// chain observer which is being replaced after a reorg. It returns
+// ErrManagerStopped if the manager stops before the query runs
+func (man *Manager) QueryChain(query func(*rollup.ChainObserver)) error {
+	done := make(chan struct{})
+	err := man.runAction(func(chain *rollup.ChainObserver) {
+		query(chain)
+		close(done)
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-man.done:
+		return ErrManagerStopped
 	}
-	return <-retChan
 }
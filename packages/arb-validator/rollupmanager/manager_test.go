@@ -0,0 +1,132 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollupmanager
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/mockbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/valprotocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+)
+
+var contractPath = "../contract.ao"
+
+// TestValidatorConfirmsNode runs a manager and an aggressive validator
+// against a simulated L1 until the validator has staked on, asserted and
+// confirmed a node
+func TestValidatorConfirmsNode(t *testing.T) {
+	ctx := context.Background()
+	eth := mockbridge.NewMockEth()
+	validator := common.Address{5}
+	eth.AddBalance(validator, big.NewInt(100))
+	client := mockbridge.NewEthAuthClient(eth, validator)
+
+	ckpFac := checkpointing.NewDummyCheckpointerFactory(contractPath)
+	initialMachine, err := ckpFac.New(ctx).GetInitialMachine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	factoryAddress, err := client.DeployArbFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory, err := client.NewArbFactory(factoryAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollupAddress, err := factory.CreateRollup(
+		ctx,
+		initialMachine.Hash(),
+		valprotocol.ChainParams{
+			StakeRequirement:        big.NewInt(10),
+			GracePeriod:             common.TicksFromBlockNum(common.NewTimeBlocksInt(5)),
+			MaxExecutionSteps:       1000000,
+			MaxBlockBoundsWidth:     20,
+			MaxTimestampBoundsWidth: 900,
+			ArbGasSpeedLimitPerTick: 1000,
+		},
+		validator,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actor, err := client.NewRollup(rollupAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	man, err := CreateManagerAdvanced(ctx, rollupAddress, true, client, ckpFac, eth.Clock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := rollup.NewValidatorChainListener(
+		rollupAddress,
+		actor,
+		rollup.NewAggressiveStrategy(),
+		eth.Clock(),
+	)
+	if err := listener.AddStaker(client); err != nil {
+		t.Fatal(err)
+	}
+	man.AddListener(listener)
+
+	var initial rollup.NodeInfo
+	if err := man.QueryChain(func(chain *rollup.ChainObserver) {
+		initial = chain.LatestConfirmedNode()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 30)
+	for {
+		var confirmed rollup.NodeInfo
+		if err := man.QueryChain(func(chain *rollup.ChainObserver) {
+			confirmed = chain.LatestConfirmedNode()
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if confirmed.Depth > initial.Depth {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no node confirmed")
+		}
+		eth.MineBlocks(1)
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	staked, err := actor.IsStaked(validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !staked {
+		t.Error("validator isn't staked")
+	}
+
+	if err := man.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := man.QueryChain(func(*rollup.ChainObserver) {}); err != ErrManagerStopped {
+		t.Error("query after stop returned", err)
+	}
+}
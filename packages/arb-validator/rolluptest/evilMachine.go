@@ -19,7 +19,6 @@ package rolluptest
 import (
 	"time"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
@@ -29,15 +28,15 @@ import (
 // evil machine is like a regular machine, except it returns a wrong hash w/ probability 1/8, repeatably
 // this is useful for testing challenge functionality
 type EvilMachine struct {
-	*cmachine.Machine
+	machine.Machine
 }
 
-func NewEvilMachine(machine *cmachine.Machine) *EvilMachine {
-	return &EvilMachine{Machine: machine}
+func NewEvilMachine(mach machine.Machine) *EvilMachine {
+	return &EvilMachine{Machine: mach}
 }
 
 func (e EvilMachine) Clone() machine.Machine {
-	return NewEvilMachine(e.Machine.Clone().(*cmachine.Machine))
+	return NewEvilMachine(e.Machine.Clone())
 }
 
 func (e EvilMachine) Hash() common.Hash {
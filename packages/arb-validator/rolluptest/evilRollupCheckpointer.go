@@ -20,12 +20,12 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
 )
 
 type EvilRollupCheckpointerFactory struct {
@@ -38,16 +38,19 @@ func NewEvilRollupCheckpointerFactory(
 	databasePath string,
 	maxReorgDepth *big.Int,
 	forceFreshStart bool,
-) checkpointing.RollupCheckpointerFactory {
-	return &EvilRollupCheckpointerFactory{
-		checkpointing.NewIndexedCheckpointerFactory(
-			rollupAddr,
-			arbitrumCodeFilePath,
-			databasePath,
-			maxReorgDepth,
-			forceFreshStart,
-		),
+) (checkpointing.RollupCheckpointerFactory, error) {
+	fac, err := checkpointing.NewIndexedCheckpointerFactory(
+		rollupAddr,
+		arbitrumCodeFilePath,
+		databasePath,
+		loader.DefaultVMType,
+		maxReorgDepth,
+		forceFreshStart,
+	)
+	if err != nil {
+		return nil, err
 	}
+	return &EvilRollupCheckpointerFactory{fac}, nil
 }
 
 type evilRollupCheckpointer struct {
@@ -59,13 +62,21 @@ func (e evilRollupCheckpointer) GetValue(h common.Hash) value.Value {
 }
 
 func (e evilRollupCheckpointer) GetMachine(h common.Hash) machine.Machine {
-	return NewEvilMachine(e.cp.(checkpointing.RestoreContext).GetMachine(h).(*cmachine.Machine))
+	return NewEvilMachine(e.cp.(checkpointing.RestoreContext).GetMachine(h))
 }
 
 func (fac *EvilRollupCheckpointerFactory) New(ctx context.Context) checkpointing.RollupCheckpointer {
 	return &evilRollupCheckpointer{fac.fac.New(ctx).(checkpointing.RollupCheckpointer)}
 }
 
+func (fac *EvilRollupCheckpointerFactory) Close() error {
+	return fac.fac.Close()
+}
+
+func (fac *EvilRollupCheckpointerFactory) IndexStorage() checkpointing.IndexStorage {
+	return fac.fac.IndexStorage()
+}
+
 func (e evilRollupCheckpointer) HasCheckpointedState() bool {
 	return e.cp.HasCheckpointedState()
 }
@@ -93,7 +104,7 @@ func (erc *evilRestoreContext) GetValue(h common.Hash) value.Value {
 }
 
 func (erc *evilRestoreContext) GetMachine(h common.Hash) machine.Machine {
-	return NewEvilMachine(erc.rc.GetMachine(h).(*cmachine.Machine))
+	return NewEvilMachine(erc.rc.GetMachine(h))
 }
 
 func (e evilRollupCheckpointer) GetInitialMachine() (machine.Machine, error) {
@@ -101,9 +112,29 @@ func (e evilRollupCheckpointer) GetInitialMachine() (machine.Machine, error) {
 	if err != nil {
 		return m, err
 	}
-	return NewEvilMachine(m.(*cmachine.Machine)), nil
+	return NewEvilMachine(m), nil
 }
 
 func (e evilRollupCheckpointer) AsyncSaveCheckpoint(blockId *common.BlockId, contents []byte, cpCtx *checkpointing.CheckpointContext) {
 	e.cp.AsyncSaveCheckpoint(blockId, contents, cpCtx)
 }
+
+func (e evilRollupCheckpointer) SaveChallengeState(challenge common.Address, contents []byte, cpCtx *checkpointing.CheckpointContext) error {
+	return e.cp.SaveChallengeState(challenge, contents, cpCtx)
+}
+
+func (e evilRollupCheckpointer) RestoreChallengeState(
+	challenge common.Address,
+	unmarshalFunc func([]byte, checkpointing.RestoreContext) error,
+) error {
+	return e.cp.RestoreChallengeState(
+		challenge,
+		func(contents []byte, resCtx checkpointing.RestoreContext) error {
+			return unmarshalFunc(contents, &evilRestoreContext{resCtx})
+		},
+	)
+}
+
+func (e evilRollupCheckpointer) DeleteChallengeState(challenge common.Address) error {
+	return e.cp.DeleteChallengeState(challenge)
+}
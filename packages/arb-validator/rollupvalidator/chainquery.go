@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollupvalidator
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/validatorserver"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+)
+
+func nodeInfoToBuf(node rollup.NodeInfo) *validatorserver.NodeInfo {
+	return &validatorserver.NodeInfo{
+		Hash:        hexutil.Encode(node.Hash[:]),
+		PrevHash:    hexutil.Encode(node.PrevHash[:]),
+		Depth:       node.Depth,
+		ChildType:   node.ChildType.String(),
+		Deadline:    hexutil.EncodeBig(node.Deadline.Val),
+		MachineHash: hexutil.Encode(node.VMProtoData.MachineHash[:]),
+		InboxTop:    hexutil.Encode(node.VMProtoData.InboxTop[:]),
+		InboxCount:  hexutil.EncodeBig(node.VMProtoData.InboxCount),
+		StakerCount: node.NumStakers,
+		IsLeaf:      node.IsLeaf,
+	}
+}
+
+func nodeInfosToBuf(nodes []rollup.NodeInfo) []*validatorserver.NodeInfo {
+	ret := make([]*validatorserver.NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		ret = append(ret, nodeInfoToBuf(node))
+	}
+	return ret
+}
+
+// GetNodes returns every node the validator is tracking ordered by depth
+func (m *Server) GetNodes(ctx context.Context, args *validatorserver.GetNodesArgs) (*validatorserver.GetNodesReply, error) {
+	var nodes []rollup.NodeInfo
+	err := m.man.QueryChain(func(chain *rollup.ChainObserver) {
+		nodes = chain.Nodes()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &validatorserver.GetNodesReply{
+		Nodes: nodeInfosToBuf(nodes),
+	}, nil
+}
+
+// GetLeaves returns the leaves of the node graph ordered by depth
+func (m *Server) GetLeaves(ctx context.Context, args *validatorserver.GetLeavesArgs) (*validatorserver.GetLeavesReply, error) {
+	var leaves []rollup.NodeInfo
+	err := m.man.QueryChain(func(chain *rollup.ChainObserver) {
+		leaves = chain.Leaves()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &validatorserver.GetLeavesReply{
+		Leaves: nodeInfosToBuf(leaves),
+	}, nil
+}
+
+// GetStakers returns every staker along with where it is staked and the
+// challenge it is in, if any
+func (m *Server) GetStakers(ctx context.Context, args *validatorserver.GetStakersArgs) (*validatorserver.GetStakersReply, error) {
+	var stakers []rollup.StakerInfo
+	err := m.man.QueryChain(func(chain *rollup.ChainObserver) {
+		stakers = chain.Stakers()
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*validatorserver.StakerInfo, 0, len(stakers))
+	for _, staker := range stakers {
+		info := &validatorserver.StakerInfo{
+			Address:       hexutil.Encode(staker.Address[:]),
+			Location:      hexutil.Encode(staker.Location[:]),
+			LocationDepth: staker.LocationDepth,
+			CreationTime:  hexutil.EncodeBig(staker.CreationTime.Val),
+			InChallenge:   staker.InChallenge(),
+		}
+		if staker.InChallenge() {
+			info.Challenge = hexutil.Encode(staker.Challenge[:])
+		}
+		ret = append(ret, info)
+	}
+	return &validatorserver.GetStakersReply{
+		Stakers: ret,
+	}, nil
+}
+
+// GetChallenges returns every open challenge
+func (m *Server) GetChallenges(ctx context.Context, args *validatorserver.GetChallengesArgs) (*validatorserver.GetChallengesReply, error) {
+	var challenges []rollup.ChallengeInfo
+	err := m.man.QueryChain(func(chain *rollup.ChainObserver) {
+		challenges = chain.Challenges()
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*validatorserver.ChallengeInfo, 0, len(challenges))
+	for _, challenge := range challenges {
+		ret = append(ret, &validatorserver.ChallengeInfo{
+			Contract:      hexutil.Encode(challenge.Contract[:]),
+			ChallengeType: challenge.Type.String(),
+			Asserter:      hexutil.Encode(challenge.Asserter[:]),
+			Challenger:    hexutil.Encode(challenge.Challenger[:]),
+			ConflictNode:  hexutil.Encode(challenge.ConflictNode[:]),
+			BlockHash:     hexutil.Encode(challenge.BlockId.HeaderHash[:]),
+			BlockNumber:   hexutil.EncodeBig(challenge.BlockId.Height.AsInt()),
+		})
+	}
+	return &validatorserver.GetChallengesReply{
+		Challenges: ret,
+	}, nil
+}
+
+// GetChainState returns the latest confirmed node, the node the validator
+// has calculated to be valid and the latest block the validator has seen
+func (m *Server) GetChainState(ctx context.Context, args *validatorserver.GetChainStateArgs) (*validatorserver.GetChainStateReply, error) {
+	var reply *validatorserver.GetChainStateReply
+	err := m.man.QueryChain(func(chain *rollup.ChainObserver) {
+		blockId := chain.CurrentBlockId()
+		reply = &validatorserver.GetChainStateReply{
+			LatestConfirmed: nodeInfoToBuf(chain.LatestConfirmedNode()),
+			CalculatedValid: nodeInfoToBuf(chain.CalculatedValidNode()),
+			BlockHash:       hexutil.Encode(blockId.HeaderHash[:]),
+			BlockNumber:     hexutil.EncodeBig(blockId.Height.AsInt()),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
@@ -0,0 +1,555 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollupvalidator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/ethbridge/globalinbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+)
+
+// arbInfoAddress is the precompiled contract which reports balances and
+// code on the chain
+var arbInfoAddress = common.HexToAddress("0x0000000000000000000000000000000000000065")
+
+const arbInfoABIJSON = "[{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getBalance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getCode\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"o_code\",\"type\":\"bytes\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+var arbInfoABI abi.ABI
+var globalInboxABI abi.ABI
+
+func init() {
+	var err error
+	arbInfoABI, err = abi.JSON(strings.NewReader(arbInfoABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	globalInboxABI, err = abi.JSON(strings.NewReader(globalinbox.GlobalInboxABI))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// chainID is the id which the chain reports through net_version and
+// eth_chainId. Chains have no assigned id, so it is taken from the low 6
+// bytes of the rollup address. That keeps ids of different chains distinct
+// while staying below 2^53, so JavaScript tools can hold them as numbers.
+// It isn't used to check signatures, since raw transactions are signed
+// for L1
+func chainID(rollupAddress common.Address) *big.Int {
+	return new(big.Int).SetBytes(rollupAddress[14:])
+}
+
+// NewEthRPCServer serves the standard Ethereum JSON-RPC methods for the
+// chain validated by server, so that Ethereum tools can use it without an
+// Arbitrum specific provider. Block numbers and hashes are those of the L1
+// blocks which included the chain's finalized assertions. Raw transactions
+// must be signed L1 transactions calling sendTransactionMessage on the
+// chain's GlobalInbox, which are broadcast through l1. Websocket
+// connections can also subscribe to logs and receipts
+func NewEthRPCServer(
+	server *Server,
+	l1 ethereum.TransactionSender,
+	inboxAddress common.Address,
+) (http.Handler, error) {
+	s := rpc.NewServer()
+	if err := s.RegisterName("eth", &ethAPI{
+		srv:          server,
+		l1:           l1,
+		inboxAddress: inboxAddress,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.RegisterName("net", &netAPI{chainID(server.rollupAddress)}); err != nil {
+		return nil, err
+	}
+	return &ethRPCHandler{
+		http: s,
+		ws:   s.WebsocketHandler([]string{"*"}),
+	}, nil
+}
+
+// ethRPCHandler serves websocket connections alongside plain HTTP requests
+type ethRPCHandler struct {
+	http http.Handler
+	ws   http.Handler
+}
+
+func (h *ethRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		h.ws.ServeHTTP(w, r)
+		return
+	}
+	h.http.ServeHTTP(w, r)
+}
+
+type netAPI struct {
+	chainID *big.Int
+}
+
+// Version returns the chain id in decimal
+func (n *netAPI) Version() string {
+	return n.chainID.String()
+}
+
+type ethAPI struct {
+	srv          *Server
+	l1           ethereum.TransactionSender
+	inboxAddress common.Address
+}
+
+// CallArgs is the call object accepted by eth_call. Gas and value are
+// ignored since calls on the chain don't use them
+type CallArgs struct {
+	From *ethcommon.Address `json:"from"`
+	To   *ethcommon.Address `json:"to"`
+	Data hexutil.Bytes      `json:"data"`
+}
+
+// FilterArgs is the filter object accepted by eth_getLogs. Block numbers
+// and hashes refer to L1 blocks
+type FilterArgs struct {
+	BlockHash *ethcommon.Hash  `json:"blockHash"`
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`
+	Address   addressFilter    `json:"address"`
+	Topics    []topicFilter    `json:"topics"`
+}
+
+// addressFilter decodes either a single address or a list of addresses
+type addressFilter []ethcommon.Address
+
+func (f *addressFilter) UnmarshalJSON(data []byte) error {
+	var address ethcommon.Address
+	if err := json.Unmarshal(data, &address); err == nil {
+		*f = addressFilter{address}
+		return nil
+	}
+	var addresses []ethcommon.Address
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return fmt.Errorf("invalid address filter: %v", err)
+	}
+	*f = addresses
+	return nil
+}
+
+// topicFilter matches a topic equal to any of its hashes, or any topic at
+// all if it's empty. It decodes from null, a single hash or a list of hashes
+type topicFilter []ethcommon.Hash
+
+func (f *topicFilter) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = nil
+		return nil
+	}
+	var hash ethcommon.Hash
+	if err := json.Unmarshal(data, &hash); err == nil {
+		*f = topicFilter{hash}
+		return nil
+	}
+	var hashes []ethcommon.Hash
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return fmt.Errorf("invalid topic filter: %v", err)
+	}
+	*f = hashes
+	return nil
+}
+
+func (f topicFilter) matches(topic ethcommon.Hash) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, hash := range f {
+		if hash == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// logFilter converts the address and topic criteria to the tracker's filter
+func (args FilterArgs) logFilter() logFilter {
+	var filter logFilter
+	for _, address := range args.Address {
+		filter.addresses = append(filter.addresses, common.NewAddressFromEth(address))
+	}
+	for _, topics := range args.Topics {
+		alternatives := make([]common.Hash, 0, len(topics))
+		for _, topic := range topics {
+			alternatives = append(alternatives, common.Hash(topic))
+		}
+		filter.topics = append(filter.topics, alternatives)
+	}
+	return filter
+}
+
+func (args FilterArgs) matches(l *types.Log) bool {
+	if len(args.Address) > 0 {
+		found := false
+		for _, address := range args.Address {
+			if address == l.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(args.Topics) > len(l.Topics) {
+		return false
+	}
+	for i, topics := range args.Topics {
+		if !topics.matches(l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// latestHeight returns the height of the latest L1 block the validator has
+// processed
+func (a *ethAPI) latestHeight() (int64, error) {
+	blockId, err := a.srv.man.CurrentBlockId()
+	if err != nil {
+		return 0, err
+	}
+	return blockId.Height.AsInt().Int64(), nil
+}
+
+// resolveHeight returns the L1 block height num refers to, which is the
+// latest block if it's missing or is a tag such as latest or pending
+func (a *ethAPI) resolveHeight(num *rpc.BlockNumber) (int64, error) {
+	if num == nil || *num < 0 {
+		return a.latestHeight()
+	}
+	return num.Int64(), nil
+}
+
+// ChainId returns the id of the chain
+func (a *ethAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(chainID(a.srv.rollupAddress))
+}
+
+// BlockNumber returns the height of the latest L1 block the validator has
+// processed
+func (a *ethAPI) BlockNumber() (hexutil.Uint64, error) {
+	latest, err := a.latestHeight()
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(latest), nil
+}
+
+// Call executes a call against the latest state of the chain. The block
+// number is accepted for compatibility but calls always see the latest state
+func (a *ethAPI) Call(ctx context.Context, args CallArgs, blockNr *rpc.BlockNumber) (hexutil.Bytes, error) {
+	if args.To == nil {
+		return nil, errors.New("eth_call requires a destination address")
+	}
+	var from common.Address
+	if args.From != nil {
+		from = common.NewAddressFromEth(*args.From)
+	}
+	return a.call(common.NewAddressFromEth(*args.To), from, args.Data)
+}
+
+func (a *ethAPI) call(to common.Address, from common.Address, data []byte) ([]byte, error) {
+	result, _, err := a.srv.executeCall(to, from, data)
+	if err != nil {
+		return nil, err
+	}
+	return callReturnData(result, a.srv.rollupAddress)
+}
+
+func callReturnData(result value.Value, rollupAddress common.Address) ([]byte, error) {
+	processed, err := evm.ProcessLog(result, rollupAddress)
+	if err != nil {
+		return nil, err
+	}
+	switch processed := processed.(type) {
+	case evm.Return:
+		return processed.ReturnVal, nil
+	case evm.Stop:
+		return []byte{}, nil
+	case evm.Revert:
+		return nil, fmt.Errorf("call reverted with result %v", string(processed.ReturnVal))
+	default:
+		return nil, errors.New("call reverted")
+	}
+}
+
+// GetBalance returns the balance of an account on the chain
+func (a *ethAPI) GetBalance(ctx context.Context, address ethcommon.Address, blockNr *rpc.BlockNumber) (*hexutil.Big, error) {
+	data, err := arbInfoABI.Pack("getBalance", address)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := a.call(arbInfoAddress, common.Address{}, data)
+	if err != nil {
+		return nil, err
+	}
+	balance := new(big.Int)
+	if err := arbInfoABI.Unpack(&balance, "getBalance", ret); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+// GetCode returns the code of a contract on the chain
+func (a *ethAPI) GetCode(ctx context.Context, address ethcommon.Address, blockNr *rpc.BlockNumber) (hexutil.Bytes, error) {
+	data, err := arbInfoABI.Pack("getCode", address)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := a.call(arbInfoAddress, common.Address{}, data)
+	if err != nil {
+		return nil, err
+	}
+	var code []byte
+	if err := arbInfoABI.Unpack(&code, "getCode", ret); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// GetTransactionReceipt returns the receipt for a transaction on the chain,
+// or null if it isn't part of a finalized assertion yet
+func (a *ethAPI) GetTransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	info := <-a.srv.tracker.TxInfo(common.Hash(txHash))
+	if !info.Found {
+		return nil, nil
+	}
+
+	processed, err := evm.ProcessLog(info.RawVal, a.srv.rollupAddress)
+	if err != nil {
+		return nil, err
+	}
+	return ethReceipt(common.Hash(txHash), info, processed), nil
+}
+
+func ethReceipt(txHash common.Hash, info txInfo, result evm.Result) *types.Receipt {
+	status := types.ReceiptStatusFailed
+	var evmLogs []evm.Log
+	switch result := result.(type) {
+	case evm.Return:
+		status = types.ReceiptStatusSuccessful
+		evmLogs = result.Logs
+	case evm.Stop:
+		status = types.ReceiptStatusSuccessful
+		evmLogs = result.Logs
+	}
+
+	logs := make([]*types.Log, 0, len(evmLogs))
+	for i, evmLog := range evmLogs {
+		logs = append(logs, ethLog(
+			evmLog,
+			info.block,
+			txHash,
+			info.txIndex,
+			info.logIndex+i,
+		))
+	}
+
+	receipt := &types.Receipt{
+		Status:           status,
+		Logs:             logs,
+		TxHash:           txHash.ToEthHash(),
+		BlockHash:        info.block.HeaderHash.ToEthHash(),
+		BlockNumber:      info.block.Height.AsInt(),
+		TransactionIndex: uint(info.txIndex),
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt
+}
+
+// GetLogs returns the logs matching the filter from the L1 block with the
+// given hash or from the given range of L1 blocks. Missing block numbers
+// default to the latest L1 block
+func (a *ethAPI) GetLogs(ctx context.Context, args FilterArgs) ([]*types.Log, error) {
+	if args.BlockHash != nil {
+		if args.FromBlock != nil || args.ToBlock != nil {
+			return nil, errors.New("cannot specify both blockHash and fromBlock/toBlock")
+		}
+		blockHash := common.Hash(*args.BlockHash)
+		return <-a.srv.tracker.FindLogs(nil, nil, &blockHash, args.logFilter()), nil
+	}
+
+	fromHeight, err := a.resolveHeight(args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	toHeight, err := a.resolveHeight(args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	return <-a.srv.tracker.FindLogs(&fromHeight, &toHeight, nil, args.logFilter()), nil
+}
+
+// subscriptionBuffer is how many finalized assertions can be queued for a
+// subscriber before the tracker waits for it
+const subscriptionBuffer = 100
+
+// Logs streams the logs matching the filter from each assertion as it's
+// finalized. It's called through eth_subscribe and ignores the filter's
+// block range and hash
+func (a *ethAPI) Logs(ctx context.Context, args FilterArgs) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	logsChan := make(chan []*types.Log, subscriptionBuffer)
+	sub := a.srv.tracker.SubscribeLogs(logsChan)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-logsChan:
+				for _, l := range logs {
+					if args.matches(l) {
+						if err := notifier.Notify(rpcSub.ID, l); err != nil {
+							return
+						}
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// Receipts streams the receipt of each transaction as the assertion which
+// includes it is finalized. It's called through eth_subscribe
+func (a *ethAPI) Receipts(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	receiptsChan := make(chan []*types.Receipt, subscriptionBuffer)
+	sub := a.srv.tracker.SubscribeReceipts(receiptsChan)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case receipts := <-receiptsChan:
+				for _, receipt := range receipts {
+					if err := notifier.Notify(rpcSub.ID, receipt); err != nil {
+						return
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// errNotInboxTransaction is returned by eth_sendRawTransaction for anything
+// other than an L1 call to sendTransactionMessage
+var errNotInboxTransaction = errors.New(
+	"eth_sendRawTransaction only accepts L1 transactions calling sendTransactionMessage " +
+		"on the GlobalInbox; transactions signed for the chain itself are not supported",
+)
+
+// SendRawTransaction broadcasts a signed L1 transaction which calls
+// sendTransactionMessage on the chain's GlobalInbox. It returns the hash of
+// the transaction on the chain, which can be passed to
+// eth_getTransactionReceipt.
+//
+// Transactions signed for the chain itself are rejected with
+// errNotInboxTransaction rather than wrapped into an inbox message. The
+// GlobalInbox takes a message's sender from the L1 transaction delivering
+// it, so the validator couldn't deliver them as sent by their signer
+func (a *ethAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (ethcommon.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return ethcommon.Hash{}, err
+	}
+	msg, err := a.inboxTransaction(tx)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	if err := a.l1.SendTransaction(ctx, tx); err != nil {
+		return ethcommon.Hash{}, err
+	}
+	return msg.ReceiptHash().ToEthHash(), nil
+}
+
+// inboxTransaction decodes the message which an L1 transaction calling
+// sendTransactionMessage will deliver to the chain
+func (a *ethAPI) inboxTransaction(tx *types.Transaction) (message.Transaction, error) {
+	if tx.To() == nil || common.NewAddressFromEth(*tx.To()) != a.inboxAddress {
+		return message.Transaction{}, errNotInboxTransaction
+	}
+	method := globalInboxABI.Methods["sendTransactionMessage"]
+	data := tx.Data()
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID()) {
+		return message.Transaction{}, errNotInboxTransaction
+	}
+	args, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return message.Transaction{}, err
+	}
+	chain := common.NewAddressFromEth(args[0].(ethcommon.Address))
+	if chain != a.srv.rollupAddress {
+		return message.Transaction{}, fmt.Errorf("transaction is for chain %v", chain)
+	}
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return message.Transaction{}, err
+	}
+	return message.Transaction{
+		Chain:       chain,
+		To:          common.NewAddressFromEth(args[1].(ethcommon.Address)),
+		From:        common.NewAddressFromEth(from),
+		SequenceNum: args[2].(*big.Int),
+		Value:       args[3].(*big.Int),
+		Data:        args[4].([]byte),
+	}, nil
+}
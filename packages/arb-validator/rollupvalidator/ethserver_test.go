@@ -0,0 +1,213 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollupvalidator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	goarbitrum "github.com/offchainlabs/arbitrum/packages/arb-provider-go"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+)
+
+func TestFilterArgs(t *testing.T) {
+	var args FilterArgs
+	if err := json.Unmarshal([]byte(`{
+		"fromBlock": "0x1",
+		"toBlock": "latest",
+		"address": "0x1000000000000000000000000000000000000001",
+		"topics": [null, ["0x0000000000000000000000000000000000000000000000000000000000000002", "0x0000000000000000000000000000000000000000000000000000000000000003"]]
+	}`), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args.FromBlock.Int64() != 1 || args.ToBlock.Int64() >= 0 {
+		t.Error("unexpected block range", args.FromBlock, args.ToBlock)
+	}
+	if len(args.Address) != 1 || len(args.Topics) != 2 || len(args.Topics[0]) != 0 || len(args.Topics[1]) != 2 {
+		t.Fatalf("unexpected filter %+v", args)
+	}
+
+	address := ethcommon.HexToAddress("0x1000000000000000000000000000000000000001")
+	matching := &types.Log{
+		Address: address,
+		Topics:  []ethcommon.Hash{{1}, ethcommon.BigToHash(big.NewInt(3)), {4}},
+	}
+	if !args.matches(matching) {
+		t.Error("filter should match log")
+	}
+	wrongTopic := &types.Log{
+		Address: address,
+		Topics:  []ethcommon.Hash{{1}, ethcommon.BigToHash(big.NewInt(4))},
+	}
+	if args.matches(wrongTopic) {
+		t.Error("filter shouldn't match log with a different topic")
+	}
+	tooFewTopics := &types.Log{
+		Address: address,
+		Topics:  []ethcommon.Hash{{1}},
+	}
+	if args.matches(tooFewTopics) {
+		t.Error("filter shouldn't match log with too few topics")
+	}
+}
+
+func TestInboxTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollupAddress := common.Address{1}
+	inboxAddress := common.Address{2}
+	dest := common.Address{3}
+	api := &ethAPI{
+		srv:          &Server{rollupAddress: rollupAddress},
+		inboxAddress: inboxAddress,
+	}
+
+	data, err := globalInboxABI.Pack(
+		"sendTransactionMessage",
+		rollupAddress.ToEthAddress(),
+		dest.ToEthAddress(),
+		big.NewInt(7),
+		big.NewInt(100),
+		[]byte{5, 6},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1000))
+	tx, err := types.SignTx(
+		types.NewTransaction(0, inboxAddress.ToEthAddress(), big.NewInt(0), 100000, big.NewInt(1), data),
+		signer,
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := api.inboxTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Chain != rollupAddress ||
+		msg.To != dest ||
+		msg.From != common.NewAddressFromEth(crypto.PubkeyToAddress(key.PublicKey)) ||
+		msg.SequenceNum.Cmp(big.NewInt(7)) != 0 ||
+		msg.Value.Cmp(big.NewInt(100)) != 0 ||
+		!bytes.Equal(msg.Data, []byte{5, 6}) {
+		t.Errorf("unexpected message %v", msg)
+	}
+
+	wrongInbox, err := types.SignTx(
+		types.NewTransaction(0, dest.ToEthAddress(), big.NewInt(0), 100000, big.NewInt(1), data),
+		signer,
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.inboxTransaction(wrongInbox); err != errNotInboxTransaction {
+		t.Error("expected transaction to another contract to be rejected, got", err)
+	}
+
+	// A transaction signed for the chain itself calls the contract directly
+	chainTx, err := types.SignTx(
+		types.NewTransaction(7, dest.ToEthAddress(), big.NewInt(100), 100000, big.NewInt(0), []byte{5, 6}),
+		types.NewEIP155Signer(chainID(rollupAddress)),
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedTx, err := rlp.EncodeToBytes(chainTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.SendRawTransaction(context.Background(), encodedTx); err != errNotInboxTransaction {
+		t.Error("expected transaction signed for the chain to be rejected, got", err)
+	}
+}
+
+func TestProviderSubscribeFilterLogs(t *testing.T) {
+	addressA := common.Address{1}
+	addressB := common.Address{2}
+	topic := common.Hash{3}
+	txHash := common.Hash{4}
+
+	tr, err := newTxTracker(common.Address{}, checkpointing.NewMemoryIndexStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewEthRPCServer(&Server{tracker: tr}, nil, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	conn := new(goarbitrum.ArbConnection)
+	if err := conn.ConnectStream(ctx, "ws"+strings.TrimPrefix(httpServer.URL, "http")); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	logs := make(chan types.Log, 10)
+	sub, err := conn.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []ethcommon.Address{addressB.ToEthAddress()},
+	}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	tr.processFinalizedAssertion(testAssertion(12, common.Hash{1},
+		testResult(txHash, evm.StopCode, testLog(addressA, topic), testLog(addressB, topic)),
+	))
+
+	select {
+	case l := <-logs:
+		if l.Address != addressB.ToEthAddress() || l.TxHash != txHash.ToEthHash() {
+			t.Errorf("pushed wrong log %v", l)
+		}
+		if l.BlockNumber != 12 || l.Index != 1 {
+			t.Errorf("log has position %v %v", l.BlockNumber, l.Index)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("no log pushed")
+	}
+	select {
+	case l := <-logs:
+		t.Error("pushed log which doesn't match the filter", l)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
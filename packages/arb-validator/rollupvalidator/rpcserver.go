@@ -34,8 +34,12 @@ type RPCServer struct {
 func NewRPCServer(
 	man *rollupmanager.Manager,
 	maxCallTime time.Duration,
-) *RPCServer {
-	return &RPCServer{Server: NewServer(man, maxCallTime)}
+) (*RPCServer, error) {
+	server, err := NewServer(man, maxCallTime)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCServer{Server: server}, nil
 }
 
 // FindLogs takes a set of parameters and return the list of all logs that match
@@ -113,3 +117,68 @@ func (m *RPCServer) CallMessage(
 	}
 	return err
 }
+
+// GetNodes returns every node the validator is tracking
+func (m *RPCServer) GetNodes(
+	r *http.Request,
+	args *validatorserver.GetNodesArgs,
+	reply *validatorserver.GetNodesReply,
+) error {
+	ret, err := m.Server.GetNodes(context.Background(), args)
+	if ret != nil {
+		*reply = *ret
+	}
+	return err
+}
+
+// GetLeaves returns the leaves of the node graph
+func (m *RPCServer) GetLeaves(
+	r *http.Request,
+	args *validatorserver.GetLeavesArgs,
+	reply *validatorserver.GetLeavesReply,
+) error {
+	ret, err := m.Server.GetLeaves(context.Background(), args)
+	if ret != nil {
+		*reply = *ret
+	}
+	return err
+}
+
+// GetStakers returns every staker and the challenge it is in
+func (m *RPCServer) GetStakers(
+	r *http.Request,
+	args *validatorserver.GetStakersArgs,
+	reply *validatorserver.GetStakersReply,
+) error {
+	ret, err := m.Server.GetStakers(context.Background(), args)
+	if ret != nil {
+		*reply = *ret
+	}
+	return err
+}
+
+// GetChallenges returns every open challenge
+func (m *RPCServer) GetChallenges(
+	r *http.Request,
+	args *validatorserver.GetChallengesArgs,
+	reply *validatorserver.GetChallengesReply,
+) error {
+	ret, err := m.Server.GetChallenges(context.Background(), args)
+	if ret != nil {
+		*reply = *ret
+	}
+	return err
+}
+
+// GetChainState returns the latest confirmed and calculated valid nodes
+func (m *RPCServer) GetChainState(
+	r *http.Request,
+	args *validatorserver.GetChainStateArgs,
+	reply *validatorserver.GetChainStateReply,
+) error {
+	ret, err := m.Server.GetChainState(context.Background(), args)
+	if ret != nil {
+		*reply = *ret
+	}
+	return err
+}
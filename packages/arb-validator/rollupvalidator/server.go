@@ -33,6 +33,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollupmanager"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
@@ -49,60 +50,159 @@ type Server struct {
 }
 
 // NewServer returns a new instance of the Server class
-func NewServer(man *rollupmanager.Manager, maxCallTime time.Duration) *Server {
-	completedAssertionChan := make(chan rollup.FinalizedAssertion)
-	assertionListener := &rollup.AssertionListener{completedAssertionChan}
+func NewServer(man *rollupmanager.Manager, maxCallTime time.Duration) (*Server, error) {
+	tracker, err := newTxTracker(man.RollupAddress, man.IndexStorage())
+	if err != nil {
+		return nil, err
+	}
+
+	assertionListener := &rollup.AssertionListener{
+		CompletedAssertionChan: make(chan rollup.FinalizedAssertion),
+		StartedChan:            make(chan *common.BlockId),
+	}
 	man.AddListener(assertionListener)
 
-	tracker := newTxTracker(man.RollupAddress)
 	go func() {
-		tracker.handleTxResults(assertionListener.CompletedAssertionChan)
+		tracker.handleTxResults(
+			assertionListener.CompletedAssertionChan,
+			assertionListener.StartedChan,
+		)
 	}()
 
-	return &Server{man.RollupAddress, tracker, man, maxCallTime}
+	return &Server{man.RollupAddress, tracker, man, maxCallTime}, nil
+}
+
+// RollupAddress returns the address of the chain the server is for
+func (m *Server) RollupAddress() common.Address {
+	return m.rollupAddress
 }
 
 // FindLogs takes a set of parameters and return the list of all logs that match the query
 func (m *Server) FindLogs(ctx context.Context, args *validatorserver.FindLogsArgs) (*validatorserver.FindLogsReply, error) {
-	addressBytes, err := hexutil.Decode(args.Address)
+	filter, err := parseLogFilter(args)
 	if err != nil {
-		fmt.Println("FindLogs error1", err)
+		fmt.Println("FindLogs error, bad filter", err)
 		return nil, err
 	}
-	addressInt := new(big.Int).SetBytes(addressBytes[:])
 
-	topics := make([]common.Hash, 0, len(args.Topics))
-	for _, topic := range args.Topics {
-		topicBytes, err := hexutil.Decode(topic)
-		if err == nil {
-			var topic common.Hash
-			copy(topic[:], topicBytes)
-			topics = append(topics, topic)
+	var logsChan <-chan []*types.Log
+	if args.BlockHash != "" {
+		blockHashBytes, err := hexutil.Decode(args.BlockHash)
+		if err != nil {
+			fmt.Println("FindLogs error, bad blockHash", err)
+			return nil, err
+		}
+		var blockHash common.Hash
+		copy(blockHash[:], blockHashBytes)
+		logsChan = m.tracker.FindLogs(nil, nil, &blockHash, filter)
+	} else {
+		fromHeight, err := parseBlockHeight(args.FromHeight)
+		if err != nil {
+			fmt.Println("FindLogs error, bad fromHeight", err)
+			return nil, err
+		}
+		toHeight, err := parseBlockHeight(args.ToHeight)
+		if err != nil {
+			fmt.Println("FindLogs error, bad toHeight", err)
+			return nil, err
 		}
+		logsChan = m.tracker.FindLogs(fromHeight, toHeight, nil, filter)
 	}
 
-	fromHeight, err := strconv.ParseInt(args.FromHeight[2:], 16, 64)
+	logs := <-logsChan
+	ret := make([]*validatorserver.LogInfo, 0, len(logs))
+	for _, l := range logs {
+		ret = append(ret, logInfo(l))
+	}
+	return &validatorserver.FindLogsReply{
+		Logs: ret,
+	}, nil
+}
+
+func logInfo(l *types.Log) *validatorserver.LogInfo {
+	topics := make([]string, 0, len(l.Topics))
+	for _, topic := range l.Topics {
+		topics = append(topics, hexutil.Encode(topic[:]))
+	}
+	return &validatorserver.LogInfo{
+		Address:          hexutil.Encode(l.Address[:]),
+		BlockHash:        hexutil.Encode(l.BlockHash[:]),
+		BlockNumber:      hexutil.EncodeUint64(l.BlockNumber),
+		Data:             hexutil.Encode(l.Data),
+		LogIndex:         hexutil.EncodeUint64(uint64(l.Index)),
+		Topics:           topics,
+		TransactionIndex: hexutil.EncodeUint64(uint64(l.TxIndex)),
+		TransactionHash:  hexutil.Encode(l.TxHash[:]),
+	}
+}
+
+// parseBlockHeight decodes an L1 block height given in hex, returning nil
+// for an open bound
+func parseBlockHeight(height string) (*int64, error) {
+	switch height {
+	case "", "latest", "pending":
+		return nil, nil
+	case "earliest":
+		height = "0x0"
+	}
+	val, err := hexutil.DecodeUint64(height)
 	if err != nil {
-		fmt.Println("FindLogs error, bad fromHeight", err)
 		return nil, err
 	}
+	ret := int64(val)
+	return &ret, nil
+}
 
-	var logsChan <-chan []*validatorserver.LogInfo
-	if args.ToHeight == "latest" {
-		logsChan = m.tracker.FindLogs(&fromHeight, nil, addressInt, topics)
-	} else {
-		toHeight, err := strconv.ParseInt(args.ToHeight[2:], 16, 64)
+// parseLogFilter combines the address and topics fields of args with the
+// addresses and topic groups that replaced them
+func parseLogFilter(args *validatorserver.FindLogsArgs) (logFilter, error) {
+	var filter logFilter
+	addresses := args.Addresses
+	if args.Address != "" {
+		addresses = append(addresses, args.Address)
+	}
+	for _, address := range addresses {
+		addressBytes, err := hexutil.Decode(address)
 		if err != nil {
-			fmt.Println("FindLogs error4", err)
-			return nil, err
+			return filter, err
 		}
-		logsChan = m.tracker.FindLogs(&fromHeight, &toHeight, addressInt, topics)
+		var contract common.Address
+		copy(contract[:], addressBytes)
+		filter.addresses = append(filter.addresses, contract)
 	}
 
-	ret := <-logsChan
-	return &validatorserver.FindLogsReply{
-		Logs: ret,
-	}, nil
+	if len(args.TopicGroups) > 0 {
+		for _, group := range args.TopicGroups {
+			alternatives, err := parseTopics(group.Topics)
+			if err != nil {
+				return filter, err
+			}
+			filter.topics = append(filter.topics, alternatives)
+		}
+		return filter, nil
+	}
+	for _, topic := range args.Topics {
+		alternatives, err := parseTopics([]string{topic})
+		if err != nil {
+			return filter, err
+		}
+		filter.topics = append(filter.topics, alternatives)
+	}
+	return filter, nil
+}
+
+func parseTopics(topics []string) ([]common.Hash, error) {
+	hashes := make([]common.Hash, 0, len(topics))
+	for _, topic := range topics {
+		topicBytes, err := hexutil.Decode(topic)
+		if err != nil {
+			return nil, err
+		}
+		var hash common.Hash
+		copy(hash[:], topicBytes)
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
 }
 
 func (m *Server) GetOutputMessage(ctx context.Context, args *validatorserver.GetOutputMessageArgs) (*validatorserver.GetOutputMessageReply, error) {
@@ -196,38 +296,56 @@ func (m *Server) CallMessage(ctx context.Context, args *validatorserver.CallMess
 	var sender common.Address
 	copy(sender[:], senderBytes)
 
+	result, numGas, err := m.executeCall(contractAddress, sender, dataBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	_ = value.MarshalValue(result, &buf) // error can only occur from writes and bytes.Buffer is safe
+	return &validatorserver.CallMessageReply{
+		RawVal: hexutil.Encode(buf.Bytes()),
+		NumGas: numGas,
+	}, nil
+}
+
+// executeCall runs a call against the latest state of the chain without
+// changing it and returns the log the VM produced for it along with the
+// ArbGas it used
+func (m *Server) executeCall(to common.Address, from common.Address, data []byte) (value.Value, uint64, error) {
+	blockId, err := m.man.CurrentBlockId()
+	if err != nil {
+		return nil, 0, err
+	}
 	msg := message.Call{
-		To:        contractAddress,
-		From:      sender,
-		Data:      dataBytes,
-		BlockNum:  m.man.CurrentBlockId().Height,
+		To:        to,
+		From:      from,
+		Data:      data,
+		BlockNum:  blockId.Height,
 		Timestamp: big.NewInt(time.Now().Unix()),
 	}
 
 	inbox := message.AddToPrev(value.NewEmptyTuple(), msg)
-	assertion, steps := m.man.ExecuteCall(inbox, m.maxCallTime)
+	assertion, steps, err := m.man.ExecuteCall(inbox, m.maxCallTime)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	log.Println("Executed call for", steps, "steps")
 
 	results := assertion.Logs
 	if len(results) == 0 {
-		return nil, errors.New("call produced no output")
+		return nil, 0, errors.New("call produced no output")
 	}
 	lastLogVal := results[len(results)-1]
 	lastLog, err := evm.ProcessLog(lastLogVal, m.rollupAddress)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	logHash := lastLog.GetEthMsg().TxHash
 	if logHash != msg.ReceiptHash() {
 		// Last produced log is not the call we sent
-		return nil, errors.New("call took too long to execute")
+		return nil, 0, errors.New("call took too long to execute")
 	}
-
-	result := results[len(results)-1]
-	var buf bytes.Buffer
-	_ = value.MarshalValue(result, &buf) // error can only occur from writes and bytes.Buffer is safe
-	return &validatorserver.CallMessageReply{
-		RawVal: hexutil.Encode(buf.Bytes()),
-	}, nil
+	return lastLogVal, assertion.NumGas, nil
 }
@@ -17,18 +17,22 @@
 package rollupvalidator
 
 import (
+	"encoding/binary"
 	"log"
 	"math/big"
-	"strconv"
-
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/validatorserver"
+	"sort"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
 )
 
@@ -50,13 +54,63 @@ type txRequest struct {
 	resultChan chan<- txInfo
 }
 
+// findLogsRequest looks up logs from the assertions included in L1 blocks
+// fromBlock to toBlock, or in the block with hash blockHash if it's set.
+// Missing bounds leave that side of the range open
 type findLogsRequest struct {
-	fromHeight *int64
-	toHeight   *int64
-	address    *big.Int
-	topics     []common.Hash
+	fromBlock *int64
+	toBlock   *int64
+	blockHash *common.Hash
+	filter    logFilter
+
+	resultChan chan<- []*types.Log
+}
 
-	resultChan chan<- []*validatorserver.LogInfo
+// logFilter matches logs the way an Ethereum log filter does. A log matches
+// if it was emitted by any of addresses, and if each of its topics is one of
+// the alternatives given for that position. An empty address list or topic
+// position matches anything
+type logFilter struct {
+	addresses []common.Address
+	topics    [][]common.Hash
+}
+
+func (f logFilter) matches(evmLog evm.Log) bool {
+	if len(f.addresses) > 0 {
+		contractBytes := evmLog.ContractID.ToBytes()
+		var contract common.Address
+		copy(contract[:], contractBytes[12:])
+		found := false
+		for _, address := range f.addresses {
+			if address == contract {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.topics) > len(evmLog.Topics) {
+		return false
+	}
+	for i, alternatives := range f.topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if topic == evmLog.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 type logsInfo struct {
@@ -72,6 +126,13 @@ type txInfo struct {
 	LogsPostHash   string
 	LogsValHashes  []string
 	OnChainTxHash  string
+
+	// block is the L1 block which included the assertion, and txIndex and
+	// logIndex are the positions of the transaction and of its first log
+	// within that block
+	block    *common.BlockId
+	txIndex  int
+	logIndex int
 }
 
 type assertionInfo struct {
@@ -83,40 +144,45 @@ type assertionInfo struct {
 	BeforeHash        common.Hash
 	OriginalInboxHash common.Hash
 	AssertNodeHash    common.Hash
+	Block             *common.BlockId
+	// FirstTxIndex and FirstLogIndex count the transactions and logs from
+	// earlier assertions included in the same L1 block
+	FirstTxIndex  int
+	FirstLogIndex int
 }
 
 type logResponse struct {
-	Log evm.Log
-	Msg evm.EthBridgeMessage
+	Log      evm.Log
+	Msg      evm.EthBridgeMessage
+	TxIndex  int
+	LogIndex int
 }
 
-func (a *assertionInfo) FindLogs(address *big.Int, topics []common.Hash) []logResponse {
+// FindLogs returns the logs in the assertion matching filter along with
+// their positions in the L1 block which included it
+func (a *assertionInfo) FindLogs(filter logFilter) []logResponse {
 	logs := make([]logResponse, 0)
-	for _, txLogs := range a.TxLogs {
+	logIndex := a.FirstLogIndex
+	for i, txLogs := range a.TxLogs {
 		for _, evmLog := range txLogs.Logs {
-			if address != nil && !value.NewIntValue(address).Equal(evmLog.ContractID) {
-				continue
-			}
-
-			if len(topics) > len(evmLog.Topics) {
-				continue
-			}
-
-			match := true
-			for i, topic := range topics {
-				if topic != evmLog.Topics[i] {
-					match = false
-					break
-				}
-			}
-			if match {
-				logs = append(logs, logResponse{evmLog, txLogs.msg})
+			if filter.matches(evmLog) {
+				logs = append(logs, logResponse{evmLog, txLogs.msg, a.FirstTxIndex + i, logIndex})
 			}
+			logIndex++
 		}
 	}
 	return logs
 }
 
+// logCount returns the number of logs emitted by the assertion
+func (a *assertionInfo) logCount() int {
+	count := 0
+	for _, txLogs := range a.TxLogs {
+		count += len(txLogs.Logs)
+	}
+	return count
+}
+
 func newAssertionInfo() *assertionInfo {
 	return &assertionInfo{}
 }
@@ -129,13 +195,25 @@ type txTracker struct {
 	accountNonces  map[common.Address]uint64
 	vmID           common.Address
 	requests       chan validatorRequest
+
+	// store holds every finalized assertion so the index can be rebuilt
+	// after a restart
+	store checkpointing.IndexStorage
+
+	// logsFeed and receiptsFeed publish the logs and receipts from each
+	// assertion as it's finalized
+	logsFeed     event.Feed
+	receiptsFeed event.Feed
 }
 
+// newTxTracker creates a tracker which saves finalized assertions to store
+// and restores the ones which were saved there previously
 func newTxTracker(
 	vmID common.Address,
-) *txTracker {
+	store checkpointing.IndexStorage,
+) (*txTracker, error) {
 	requests := make(chan validatorRequest, 100)
-	return &txTracker{
+	tr := &txTracker{
 		txRequestIndex: 0,
 		transactions:   make(map[common.Hash]txInfo),
 		assertionInfo:  make([]*assertionInfo, 0),
@@ -143,7 +221,66 @@ func newTxTracker(
 		accountNonces:  make(map[common.Address]uint64),
 		vmID:           vmID,
 		requests:       requests,
+		store:          store,
+	}
+	if err := tr.restoreAssertions(); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func assertionKey(index int) []byte {
+	key := []byte("txtracker:assertion:")
+	var indexBytes [8]byte
+	binary.BigEndian.PutUint64(indexBytes[:], uint64(index))
+	return append(key, indexBytes[:]...)
+}
+
+// restoreAssertions reloads the assertions saved in the store, which are
+// kept at consecutive indexes
+func (tr *txTracker) restoreAssertions() error {
+	for {
+		data := tr.store.GetIndexData(assertionKey(len(tr.assertionInfo)))
+		if data == nil {
+			break
+		}
+		buf := &rollup.FinalizedAssertionBuf{}
+		if err := proto.Unmarshal(data, buf); err != nil {
+			return err
+		}
+		assertion, err := buf.Unmarshal()
+		if err != nil {
+			return err
+		}
+		tr.addAssertion(assertion)
 	}
+	if len(tr.assertionInfo) > 0 {
+		log.Println("Restored", len(tr.assertionInfo), "finalized assertions")
+	}
+	return nil
+}
+
+// rollbackAssertions removes the assertions from L1 blocks after block,
+// which may no longer be part of the L1 chain
+func (tr *txTracker) rollbackAssertions(block *common.BlockId) {
+	newLength := len(tr.assertionInfo)
+	for newLength > 0 && tr.assertionInfo[newLength-1].Block.Height.Cmp(block.Height) > 0 {
+		newLength--
+		if err := tr.store.DeleteIndexData(assertionKey(newLength)); err != nil {
+			log.Println("Error deleting saved assertion", err)
+		}
+		delete(tr.assertionMap, tr.assertionInfo[newLength].AssertNodeHash)
+	}
+	if newLength == len(tr.assertionInfo) {
+		return
+	}
+	log.Println("Rolling back", len(tr.assertionInfo)-newLength, "assertions after block", block.Height)
+	for txHash, tx := range tr.transactions {
+		if tx.assertionIndex >= newLength {
+			delete(tr.transactions, txHash)
+		}
+	}
+	tr.assertionInfo = tr.assertionInfo[:newLength]
 }
 
 func (tr *txTracker) AssertionCount() <-chan int {
@@ -164,18 +301,63 @@ func (tr *txTracker) TxInfo(txHash common.Hash) <-chan txInfo {
 	return req
 }
 
+// FindLogs returns the logs matching filter from the assertions included in
+// the given range of L1 blocks, or in the L1 block with hash blockHash if it
+// isn't nil
 func (tr *txTracker) FindLogs(
-	fromHeight *int64,
-	toHeight *int64,
-	address *big.Int,
-	topics []common.Hash,
-) <-chan []*validatorserver.LogInfo {
-	req := make(chan []*validatorserver.LogInfo, 1)
-	tr.requests <- findLogsRequest{fromHeight, toHeight, address, topics, req}
+	fromBlock *int64,
+	toBlock *int64,
+	blockHash *common.Hash,
+	filter logFilter,
+) <-chan []*types.Log {
+	req := make(chan []*types.Log, 1)
+	tr.requests <- findLogsRequest{fromBlock, toBlock, blockHash, filter, req}
 	return req
 }
 
+// SubscribeLogs sends the logs from each finalized assertion which has any
+// to ch until the subscription is cancelled
+func (tr *txTracker) SubscribeLogs(ch chan<- []*types.Log) event.Subscription {
+	return tr.logsFeed.Subscribe(ch)
+}
+
+// SubscribeReceipts sends the receipts of the transactions in each
+// finalized assertion to ch until the subscription is cancelled
+func (tr *txTracker) SubscribeReceipts(ch chan<- []*types.Receipt) event.Subscription {
+	return tr.receiptsFeed.Subscribe(ch)
+}
+
 func (tr *txTracker) processFinalizedAssertion(assertion rollup.FinalizedAssertion) {
+	// The assertion will be delivered again if the chain is restored from a
+	// checkpoint before it was confirmed
+	if _, ok := tr.assertionMap[assertion.NodeHash]; ok {
+		return
+	}
+
+	data, err := proto.Marshal(assertion.MarshalToBuf())
+	if err != nil {
+		log.Println("Error marshalling finalized assertion", err)
+		return
+	}
+	if err := tr.store.SaveIndexData(assertionKey(len(tr.assertionInfo)), data); err != nil {
+		log.Println("Error saving finalized assertion", err)
+		return
+	}
+
+	receipts := tr.addAssertion(assertion)
+
+	newLogs := assertionLogs(tr.assertionInfo[len(tr.assertionInfo)-1], logFilter{})
+	if len(newLogs) > 0 {
+		tr.logsFeed.Send(newLogs)
+	}
+	if len(receipts) > 0 {
+		tr.receiptsFeed.Send(receipts)
+	}
+}
+
+// addAssertion indexes the transactions and logs of assertion and returns
+// the receipts of its transactions
+func (tr *txTracker) addAssertion(assertion rollup.FinalizedAssertion) []*types.Receipt {
 	info := newAssertionInfo()
 
 	zero := common.Hash{}
@@ -187,10 +369,20 @@ func (tr *txTracker) processFinalizedAssertion(assertion rollup.FinalizedAsserti
 
 	info.OutMessages = assertion.Assertion.OutMsgs
 	info.AssertNodeHash = assertion.NodeHash
+	info.Block = assertion.Block
+	if len(tr.assertionInfo) > 0 {
+		prev := tr.assertionInfo[len(tr.assertionInfo)-1]
+		if prev.Block.HeaderHash == info.Block.HeaderHash {
+			info.FirstTxIndex = prev.FirstTxIndex + len(prev.TxLogs)
+			info.FirstLogIndex = prev.FirstLogIndex + prev.logCount()
+		}
+	}
 	info.LogsValHashes = make([]string, 0, len(logs))
 	info.LogsAccHashes = make([]string, 0, len(logs))
 
 	acc := common.Hash{}
+	logCount := 0
+	receipts := make([]*types.Receipt, 0, len(logs))
 	for _, logsVal := range logs {
 		logsValHash := logsVal.Hash()
 		info.LogsValHashes = append(info.LogsValHashes,
@@ -224,6 +416,9 @@ func (tr *txTracker) processFinalizedAssertion(assertion rollup.FinalizedAsserti
 			LogsPostHash:   logsPostHash,
 			LogsValHashes:  logsValHashes,
 			OnChainTxHash:  disputableTxHash,
+			block:          assertion.Block,
+			txIndex:        info.FirstTxIndex + len(info.TxLogs),
+			logIndex:       info.FirstLogIndex + logCount,
 		}
 
 		evmVal, err := evm.ProcessLog(logVal, tr.vmID)
@@ -231,21 +426,28 @@ func (tr *txTracker) processFinalizedAssertion(assertion rollup.FinalizedAsserti
 			log.Printf("VM produced invalid evm result: %v\n", err)
 			continue
 		}
+		// Every result gets a receipt so it takes a transaction index even
+		// if it failed and emitted no logs
+		var evmLogs []evm.Log
 		switch evmVal := evmVal.(type) {
 		case evm.Stop:
-			info.TxLogs = append(info.TxLogs, logsInfo{evmVal.Msg, evmVal.Logs})
+			evmLogs = evmVal.Logs
 		case evm.Return:
-			info.TxLogs = append(info.TxLogs, logsInfo{evmVal.Msg, evmVal.Logs})
+			evmLogs = evmVal.Logs
 		case evm.Revert:
 			log.Printf("*********** evm.Revert occurred with message \"%v\"\n", string(evmVal.ReturnVal))
 		}
 
 		msg := evmVal.GetEthMsg()
+		info.TxLogs = append(info.TxLogs, logsInfo{msg, evmLogs})
+		logCount += len(evmLogs)
 		log.Println("Coordinator got response for", hexutil.Encode(msg.TxHash[:]))
 		tr.transactions[msg.TxHash] = txInfo
+		receipts = append(receipts, ethReceipt(msg.TxHash, txInfo, evmVal))
 	}
 	tr.assertionInfo = append(tr.assertionInfo, info)
 	tr.assertionMap[info.AssertNodeHash] = info
+	return receipts
 }
 
 func (tr *txTracker) processRequest(request validatorRequest) {
@@ -268,54 +470,92 @@ func (tr *txTracker) processRequest(request validatorRequest) {
 			request.resultChan <- txInfo{Found: false}
 		}
 	case findLogsRequest:
-		startHeight := int64(0)
-		endHeight := int64(len(tr.assertionInfo))
-		if request.fromHeight != nil && *request.fromHeight > int64(0) {
-			startHeight = *request.fromHeight
-		}
-		if request.toHeight != nil {
-			altEndHeight := *request.toHeight + 1
-			if endHeight > altEndHeight {
-				endHeight = altEndHeight
+		request.resultChan <- tr.findLogs(request)
+	}
+}
+
+// blockAssertions returns the assertions included in the requested L1 blocks
+func (tr *txTracker) blockAssertions(request findLogsRequest) []*assertionInfo {
+	assertions := make([]*assertionInfo, 0)
+	if request.blockHash != nil {
+		for _, assertion := range tr.assertionInfo {
+			if assertion.Block.HeaderHash == *request.blockHash {
+				assertions = append(assertions, assertion)
 			}
 		}
-		logs := make([]*validatorserver.LogInfo, 0)
-		if startHeight >= int64(len(tr.assertionInfo)) {
-			request.resultChan <- logs
+		return assertions
+	}
+
+	// Assertions are finalized in order so their blocks never decrease
+	start := 0
+	if request.fromBlock != nil {
+		from := big.NewInt(*request.fromBlock)
+		start = sort.Search(len(tr.assertionInfo), func(i int) bool {
+			return tr.assertionInfo[i].Block.Height.AsInt().Cmp(from) >= 0
+		})
+	}
+	for _, assertion := range tr.assertionInfo[start:] {
+		if request.toBlock != nil && assertion.Block.Height.AsInt().Cmp(big.NewInt(*request.toBlock)) > 0 {
 			break
 		}
-		assertions := tr.assertionInfo[startHeight:endHeight]
-
-		for i, assertion := range assertions {
-			assertionLogs := assertion.FindLogs(request.address, request.topics)
-			for j, evmLog := range assertionLogs {
-				addressBytes := evmLog.Log.ContractID.ToBytes()
-				topicStrings := make([]string, 0, len(evmLog.Log.Topics))
-				for _, topic := range evmLog.Log.Topics {
-					topicStrings = append(topicStrings, hexutil.Encode(topic[:]))
-				}
-
-				logs = append(logs, &validatorserver.LogInfo{
-					Address:          hexutil.Encode(addressBytes[12:]),
-					BlockHash:        hexutil.Encode(evmLog.Msg.TxHash[:]),
-					BlockNumber:      "0x" + strconv.FormatInt(startHeight+int64(i), 16),
-					Data:             hexutil.Encode(evmLog.Log.Data[:]),
-					LogIndex:         "0x" + strconv.FormatInt(int64(j), 16),
-					Topics:           topicStrings,
-					TransactionIndex: "0x0",
-					TransactionHash:  hexutil.Encode(evmLog.Msg.TxHash[:]),
-				})
-			}
-		}
-		request.resultChan <- logs
+		assertions = append(assertions, assertion)
+	}
+	return assertions
+}
+
+func (tr *txTracker) findLogs(request findLogsRequest) []*types.Log {
+	logs := make([]*types.Log, 0)
+	for _, assertion := range tr.blockAssertions(request) {
+		logs = append(logs, assertionLogs(assertion, request.filter)...)
+	}
+	return logs
+}
+
+// assertionLogs returns the logs in assertion matching filter in the form
+// used by the Ethereum JSON-RPC interface
+func assertionLogs(assertion *assertionInfo, filter logFilter) []*types.Log {
+	found := assertion.FindLogs(filter)
+	logs := make([]*types.Log, 0, len(found))
+	for _, l := range found {
+		logs = append(logs, ethLog(l.Log, assertion.Block, l.Msg.TxHash, l.TxIndex, l.LogIndex))
+	}
+	return logs
+}
+
+func ethLog(
+	evmLog evm.Log,
+	block *common.BlockId,
+	txHash common.Hash,
+	txIndex int,
+	logIndex int,
+) *types.Log {
+	addressBytes := evmLog.ContractID.ToBytes()
+	topics := make([]ethcommon.Hash, 0, len(evmLog.Topics))
+	for _, topic := range evmLog.Topics {
+		topics = append(topics, topic.ToEthHash())
+	}
+	return &types.Log{
+		Address:     ethcommon.BytesToAddress(addressBytes[12:]),
+		Topics:      topics,
+		Data:        evmLog.Data,
+		BlockNumber: block.Height.AsInt().Uint64(),
+		TxHash:      txHash.ToEthHash(),
+		TxIndex:     uint(txIndex),
+		BlockHash:   block.HeaderHash.ToEthHash(),
+		Index:       uint(logIndex),
 	}
 }
 
-func (tr *txTracker) handleTxResults(completedCalls chan rollup.FinalizedAssertion) {
+func (tr *txTracker) handleTxResults(
+	completedCalls chan rollup.FinalizedAssertion,
+	startedChain chan *common.BlockId,
+) {
 	for {
 		select {
 		case finalizedAssertion := <-completedCalls:
 			tr.processFinalizedAssertion(finalizedAssertion)
+		case block := <-startedChain:
+			tr.rollbackAssertions(block)
 		case request := <-tr.requests:
 			tr.processRequest(request)
 		}
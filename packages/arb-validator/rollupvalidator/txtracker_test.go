@@ -0,0 +1,321 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rollupvalidator
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/validatorserver"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/checkpointing"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+)
+
+func testLog(address common.Address, topics ...common.Hash) evm.Log {
+	return evm.Log{
+		ContractID: value.NewIntValue(new(big.Int).SetBytes(address[:])),
+		Topics:     topics,
+	}
+}
+
+// testResult encodes the result of a call with the given transaction hash in
+// the form the VM logs it
+func testResult(txHash common.Hash, returnCode int64, logs ...evm.Log) value.Value {
+	call, _ := value.NewTupleFromSlice([]value.Value{
+		value.NewInt64Value(int64(message.CallType)),
+		value.NewInt64Value(0),
+		value.NewTuple2(value.NewInt64Value(0), message.BytesToByteStack(nil)),
+	})
+	msg, _ := value.NewTupleFromSlice([]value.Value{
+		value.NewInt64Value(0),
+		value.NewInt64Value(0),
+		value.NewIntValue(new(big.Int).SetBytes(txHash[:])),
+		call,
+	})
+	// Logs are popped off the stack in reverse
+	logStack := value.NewEmptyTuple()
+	for i := len(logs) - 1; i >= 0; i-- {
+		logVals := []value.Value{logs[i].ContractID, message.BytesToByteStack(logs[i].Data)}
+		for _, topic := range logs[i].Topics {
+			logVals = append(logVals, value.NewIntValue(new(big.Int).SetBytes(topic[:])))
+		}
+		logVal, _ := value.NewTupleFromSlice(logVals)
+		logStack = value.NewTuple2(logStack, logVal)
+	}
+	result, _ := value.NewTupleFromSlice([]value.Value{
+		msg,
+		logStack,
+		message.BytesToByteStack(nil),
+		value.NewInt64Value(returnCode),
+	})
+	return result
+}
+
+func testAssertion(height int64, nodeHash common.Hash, results ...value.Value) rollup.FinalizedAssertion {
+	return rollup.FinalizedAssertion{
+		Assertion: &protocol.ExecutionAssertion{Logs: results},
+		NodeHash:  nodeHash,
+		Block: &common.BlockId{
+			Height:     common.NewTimeBlocksInt(height),
+			HeaderHash: common.Hash{byte(height)},
+		},
+	}
+}
+
+func TestFindLogs(t *testing.T) {
+	addressA := common.Address{1}
+	addressB := common.Address{2}
+	addressC := common.Address{3}
+	topicA := common.Hash{4}
+	topicB := common.Hash{5}
+
+	tr, err := newTxTracker(common.Address{}, checkpointing.NewMemoryIndexStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, height := range []int64{10, 12, 12, 15} {
+		tr.assertionInfo = append(tr.assertionInfo, &assertionInfo{
+			TxLogs: []logsInfo{{
+				msg: evm.EthBridgeMessage{TxHash: common.Hash{byte(i)}},
+				Logs: []evm.Log{
+					testLog(addressA, topicA, topicB),
+					testLog(addressB, topicB),
+					testLog(addressC, topicA),
+				},
+			}},
+			Block: &common.BlockId{
+				Height:     common.NewTimeBlocksInt(height),
+				HeaderHash: common.Hash{byte(height)},
+			},
+		})
+	}
+
+	from := int64(11)
+	to := int64(12)
+	logs := tr.findLogs(findLogsRequest{fromBlock: &from, toBlock: &to})
+	if len(logs) != 6 {
+		t.Fatalf("expected 6 logs from blocks 11 to 12, got %v", len(logs))
+	}
+	for _, l := range logs {
+		if l.BlockNumber != 12 {
+			t.Errorf("log from block %v outside of range", l.BlockNumber)
+		}
+	}
+
+	blockHash := common.Hash{15}
+	logs = tr.findLogs(findLogsRequest{blockHash: &blockHash})
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logs from block 15, got %v", len(logs))
+	}
+
+	logs = tr.findLogs(findLogsRequest{
+		filter: logFilter{addresses: []common.Address{addressA, addressB}},
+	})
+	if len(logs) != 8 {
+		t.Errorf("expected 8 logs from either address, got %v", len(logs))
+	}
+
+	logs = tr.findLogs(findLogsRequest{
+		filter: logFilter{topics: [][]common.Hash{{topicA, topicB}}},
+	})
+	if len(logs) != 12 {
+		t.Errorf("expected 12 logs with either first topic, got %v", len(logs))
+	}
+
+	logs = tr.findLogs(findLogsRequest{
+		filter: logFilter{topics: [][]common.Hash{nil, {topicB}}},
+	})
+	if len(logs) != 4 {
+		t.Fatalf("expected 4 logs with wildcard first topic, got %v", len(logs))
+	}
+	if logs[0].Index != 0 || logs[0].TxIndex != 0 {
+		t.Errorf("unexpected log position %v %v", logs[0].Index, logs[0].TxIndex)
+	}
+}
+
+func TestRestoreAssertions(t *testing.T) {
+	store := checkpointing.NewMemoryIndexStorage()
+	tr, err := newTxTracker(common.Address{}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reverted := common.Hash{20}
+	stopped := common.Hash{21}
+	assertions := make([]rollup.FinalizedAssertion, 0)
+	for i, height := range []int64{10, 12} {
+		assertions = append(assertions, rollup.FinalizedAssertion{
+			Assertion: &protocol.ExecutionAssertion{
+				OutMsgs: []value.Value{value.NewInt64Value(int64(i))},
+			},
+			NodeHash: common.Hash{byte(i)},
+			Block: &common.BlockId{
+				Height:     common.NewTimeBlocksInt(height),
+				HeaderHash: common.Hash{byte(height)},
+			},
+		})
+	}
+	assertions[1].Assertion.Logs = []value.Value{
+		testResult(reverted, evm.RevertCode),
+		testResult(stopped, evm.StopCode, testLog(common.Address{1}, common.Hash{2})),
+	}
+	for _, assertion := range assertions {
+		tr.processFinalizedAssertion(assertion)
+	}
+	tr.processFinalizedAssertion(assertions[1])
+	if len(tr.assertionInfo) != 2 {
+		t.Fatalf("expected 2 assertions, got %v", len(tr.assertionInfo))
+	}
+
+	restored, err := newTxTracker(common.Address{}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.assertionInfo) != 2 {
+		t.Fatalf("expected 2 restored assertions, got %v", len(restored.assertionInfo))
+	}
+	msg, ok := restored.assertionMap[common.Hash{1}]
+	if !ok || !msg.OutMessages[0].Equal(value.NewInt64Value(1)) {
+		t.Error("restored assertion has the wrong messages")
+	}
+	// The reverted transaction still takes up a transaction index
+	if tx := restored.transactions[reverted]; !tx.Found || tx.txIndex != 0 {
+		t.Errorf("reverted transaction restored at index %v", tx.txIndex)
+	}
+	if tx := restored.transactions[stopped]; !tx.Found || tx.txIndex != 1 || tx.logIndex != 0 {
+		t.Errorf("transaction after revert restored at index %v with log index %v", tx.txIndex, tx.logIndex)
+	}
+
+	restored.rollbackAssertions(&common.BlockId{Height: common.NewTimeBlocksInt(11)})
+	if len(restored.assertionInfo) != 1 {
+		t.Fatalf("expected 1 assertion after rollback, got %v", len(restored.assertionInfo))
+	}
+	if _, ok := restored.assertionMap[common.Hash{1}]; ok {
+		t.Error("rolled back assertion still indexed")
+	}
+
+	restored, err = newTxTracker(common.Address{}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.assertionInfo) != 1 {
+		t.Errorf("expected 1 restored assertion after rollback, got %v", len(restored.assertionInfo))
+	}
+}
+
+func TestFindLogsMatchesGetLogs(t *testing.T) {
+	addressA := common.Address{1}
+	addressB := common.Address{2}
+	topic := common.Hash{3}
+	tx1 := common.Hash{4}
+	tx2 := common.Hash{5}
+	tx3 := common.Hash{6}
+
+	tr, err := newTxTracker(common.Address{}, checkpointing.NewMemoryIndexStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first two assertions are included in the same L1 block, so the
+	// second one's positions follow on from the first's
+	tr.processFinalizedAssertion(testAssertion(12, common.Hash{1},
+		testResult(tx1, evm.StopCode, testLog(addressA, topic), testLog(addressB, topic)),
+	))
+	tr.processFinalizedAssertion(testAssertion(12, common.Hash{2},
+		testResult(tx2, evm.ReturnCode, testLog(addressB, topic)),
+	))
+	tr.processFinalizedAssertion(testAssertion(15, common.Hash{3},
+		testResult(tx3, evm.StopCode, testLog(addressB, topic)),
+	))
+	go tr.handleTxResults(nil, nil)
+
+	srv := &Server{tracker: tr}
+	api := &ethAPI{srv: srv}
+	ctx := context.Background()
+	blockHash := common.Hash{12}
+
+	reply, err := srv.FindLogs(ctx, &validatorserver.FindLogsArgs{
+		BlockHash: hexutil.Encode(blockHash[:]),
+		Addresses: []string{hexutil.Encode(addressB[:])},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethBlockHash := blockHash.ToEthHash()
+	ethLogs, err := api.GetLogs(ctx, FilterArgs{
+		BlockHash: &ethBlockHash,
+		Address:   addressFilter{addressB.ToEthAddress()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Logs) != 2 || len(ethLogs) != 2 {
+		t.Fatalf("expected 2 logs from each call, got %v and %v", len(reply.Logs), len(ethLogs))
+	}
+	for i, l := range ethLogs {
+		if !reflect.DeepEqual(reply.Logs[i], logInfo(l)) {
+			t.Errorf("log %v differs between calls: %v and %v", i, reply.Logs[i], l)
+		}
+	}
+	found := reply.Logs[1]
+	if found.BlockNumber != "0xc" || found.BlockHash != hexutil.Encode(blockHash[:]) {
+		t.Errorf("log has L1 block %v %v", found.BlockNumber, found.BlockHash)
+	}
+	if found.TransactionIndex != "0x1" || found.LogIndex != "0x2" || found.TransactionHash != hexutil.Encode(tx2[:]) {
+		t.Errorf("log has position %v %v in %v", found.TransactionIndex, found.LogIndex, found.TransactionHash)
+	}
+
+	receipt, err := api.GetTransactionReceipt(ctx, tx2.ToEthHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.BlockNumber.Int64() != 12 || receipt.BlockHash != ethBlockHash || receipt.TransactionIndex != 1 {
+		t.Errorf("receipt has position %v %v %v", receipt.BlockNumber, receipt.BlockHash, receipt.TransactionIndex)
+	}
+	if len(receipt.Logs) != 1 || !reflect.DeepEqual(receipt.Logs[0], ethLogs[1]) {
+		t.Errorf("receipt logs %v differ from %v", receipt.Logs, ethLogs[1])
+	}
+
+	reply, err = srv.FindLogs(ctx, &validatorserver.FindLogsArgs{
+		FromHeight: "0xd",
+		ToHeight:   "0xf",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := rpc.BlockNumber(13)
+	to := rpc.BlockNumber(15)
+	ethLogs, err = api.GetLogs(ctx, FilterArgs{FromBlock: &from, ToBlock: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Logs) != 1 || len(ethLogs) != 1 || !reflect.DeepEqual(reply.Logs[0], logInfo(ethLogs[0])) {
+		t.Errorf("expected the same log from blocks 13 to 15, got %v and %v", reply.Logs, ethLogs)
+	}
+	if ethLogs[0].BlockNumber != 15 || ethLogs[0].TxHash != tx3.ToEthHash() {
+		t.Errorf("log from wrong transaction %v", ethLogs[0])
+	}
+}
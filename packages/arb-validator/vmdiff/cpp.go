@@ -0,0 +1,52 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/loader"
+)
+
+// CppLoader runs programs on the C++ AVM, which can only load programs from
+// a file. It fails in builds without cgo
+func CppLoader(program *goloader.Program) (machine.Machine, error) {
+	f, err := ioutil.TempFile("", "vmdiff-*.ao")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if err := program.Marshal(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return loader.LoadMachineFromFile(f.Name(), false, "cpp")
+}
+
+// NewGoCppDiffer compares the Go AVM against the C++ AVM
+func NewGoCppDiffer() *Differ {
+	return NewDiffer(
+		Implementation{Name: "go", Load: GoLoader},
+		Implementation{Name: "cpp", Load: CppLoader},
+	)
+}
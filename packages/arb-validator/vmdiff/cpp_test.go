@@ -0,0 +1,54 @@
+//go:build cgo
+// +build cgo
+
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func checkGoCpp(t *testing.T, d *Differ, tc *TestCase) {
+	div, err := d.Check(tc)
+	if err != nil {
+		t.Fatalf("generated invalid program %v: %v", tc.Source(), err)
+	}
+	if div != nil {
+		minimized, minimizedDiv := d.Minimize(tc, div)
+		t.Fatalf("go and cpp machines diverged on %v: %v", minimized.Source(), minimizedDiv)
+	}
+}
+
+func TestGoAndCppMachinesAgree(t *testing.T) {
+	d := NewGoCppDiffer()
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		checkGoCpp(t, d, Generate(rng, DefaultGeneratorConfig))
+	}
+}
+
+func FuzzGoAndCppMachines(f *testing.F) {
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed)
+	}
+	d := NewGoCppDiffer()
+	f.Fuzz(func(t *testing.T, seed int64) {
+		checkGoCpp(t, d, Generate(rand.New(rand.NewSource(seed)), DefaultGeneratorConfig))
+	})
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// Loader creates a machine running program
+type Loader func(program *goloader.Program) (machine.Machine, error)
+
+// GoLoader runs programs on the Go AVM
+func GoLoader(program *goloader.Program) (machine.Machine, error) {
+	return program.NewMachine(false), nil
+}
+
+type Implementation struct {
+	Name string
+	Load Loader
+}
+
+// Differ compares two machine implementations
+type Differ struct {
+	Implementations [2]Implementation
+	MaxSteps        uint64
+	TimeBounds      *protocol.TimeBounds
+}
+
+func NewDiffer(first, second Implementation) *Differ {
+	return &Differ{
+		Implementations: [2]Implementation{first, second},
+		MaxSteps:        1000,
+		TimeBounds: &protocol.TimeBounds{
+			LowerBoundBlock:     common.NewTimeBlocksInt(10),
+			UpperBoundBlock:     common.NewTimeBlocksInt(20),
+			LowerBoundTimestamp: big.NewInt(1000),
+			UpperBoundTimestamp: big.NewInt(2000),
+		},
+	}
+}
+
+// Divergence describes the first point at which two machines disagreed
+type Divergence struct {
+	// Step is the number of steps both machines ran before disagreeing
+	Step uint64
+	// Reason names the property which differed
+	Reason string
+	// Values are the differing values from each machine
+	Values [2]string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("machines differ in %v after %v steps: %v vs %v", d.Reason, d.Step, d.Values[0], d.Values[1])
+}
+
+// stepResult is everything compared after a machine runs a step
+type stepResult struct {
+	assertion *protocol.ExecutionAssertion
+	steps     uint64
+	hash      common.Hash
+	status    machine.Status
+	proof     []byte
+	proofErr  error
+	// panicked records a panic inside the implementation, which is itself
+	// reported as a divergence from a machine which didn't panic
+	panicked interface{}
+}
+
+func snapshot(mach machine.Machine, res *stepResult) {
+	res.hash = mach.Hash()
+	res.status = mach.CurrentStatus()
+	// Proofs only exist for machines which can still run
+	if res.status == machine.Extensive {
+		res.proof, res.proofErr = mach.MarshalForProof()
+	}
+}
+
+func runStep(mach machine.Machine, timeBounds *protocol.TimeBounds, inbox value.TupleValue) (res stepResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			res.panicked = r
+		}
+	}()
+	res.assertion, res.steps = mach.ExecuteAssertion(1, timeBounds, inbox, 0)
+	snapshot(mach, &res)
+	return res
+}
+
+func initialState(mach machine.Machine) (res stepResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			res.panicked = r
+		}
+	}()
+	snapshot(mach, &res)
+	return res
+}
+
+func compare(step uint64, a, b stepResult) *Divergence {
+	diff := func(reason string, x, y interface{}) *Divergence {
+		return &Divergence{
+			Step:   step,
+			Reason: reason,
+			Values: [2]string{fmt.Sprint(x), fmt.Sprint(y)},
+		}
+	}
+	switch {
+	case a.panicked != nil || b.panicked != nil:
+		if a.panicked != nil && b.panicked != nil {
+			// Neither machine can be compared further
+			return nil
+		}
+		return diff("panic", a.panicked, b.panicked)
+	case a.steps != b.steps:
+		return diff("steps", a.steps, b.steps)
+	case a.assertion != nil && b.assertion != nil && !a.assertion.Equals(b.assertion):
+		return diff("assertion", a.assertion, b.assertion)
+	case a.hash != b.hash:
+		return diff("hash", a.hash, b.hash)
+	case a.status != b.status:
+		return diff("status", a.status, b.status)
+	case (a.proofErr == nil) != (b.proofErr == nil):
+		return diff("proof error", a.proofErr, b.proofErr)
+	case !bytes.Equal(a.proof, b.proof):
+		return diff("proof", fmt.Sprintf("%x", a.proof), fmt.Sprintf("%x", b.proof))
+	default:
+		return nil
+	}
+}
+
+// Check runs the test case on both implementations one step at a time and
+// returns the first divergence between them, or nil if they agree until
+// both stop running
+func (d *Differ) Check(tc *TestCase) (*Divergence, error) {
+	program, err := tc.Program()
+	if err != nil {
+		return nil, err
+	}
+	return d.CheckProgram(program)
+}
+
+// CheckProgram is like Check but runs an assembled program with the inbox
+// stored in it by TestCase.Program
+func (d *Differ) CheckProgram(program *goloader.Program) (*Divergence, error) {
+	inbox, err := ProgramInbox(program)
+	if err != nil {
+		return nil, err
+	}
+	var machines [2]machine.Machine
+	for i, impl := range d.Implementations {
+		machines[i], err = impl.Load(program)
+		if err != nil {
+			return nil, fmt.Errorf("%v failed to load program: %v", impl.Name, err)
+		}
+	}
+
+	if div := compare(0, initialState(machines[0]), initialState(machines[1])); div != nil {
+		return div, nil
+	}
+	for step := uint64(0); step < d.MaxSteps; step++ {
+		a := runStep(machines[0], d.TimeBounds, inbox)
+		b := runStep(machines[1], d.TimeBounds, inbox)
+		if div := compare(step, a, b); div != nil {
+			return div, nil
+		}
+		if a.panicked != nil || a.steps == 0 {
+			break
+		}
+		if a.assertion.DidInboxInsn {
+			inbox = value.NewEmptyTuple()
+		}
+	}
+	return nil, nil
+}
@@ -1,3 +1,6 @@
+//go:build gofuzz && cgo
+// +build gofuzz,cgo
+
 /*
  * Copyright 2020, Offchain Labs, Inc.
  *
@@ -14,20 +17,27 @@
  * limitations under the License.
  */
 
-package mockbridge
+package vmdiff
 
 import (
-	"context"
-
-	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
-
-	"github.com/offchainlabs/arbitrum/packages/arb-validator-core/arbbridge"
+	"encoding/binary"
+	"math/rand"
 )
 
-type ClientConnection struct {
-	Client arbbridge.ArbClient
-}
-
-func (c *ClientConnection) CurrentBlockId(ctx context.Context) (*common.BlockId, error) {
-	return c.Client.CurrentBlockId(ctx)
+// Fuzz is the entry point for go-fuzz. The input seeds the program
+// generator, and programs on which the Go and C++ machines diverge crash
+func Fuzz(data []byte) int {
+	if len(data) < 8 {
+		return -1
+	}
+	seed := int64(binary.BigEndian.Uint64(data))
+	tc := Generate(rand.New(rand.NewSource(seed)), DefaultGeneratorConfig)
+	div, err := NewGoCppDiffer().Check(tc)
+	if err != nil {
+		panic(err)
+	}
+	if div != nil {
+		panic(div.String() + "\n" + tc.Source())
+	}
+	return 1
 }
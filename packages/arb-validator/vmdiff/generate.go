@@ -0,0 +1,167 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/code"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// GeneratorConfig controls the shape of generated test cases
+type GeneratorConfig struct {
+	// MaxCode is the maximum number of instructions in a program
+	MaxCode int
+	// MaxMessages is the maximum number of messages in the inbox
+	MaxMessages int
+	// MaxDepth is the maximum nesting of generated tuples
+	MaxDepth int
+}
+
+var DefaultGeneratorConfig = GeneratorConfig{
+	MaxCode:     64,
+	MaxMessages: 3,
+	MaxDepth:    3,
+}
+
+// opcodeNames lists every instruction in a fixed order so that generation
+// is reproducible from a seed
+var opcodeNames []string
+
+func init() {
+	for _, name := range code.InstructionNames {
+		opcodeNames = append(opcodeNames, name)
+	}
+	sort.Strings(opcodeNames)
+}
+
+// Integers which commonly expose arithmetic edge cases
+var interestingInts = []*big.Int{
+	big.NewInt(0),
+	big.NewInt(1),
+	big.NewInt(2),
+	big.NewInt(31),
+	big.NewInt(32),
+	new(big.Int).Lsh(big.NewInt(1), 255),
+	new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)),
+	new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+}
+
+type generator struct {
+	rng    *rand.Rand
+	config GeneratorConfig
+	// codeLen is the length of the program being generated
+	codeLen int
+}
+
+// Generate creates a random test case. Programs are valid AO code but are
+// free to fail at run time, which exercises the error handling of the
+// machines as much as the instructions themselves
+func Generate(rng *rand.Rand, config GeneratorConfig) *TestCase {
+	g := &generator{
+		rng:     rng,
+		config:  config,
+		codeLen: 1 + rng.Intn(config.MaxCode),
+	}
+	tc := &TestCase{
+		Static: g.value(-1, config.MaxDepth),
+		Code:   make([]string, 0, g.codeLen),
+	}
+	for pc := 0; pc < g.codeLen; pc++ {
+		tc.Code = append(tc.Code, g.instruction(pc))
+	}
+	for i := rng.Intn(config.MaxMessages + 1); i > 0; i-- {
+		tc.Inbox = append(tc.Inbox, g.message())
+	}
+	return tc
+}
+
+func (g *generator) instruction(pc int) string {
+	// Pushing values often keeps the following instructions from failing
+	// immediately with an empty stack
+	if g.rng.Intn(3) == 0 {
+		return "nop " + g.value(pc, g.config.MaxDepth)
+	}
+	name := opcodeNames[g.rng.Intn(len(opcodeNames))]
+	switch name {
+	case "jump", "cjump", "errset":
+		if pc+1 < g.codeLen && g.rng.Intn(4) != 0 {
+			return fmt.Sprintf("%v @%v", name, pc+1+g.rng.Intn(g.codeLen-pc-1))
+		}
+	case "inbox":
+		// A timeout of 0 has already passed so the machine receives
+		// whatever is in its inbox instead of waiting for messages
+		if g.rng.Intn(2) == 0 {
+			return "inbox 0"
+		}
+	}
+	if g.rng.Intn(3) == 0 {
+		return name + " " + g.value(pc, g.config.MaxDepth)
+	}
+	return name
+}
+
+// value generates a value in assembly syntax. Code points may only refer to
+// instructions after pc
+func (g *generator) value(pc int, depth int) string {
+	choice := g.rng.Intn(10)
+	switch {
+	case choice < 5 || depth == 0:
+		return g.intValue()
+	case choice < 8:
+		size := g.rng.Intn(value.MaxTupleSize + 1)
+		elems := make([]string, 0, size)
+		for i := 0; i < size; i++ {
+			elems = append(elems, g.value(pc, depth-1))
+		}
+		return "(" + strings.Join(elems, ", ") + ")"
+	default:
+		if pc+1 < g.codeLen {
+			return fmt.Sprintf("@%v", pc+1+g.rng.Intn(g.codeLen-pc-1))
+		}
+		return g.intValue()
+	}
+}
+
+func (g *generator) intValue() string {
+	switch g.rng.Intn(3) {
+	case 0:
+		return fmt.Sprint(g.rng.Intn(16))
+	case 1:
+		return "0x" + interestingInts[g.rng.Intn(len(interestingInts))].Text(16)
+	default:
+		data := make([]byte, 1+g.rng.Intn(32))
+		g.rng.Read(data)
+		return "0x" + new(big.Int).SetBytes(data).Text(16)
+	}
+}
+
+func (g *generator) message() value.Value {
+	var buf []value.Value
+	for i := g.rng.Intn(4); i > 0; i-- {
+		data := make([]byte, g.rng.Intn(33))
+		g.rng.Read(data)
+		buf = append(buf, value.NewIntValue(new(big.Int).SetBytes(data)))
+	}
+	tup, _ := value.NewTupleFromSlice(buf)
+	return tup
+}
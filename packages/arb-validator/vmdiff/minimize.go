@@ -0,0 +1,104 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"strings"
+)
+
+// Minimize shrinks a test case on which the machines diverge while keeping
+// the same kind of divergence. It returns the smallest case found along with
+// its divergence
+func (d *Differ) Minimize(tc *TestCase, div *Divergence) (*TestCase, *Divergence) {
+	best, bestDiv := tc.Clone(), div
+	try := func(candidate *TestCase) bool {
+		candidateDiv, err := d.Check(candidate)
+		// Candidates which fail to assemble, usually because they refer to
+		// removed instructions, are skipped
+		if err != nil || candidateDiv == nil || candidateDiv.Reason != div.Reason {
+			return false
+		}
+		best, bestDiv = candidate, candidateDiv
+		return true
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		// Drop instructions after the divergence since they never ran
+		for len(best.Code) > 1 {
+			candidate := best.Clone()
+			candidate.Code = candidate.Code[:len(candidate.Code)-1]
+			if !try(candidate) {
+				break
+			}
+			progress = true
+		}
+
+		// Replace runs of instructions with nops, halving the run length
+		// each time no run can be replaced
+		for chunk := len(best.Code); chunk > 0; chunk /= 2 {
+			for start := 0; start < len(best.Code); start += chunk {
+				end := start + chunk
+				if end > len(best.Code) {
+					end = len(best.Code)
+				}
+				candidate := best.Clone()
+				changed := false
+				for i := start; i < end; i++ {
+					if candidate.Code[i] != "nop" {
+						candidate.Code[i] = "nop"
+						changed = true
+					}
+				}
+				if changed && try(candidate) {
+					progress = true
+				}
+			}
+		}
+
+		// Remove immediate values from the remaining instructions
+		for i, insn := range best.Code {
+			fields := strings.SplitN(insn, " ", 2)
+			if len(fields) < 2 {
+				continue
+			}
+			candidate := best.Clone()
+			candidate.Code[i] = fields[0]
+			if try(candidate) {
+				progress = true
+			}
+		}
+
+		for i := len(best.Inbox) - 1; i >= 0; i-- {
+			candidate := best.Clone()
+			candidate.Inbox = append(candidate.Inbox[:i], candidate.Inbox[i+1:]...)
+			if try(candidate) {
+				progress = true
+			}
+		}
+
+		if best.Static != "()" {
+			candidate := best.Clone()
+			candidate.Static = "()"
+			if try(candidate) {
+				progress = true
+			}
+		}
+	}
+	return best, bestDiv
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vmdiff runs AVM programs on two machine implementations side by
+// side and reports the first step at which they disagree. It generates
+// random programs, shrinks programs on which the machines diverge and saves
+// them as AO files which can be replayed as regression tests
+package vmdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/asm"
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// InboxExtensionID is the AO extension used by regression files to store
+// the inbox the program runs with. Machines ignore extensions so the files
+// can be loaded by either implementation
+const InboxExtensionID uint32 = 100
+
+// TestCase is a program written in the assembly syntax of the asm package
+// together with the messages delivered to it
+type TestCase struct {
+	// Static is the static value of the program
+	Static string
+	// Code holds one instruction per entry
+	Code  []string
+	Inbox []value.Value
+}
+
+func (tc *TestCase) Clone() *TestCase {
+	return &TestCase{
+		Static: tc.Static,
+		Code:   append([]string{}, tc.Code...),
+		Inbox:  append([]value.Value{}, tc.Inbox...),
+	}
+}
+
+// Source returns the assembly source of the program
+func (tc *TestCase) Source() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ".static %v\n", tc.Static)
+	for _, insn := range tc.Code {
+		sb.WriteString(insn)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// InboxValue returns the inbox tuple containing the test case's messages
+func (tc *TestCase) InboxValue() value.TupleValue {
+	inbox := value.NewEmptyTuple()
+	for _, msg := range tc.Inbox {
+		inbox = value.NewTuple2(inbox, msg)
+	}
+	return inbox
+}
+
+// Program assembles the test case into a program which includes its inbox
+func (tc *TestCase) Program() (*goloader.Program, error) {
+	program, err := asm.Assemble(strings.NewReader(tc.Source()))
+	if err != nil {
+		return nil, err
+	}
+	program.Extensions = append(program.Extensions, goloader.RawExtension{
+		ID:   InboxExtensionID,
+		Data: value.MarshalValueToBytes(tc.InboxValue()),
+	})
+	return program, nil
+}
+
+// ProgramInbox returns the inbox stored in a program created by
+// TestCase.Program, or an empty inbox if there isn't one
+func ProgramInbox(program *goloader.Program) (value.TupleValue, error) {
+	for _, ext := range program.Extensions {
+		if ext.ID != InboxExtensionID {
+			continue
+		}
+		val, err := value.UnmarshalValueFromBytes(ext.Data)
+		if err != nil {
+			return value.TupleValue{}, err
+		}
+		inbox, ok := val.(value.TupleValue)
+		if !ok {
+			return value.TupleValue{}, errors.New("inbox extension doesn't contain a tuple")
+		}
+		return inbox, nil
+	}
+	return value.NewEmptyTuple(), nil
+}
+
+// WriteRegression saves the program of a divergent test case into dir as an
+// AO file along with its assembly source. Files are named by the hash of the
+// program so saving the same case twice overwrites it
+func WriteRegression(dir string, tc *TestCase, div *Divergence) (string, error) {
+	program, err := tc.Program()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := program.Marshal(&buf); err != nil {
+		return "", err
+	}
+	name := hashing.SoliditySHA3(buf.Bytes()).String()[2:18]
+	path := filepath.Join(dir, name+".ao")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "; %v\n", div)
+	for _, msg := range tc.Inbox {
+		fmt.Fprintf(&source, "; inbox message %v\n", msg)
+	}
+	if err := asm.Disassemble(&source, program); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".s"), source.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
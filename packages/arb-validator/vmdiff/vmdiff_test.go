@@ -0,0 +1,138 @@
+/*
+ * Copyright 2020, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmdiff
+
+import (
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-go/goloader"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// brokenMachine logs every value twice
+type brokenMachine struct {
+	machine.Machine
+}
+
+func (m brokenMachine) ExecuteAssertion(
+	maxSteps uint64,
+	timeBounds *protocol.TimeBounds,
+	inbox value.TupleValue,
+	maxWallTime time.Duration,
+) (*protocol.ExecutionAssertion, uint64) {
+	assertion, steps := m.Machine.ExecuteAssertion(maxSteps, timeBounds, inbox, maxWallTime)
+	assertion.Logs = append(assertion.Logs, assertion.Logs...)
+	return assertion, steps
+}
+
+func brokenLoader(program *goloader.Program) (machine.Machine, error) {
+	return brokenMachine{program.NewMachine(false)}, nil
+}
+
+func TestGoMachinesAgree(t *testing.T) {
+	d := NewDiffer(Implementation{"go", GoLoader}, Implementation{"go", GoLoader})
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		tc := Generate(rng, DefaultGeneratorConfig)
+		div, err := d.Check(tc)
+		if err != nil {
+			t.Fatalf("generated invalid program %v: %v", tc.Source(), err)
+		}
+		if div != nil {
+			t.Fatalf("identical machines diverged on %v: %v", tc.Source(), div)
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	d := NewDiffer(Implementation{"go", GoLoader}, Implementation{"broken", brokenLoader})
+	tc := &TestCase{
+		Static: "(1, 2, 3)",
+		Code: []string{
+			"nop 5",
+			"nop (1, 2)",
+			"pop",
+			"jump @5",
+			"halt",
+			"nop 7",
+			"log",
+			"nop 3",
+			"add",
+			"halt",
+		},
+		Inbox: []value.Value{value.NewInt64Value(4)},
+	}
+	div, err := d.Check(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if div == nil || div.Reason != "assertion" {
+		t.Fatalf("expected an assertion divergence but got %v", div)
+	}
+
+	minimized, minimizedDiv := d.Minimize(tc, div)
+	if minimizedDiv.Reason != div.Reason {
+		t.Errorf("minimizing changed the divergence to %v", minimizedDiv)
+	}
+	if len(minimized.Code) != 7 {
+		t.Errorf("expected code after the log to be removed but got %v", minimized.Source())
+	}
+	for i, insn := range minimized.Code[:5] {
+		if insn != "nop" {
+			t.Errorf("expected instruction %v to be removed but got %v", i, insn)
+		}
+	}
+	if len(minimized.Inbox) != 0 || minimized.Static != "()" {
+		t.Errorf("expected inbox and static to be removed but got %v and %v", minimized.Inbox, minimized.Static)
+	}
+
+	dir, err := ioutil.TempDir("", "vmdiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	minimized.Inbox = []value.Value{value.NewIntValue(big.NewInt(9))}
+	path, err := WriteRegression(dir, minimized, minimizedDiv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := goloader.ReadProgramFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := ProgramInbox(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !value.Eq(inbox, minimized.InboxValue()) {
+		t.Errorf("regression file has inbox %v instead of %v", inbox, minimized.InboxValue())
+	}
+	replayDiv, err := d.CheckProgram(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayDiv == nil || replayDiv.Reason != div.Reason {
+		t.Errorf("replaying the regression file gave %v", replayDiv)
+	}
+}